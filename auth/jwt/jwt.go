@@ -1,10 +1,16 @@
 package jwt
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/patiphanak/league-of-quiz/config"
 	models "github.com/patiphanak/league-of-quiz/model"
 )
@@ -14,9 +20,18 @@ var (
 	ErrExpiredToken = errors.New("token expired")
 )
 
+const (
+	// AccessTokenTTL อายุของ access token ต้องต่ออายุด้วย refresh token เมื่อหมดอายุ
+	AccessTokenTTL = 15 * time.Minute
+	// RefreshTokenTTL อายุของ refresh token แต่ละตัว
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
 // Claims โครงสร้าง claims สำหรับ JWT
+// RegisteredClaims.ID (jti) ระบุ token แต่ละตัว ส่วน SessionID ระบุ session ที่ออก token นี้
 type Claims struct {
-	UserID uint `json:"user_id"`
+	UserID    uint `json:"user_id"`
+	SessionID uint `json:"sid"`
 	jwt.RegisteredClaims
 }
 
@@ -32,13 +47,15 @@ func NewJWTService(config *config.Config) *JWTService {
 	}
 }
 
-// GenerateToken สร้าง JWT token สำหรับผู้ใช้
-func (s *JWTService) GenerateToken(user *models.User) (string, error) {
+// GenerateToken สร้าง JWT access token สำหรับผู้ใช้ใน session ที่กำหนด
+func (s *JWTService) GenerateToken(user *models.User, sessionID uint) (string, error) {
 	// สร้าง claims
 	claims := &Claims{
-		UserID: user.ID,
+		UserID:    user.ID,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)), // หมดอายุใน 24 ชั่วโมง
+			ID:        uuid.New().String(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
@@ -82,3 +99,21 @@ func (s *JWTService) ValidateToken(tokenString string) (*Claims, error) {
 
 	return claims, nil
 }
+
+// GenerateRefreshToken สร้าง refresh token แบบสุ่ม คืนค่า token สำหรับส่งให้ client และ hash สำหรับเก็บในฐานข้อมูล
+func (s *JWTService) GenerateRefreshToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, s.HashRefreshToken(token), nil
+}
+
+// HashRefreshToken คำนวณ hash ของ refresh token ด้วย HMAC-SHA256 และ JWTRefreshSecret
+// ข้อมูลในฐานข้อมูลเพียงอย่างเดียวจึงไม่พอที่จะปลอม token ได้
+func (s *JWTService) HashRefreshToken(token string) string {
+	mac := hmac.New(sha256.New, []byte(s.config.JWTRefreshSecret))
+	mac.Write([]byte(token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,159 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// devCodeTTL อายุของ authorization code และ access token ของ DevProvider
+const devCodeTTL = 5 * time.Minute
+
+var ErrInvalidDevCode = errors.New("invalid or expired authorization code")
+
+// DevIdentity ผู้ใช้ทดสอบที่เลือกได้จากหน้าเข้าสู่ระบบของ DevProvider
+type DevIdentity struct {
+	Email string
+	Name  string
+}
+
+// DefaultDevIdentities ผู้ใช้ทดสอบที่แสดงในหน้าเข้าสู่ระบบ
+var DefaultDevIdentities = []DevIdentity{
+	{Email: "student@example.test", Name: "Sam Student"},
+	{Email: "teacher@example.test", Name: "Tina Teacher"},
+	{Email: "admin@example.test", Name: "Ada Admin"},
+}
+
+// devGrant ข้อมูลผู้ใช้ที่ผูกกับ code หรือ access token ที่ออกให้
+type devGrant struct {
+	info      UserInfo
+	expiresAt time.Time
+}
+
+// DevProvider implementation ของ Provider interface สำหรับพัฒนาและทดสอบแบบไม่ต้องใช้เครือข่าย
+// แทนที่จะ redirect ไป provider จริง จะ redirect ไปหน้าเลือกผู้ใช้ทดสอบของ server เอง
+// ห้ามเปิดใช้ใน production เพราะใครก็เข้าสู่ระบบเป็นใครก็ได้
+type DevProvider struct {
+	baseURL string
+
+	mu     sync.Mutex
+	codes  map[string]devGrant
+	tokens map[string]devGrant
+}
+
+// NewDevProvider สร้าง DevProvider baseURL คือ URL ของ backend เช่น http://localhost:3000
+func NewDevProvider(baseURL string) *DevProvider {
+	return &DevProvider{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		codes:   make(map[string]devGrant),
+		tokens:  make(map[string]devGrant),
+	}
+}
+
+// GetAuthURL implements Provider
+func (p *DevProvider) GetAuthURL(state string) string {
+	return fmt.Sprintf("%s/auth/dev/authorize?state=%s", p.baseURL, url.QueryEscape(state))
+}
+
+// Authorize ออก authorization code ให้ผู้ใช้ทดสอบที่เลือก และคืนค่า URL ของ callback
+func (p *DevProvider) Authorize(identity DevIdentity, state string) (string, error) {
+	email := strings.ToLower(strings.TrimSpace(identity.Email))
+	if email == "" || !strings.Contains(email, "@") {
+		return "", errors.New("a valid email is required")
+	}
+	name := strings.TrimSpace(identity.Name)
+	if name == "" {
+		name = strings.Split(email, "@")[0]
+	}
+
+	code, err := randomDevToken()
+	if err != nil {
+		return "", err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.prune(time.Now())
+	p.codes[code] = devGrant{
+		info: UserInfo{
+			ID:            "dev|" + email,
+			Email:         email,
+			Name:          name,
+			EmailVerified: true,
+		},
+		expiresAt: time.Now().Add(devCodeTTL),
+	}
+
+	params := url.Values{}
+	params.Set("code", code)
+	params.Set("state", state)
+	return fmt.Sprintf("%s/auth/dev/callback?%s", p.baseURL, params.Encode()), nil
+}
+
+// Exchange implements Provider
+func (p *DevProvider) Exchange(ctx context.Context, code string) (*Token, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	grant, ok := p.codes[code]
+	delete(p.codes, code)
+	if !ok || time.Now().After(grant.expiresAt) {
+		return nil, ErrInvalidDevCode
+	}
+
+	accessToken, err := randomDevToken()
+	if err != nil {
+		return nil, err
+	}
+	grant.expiresAt = time.Now().Add(devCodeTTL)
+	p.tokens[accessToken] = grant
+
+	return &Token{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(devCodeTTL.Seconds()),
+	}, nil
+}
+
+// GetUserInfo implements Provider
+func (p *DevProvider) GetUserInfo(ctx context.Context, token *Token) (*UserInfo, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	grant, ok := p.tokens[token.AccessToken]
+	delete(p.tokens, token.AccessToken)
+	if !ok || time.Now().After(grant.expiresAt) {
+		return nil, errors.New("invalid or expired access token")
+	}
+
+	info := grant.info
+	return &info, nil
+}
+
+// prune ลบ code และ token ที่หมดอายุ ต้องถือ lock อยู่แล้ว
+func (p *DevProvider) prune(now time.Time) {
+	for code, grant := range p.codes {
+		if now.After(grant.expiresAt) {
+			delete(p.codes, code)
+		}
+	}
+	for token, grant := range p.tokens {
+		if now.After(grant.expiresAt) {
+			delete(p.tokens, token)
+		}
+	}
+}
+
+func randomDevToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
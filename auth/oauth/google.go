@@ -125,5 +125,7 @@ func (g *GoogleOAuth) GetUserInfo(ctx context.Context, token *Token) (*UserInfo,
 		Email:   userInfo.Email,
 		Name:    userInfo.Name,
 		Picture: userInfo.Picture,
+
+		EmailVerified: userInfo.EmailVerified,
 	}, nil
 }
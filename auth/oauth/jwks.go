@@ -0,0 +1,172 @@
+package oauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// jwksRefreshInterval ระยะห่างขั้นต่ำระหว่างการดึง JWKS ใหม่เมื่อพบ kid ที่ไม่รู้จัก
+const jwksRefreshInterval = time.Minute
+
+var ErrUnknownSigningKey = errors.New("unknown signing key")
+
+// jsonWebKey กุญแจสาธารณะหนึ่งตัวใน JWKS (รองรับ RSA และ EC)
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// jwksCache เก็บกุญแจสาธารณะของ provider และดึงใหม่เมื่อ provider หมุนกุญแจ
+type jwksCache struct {
+	url    string
+	client *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+func newJWKSCache(url string, client *http.Client) *jwksCache {
+	return &jwksCache{url: url, client: client, keys: make(map[string]crypto.PublicKey)}
+}
+
+// getKey ดึงกุญแจตาม kid ถ้าไม่พบจะดึง JWKS ใหม่ (ไม่บ่อยกว่า jwksRefreshInterval)
+func (c *jwksCache) getKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if key, ok := c.lookup(kid); ok {
+		return key, nil
+	}
+	if !c.fetchedAt.IsZero() && time.Since(c.fetchedAt) < jwksRefreshInterval {
+		return nil, ErrUnknownSigningKey
+	}
+	if err := c.fetch(ctx); err != nil {
+		return nil, err
+	}
+	if key, ok := c.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, ErrUnknownSigningKey
+}
+
+// lookup หา kid ใน cache ถ้า token ไม่ระบุ kid และ JWKS มีกุญแจเดียวให้ใช้กุญแจนั้น
+func (c *jwksCache) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(c.keys) == 1 {
+		for _, key := range c.keys {
+			return key, true
+		}
+	}
+	key, ok := c.keys[kid]
+	return key, ok
+}
+
+func (c *jwksCache) fetch(ctx context.Context) error {
+	c.fetchedAt = time.Now()
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := getJSON(ctx, c.client, c.url, &set); err != nil {
+		return fmt.Errorf("fetching JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			// ข้ามกุญแจชนิดที่ไม่รองรับ
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	c.keys = keys
+	return nil
+}
+
+// publicKey แปลง JWK เป็นกุญแจสาธารณะของ Go
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+// getJSON ส่ง GET request และแปลง response เป็น JSON
+func getJSON(ctx context.Context, client *http.Client, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,234 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/patiphanak/league-of-quiz/config"
+)
+
+// oidcDiscovery ข้อมูลจาก /.well-known/openid-configuration ที่ต้องใช้
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// idTokenClaims claims ใน ID token ที่ใช้ระบุตัวผู้ใช้
+type idTokenClaims struct {
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+	Picture       string `json:"picture"`
+	AuthorizedBy  string `json:"azp"`
+	jwt.RegisteredClaims
+}
+
+// OIDCProvider implementation ของ Provider interface สำหรับ OpenID Connect provider ทั่วไป
+// ตั้งค่า endpoint จาก discovery metadata และตรวจ ID token กับ JWKS ของ provider
+type OIDCProvider struct {
+	config    config.OIDCProviderConfig
+	discovery oidcDiscovery
+	jwks      *jwksCache
+	client    *http.Client
+}
+
+// NewOIDCProvider ดึง discovery metadata ของ issuer และสร้าง OIDCProvider
+func NewOIDCProvider(ctx context.Context, cfg config.OIDCProviderConfig) (*OIDCProvider, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	issuer := strings.TrimSuffix(cfg.Issuer, "/")
+	var discovery oidcDiscovery
+	if err := getJSON(ctx, client, issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, fmt.Errorf("fetching discovery metadata for %s: %w", cfg.Name, err)
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("discovery issuer %q does not match configured issuer %q", discovery.Issuer, cfg.Issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, fmt.Errorf("discovery metadata for %s is missing required endpoints", cfg.Name)
+	}
+
+	return &OIDCProvider{
+		config:    cfg,
+		discovery: discovery,
+		jwks:      newJWKSCache(discovery.JWKSURI, client),
+		client:    client,
+	}, nil
+}
+
+// GetAuthURL implements Provider
+func (p *OIDCProvider) GetAuthURL(state string) string {
+	scopes := p.config.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "profile", "email"}
+	}
+
+	params := url.Values{}
+	params.Add("client_id", p.config.ClientID)
+	params.Add("redirect_uri", p.config.RedirectURL)
+	params.Add("response_type", "code")
+	params.Add("scope", strings.Join(scopes, " "))
+	params.Add("state", state)
+
+	separator := "?"
+	if strings.Contains(p.discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return p.discovery.AuthorizationEndpoint + separator + params.Encode()
+}
+
+// Exchange implements Provider
+func (p *OIDCProvider) Exchange(ctx context.Context, code string) (*Token, error) {
+	data := url.Values{}
+	data.Set("code", code)
+	data.Set("client_id", p.config.ClientID)
+	data.Set("client_secret", p.config.ClientSecret)
+	data.Set("redirect_uri", p.config.RedirectURL)
+	data.Set("grant_type", "authorization_code")
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.discovery.TokenEndpoint, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("creating token request: %w", err)
+	}
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Add("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("exchanging code for token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading token response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token response error: %s", body)
+	}
+
+	var token Token
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("parsing token: %w", err)
+	}
+	if token.IDToken == "" {
+		return nil, errors.New("token response has no id_token")
+	}
+
+	return &token, nil
+}
+
+// GetUserInfo implements Provider
+// ข้อมูลผู้ใช้มาจาก ID token ที่ตรวจลายเซ็นแล้ว ถ้าไม่มีอีเมลหรือชื่อจะเติมจาก userinfo endpoint
+func (p *OIDCProvider) GetUserInfo(ctx context.Context, token *Token) (*UserInfo, error) {
+	claims, err := p.verifyIDToken(ctx, token.IDToken)
+	if err != nil {
+		return nil, fmt.Errorf("verifying id token: %w", err)
+	}
+
+	info := &UserInfo{
+		ID:            claims.Subject,
+		Email:         claims.Email,
+		Name:          claims.Name,
+		Picture:       claims.Picture,
+		EmailVerified: claims.EmailVerified,
+	}
+
+	if (info.Email == "" || info.Name == "") && p.discovery.UserInfoEndpoint != "" {
+		if err := p.fillFromUserInfo(ctx, token, info); err != nil {
+			return nil, err
+		}
+	}
+
+	return info, nil
+}
+
+// verifyIDToken ตรวจลายเซ็น issuer audience และวันหมดอายุของ ID token
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, rawToken string) (*idTokenClaims, error) {
+	claims := &idTokenClaims{}
+	_, err := jwt.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.jwks.getKey(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(p.discovery.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Subject == "" {
+		return nil, errors.New("id token has no subject")
+	}
+	// ถ้ามีหลาย audience ต้องระบุว่าออกให้ client นี้
+	if len(claims.Audience) > 1 && claims.AuthorizedBy != p.config.ClientID {
+		return nil, errors.New("id token was not issued for this client")
+	}
+	return claims, nil
+}
+
+// fillFromUserInfo เติมข้อมูลผู้ใช้ที่ขาดจาก userinfo endpoint โดย subject ต้องตรงกับ ID token
+func (p *OIDCProvider) fillFromUserInfo(ctx context.Context, token *Token, info *UserInfo) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.discovery.UserInfoEndpoint, nil)
+	if err != nil {
+		return fmt.Errorf("creating user info request: %w", err)
+	}
+	req.Header.Add("Authorization", "Bearer "+token.AccessToken)
+	req.Header.Add("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("getting user info: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading user info response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("user info response error: %s", body)
+	}
+
+	var userInfo struct {
+		Sub           string `json:"sub"`
+		Email         string `json:"email"`
+		Name          string `json:"name"`
+		Picture       string `json:"picture"`
+		EmailVerified bool   `json:"email_verified"`
+	}
+	if err := json.Unmarshal(body, &userInfo); err != nil {
+		return fmt.Errorf("parsing user info: %w", err)
+	}
+	if userInfo.Sub != info.ID {
+		return errors.New("user info subject does not match id token")
+	}
+
+	if info.Email == "" {
+		info.Email = userInfo.Email
+		info.EmailVerified = userInfo.EmailVerified
+	}
+	if info.Name == "" {
+		info.Name = userInfo.Name
+	}
+	if info.Picture == "" {
+		info.Picture = userInfo.Picture
+	}
+	return nil
+}
@@ -11,6 +11,7 @@ type Token struct {
 	TokenType    string `json:"token_type"`
 	RefreshToken string `json:"refresh_token,omitempty"`
 	ExpiresIn    int    `json:"expires_in,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
 }
 
 // UserInfo เก็บข้อมูลผู้ใช้ที่ได้จาก OAuth provider
@@ -19,6 +20,9 @@ type UserInfo struct {
 	Email   string `json:"email"`
 	Name    string `json:"name"`
 	Picture string `json:"picture"`
+
+	// EmailVerified ผู้ให้บริการยืนยันแล้วว่าอีเมลเป็นของผู้ใช้ ใช้ตัดสินว่าจะเชื่อมกับบัญชีเดิมที่ใช้อีเมลเดียวกันได้หรือไม่
+	EmailVerified bool `json:"email_verified"`
 }
 
 // Provider เป็น interface สำหรับ OAuth providers
@@ -0,0 +1,33 @@
+package oauth
+
+import "strings"
+
+// Registry เก็บ OAuth provider ทั้งหมดที่เปิดใช้งาน โดยอ้างถึงด้วยชื่อใน URL เช่น /auth/google/login
+type Registry struct {
+	providers map[string]Provider
+}
+
+// NewRegistry สร้าง Registry เปล่า
+func NewRegistry() *Registry {
+	return &Registry{providers: make(map[string]Provider)}
+}
+
+// Register เพิ่ม provider ภายใต้ชื่อที่กำหนด (ไม่สนตัวพิมพ์เล็ก/ใหญ่)
+func (r *Registry) Register(name string, provider Provider) {
+	r.providers[strings.ToLower(name)] = provider
+}
+
+// Get ดึง provider จากชื่อ
+func (r *Registry) Get(name string) (Provider, bool) {
+	provider, ok := r.providers[strings.ToLower(name)]
+	return provider, ok
+}
+
+// Names คืนรายชื่อ provider ที่เปิดใช้งาน
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	return names
+}
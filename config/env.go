@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -12,6 +13,40 @@ type Config struct {
 	GoogleRedirectURL  string
 	JWTSecret          string
 	JWTRefreshSecret   string
+
+	// อีเมลของผู้ใช้ที่จะได้รับบทบาท admin เมื่อเข้าสู่ระบบ (คั่นด้วย comma)
+	AdminEmails []string
+
+	// OpenID Connect provider เพิ่มเติม เช่น identity provider ของโรงเรียน
+	OIDCProviders []OIDCProviderConfig
+
+	// URL ของ frontend ใช้สร้างลิงก์ในอีเมล เช่น ลิงก์ยืนยันอีเมลและรีเซ็ตรหัสผ่าน
+	FrontendURL string
+
+	// การส่งอีเมล: MAIL_DRIVER เป็น smtp, file หรือ log (ค่าเริ่มต้น)
+	MailDriver   string
+	MailFrom     string
+	MailDir      string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+
+	// DEV_AUTH_ENABLED=true เปิด provider "dev" สำหรับเข้าสู่ระบบเป็นผู้ใช้ทดสอบโดยไม่ต้องใช้ Google
+	// ห้ามเปิดใน production
+	DevAuthEnabled bool
+	BackendURL     string
+}
+
+// OIDCProviderConfig การตั้งค่า OpenID Connect provider หนึ่งตัว
+// อ่านจาก OIDC_PROVIDERS="school" และ OIDC_SCHOOL_ISSUER, OIDC_SCHOOL_CLIENT_ID เป็นต้น
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
 }
 
 func LoadConfig() (*Config, error) {
@@ -25,5 +60,54 @@ func LoadConfig() (*Config, error) {
 		GoogleRedirectURL:  os.Getenv("GOOGLE_REDIRECT_URL"),
 		JWTSecret:          os.Getenv("JWT_SECRET"),
 		JWTRefreshSecret:   os.Getenv("JWT_REFRESH_SECRET"),
+		AdminEmails:        splitList(os.Getenv("ADMIN_EMAILS")),
+		OIDCProviders:      loadOIDCProviders(),
+		FrontendURL:        getEnvDefault("FRONTEND_URL", "http://localhost:4000"),
+		MailDriver:         getEnvDefault("MAIL_DRIVER", "log"),
+		MailFrom:           getEnvDefault("MAIL_FROM", "League of Quiz <no-reply@localhost>"),
+		MailDir:            os.Getenv("MAIL_DIR"),
+		SMTPHost:           os.Getenv("SMTP_HOST"),
+		SMTPPort:           getEnvDefault("SMTP_PORT", "587"),
+		SMTPUsername:       os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:       os.Getenv("SMTP_PASSWORD"),
+		DevAuthEnabled:     os.Getenv("DEV_AUTH_ENABLED") == "true",
+		BackendURL:         getEnvDefault("BACKEND_URL", "http://localhost:3000"),
 	}, nil
 }
+
+// loadOIDCProviders อ่านการตั้งค่า OIDC provider ตามรายชื่อใน OIDC_PROVIDERS
+func loadOIDCProviders() []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+	for _, name := range splitList(os.Getenv("OIDC_PROVIDERS")) {
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		providers = append(providers, OIDCProviderConfig{
+			Name:         name,
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+			Scopes:       strings.Fields(os.Getenv(prefix + "SCOPES")),
+		})
+	}
+	return providers
+}
+
+// getEnvDefault อ่าน environment variable และใช้ค่าเริ่มต้นถ้าไม่ได้กำหนด
+func getEnvDefault(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// splitList แยกค่าที่คั่นด้วย comma ตัดช่องว่าง แปลงเป็นตัวพิมพ์เล็ก และข้ามค่าว่าง
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
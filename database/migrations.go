@@ -1,16 +1,121 @@
 package database
 
 import (
+	"fmt"
+	"log"
+
 	models "github.com/patiphanak/league-of-quiz/model"
+	"github.com/patiphanak/league-of-quiz/utils"
 	"gorm.io/gorm"
 )
 
 func AutoMigration(db *gorm.DB) {
 	db.AutoMigrate(&models.User{})
+	db.AutoMigrate(&models.Organization{})
+	db.AutoMigrate(&models.OrganizationMember{})
+	db.AutoMigrate(&models.Classroom{})
+	db.AutoMigrate(&models.ClassroomMember{})
 	db.AutoMigrate(&models.Quiz{})
+	db.AutoMigrate(&models.Category{})
 	db.AutoMigrate(&models.Question{})
 	db.AutoMigrate(&models.PlayerAnswer{})
 	db.AutoMigrate(&models.Choice{})
 	db.AutoMigrate(&models.GameSession{})
+	db.AutoMigrate(&models.GameTeam{})
 	db.AutoMigrate(&models.GamePlayer{})
+	db.AutoMigrate(&models.GameSessionAllowedUser{})
+	db.AutoMigrate(&models.GameJoinRequest{})
+	db.AutoMigrate(&models.Tag{})
+	db.AutoMigrate(&models.BankQuestion{})
+	db.AutoMigrate(&models.BankChoice{})
+	db.AutoMigrate(&models.QuizCollaborator{})
+	db.AutoMigrate(&models.RefreshToken{})
+	db.AutoMigrate(&models.Session{})
+	db.AutoMigrate(&models.UserIdentity{})
+	db.AutoMigrate(&models.UserToken{})
+	db.AutoMigrate(&models.PersonalAccessToken{})
+	db.AutoMigrate(&models.UploadedFile{})
+
+	backfillCategorySlugs(db)
+	migrateGoogleIdentities(db)
+	ensureUniqueUserEmails(db)
+}
+
+// ensureUniqueUserEmails สร้าง unique index ของอีเมลแบบไม่สนตัวพิมพ์ เพื่อไม่ให้การสมัครพร้อมกันสร้างบัญชีซ้ำ
+// บัญชีที่ไม่มีอีเมล (เช่น บัญชีที่ลบแล้ว) ไม่อยู่ใน index
+// ถ้ามีอีเมลซ้ำกันอยู่แล้วจะแสดงรายการและหยุดการเริ่มระบบ เพราะระบบพึ่ง index นี้ในการป้องกันบัญชีซ้ำ
+func ensureUniqueUserEmails(db *gorm.DB) {
+	var duplicates []struct {
+		Email string
+		IDs   string
+	}
+	err := db.Raw(`
+		SELECT LOWER(email) AS email, STRING_AGG(id::text, ', ' ORDER BY id) AS ids
+		FROM users
+		WHERE email <> ''
+		GROUP BY LOWER(email)
+		HAVING COUNT(*) > 1`).Scan(&duplicates).Error
+	if err != nil {
+		log.Fatalf("Failed to check for duplicate user emails: %v", err)
+	}
+	if len(duplicates) > 0 {
+		for _, duplicate := range duplicates {
+			log.Printf("Duplicate user email %q used by users %s", duplicate.Email, duplicate.IDs)
+		}
+		log.Fatalf("Found %d emails shared by more than one user (ignoring case); merge or rename these accounts before starting the server", len(duplicates))
+	}
+
+	err = db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_lower ON users (LOWER(email)) WHERE email <> ''`).Error
+	if err != nil {
+		log.Fatalf("Failed to create unique index on users.email: %v", err)
+	}
+}
+
+// migrateGoogleIdentities ย้ายคอลัมน์ users.google_id เดิมไปเป็น identity ของ provider "google" แล้วลบคอลัมน์ทิ้ง
+func migrateGoogleIdentities(db *gorm.DB) {
+	if !db.Migrator().HasColumn("users", "google_id") {
+		return
+	}
+
+	err := db.Exec(`
+		INSERT INTO user_identities (user_id, provider, subject, email, created_at, updated_at)
+		SELECT id, 'google', google_id, email, NOW(), NOW()
+		FROM users
+		WHERE google_id IS NOT NULL AND google_id <> ''
+		ON CONFLICT (provider, subject) DO NOTHING`).Error
+	if err != nil {
+		log.Printf("Failed to migrate Google identities: %v", err)
+		return
+	}
+
+	if err := db.Migrator().DropColumn("users", "google_id"); err != nil {
+		log.Printf("Failed to drop users.google_id: %v", err)
+	}
+}
+
+// backfillCategorySlugs สร้าง slug ให้หมวดหมู่ที่สร้างไว้ก่อนมีคอลัมน์ slug
+func backfillCategorySlugs(db *gorm.DB) {
+	var categories []models.Category
+	if err := db.Where("slug = '' OR slug IS NULL").Find(&categories).Error; err != nil {
+		log.Printf("Failed to load categories for slug backfill: %v", err)
+		return
+	}
+
+	for _, category := range categories {
+		slug := utils.Slugify(category.Name)
+		if slug == "" {
+			continue
+		}
+
+		// ถ้า slug ซ้ำกับหมวดหมู่อื่นให้ต่อท้ายด้วย ID
+		var count int64
+		db.Model(&models.Category{}).Where("slug = ? AND id <> ?", slug, category.ID).Count(&count)
+		if count > 0 {
+			slug = fmt.Sprintf("%s-%d", slug, category.ID)
+		}
+
+		if err := db.Model(&category).Update("slug", slug).Error; err != nil {
+			log.Printf("Failed to backfill slug for category %d: %v", category.ID, err)
+		}
+	}
 }
@@ -0,0 +1,98 @@
+package dto
+
+import "time"
+
+// ProfileResponse ข้อมูลโปรไฟล์ของผู้ใช้ปัจจุบัน
+type ProfileResponse struct {
+	ID            uint      `json:"id"`
+	Email         string    `json:"email"`
+	DisplayName   string    `json:"displayName"`
+	Nickname      string    `json:"nickname"`
+	Picture       string    `json:"picture"`
+	AvatarURL     string    `json:"avatarUrl"`
+	Role          string    `json:"role"`
+	EmailVerified bool      `json:"emailVerified"`
+	HasPassword   bool      `json:"hasPassword"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// UpdateProfileRequest แก้ไขโปรไฟล์ ฟิลด์ที่ไม่ส่งมาจะไม่ถูกเปลี่ยน
+type UpdateProfileRequest struct {
+	DisplayName *string `json:"displayName"`
+	Nickname    *string `json:"nickname"`
+}
+
+// DeleteAccountRequest ยืนยันการลบบัญชี
+type DeleteAccountRequest struct {
+	Confirm bool `json:"confirm"`
+}
+
+// AccountExport ข้อมูลทั้งหมดของผู้ใช้สำหรับดาวน์โหลด
+type AccountExport struct {
+	ExportedAt    time.Time            `json:"exportedAt"`
+	Profile       ProfileResponse      `json:"profile"`
+	Identities    []ExportIdentity     `json:"identities"`
+	Quizzes       []ExportQuiz         `json:"quizzes"`
+	BankQuestions []ExportBankQuestion `json:"bankQuestions"`
+	HostedGames   []ExportHostedGame   `json:"hostedGames"`
+	PlayedGames   []ExportPlayedGame   `json:"playedGames"`
+	Answers       []ExportAnswer       `json:"answers"`
+}
+
+// ExportIdentity บัญชีภายนอกที่เชื่อมไว้
+type ExportIdentity struct {
+	Provider  string    `json:"provider"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ExportQuiz quiz ที่ผู้ใช้สร้าง ไฟล์อ้างอิงเป็น URL
+type ExportQuiz struct {
+	ID          uint      `json:"id"`
+	IsPublished bool      `json:"isPublished"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+	BundleQuiz
+}
+
+// ExportBankQuestion คำถามในคลังข้อสอบของผู้ใช้
+type ExportBankQuestion struct {
+	ID          uint           `json:"id"`
+	Text        string         `json:"text"`
+	Image       string         `json:"image,omitempty"`
+	Explanation string         `json:"explanation,omitempty"`
+	Tags        []string       `json:"tags"`
+	Choices     []BundleChoice `json:"choices"`
+	CreatedAt   time.Time      `json:"createdAt"`
+}
+
+// ExportHostedGame เกมที่ผู้ใช้เป็นผู้จัด
+type ExportHostedGame struct {
+	SessionID  string     `json:"sessionId"`
+	QuizID     uint       `json:"quizId"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	StartedAt  *time.Time `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt"`
+}
+
+// ExportPlayedGame เกมที่ผู้ใช้เข้าร่วมเล่น
+type ExportPlayedGame struct {
+	SessionID string    `json:"sessionId"`
+	QuizID    uint      `json:"quizId"`
+	Nickname  string    `json:"nickname"`
+	Score     uint      `json:"score"`
+	JoinedAt  time.Time `json:"joinedAt"`
+}
+
+// ExportAnswer คำตอบของผู้ใช้ในเกม
+type ExportAnswer struct {
+	SessionID  string    `json:"sessionId"`
+	QuizID     uint      `json:"quizId"`
+	QuestionID uint      `json:"questionId"`
+	ChoiceID   uint      `json:"choiceId"`
+	IsCorrect  bool      `json:"isCorrect"`
+	Points     uint      `json:"points"`
+	TimeSpent  float64   `json:"timeSpent"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
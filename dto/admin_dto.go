@@ -0,0 +1,19 @@
+package dto
+
+import "time"
+
+// AdminUserResponse ข้อมูลผู้ใช้สำหรับหน้าผู้ดูแลระบบ
+type AdminUserResponse struct {
+	ID          uint       `json:"id"`
+	Email       string     `json:"email"`
+	DisplayName string     `json:"displayName"`
+	PictureURL  string     `json:"pictureUrl"`
+	Role        string     `json:"role"`
+	DisabledAt  *time.Time `json:"disabledAt"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+// UpdateUserRoleRequest เปลี่ยนบทบาทของผู้ใช้
+type UpdateUserRoleRequest struct {
+	Role string `json:"role"`
+}
@@ -0,0 +1,11 @@
+package dto
+
+// CategoryRequest ข้อมูลสำหรับสร้างหรือแก้ไขหมวดหมู่
+// ParentID เป็น nil หรือ 0 หมายถึงหมวดหมู่ระดับบนสุด ถ้าไม่ส่ง Slug จะสร้างจากชื่อให้
+type CategoryRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Slug        string `json:"slug"`
+	Icon        string `json:"icon"`
+	ParentID    *uint  `json:"parentId"`
+}
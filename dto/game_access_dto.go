@@ -0,0 +1,23 @@
+package dto
+
+// GameSessionAccessResponse การจำกัดการเข้าร่วมของเกม (ไม่ส่งรหัสผ่านกลับไป)
+type GameSessionAccessResponse struct {
+	SessionID       string `json:"sessionId"`
+	ClassroomID     *uint  `json:"classroomId"`
+	AllowedUserIDs  []uint `json:"allowedUserIds"`
+	HasPassword     bool   `json:"hasPassword"`
+	RequireApproval bool   `json:"requireApproval"`
+	LobbyLocked     bool   `json:"lobbyLocked"`
+	MaxPlayers      int    `json:"maxPlayers"`  // 0 หมายถึงไม่จำกัด
+	PlayerCount     int64  `json:"playerCount"` // จำนวนผู้เล่นปัจจุบันไม่นับโฮสต์
+}
+
+// UpdateGameSessionAccessRequest เปลี่ยนการจำกัดการเข้าร่วม ฟิลด์ที่ไม่ส่งมาจะไม่ถูกเปลี่ยน
+// classroomId เป็น 0 เพื่อยกเลิกห้องเรียน password เป็นค่าว่างเพื่อยกเลิกรหัสผ่าน และ maxPlayers เป็น 0 เพื่อไม่จำกัด
+type UpdateGameSessionAccessRequest struct {
+	ClassroomID     *uint   `json:"classroomId"`
+	AllowedUserIDs  *[]uint `json:"allowedUserIds"`
+	Password        *string `json:"password"`
+	RequireApproval *bool   `json:"requireApproval"`
+	MaxPlayers      *int    `json:"maxPlayers"`
+}
@@ -0,0 +1,41 @@
+package dto
+
+// TeamMember ผู้เล่นในทีมพร้อมคะแนนของตัวเอง
+type TeamMember struct {
+	UserID   uint   `json:"userId"`
+	Nickname string `json:"nickname"`
+	Score    uint   `json:"score"`
+}
+
+// GameTeamResponse ทีมพร้อมสมาชิกและคะแนนทีม
+type GameTeamResponse struct {
+	ID      uint         `json:"id"`
+	Name    string       `json:"name"`
+	Score   float64      `json:"score"`
+	Members []TeamMember `json:"members"`
+}
+
+// GameTeamsResponse ทีมทั้งหมดของเกมและผู้เล่นที่ยังไม่มีทีม (ไม่รวมโฮสต์)
+type GameTeamsResponse struct {
+	SessionID       string             `json:"sessionId"`
+	TeamScoring     string             `json:"teamScoring"`
+	TeamTalkSeconds uint               `json:"teamTalkSeconds"`
+	Teams           []GameTeamResponse `json:"teams"`
+	Unassigned      []TeamMember       `json:"unassigned"`
+}
+
+// TeamStanding อันดับของทีมใน leaderboard ทีมที่คะแนนเท่ากันได้อันดับเดียวกัน
+type TeamStanding struct {
+	Rank        int     `json:"rank"`
+	TeamID      uint    `json:"teamId"`
+	Name        string  `json:"name"`
+	Score       float64 `json:"score"`
+	MemberCount int     `json:"memberCount"`
+}
+
+// UpdateTeamSettingsRequest เปลี่ยนการตั้งค่าโหมดทีม ฟิลด์ที่ไม่ส่งมาจะไม่ถูกเปลี่ยน
+type UpdateTeamSettingsRequest struct {
+	TeamMode        *bool   `json:"teamMode"`
+	TeamScoring     *string `json:"teamScoring"` // sum หรือ average
+	TeamTalkSeconds *uint   `json:"teamTalkSeconds"`
+}
@@ -0,0 +1,30 @@
+package dto
+
+// RegisterRequest ข้อมูลสมัครสมาชิกด้วยอีเมลและรหัสผ่าน
+type RegisterRequest struct {
+	Email       string `json:"email"`
+	Password    string `json:"password"`
+	DisplayName string `json:"displayName"`
+}
+
+// LoginRequest ข้อมูลเข้าสู่ระบบด้วยอีเมลและรหัสผ่าน
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// EmailRequest คำขอที่ระบุแค่อีเมล เช่น ขอส่งอีเมลยืนยันซ้ำหรือลืมรหัสผ่าน
+type EmailRequest struct {
+	Email string `json:"email"`
+}
+
+// EmailTokenRequest token ที่ได้จากลิงก์ในอีเมล
+type EmailTokenRequest struct {
+	Token string `json:"token"`
+}
+
+// ResetPasswordRequest ตั้งรหัสผ่านใหม่ด้วย token จากอีเมล
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
@@ -0,0 +1,113 @@
+package dto
+
+import "time"
+
+// CreateOrganizationRequest สร้างองค์กรใหม่
+type CreateOrganizationRequest struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// UpdateOrganizationRequest แก้ไขชื่อองค์กร
+type UpdateOrganizationRequest struct {
+	Name string `json:"name"`
+}
+
+// OrganizationResponse ข้อมูลองค์กรพร้อมบทบาทของผู้ใช้ปัจจุบัน
+type OrganizationResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// AddOrganizationMemberRequest เพิ่มสมาชิกด้วยอีเมล หรือเปลี่ยนบทบาทของสมาชิกเดิม
+type AddOrganizationMemberRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// OrganizationMemberResponse ข้อมูลสมาชิกขององค์กร
+type OrganizationMemberResponse struct {
+	UserID      uint      `json:"userId"`
+	Email       string    `json:"email"`
+	DisplayName string    `json:"displayName"`
+	Role        string    `json:"role"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// ShareQuizRequest แชร์ quiz ให้องค์กร ส่ง organizationId เป็น null เพื่อยกเลิกการแชร์
+type ShareQuizRequest struct {
+	OrganizationID *uint `json:"organizationId"`
+}
+
+// CreateClassroomRequest สร้างห้องเรียนในองค์กร ถ้าไม่ระบุ teacherId ผู้สร้างจะเป็นครูประจำห้อง
+type CreateClassroomRequest struct {
+	Name      string `json:"name"`
+	TeacherID uint   `json:"teacherId"`
+}
+
+// UpdateClassroomRequest แก้ไขห้องเรียน ฟิลด์ที่ไม่ส่งมาจะไม่ถูกเปลี่ยน
+type UpdateClassroomRequest struct {
+	Name      *string `json:"name"`
+	TeacherID *uint   `json:"teacherId"`
+}
+
+// ClassroomResponse ข้อมูลห้องเรียน
+type ClassroomResponse struct {
+	ID             uint      `json:"id"`
+	OrganizationID uint      `json:"organizationId"`
+	Name           string    `json:"name"`
+	TeacherID      uint      `json:"teacherId"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// AddClassroomMemberRequest เพิ่มผู้ใช้เข้ารายชื่อห้องเรียนด้วยอีเมล
+type AddClassroomMemberRequest struct {
+	Email string `json:"email"`
+}
+
+// ClassroomMemberResponse ข้อมูลนักเรียนในรายชื่อ
+type ClassroomMemberResponse struct {
+	UserID      uint      `json:"userId"`
+	Email       string    `json:"email"`
+	DisplayName string    `json:"displayName"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// ClassroomResults ผลของทุกเกมที่ห้องเรียนเล่น และสรุปรายนักเรียน
+type ClassroomResults struct {
+	ClassroomID uint                     `json:"classroomId"`
+	Games       []ClassroomGameResult    `json:"games"`
+	Students    []ClassroomStudentResult `json:"students"`
+}
+
+// ClassroomGameResult ผลของเกมหนึ่งเกมที่ผูกกับห้องเรียน
+type ClassroomGameResult struct {
+	SessionID  string                `json:"sessionId"`
+	QuizID     uint                  `json:"quizId"`
+	QuizTitle  string                `json:"quizTitle"`
+	HostID     uint                  `json:"hostId"`
+	Status     string                `json:"status"`
+	StartedAt  *time.Time            `json:"startedAt"`
+	FinishedAt *time.Time            `json:"finishedAt"`
+	Players    []ClassroomGamePlayer `json:"players"`
+}
+
+// ClassroomGamePlayer คะแนนและอันดับของผู้เล่นในเกม
+type ClassroomGamePlayer struct {
+	UserID   uint   `json:"userId"`
+	Nickname string `json:"nickname"`
+	Score    uint   `json:"score"`
+	Rank     int    `json:"rank"`
+}
+
+// ClassroomStudentResult สรุปผลของนักเรียนหนึ่งคนจากทุกเกมของห้องเรียน
+type ClassroomStudentResult struct {
+	UserID       uint    `json:"userId"`
+	DisplayName  string  `json:"displayName"`
+	GamesPlayed  int     `json:"gamesPlayed"`
+	TotalScore   uint    `json:"totalScore"`
+	AverageScore float64 `json:"averageScore"`
+}
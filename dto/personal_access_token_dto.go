@@ -0,0 +1,28 @@
+package dto
+
+import "time"
+
+// CreatePersonalAccessTokenRequest ข้อมูลสำหรับสร้าง personal access token
+// ExpiresInDays เป็น 0 หมายถึงไม่มีวันหมดอายุ
+type CreatePersonalAccessTokenRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expiresInDays"`
+}
+
+// PersonalAccessTokenResponse ข้อมูล token ที่แสดงให้ผู้ใช้ (ไม่มีค่า token จริง)
+type PersonalAccessTokenResponse struct {
+	ID          uint       `json:"id"`
+	Name        string     `json:"name"`
+	TokenPrefix string     `json:"tokenPrefix"`
+	Scopes      []string   `json:"scopes"`
+	ExpiresAt   *time.Time `json:"expiresAt"`
+	LastUsedAt  *time.Time `json:"lastUsedAt"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+// CreatedPersonalAccessTokenResponse token ที่สร้างใหม่ ค่า Token แสดงได้ครั้งเดียวตอนสร้าง
+type CreatedPersonalAccessTokenResponse struct {
+	PersonalAccessTokenResponse
+	Token string `json:"token"`
+}
@@ -0,0 +1,22 @@
+package dto
+
+// BankQuestionFormData ข้อมูลคำถามในคลังข้อสอบจาก form
+type BankQuestionFormData struct {
+	Text        string           `json:"text"`
+	Explanation string           `json:"explanation"`
+	Tags        []string         `json:"tags"`
+	Choices     []ChoiceFormData `json:"choices"`
+}
+
+// AddBankQuestionsRequest รายการคำถามในคลังที่จะนำเข้า quiz
+type AddBankQuestionsRequest struct {
+	BankQuestionIDs []uint `json:"bankQuestionIds"`
+}
+
+// DrawQuizRequest สร้าง quiz ใหม่โดยสุ่มคำถามจากคลังตาม tag
+type DrawQuizRequest struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	Count       int      `json:"count"`
+}
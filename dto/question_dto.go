@@ -6,9 +6,10 @@ type QuestionChoiceForm struct {
 	IsCorrect bool   `json:"isCorrect"`
 }
 type QuestionFormData struct {
-	QuizID  int             `json:"quizId"`
-	Text    string          `json:"text"`
-	Choices []ChoiceFormData `json:"choices"`
+	QuizID      int              `json:"quizId"`
+	Text        string           `json:"text"`
+	Choices     []ChoiceFormData `json:"choices"`
+	Explanation string           `json:"explanation"`
 }
 
 // ChoiceFormData สำหรับข้อมูลตัวเลือกจาก form
@@ -16,4 +17,9 @@ type ChoiceFormData struct {
 	ID        string `json:"id"`
 	Text      string `json:"text"`
 	IsCorrect bool   `json:"isCorrect"`
-}
\ No newline at end of file
+}
+
+// QuestionOrderRequest ลำดับคำถามใหม่ของ quiz
+type QuestionOrderRequest struct {
+	QuestionIDs []uint `json:"questionIds"`
+}
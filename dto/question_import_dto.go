@@ -0,0 +1,19 @@
+package dto
+
+// QuestionImportRowError ข้อผิดพลาดของแถวในไฟล์นำเข้าคำถาม
+// Row นับจาก 1 รวมแถวหัวตาราง สำหรับไฟล์ข้อความ เช่น GIFT หรือ Aiken คือบรรทัดแรกของคำถาม
+type QuestionImportRowError struct {
+	Row    int      `json:"row"`
+	Errors []string `json:"errors"`
+}
+
+// QuestionImportResult ผลการนำเข้าคำถามจากไฟล์ CSV หรือ XLSX
+type QuestionImportResult struct {
+	DryRun      bool                     `json:"dryRun"`
+	TotalRows   int                      `json:"totalRows"`
+	ValidRows   int                      `json:"validRows"`
+	Created     int                      `json:"created"`
+	QuestionIDs []uint                   `json:"questionIds"`
+	Errors      []QuestionImportRowError `json:"errors"`
+	Warnings    []QuestionImportRowError `json:"warnings,omitempty"`
+}
@@ -0,0 +1,45 @@
+package dto
+
+import "time"
+
+// QuizBundleVersion เวอร์ชันของรูปแบบไฟล์ quiz bundle ที่ระบบรองรับ
+const QuizBundleVersion = 1
+
+// QuizBundleManifest ชื่อไฟล์ JSON หลักภายใน archive ของ quiz bundle
+const QuizBundleManifest = "quiz.json"
+
+// QuizBundle เอกสาร JSON ที่ใช้ส่งออกและนำเข้า quiz ระหว่างระบบ
+type QuizBundle struct {
+	Version    int        `json:"version"`
+	ExportedAt time.Time  `json:"exportedAt"`
+	Quiz       BundleQuiz `json:"quiz"`
+}
+
+// BundleQuiz ข้อมูล quiz ใน bundle โดยไฟล์อ้างอิงเป็น path ภายใน archive
+type BundleQuiz struct {
+	Title       string           `json:"title"`
+	Description string           `json:"description"`
+	TimeLimit   uint             `json:"timeLimit"`
+	Image       string           `json:"image,omitempty"`
+	Categories  []string         `json:"categories"`
+	Questions   []BundleQuestion `json:"questions"`
+}
+
+// BundleQuestion ข้อมูลคำถามใน bundle
+type BundleQuestion struct {
+	Text             string         `json:"text"`
+	Image            string         `json:"image,omitempty"`
+	Media            string         `json:"media,omitempty"`
+	MediaType        string         `json:"mediaType,omitempty"`
+	Explanation      string         `json:"explanation,omitempty"`
+	ExplanationImage string         `json:"explanationImage,omitempty"`
+	TimeLimit        uint           `json:"timeLimit,omitempty"`
+	Choices          []BundleChoice `json:"choices"`
+}
+
+// BundleChoice ข้อมูลตัวเลือกใน bundle
+type BundleChoice struct {
+	Text      string `json:"text"`
+	Image     string `json:"image,omitempty"`
+	IsCorrect bool   `json:"isCorrect"`
+}
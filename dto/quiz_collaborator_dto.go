@@ -0,0 +1,19 @@
+package dto
+
+import "time"
+
+// InviteCollaboratorRequest เชิญผู้ใช้เป็นผู้ร่วมแก้ไข quiz ด้วยอีเมล
+type InviteCollaboratorRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// CollaboratorResponse ข้อมูลผู้ร่วมแก้ไขที่ส่งกลับให้ client
+type CollaboratorResponse struct {
+	UserID      uint      `json:"userId"`
+	Email       string    `json:"email"`
+	DisplayName string    `json:"displayName"`
+	PictureURL  string    `json:"pictureUrl"`
+	Role        string    `json:"role"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
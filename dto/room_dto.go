@@ -1 +1,54 @@
 package dto
+
+import "time"
+
+// PlayerChoice ตัวเลือกที่ส่งให้ผู้เล่นระหว่างเกม (ไม่มีข้อมูลคำตอบที่ถูกต้อง)
+type PlayerChoice struct {
+	ID       uint   `json:"id"`
+	Text     string `json:"text"`
+	ImageURL string `json:"imageUrl,omitempty"`
+}
+
+// PlayerQuestion คำถามที่ส่งให้ผู้เล่นใน event question_started
+type PlayerQuestion struct {
+	ID        uint           `json:"id"`
+	Index     int            `json:"index"`
+	Total     int            `json:"total"`
+	Text      string         `json:"text"`
+	ImageURL  string         `json:"imageUrl,omitempty"`
+	MediaURL  string         `json:"mediaUrl,omitempty"`
+	MediaType string         `json:"mediaType,omitempty"`
+	TimeLimit uint           `json:"timeLimit"`
+	Choices   []PlayerChoice `json:"choices"`
+	// เวลาที่ช่วงคุยกันในทีมจบ ก่อนเวลานี้ยังส่งคำตอบไม่ได้ (เฉพาะโหมดทีมที่เปิดช่วงคุยกัน)
+	TeamTalkEndsAt *time.Time `json:"teamTalkEndsAt,omitempty"`
+}
+
+// QuestionReveal เฉลยที่ส่งให้ผู้เล่นใน event question_ended
+type QuestionReveal struct {
+	QuestionID          uint   `json:"questionId"`
+	CorrectChoiceIDs    []uint `json:"correctChoiceIds"`
+	Explanation         string `json:"explanation,omitempty"`
+	ExplanationImageURL string `json:"explanationImageUrl,omitempty"`
+}
+
+// ReviewChoice ตัวเลือกในหน้าทบทวนหลังจบเกม
+type ReviewChoice struct {
+	ID        uint   `json:"id"`
+	Text      string `json:"text"`
+	ImageURL  string `json:"imageUrl,omitempty"`
+	IsCorrect bool   `json:"isCorrect"`
+}
+
+// ReviewQuestion คำถามพร้อมเฉลยและคำตอบของผู้เล่นในหน้าทบทวนหลังจบเกม
+type ReviewQuestion struct {
+	QuestionID          uint           `json:"questionId"`
+	Text                string         `json:"text"`
+	ImageURL            string         `json:"imageUrl,omitempty"`
+	Choices             []ReviewChoice `json:"choices"`
+	Explanation         string         `json:"explanation,omitempty"`
+	ExplanationImageURL string         `json:"explanationImageUrl,omitempty"`
+	SelectedChoiceID    *uint          `json:"selectedChoiceId"`
+	IsCorrect           bool           `json:"isCorrect"`
+	Points              uint           `json:"points"`
+}
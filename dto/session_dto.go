@@ -0,0 +1,14 @@
+package dto
+
+import "time"
+
+// SessionResponse ข้อมูล session การเข้าสู่ระบบของผู้ใช้
+type SessionResponse struct {
+	ID         uint      `json:"id"`
+	Device     string    `json:"device"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"userAgent"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	CreatedAt  time.Time `json:"createdAt"`
+	Current    bool      `json:"current"`
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+
+	"github.com/patiphanak/league-of-quiz/dto"
+	"github.com/patiphanak/league-of-quiz/services"
+	"github.com/patiphanak/league-of-quiz/utils"
+)
+
+// AccountHandler สำหรับจัดการบัญชีของผู้ใช้เอง เช่น โปรไฟล์ การส่งออกข้อมูล และการลบบัญชี
+type AccountHandler struct {
+	accountService *services.AccountService
+}
+
+// NewAccountHandler สร้าง instance ใหม่ของ AccountHandler
+func NewAccountHandler(accountService *services.AccountService) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+	}
+}
+
+// GetProfile ดึงโปรไฟล์ของผู้ใช้ปัจจุบัน
+func (h *AccountHandler) GetProfile(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	profile, err := h.accountService.GetProfile(userID)
+	if err != nil {
+		return accountError(c, err, "Failed to fetch profile")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": profile,
+	})
+}
+
+// UpdateProfile แก้ไขชื่อที่แสดงและชื่อเล่นเริ่มต้นในเกม
+func (h *AccountHandler) UpdateProfile(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var req dto.UpdateProfileRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	profile, err := h.accountService.UpdateProfile(userID, req)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Profile updated successfully",
+		"data":    profile,
+	})
+}
+
+// UpdateAvatar อัปโหลดรูปโปรไฟล์ (multipart field "avatar")
+func (h *AccountHandler) UpdateAvatar(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	file, err := c.FormFile("avatar")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Avatar file is required"})
+	}
+
+	profile, err := h.accountService.UpdateAvatar(userID, file)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Avatar updated successfully",
+		"data":    profile,
+	})
+}
+
+// RemoveAvatar ลบรูปโปรไฟล์ที่อัปโหลด
+func (h *AccountHandler) RemoveAvatar(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	profile, err := h.accountService.RemoveAvatar(userID)
+	if err != nil {
+		return accountError(c, err, "Failed to remove avatar")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Avatar removed successfully",
+		"data":    profile,
+	})
+}
+
+// ExportData ดาวน์โหลดข้อมูลทั้งหมดของผู้ใช้เป็นไฟล์ JSON
+func (h *AccountHandler) ExportData(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	export, err := h.accountService.ExportData(userID)
+	if err != nil {
+		return accountError(c, err, "Failed to export account data")
+	}
+
+	filename := fmt.Sprintf("league-of-quiz-export-%s.json", time.Now().UTC().Format("20060102"))
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+	return c.JSON(export)
+}
+
+// DeleteAccount ลบบัญชีของผู้ใช้ปัจจุบัน ต้องส่ง {"confirm": true} มาเพื่อยืนยัน
+func (h *AccountHandler) DeleteAccount(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var req dto.DeleteAccountRequest
+	if err := c.BodyParser(&req); err != nil || !req.Confirm {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Account deletion must be confirmed with {\"confirm\": true}"})
+	}
+
+	if err := h.accountService.DeleteAccount(userID); err != nil {
+		return accountError(c, err, "Failed to delete account")
+	}
+
+	clearAuthCookies(c)
+	return c.JSON(fiber.Map{
+		"message": "Account deleted successfully",
+	})
+}
+
+// accountError แปลง error จาก AccountService เป็น response
+func accountError(c *fiber.Ctx, err error, message string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+	}
+	if errors.Is(err, services.ErrSoleOrganizationOwner) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": message})
+}
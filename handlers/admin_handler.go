@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+
+	"github.com/patiphanak/league-of-quiz/dto"
+	"github.com/patiphanak/league-of-quiz/services"
+	"github.com/patiphanak/league-of-quiz/utils"
+)
+
+// AdminHandler สำหรับการจัดการ API ของผู้ดูแลระบบ
+type AdminHandler struct {
+	adminService *services.AdminService
+}
+
+// NewAdminHandler สร้าง instance ใหม่ของ AdminHandler
+func NewAdminHandler(adminService *services.AdminService) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+	}
+}
+
+// adminErrorStatus แปลงข้อผิดพลาดจาก service เป็น HTTP status
+func adminErrorStatus(err error) int {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fiber.StatusNotFound
+	}
+	return fiber.StatusBadRequest
+}
+
+// ListUsers ค้นหาผู้ใช้ทั้งหมด
+func (h *AdminHandler) ListUsers(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+
+	users, count, err := h.adminService.ListUsers(c.Query("search"), c.Query("role"), page, limit)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": users,
+		"meta": fiber.Map{
+			"total": count,
+			"page":  page,
+			"limit": limit,
+		},
+	})
+}
+
+// UpdateUserRole เปลี่ยนบทบาทของผู้ใช้
+func (h *AdminHandler) UpdateUserRole(c *fiber.Ctx) error {
+	adminID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	userID, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var req dto.UpdateUserRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	user, err := h.adminService.UpdateUserRole(userID, req.Role, adminID)
+	if err != nil {
+		return c.Status(adminErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "User role updated successfully",
+		"data":    user,
+	})
+}
+
+// DisableUser ระงับบัญชีผู้ใช้
+func (h *AdminHandler) DisableUser(c *fiber.Ctx) error {
+	adminID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	userID, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	user, err := h.adminService.DisableUser(userID, adminID)
+	if err != nil {
+		return c.Status(adminErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "User disabled successfully",
+		"data":    user,
+	})
+}
+
+// EnableUser ยกเลิกการระงับบัญชีผู้ใช้
+func (h *AdminHandler) EnableUser(c *fiber.Ctx) error {
+	adminID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	userID, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	user, err := h.adminService.EnableUser(userID, adminID)
+	if err != nil {
+		return c.Status(adminErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "User enabled successfully",
+		"data":    user,
+	})
+}
+
+// UnpublishQuiz ยกเลิกการเผยแพร่ quiz
+func (h *AdminHandler) UnpublishQuiz(c *fiber.Ctx) error {
+	adminID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	quizID, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.adminService.UnpublishQuiz(quizID, adminID); err != nil {
+		return c.Status(adminErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Quiz unpublished successfully",
+	})
+}
+
+// DeleteQuiz ลบ quiz ที่ไม่เหมาะสม
+func (h *AdminHandler) DeleteQuiz(c *fiber.Ctx) error {
+	adminID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	quizID, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.adminService.DeleteQuiz(quizID, adminID); err != nil {
+		return c.Status(adminErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Quiz deleted successfully",
+	})
+}
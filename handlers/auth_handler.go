@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"errors"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,25 +11,116 @@ import (
 	jwt "github.com/patiphanak/league-of-quiz/auth/jwt"
 	"github.com/patiphanak/league-of-quiz/auth/oauth"
 	models "github.com/patiphanak/league-of-quiz/model"
+	"github.com/patiphanak/league-of-quiz/services"
 	"gorm.io/gorm"
 )
 
+// refreshTokenCookiePath จำกัดให้ส่ง refresh token เฉพาะ endpoint ของ /auth
+const refreshTokenCookiePath = "/auth"
+
 type AuthHandler struct {
-	db          *gorm.DB
-	googleOAuth *oauth.GoogleOAuth
-	jwtService  *jwt.JWTService
+	db             *gorm.DB
+	providers       *oauth.Registry
+	identityService *services.IdentityService
+	tokenService    *services.TokenService
+	sessionService  *services.SessionService
+	adminEmails     []string
+	frontendURL     string
 }
 
-func NewAuthHandler(db *gorm.DB, googleOAuth *oauth.GoogleOAuth, jwtService *jwt.JWTService) *AuthHandler {
+func NewAuthHandler(
+	db *gorm.DB,
+	providers *oauth.Registry,
+	identityService *services.IdentityService,
+	tokenService *services.TokenService,
+	sessionService *services.SessionService,
+	adminEmails []string,
+	frontendURL string,
+) *AuthHandler {
 	return &AuthHandler{
-		db:          db,
-		googleOAuth: googleOAuth,
-		jwtService:  jwtService,
+		db:              db,
+		providers:       providers,
+		identityService: identityService,
+		tokenService:    tokenService,
+		sessionService:  sessionService,
+		adminEmails:     adminEmails,
+		frontendURL:     strings.TrimSuffix(frontendURL, "/"),
+	}
+}
+
+// sessionInfo ดึงข้อมูลอุปกรณ์ของ request สำหรับบันทึกใน session
+func sessionInfo(c *fiber.Ctx) services.SessionInfo {
+	return services.SessionInfo{
+		IP:        c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
 	}
 }
 
-// Google login ส่ง redirect ไป Google OAuth Login
-func (h *AuthHandler) GoogleLogin(c *fiber.Ctx) error {
+// setAuthCookies ตั้งค่า cookie แบบ HTTP-only ของ access token และ refresh token เพื่อป้องกัน XSS
+func setAuthCookies(c *fiber.Ctx, tokens *services.AuthTokens) {
+	c.Cookie(&fiber.Cookie{
+		Name:     "auth_token",
+		Value:    tokens.AccessToken,
+		Path:     "/",
+		HTTPOnly: true,
+		// ถ้าใช้งานบน localhost ในระหว่างการพัฒนา ให้ตั้งค่า Secure เป็น false
+		// ในสภาพแวดล้อมการทำงานจริง (production) ให้เปลี่ยนเป็น true
+		Secure:   false,
+		SameSite: "Lax",
+		MaxAge:   int(jwt.AccessTokenTTL.Seconds()),
+	})
+	c.Cookie(&fiber.Cookie{
+		Name:     "refresh_token",
+		Value:    tokens.RefreshToken,
+		Path:     refreshTokenCookiePath,
+		HTTPOnly: true,
+		Secure:   false, // ตั้งเป็น true ในโหมด production
+		SameSite: "Lax",
+		MaxAge:   int(jwt.RefreshTokenTTL.Seconds()),
+	})
+}
+
+// clearAuthCookies ลบ cookie ของ token ทั้งหมดโดยการตั้งค่า MaxAge เป็นค่าลบ
+func clearAuthCookies(c *fiber.Ctx) {
+	for name, path := range map[string]string{"auth_token": "/", "refresh_token": refreshTokenCookiePath} {
+		c.Cookie(&fiber.Cookie{
+			Name:     name,
+			Value:    "",
+			Path:     path,
+			MaxAge:   -1,
+			Expires:  time.Now().Add(-time.Hour),
+			HTTPOnly: true,
+			SameSite: "Lax",
+			Secure:   false, // ตั้งเป็น true ในโหมด production
+		})
+	}
+}
+
+// isAdminEmail ตรวจสอบว่าอีเมลอยู่ในรายการผู้ดูแลระบบที่กำหนดใน config หรือไม่
+func (h *AuthHandler) isAdminEmail(email string) bool {
+	return isAdminEmail(h.adminEmails, email)
+}
+
+// isAdminEmail ตรวจสอบว่าอีเมลอยู่ใน adminEmails หรือไม่
+func isAdminEmail(adminEmails []string, email string) bool {
+	email = strings.ToLower(strings.TrimSpace(email))
+	for _, adminEmail := range adminEmails {
+		if email != "" && email == adminEmail {
+			return true
+		}
+	}
+	return false
+}
+
+// Login ส่ง redirect ไปหน้าเข้าสู่ระบบของ OAuth/OIDC provider ที่ระบุใน :provider
+func (h *AuthHandler) Login(c *fiber.Ctx) error {
+	provider, ok := h.providers.Get(c.Params("provider"))
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Unknown login provider",
+		})
+	}
+
 	state := uuid.New().String()
 
 	c.Cookie(&fiber.Cookie{
@@ -37,13 +130,21 @@ func (h *AuthHandler) GoogleLogin(c *fiber.Ctx) error {
 		HTTPOnly: true,
 	})
 
-	authURL := h.googleOAuth.GetAuthURL(state)
+	authURL := provider.GetAuthURL(state)
 	return c.Redirect(authURL)
 }
 
-// GoogleCallback
-func (h *AuthHandler) GoogleCallback(c *fiber.Ctx) error {
-	state := c.Cookies("oauth_state")
+// Callback รับ authorization code จาก provider แล้วเข้าสู่ระบบด้วย identity ที่ได้
+func (h *AuthHandler) Callback(c *fiber.Ctx) error {
+	providerName := strings.ToLower(c.Params("provider"))
+	provider, ok := h.providers.Get(providerName)
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Unknown login provider",
+		})
+	}
+
+	state := c.Query("state")
 	code := c.Query("code")
 
 	// ตรวจสอบ state ว่าตรงกันหรือไม่
@@ -53,93 +154,150 @@ func (h *AuthHandler) GoogleCallback(c *fiber.Ctx) error {
 			"error": "Invalid state parameter",
 		})
 	}
+	c.ClearCookie("oauth_state")
 
 	// แลกเปลี่ยน authorization code เป็น access token
-	token, err := h.googleOAuth.Exchange(c.Context(), code)
+	token, err := provider.Exchange(c.Context(), code)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to exchange token",
 		})
 	}
 
-	// ดึงข้อมูลผู้ใช้จาก Google
-	userInfo, err := h.googleOAuth.GetUserInfo(c.Context(), token)
+	// ดึงข้อมูลผู้ใช้จาก provider
+	userInfo, err := provider.GetUserInfo(c.Context(), token)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to get user info",
 		})
 	}
 
-	// ค้นหาผู้ใช้ในฐานข้อมูล
-	var user models.User
-	result := h.db.Where("google_id = ?", userInfo.ID).First(&user)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			// ผู้ใช้ยังไม่มีในฐานข้อมูล
-			user = models.User{
-				GoogleID:    userInfo.ID,
-				Email:       userInfo.Email,
-				DisplayName: userInfo.Name,
-				PictureURL:  userInfo.Picture,
-			}
-
-			// สร้างผู้ใช้ใหม่
-			if err := h.db.Create(&user).Error; err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error": "Failed to create user",
-				})
-			}
-		} else {
-			// ถ้ามีข้อผิดพลาดในการค้นหาผู้ใช้
+	// ค้นหาผู้ใช้ที่เชื่อมกับ identity นี้ หรือสร้างใหม่
+	// ให้สิทธิ์ admin ตาม ADMIN_EMAILS เฉพาะอีเมลที่ provider ยืนยันแล้ว
+	adminEmail := userInfo.EmailVerified && h.isAdminEmail(userInfo.Email)
+	newUserRole := models.UserRoleUser
+	if adminEmail {
+		newUserRole = models.UserRoleAdmin
+	}
+	resolved, err := h.identityService.ResolveUser(providerName, userInfo, newUserRole)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to resolve user",
+		})
+	}
+	user := *resolved
+
+	// บัญชีที่ถูกระงับเข้าสู่ระบบไม่ได้
+	if user.IsDisabled() {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Account disabled",
+		})
+	}
+
+	// เลื่อนเป็น admin ถ้าอีเมลอยู่ในรายการผู้ดูแลระบบ
+	if !user.IsAdmin() && adminEmail {
+		if err := h.db.Model(&user).Update("role", models.UserRoleAdmin).Error; err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to query user from database",
+				"error": "Failed to update user role",
 			})
 		}
 	}
 
-	// สร้าง JWT token
-	jwtToken, err := h.jwtService.GenerateToken(&user)
+	// ออก access token และ refresh token ชุดใหม่
+	tokens, err := h.tokenService.IssueTokens(&user, sessionInfo(c))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to generate token",
 		})
 	}
-
-	// ตั้งค่า cookie แบบ HTTP-only เพื่อป้องกัน XSS
-	c.Cookie(&fiber.Cookie{
-		Name:     "auth_token",
-		Value:    jwtToken,
-		Path:     "/",
-		HTTPOnly: true,
-		// ถ้าใช้งานบน localhost ในระหว่างการพัฒนา ให้ตั้งค่า Secure เป็น false
-		// ในสภาพแวดล้อมการทำงานจริง (production) ให้เปลี่ยนเป็น true
-		Secure: false,
-		SameSite: "Lax",
-		MaxAge:   60 * 60 * 24 * 7, // 1 week
-	})
+	setAuthCookies(c, tokens)
 
 	// ส่งข้อมูลกลับโดยไม่รวม token ใน response body
-	// เนื่องจากเราใช้ HTTP-only cookie แล้ว
-	frontendURL := "http://localhost:4000/auth/google/callback"
-	return c.Redirect(frontendURL)
+	// เนื่องจากเราใช้ HTTP-only cookie แล้ว และกลับไปยังหน้า callback ของ provider นั้นใน frontend
+	return c.Redirect(h.frontendURL + "/auth/" + url.PathEscape(providerName) + "/callback")
 }
 
-// Logout จัดการการออกจากระบบโดยลบ cookie
+// Refresh ต่ออายุ access token ด้วย refresh token จาก cookie และหมุน refresh token ใหม่
+func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
+	tokens, err := h.tokenService.Refresh(c.Cookies("refresh_token"), sessionInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidRefreshToken), errors.Is(err, services.ErrRefreshTokenReused):
+			clearAuthCookies(c)
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, services.ErrAccountDisabled):
+			clearAuthCookies(c)
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Account disabled"})
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to refresh token"})
+		}
+	}
+
+	setAuthCookies(c, tokens)
+	return c.JSON(fiber.Map{
+		"message": "Token refreshed successfully",
+	})
+}
+
+// Logout จัดการการออกจากระบบโดยเพิกถอน refresh token ฝั่ง server และลบ cookie
 func (h *AuthHandler) Logout(c *fiber.Ctx) error {
-	// ลบ cookie โดยการตั้งค่า MaxAge เป็นค่าลบ
-	c.Cookie(&fiber.Cookie{
-		Name:     "auth_token",
-		Value:    "",
-		Path:     "/",
-		MaxAge:   -1,
-		Expires:  time.Now().Add(-time.Hour),
-		HTTPOnly: true,
-		SameSite: "Lax",
-		Secure:   false, // ตั้งเป็น true ในโหมด production
+	if err := h.tokenService.Revoke(c.Cookies("refresh_token")); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to revoke refresh token",
+		})
+	}
+	clearAuthCookies(c)
+
+	return c.Redirect(h.frontendURL)
+}
+
+// GetSessions ดึงรายการ session ที่ยังเข้าสู่ระบบอยู่ของผู้ใช้ปัจจุบัน
+func (h *AuthHandler) GetSessions(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	sessionID, _ := c.Locals("sessionID").(uint)
+
+	sessions, err := h.sessionService.ListSessions(userID, sessionID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch sessions",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"sessions": sessions,
 	})
+}
+
+// RevokeSession ออกจากระบบ session ที่ระบุ access token ของ session นั้นจะใช้ไม่ได้ทันที
+func (h *AuthHandler) RevokeSession(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid session ID",
+		})
+	}
+
+	if err := h.sessionService.RevokeSession(uint(id), userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Session not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to revoke session",
+		})
+	}
+
+	// ถ้าเพิกถอน session ปัจจุบันก็ลบ cookie ไปด้วย
+	if sessionID, _ := c.Locals("sessionID").(uint); sessionID == uint(id) {
+		clearAuthCookies(c)
+	}
 
-	frontendURL := "http://localhost:4000"
-	return c.Redirect(frontendURL)
+	return c.JSON(fiber.Map{
+		"message": "Session revoked successfully",
+	})
 }
 
 // GetCurrentUser คืนค่าข้อมูลผู้ใช้ปัจจุบันจาก JWT token
@@ -157,7 +315,9 @@ func (h *AuthHandler) GetCurrentUser(c *fiber.Ctx) error {
 			"id":          user.ID,
 			"email":       user.Email,
 			"displayName": user.DisplayName,
-			"pictureUrl":  user.PictureURL,
+			"nickname":    user.Nickname,
+			"pictureUrl":  user.Picture(),
+			"role":        user.Role,
 		},
 	})
 }
\ No newline at end of file
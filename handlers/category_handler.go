@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+
+	"github.com/patiphanak/league-of-quiz/dto"
+	"github.com/patiphanak/league-of-quiz/services"
+	"github.com/patiphanak/league-of-quiz/utils"
+)
+
+// CategoryHandler สำหรับการจัดการ API ของหมวดหมู่
+type CategoryHandler struct {
+	categoryService *services.CategoryService
+}
+
+// NewCategoryHandler สร้าง instance ใหม่ของ CategoryHandler
+func NewCategoryHandler(categoryService *services.CategoryService) *CategoryHandler {
+	return &CategoryHandler{
+		categoryService: categoryService,
+	}
+}
+
+// GetCategories ดึงหมวดหมู่ทั้งหมด ส่ง ?tree=true เพื่อรับเป็นต้นไม้
+func (h *CategoryHandler) GetCategories(c *fiber.Ctx) error {
+	getCategories := h.categoryService.GetCategories
+	if c.QueryBool("tree") {
+		getCategories = h.categoryService.GetCategoryTree
+	}
+
+	categories, err := getCategories()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": categories,
+	})
+}
+
+// GetCategory ดึงหมวดหมู่จาก ID หรือ slug
+func (h *CategoryHandler) GetCategory(c *fiber.Ctx) error {
+	category, err := h.categoryService.GetCategory(c.Params("id"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Category not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": category,
+	})
+}
+
+// CreateCategory สร้างหมวดหมู่ใหม่
+func (h *CategoryHandler) CreateCategory(c *fiber.Ctx) error {
+	var req dto.CategoryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	category, err := h.categoryService.CreateCategory(req)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Category created successfully",
+		"data":    category,
+	})
+}
+
+// UpdateCategory แก้ไขหมวดหมู่
+func (h *CategoryHandler) UpdateCategory(c *fiber.Ctx) error {
+	id, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var req dto.CategoryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	category, err := h.categoryService.UpdateCategory(id, req)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Category not found"})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Category updated successfully",
+		"data":    category,
+	})
+}
+
+// DeleteCategory ลบหมวดหมู่
+func (h *CategoryHandler) DeleteCategory(c *fiber.Ctx) error {
+	id, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.categoryService.DeleteCategory(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Category not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Category deleted successfully",
+	})
+}
@@ -43,6 +43,12 @@ func (h *ChoiceHandler) GetChoicesByQuestionID(c *fiber.Ctx) error {
 		})
 	}
 
+	if !canSeeAnswers(c) {
+		for i := range choices {
+			choices[i].HideAnswers()
+		}
+	}
+
 	return c.JSON(fiber.Map{
 		"data": choices,
 	})
@@ -66,6 +72,10 @@ func (h *ChoiceHandler) GetChoiceByID(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal server error"})
 	}
 
+	if !canSeeAnswers(c) {
+		choice.HideAnswers()
+	}
+
 	return c.JSON(fiber.Map{
 		"data": choice,
 	})
@@ -104,6 +114,9 @@ func (h *ChoiceHandler) CreateChoice(c *fiber.Ctx) error {
 
 		// สร้างตัวเลือก
 		if err := h.choiceService.CreateChoice(&choice, nil, userID); err != nil {
+			if strings.HasPrefix(err.Error(), "unauthorized") {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+			}
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": err.Error(),
 			})
@@ -156,6 +169,9 @@ func (h *ChoiceHandler) CreateChoice(c *fiber.Ctx) error {
 
 		// สร้างตัวเลือกพร้อมรูปภาพ
 		if err := h.choiceService.CreateChoice(choice, imageFile, userID); err != nil {
+			if strings.HasPrefix(err.Error(), "unauthorized") {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+			}
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": err.Error(),
 			})
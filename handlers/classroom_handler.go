@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+
+	"github.com/patiphanak/league-of-quiz/dto"
+	"github.com/patiphanak/league-of-quiz/services"
+	"github.com/patiphanak/league-of-quiz/utils"
+)
+
+// ClassroomHandler สำหรับการจัดการ API ของห้องเรียนและรายชื่อนักเรียน
+type ClassroomHandler struct {
+	classroomService *services.ClassroomService
+}
+
+// NewClassroomHandler สร้าง instance ใหม่ของ ClassroomHandler
+func NewClassroomHandler(classroomService *services.ClassroomService) *ClassroomHandler {
+	return &ClassroomHandler{
+		classroomService: classroomService,
+	}
+}
+
+// CreateClassroom สร้างห้องเรียนในองค์กร
+func (h *ClassroomHandler) CreateClassroom(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	orgID, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var req dto.CreateClassroomRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	classroom, err := h.classroomService.CreateClassroom(orgID, req, userID)
+	if err != nil {
+		return c.Status(organizationErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Classroom created successfully",
+		"data":    classroom,
+	})
+}
+
+// GetOrganizationClassrooms ดึงห้องเรียนทั้งหมดขององค์กร
+func (h *ClassroomHandler) GetOrganizationClassrooms(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	orgID, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	classrooms, err := h.classroomService.ListClassrooms(orgID, userID)
+	if err != nil {
+		return c.Status(organizationErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": classrooms,
+	})
+}
+
+// GetMyClassrooms ดึงห้องเรียนที่ผู้ใช้สอนหรืออยู่ในรายชื่อ
+func (h *ClassroomHandler) GetMyClassrooms(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	classrooms, err := h.classroomService.ListMyClassrooms(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch classrooms"})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": classrooms,
+	})
+}
+
+// GetClassroom ดึงข้อมูลห้องเรียน
+func (h *ClassroomHandler) GetClassroom(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	classroomID, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	classroom, err := h.classroomService.GetClassroom(classroomID, userID)
+	if err != nil {
+		return c.Status(organizationErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": classroom,
+	})
+}
+
+// UpdateClassroom แก้ไขชื่อหรือครูประจำห้อง
+func (h *ClassroomHandler) UpdateClassroom(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	classroomID, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var req dto.UpdateClassroomRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	classroom, err := h.classroomService.UpdateClassroom(classroomID, req, userID)
+	if err != nil {
+		return c.Status(organizationErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Classroom updated successfully",
+		"data":    classroom,
+	})
+}
+
+// DeleteClassroom ลบห้องเรียน
+func (h *ClassroomHandler) DeleteClassroom(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	classroomID, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.classroomService.DeleteClassroom(classroomID, userID); err != nil {
+		return c.Status(organizationErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Classroom deleted successfully",
+	})
+}
+
+// GetRoster ดึงรายชื่อนักเรียนของห้องเรียน
+func (h *ClassroomHandler) GetRoster(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	classroomID, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	members, err := h.classroomService.ListRoster(classroomID, userID)
+	if err != nil {
+		return c.Status(organizationErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": members,
+	})
+}
+
+// AddRosterMember เพิ่มผู้ใช้เข้ารายชื่อด้วยอีเมล
+func (h *ClassroomHandler) AddRosterMember(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	classroomID, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var req dto.AddClassroomMemberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	member, err := h.classroomService.AddRosterMember(classroomID, req.Email, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User or classroom not found"})
+		}
+		return c.Status(organizationErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Student added successfully",
+		"data":    member,
+	})
+}
+
+// RemoveRosterMember ลบผู้ใช้ออกจากรายชื่อ
+func (h *ClassroomHandler) RemoveRosterMember(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	classroomID, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	memberID, statusCode, err := utils.ParseIDParam(c, "userId")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.classroomService.RemoveRosterMember(classroomID, memberID, userID); err != nil {
+		return c.Status(organizationErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Student removed successfully",
+	})
+}
+
+// GetResults ดึงผลของทุกเกมที่ห้องเรียนเล่น
+func (h *ClassroomHandler) GetResults(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	classroomID, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	results, err := h.classroomService.GetResults(classroomID, userID)
+	if err != nil {
+		return c.Status(organizationErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": results,
+	})
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"bytes"
+	"html/template"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/patiphanak/league-of-quiz/auth/oauth"
+)
+
+// devLoginPage หน้าเลือกผู้ใช้ทดสอบของ DevProvider
+var devLoginPage = template.Must(template.New("dev-login").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>League of Quiz – Dev login</title>
+<style>
+body { font-family: sans-serif; max-width: 28rem; margin: 3rem auto; }
+form { margin: 0.5rem 0; }
+button { width: 100%; padding: 0.5rem; cursor: pointer; }
+input { width: 100%; padding: 0.4rem; margin: 0.2rem 0; box-sizing: border-box; }
+.warning { color: #b00; font-size: 0.9rem; }
+</style>
+</head>
+<body>
+<h1>Dev login</h1>
+<p class="warning">Development only: anyone can sign in as any user.</p>
+{{range .Identities}}
+<form method="post" action="/auth/dev/authorize">
+<input type="hidden" name="state" value="{{$.State}}">
+<input type="hidden" name="email" value="{{.Email}}">
+<input type="hidden" name="name" value="{{.Name}}">
+<button type="submit">{{.Name}} &lt;{{.Email}}&gt;</button>
+</form>
+{{end}}
+<h2>Other identity</h2>
+<form method="post" action="/auth/dev/authorize">
+<input type="hidden" name="state" value="{{.State}}">
+<input type="email" name="email" placeholder="email" required>
+<input type="text" name="name" placeholder="display name">
+<button type="submit">Sign in</button>
+</form>
+</body>
+</html>
+`))
+
+// DevAuthHandler หน้าเข้าสู่ระบบของ provider "dev" สำหรับพัฒนาและทดสอบ
+type DevAuthHandler struct {
+	provider *oauth.DevProvider
+}
+
+// NewDevAuthHandler สร้าง instance ใหม่ของ DevAuthHandler
+func NewDevAuthHandler(provider *oauth.DevProvider) *DevAuthHandler {
+	return &DevAuthHandler{
+		provider: provider,
+	}
+}
+
+// AuthorizePage แสดงหน้าเลือกหรือกรอกผู้ใช้ทดสอบ
+func (h *DevAuthHandler) AuthorizePage(c *fiber.Ctx) error {
+	var page bytes.Buffer
+	err := devLoginPage.Execute(&page, fiber.Map{
+		"State":      c.Query("state"),
+		"Identities": oauth.DefaultDevIdentities,
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to render login page"})
+	}
+
+	c.Type("html", "utf-8")
+	return c.Send(page.Bytes())
+}
+
+// Authorize ออก authorization code ให้ผู้ใช้ทดสอบแล้ว redirect ไป callback ของ provider "dev"
+func (h *DevAuthHandler) Authorize(c *fiber.Ctx) error {
+	identity := oauth.DevIdentity{
+		Email: c.FormValue("email"),
+		Name:  c.FormValue("name"),
+	}
+
+	callbackURL, err := h.provider.Authorize(identity, c.FormValue("state"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Redirect(callbackURL)
+}
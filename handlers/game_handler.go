@@ -1,12 +1,18 @@
 package handlers
 
 import (
+	"errors"
 	"log"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/patiphanak/league-of-quiz/dto"
+	models "github.com/patiphanak/league-of-quiz/model"
 	"github.com/patiphanak/league-of-quiz/services"
+	"github.com/patiphanak/league-of-quiz/utils"
+	"gorm.io/gorm"
 )
 
 // GameHandler จัดการ HTTP requests สำหรับเกม
@@ -26,7 +32,18 @@ func NewGameHandler(gameService *services.GameService) *GameHandler {
 
 // CreateGameSessionRequest คือ request body สำหรับการสร้างเกม
 type CreateGameSessionRequest struct {
-	QuizID uint `json:"quizId"`
+	QuizID           uint   `json:"quizId"`
+	ShuffleQuestions bool   `json:"shuffleQuestions"`
+	ShuffleChoices   bool   `json:"shuffleChoices"`
+	ShuffleMode      string `json:"shuffleMode"`     // room หรือ player
+	ClassroomID      *uint  `json:"classroomId"`     // จำกัดผู้เข้าร่วมเฉพาะรายชื่อของห้องเรียน
+	AllowedUserIDs   []uint `json:"allowedUserIds"`  // จำกัดผู้เข้าร่วมเฉพาะผู้ใช้เหล่านี้
+	Password         string `json:"password"`        // รหัสผ่านเข้าร่วมเกม
+	RequireApproval  bool   `json:"requireApproval"` // ผู้เล่นต้องรอโฮสต์อนุมัติ
+	MaxPlayers       int    `json:"maxPlayers"`      // จำนวนผู้เล่นสูงสุดไม่นับโฮสต์ 0 หมายถึงไม่จำกัด
+	TeamMode         bool   `json:"teamMode"`        // เล่นเป็นทีม
+	TeamScoring      string `json:"teamScoring"`     // sum หรือ average
+	TeamTalkSeconds  uint   `json:"teamTalkSeconds"` // ช่วงคุยกันในทีมก่อนรับคำตอบ 0 หมายถึงไม่มี
 }
 
 // CreateGameSession สร้างเกมใหม่
@@ -64,9 +81,40 @@ func (h *GameHandler) CreateGameSession(c *fiber.Ctx) error {
 	log.Println("userID", userID)
 	log.Println("req.QuizID", req.QuizID)
 
-	session, err := h.gameService.CreateGameSession(userID, req.QuizID)
+	session, err := h.gameService.CreateGameSession(userID, req.QuizID, services.GameSessionOptions{
+		ShuffleQuestions: req.ShuffleQuestions,
+		ShuffleChoices:   req.ShuffleChoices,
+		ShuffleMode:      req.ShuffleMode,
+		ClassroomID:      req.ClassroomID,
+		MaxPlayers:       req.MaxPlayers,
+		Access: services.GameSessionAccess{
+			AllowedUserIDs:  req.AllowedUserIDs,
+			Password:        req.Password,
+			RequireApproval: req.RequireApproval,
+		},
+		Teams: services.GameTeamSettings{
+			Enabled:     req.TeamMode,
+			Scoring:     req.TeamScoring,
+			TalkSeconds: req.TeamTalkSeconds,
+		},
+	})
 	if err != nil {
 		log.Printf("Error creating game session: %v", err)
+		if errors.Is(err, services.ErrInvalidMaxPlayers) || errors.Is(err, services.ErrInvalidTeamSettings) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Quiz or classroom not found",
+			})
+		}
+		if strings.HasPrefix(err.Error(), "unauthorized") {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -147,6 +195,7 @@ func (h *GameHandler) GetGameSessionDetail(c *fiber.Ctx) error {
 // JoinGameSessionRequest คือ request body สำหรับการเข้าร่วมเกม
 type JoinGameSessionRequest struct {
 	Nickname string `json:"nickname"`
+	Password string `json:"password"`
 }
 
 // JoinGameSession เข้าร่วมเกม
@@ -173,19 +222,36 @@ func (h *GameHandler) JoinGameSession(c *fiber.Ctx) error {
 		})
 	}
 
-	// ใช้ชื่อเล่นจาก request หรือสร้างชื่อเล่นจากเวลาปัจจุบัน
-	nickname := req.Nickname
+	// ใช้ชื่อเล่นจาก request ถ้าไม่ส่งมาใช้ชื่อเล่นหรือชื่อที่แสดงในโปรไฟล์
+	// ถ้าไม่มีทั้งหมดจึงสร้างชื่อเล่นจากเวลาปัจจุบัน
+	nickname := strings.TrimSpace(req.Nickname)
+	if nickname == "" {
+		if user, ok := c.Locals("user").(models.User); ok {
+			nickname = user.Nickname
+			if nickname == "" {
+				nickname = user.DisplayName
+			}
+		}
+	}
 	if nickname == "" {
 		nickname = "Player_" + strconv.FormatInt(time.Now().Unix(), 10)
 	}
 
-	player, err := h.gameService.JoinGameSession(sessionID, userID, nickname)
+	player, request, err := h.gameService.JoinGameSession(sessionID, userID, nickname, req.Password)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		return c.Status(gameAccessErrorStatus(err, fiber.StatusBadRequest)).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
+	// เกมที่ต้องให้โฮสต์อนุมัติ ผู้เล่นรอจนกว่าจะได้ event join_approved
+	if request != nil {
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+			"message":     "Waiting for the host to approve your join request",
+			"joinRequest": request,
+		})
+	}
+
 	return c.JSON(fiber.Map{
 		"message": "Joined game session successfully",
 		"player":  player,
@@ -320,8 +386,352 @@ func (h *GameHandler) GetGameResults(c *fiber.Ctx) error {
 		})
 	}
 
-	return c.JSON(fiber.Map{
+	results := fiber.Map{
 		"session": session,
 		"players": players,
+	}
+
+	// เกมโหมดทีมส่งอันดับของทีมไปด้วย
+	if session.TeamMode {
+		teams, err := h.gameService.GetTeamLeaderboard(sessionID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get team results",
+			})
+		}
+		results["teams"] = teams
+	}
+
+	return c.JSON(results)
+}
+
+// GetGameReview ดึงเฉลยและคำอธิบายของทุกคำถามเพื่อทบทวนหลังจบเกม
+func (h *GameHandler) GetGameReview(c *fiber.Ctx) error {
+	sessionID := c.Params("id")
+	if sessionID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Session ID is required",
+		})
+	}
+
+	// ดึง userID จาก context
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	review, err := h.gameService.GetGameReview(sessionID, userID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"sessionId": sessionID,
+		"questions": review,
+	})
+}
+
+// gameAccessErrorStatus แปลงข้อผิดพลาดของการจำกัดการเข้าร่วมเกมเป็น HTTP status
+func gameAccessErrorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, services.ErrTooManyJoinAttempts):
+		return fiber.StatusTooManyRequests
+	case errors.Is(err, services.ErrSessionFull):
+		return fiber.StatusConflict
+	case errors.Is(err, services.ErrLobbyLocked):
+		return fiber.StatusForbidden
+	case strings.HasPrefix(err.Error(), "unauthorized"):
+		return fiber.StatusForbidden
+	}
+	return fallback
+}
+
+// GetSessionAccess ดึงการจำกัดการเข้าร่วมของเกม เฉพาะโฮสต์
+func (h *GameHandler) GetSessionAccess(c *fiber.Ctx) error {
+	sessionID := c.Params("id")
+	if sessionID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Session ID is required",
+		})
+	}
+
+	// ดึง userID จาก context
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	access, err := h.gameService.GetSessionAccess(sessionID, userID)
+	if err != nil {
+		return c.Status(gameAccessErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(access)
+}
+
+// UpdateSessionAccess เปลี่ยนรายชื่อที่อนุญาต ห้องเรียน รหัสผ่าน หรือการอนุมัติของเกม
+func (h *GameHandler) UpdateSessionAccess(c *fiber.Ctx) error {
+	sessionID := c.Params("id")
+	if sessionID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Session ID is required",
+		})
+	}
+
+	var req dto.UpdateGameSessionAccessRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// ดึง userID จาก context
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	access, err := h.gameService.UpdateSessionAccess(sessionID, userID, req)
+	if err != nil {
+		return c.Status(gameAccessErrorStatus(err, fiber.StatusBadRequest)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(access)
+}
+
+// LockLobby ล็อกล็อบบี้ไม่ให้ผู้เล่นใหม่เข้าร่วม
+func (h *GameHandler) LockLobby(c *fiber.Ctx) error {
+	return h.setLobbyLocked(c, true)
+}
+
+// UnlockLobby ปลดล็อกล็อบบี้ให้ผู้เล่นใหม่เข้าร่วมได้อีกครั้ง
+func (h *GameHandler) UnlockLobby(c *fiber.Ctx) error {
+	return h.setLobbyLocked(c, false)
+}
+
+// setLobbyLocked ใช้ร่วมกันระหว่าง LockLobby และ UnlockLobby
+func (h *GameHandler) setLobbyLocked(c *fiber.Ctx, locked bool) error {
+	sessionID := c.Params("id")
+	if sessionID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Session ID is required",
+		})
+	}
+
+	// ดึง userID จาก context
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	session, err := h.gameService.SetLobbyLocked(sessionID, userID, locked)
+	if err != nil {
+		return c.Status(gameAccessErrorStatus(err, fiber.StatusBadRequest)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	message := "Lobby unlocked"
+	if locked {
+		message = "Lobby locked"
+	}
+	return c.JSON(fiber.Map{
+		"message": message,
+		"session": session,
+	})
+}
+
+// GetJoinRequests ดึงคำขอเข้าร่วมที่รอโฮสต์อนุมัติ
+func (h *GameHandler) GetJoinRequests(c *fiber.Ctx) error {
+	sessionID := c.Params("id")
+	if sessionID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Session ID is required",
+		})
+	}
+
+	// ดึง userID จาก context
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	requests, err := h.gameService.ListJoinRequests(sessionID, userID)
+	if err != nil {
+		return c.Status(gameAccessErrorStatus(err, fiber.StatusInternalServerError)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"joinRequests": requests,
+	})
+}
+
+// ApproveJoinRequest อนุมัติคำขอเข้าร่วมและเพิ่มผู้เล่นเข้าเกม
+func (h *GameHandler) ApproveJoinRequest(c *fiber.Ctx) error {
+	sessionID := c.Params("id")
+	if sessionID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Session ID is required",
+		})
+	}
+
+	requesterID, status, err := utils.ParseIDParam(c, "userId")
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// ดึง userID จาก context
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	player, err := h.gameService.ApproveJoinRequest(sessionID, userID, requesterID)
+	if err != nil {
+		return c.Status(gameAccessErrorStatus(err, fiber.StatusBadRequest)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Join request approved",
+		"player":  player,
+	})
+}
+
+// DenyJoinRequest ปฏิเสธคำขอเข้าร่วม
+func (h *GameHandler) DenyJoinRequest(c *fiber.Ctx) error {
+	sessionID := c.Params("id")
+	if sessionID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Session ID is required",
+		})
+	}
+
+	requesterID, status, err := utils.ParseIDParam(c, "userId")
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// ดึง userID จาก context
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	if err := h.gameService.DenyJoinRequest(sessionID, userID, requesterID); err != nil {
+		return c.Status(gameAccessErrorStatus(err, fiber.StatusBadRequest)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Join request denied",
+	})
+}
+
+// GetTeams ดึงทีมของเกมโหมดทีมพร้อมสมาชิกและคะแนนทีม
+func (h *GameHandler) GetTeams(c *fiber.Ctx) error {
+	sessionID := c.Params("id")
+	if sessionID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Session ID is required",
+		})
+	}
+
+	teams, err := h.gameService.GetTeams(sessionID)
+	if err != nil {
+		return c.Status(gameAccessErrorStatus(err, fiber.StatusBadRequest)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(teams)
+}
+
+// GetTeamLeaderboard ดึงอันดับของทีมตามคะแนนทีม
+func (h *GameHandler) GetTeamLeaderboard(c *fiber.Ctx) error {
+	sessionID := c.Params("id")
+	if sessionID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Session ID is required",
+		})
+	}
+
+	standings, err := h.gameService.GetTeamLeaderboard(sessionID)
+	if err != nil {
+		return c.Status(gameAccessErrorStatus(err, fiber.StatusBadRequest)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"sessionId": sessionID,
+		"teams":     standings,
+	})
+}
+
+// UpdateTeamSettings เปิดหรือปิดโหมดทีม และเปลี่ยนวิธีคิดคะแนนหรือช่วงคุยกันในทีม
+func (h *GameHandler) UpdateTeamSettings(c *fiber.Ctx) error {
+	sessionID := c.Params("id")
+	if sessionID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Session ID is required",
+		})
+	}
+
+	var req dto.UpdateTeamSettingsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// ดึง userID จาก context
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	session, err := h.gameService.UpdateTeamSettings(sessionID, userID, req)
+	if err != nil {
+		return c.Status(gameAccessErrorStatus(err, fiber.StatusBadRequest)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Team settings updated",
+		"session": session,
 	})
 }
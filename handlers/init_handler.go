@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"github.com/patiphanak/league-of-quiz/auth/jwt"
 	"github.com/patiphanak/league-of-quiz/auth/oauth"
 	"github.com/patiphanak/league-of-quiz/services"
 	"gorm.io/gorm"
@@ -9,27 +8,56 @@ import (
 
 // AllHandlers รวบรวม handler ทั้งหมด
 type AllHandlers struct {
-	Auth     *AuthHandler
-	Quiz     *QuizHandler
-	Upload   *UploadHandler
-	Question *QuestionHandler
-	Choice   *ChoiceHandler
-	Game     *GameHandler
+	Auth         *AuthHandler
+	LocalAuth    *LocalAuthHandler
+	AccessToken  *PersonalAccessTokenHandler
+	DevAuth      *DevAuthHandler
+	Account      *AccountHandler
+	Quiz         *QuizHandler
+	Upload       *UploadHandler
+	Question     *QuestionHandler
+	QuestionBank *QuestionBankHandler
+	Choice       *ChoiceHandler
+	Collaborator *QuizCollaboratorHandler
+	Organization *OrganizationHandler
+	Classroom    *ClassroomHandler
+	Admin        *AdminHandler
+	Category     *CategoryHandler
+	Game         *GameHandler
 }
 
 // InitHandlers สร้าง instance ทั้งหมดของ handlers
 func InitHandlers(
 	services *services.Services,
 	db *gorm.DB,
-	jwtService *jwt.JWTService,
-	googleOAuth *oauth.GoogleOAuth,
+	providers *oauth.Registry,
+	adminEmails []string,
+	frontendURL string,
 ) *AllHandlers {
+	// หน้าเข้าสู่ระบบของ provider "dev" มีเฉพาะเมื่อเปิดใช้ใน config
+	var devAuthHandler *DevAuthHandler
+	if provider, ok := providers.Get("dev"); ok {
+		if devProvider, ok := provider.(*oauth.DevProvider); ok {
+			devAuthHandler = NewDevAuthHandler(devProvider)
+		}
+	}
+
 	return &AllHandlers{
-		Auth:     NewAuthHandler(db, googleOAuth, jwtService),
-		Quiz:     NewQuizHandler(services.Quiz, services.File),
-		Upload:   NewUploadHandler(services.File),
-		Question: NewQuestionHandler(services.Question, services.File, services.Choice),
-		Choice:   NewChoiceHandler(services.Choice, services.File),
-		Game:     NewGameHandler(services.GameService),
+		Auth:         NewAuthHandler(db, providers, services.Identity, services.Token, services.Session, adminEmails, frontendURL),
+		LocalAuth:    NewLocalAuthHandler(services.LocalAuth, services.Token, adminEmails),
+		AccessToken:  NewPersonalAccessTokenHandler(services.AccessToken),
+		DevAuth:      devAuthHandler,
+		Account:      NewAccountHandler(services.Account),
+		Quiz:         NewQuizHandler(services.Quiz, services.File),
+		Upload:       NewUploadHandler(services.Upload),
+		Question:     NewQuestionHandler(services.Question, services.File, services.Choice),
+		QuestionBank: NewQuestionBankHandler(services.QuestionBank),
+		Choice:       NewChoiceHandler(services.Choice, services.File),
+		Collaborator: NewQuizCollaboratorHandler(services.Collaborator),
+		Organization: NewOrganizationHandler(services.Organization),
+		Classroom:    NewClassroomHandler(services.Classroom),
+		Admin:        NewAdminHandler(services.Admin),
+		Category:     NewCategoryHandler(services.Category),
+		Game:         NewGameHandler(services.GameService),
 	}
 }
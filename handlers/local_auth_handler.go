@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/patiphanak/league-of-quiz/dto"
+	"github.com/patiphanak/league-of-quiz/services"
+)
+
+// LocalAuthHandler สำหรับการสมัครและเข้าสู่ระบบด้วยอีเมลและรหัสผ่าน
+type LocalAuthHandler struct {
+	localAuthService *services.LocalAuthService
+	tokenService     *services.TokenService
+	adminEmails      []string
+}
+
+// NewLocalAuthHandler สร้าง instance ใหม่ของ LocalAuthHandler
+func NewLocalAuthHandler(localAuthService *services.LocalAuthService, tokenService *services.TokenService, adminEmails []string) *LocalAuthHandler {
+	return &LocalAuthHandler{
+		localAuthService: localAuthService,
+		tokenService:     tokenService,
+		adminEmails:      adminEmails,
+	}
+}
+
+// Register สมัครสมาชิกและส่งอีเมลยืนยัน ต้องยืนยันอีเมลก่อนจึงเข้าสู่ระบบได้
+func (h *LocalAuthHandler) Register(c *fiber.Ctx) error {
+	var req dto.RegisterRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	user, err := h.localAuthService.Register(req.Email, req.Password, req.DisplayName)
+	if err != nil {
+		if errors.Is(err, services.ErrEmailTaken) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Registration successful, please check your email to verify your account",
+		"user": fiber.Map{
+			"id":    user.ID,
+			"email": user.Email,
+			"name":  user.DisplayName,
+		},
+	})
+}
+
+// Login เข้าสู่ระบบด้วยอีเมลและรหัสผ่าน และตั้ง cookie แบบเดียวกับการเข้าสู่ระบบผ่าน provider
+func (h *LocalAuthHandler) Login(c *fiber.Ctx) error {
+	var req dto.LoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	user, err := h.localAuthService.Login(req.Email, req.Password, c.IP())
+	if err != nil {
+		var throttled *services.ErrTooManyAttempts
+		switch {
+		case errors.As(err, &throttled):
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(throttled.RetryAfter.Seconds())+1))
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidCredentials):
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, services.ErrEmailNotVerified):
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, services.ErrAccountDisabled):
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Account disabled"})
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to log in"})
+		}
+	}
+
+	// เลื่อนเป็น admin ถ้าอีเมล (ที่ยืนยันแล้ว) อยู่ในรายการผู้ดูแลระบบ
+	if !user.IsAdmin() && isAdminEmail(h.adminEmails, user.Email) {
+		if err := h.localAuthService.PromoteToAdmin(user); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to update user role",
+			})
+		}
+	}
+
+	tokens, err := h.tokenService.IssueTokens(user, sessionInfo(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to generate token",
+		})
+	}
+	setAuthCookies(c, tokens)
+
+	return c.JSON(fiber.Map{
+		"user": fiber.Map{
+			"id":      user.ID,
+			"email":   user.Email,
+			"name":    user.DisplayName,
+			"picture": user.Picture(),
+			"role":    user.Role,
+		},
+	})
+}
+
+// VerifyEmail ยืนยันอีเมลด้วย token จากลิงก์ในอีเมล
+func (h *LocalAuthHandler) VerifyEmail(c *fiber.Ctx) error {
+	var req dto.EmailTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if err := h.localAuthService.VerifyEmail(req.Token); err != nil {
+		if errors.Is(err, services.ErrInvalidEmailToken) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to verify email"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Email verified successfully",
+	})
+}
+
+// ResendVerification ส่งอีเมลยืนยันอีกครั้ง ตอบเหมือนกันเสมอเพื่อไม่ให้รู้ว่ามีบัญชีหรือไม่
+func (h *LocalAuthHandler) ResendVerification(c *fiber.Ctx) error {
+	var req dto.EmailRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if err := h.localAuthService.ResendVerification(req.Email); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to send verification email"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "If the account exists and is not verified, a verification email has been sent",
+	})
+}
+
+// ForgotPassword ส่งลิงก์รีเซ็ตรหัสผ่าน ตอบเหมือนกันเสมอเพื่อไม่ให้รู้ว่ามีบัญชีหรือไม่
+func (h *LocalAuthHandler) ForgotPassword(c *fiber.Ctx) error {
+	var req dto.EmailRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if err := h.localAuthService.RequestPasswordReset(req.Email); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to send password reset email"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "If the account exists, a password reset email has been sent",
+	})
+}
+
+// ResetPassword ตั้งรหัสผ่านใหม่ด้วย token จากอีเมล และออกจากระบบทุกอุปกรณ์
+func (h *LocalAuthHandler) ResetPassword(c *fiber.Ctx) error {
+	var req dto.ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if err := h.localAuthService.ResetPassword(req.Token, req.Password); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	clearAuthCookies(c)
+	return c.JSON(fiber.Map{
+		"message": "Password has been reset, please log in again",
+	})
+}
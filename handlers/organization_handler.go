@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+
+	"github.com/patiphanak/league-of-quiz/dto"
+	"github.com/patiphanak/league-of-quiz/services"
+	"github.com/patiphanak/league-of-quiz/utils"
+)
+
+// OrganizationHandler สำหรับการจัดการ API ขององค์กร สมาชิก และ quiz ที่แชร์ในองค์กร
+type OrganizationHandler struct {
+	organizationService *services.OrganizationService
+}
+
+// NewOrganizationHandler สร้าง instance ใหม่ของ OrganizationHandler
+func NewOrganizationHandler(organizationService *services.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{
+		organizationService: organizationService,
+	}
+}
+
+// organizationErrorStatus แปลงข้อผิดพลาดจาก service ขององค์กรและห้องเรียนเป็น HTTP status
+func organizationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fiber.StatusNotFound
+	case strings.HasPrefix(err.Error(), "unauthorized"):
+		return fiber.StatusForbidden
+	default:
+		return fiber.StatusBadRequest
+	}
+}
+
+// CreateOrganization สร้างองค์กรใหม่
+func (h *OrganizationHandler) CreateOrganization(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var req dto.CreateOrganizationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	org, err := h.organizationService.CreateOrganization(req, userID)
+	if err != nil {
+		return c.Status(organizationErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Organization created successfully",
+		"data":    org,
+	})
+}
+
+// GetMyOrganizations ดึงองค์กรที่ผู้ใช้เป็นสมาชิก
+func (h *OrganizationHandler) GetMyOrganizations(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	orgs, err := h.organizationService.ListOrganizations(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch organizations"})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": orgs,
+	})
+}
+
+// GetOrganization ดึงข้อมูลองค์กร
+func (h *OrganizationHandler) GetOrganization(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	orgID, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	org, err := h.organizationService.GetOrganization(orgID, userID)
+	if err != nil {
+		return c.Status(organizationErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": org,
+	})
+}
+
+// UpdateOrganization แก้ไขชื่อองค์กร
+func (h *OrganizationHandler) UpdateOrganization(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	orgID, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var req dto.UpdateOrganizationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	org, err := h.organizationService.UpdateOrganization(orgID, req, userID)
+	if err != nil {
+		return c.Status(organizationErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Organization updated successfully",
+		"data":    org,
+	})
+}
+
+// DeleteOrganization ลบองค์กร
+func (h *OrganizationHandler) DeleteOrganization(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	orgID, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.organizationService.DeleteOrganization(orgID, userID); err != nil {
+		return c.Status(organizationErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Organization deleted successfully",
+	})
+}
+
+// GetMembers ดึงรายชื่อสมาชิกขององค์กร
+func (h *OrganizationHandler) GetMembers(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	orgID, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	members, err := h.organizationService.ListMembers(orgID, userID)
+	if err != nil {
+		return c.Status(organizationErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": members,
+	})
+}
+
+// AddMember เพิ่มสมาชิกด้วยอีเมล หรือเปลี่ยนบทบาทของสมาชิกเดิม
+func (h *OrganizationHandler) AddMember(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	orgID, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var req dto.AddOrganizationMemberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	member, err := h.organizationService.AddMember(orgID, req, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User or organization not found"})
+		}
+		return c.Status(organizationErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Member saved successfully",
+		"data":    member,
+	})
+}
+
+// RemoveMember ลบสมาชิกออกจากองค์กร
+func (h *OrganizationHandler) RemoveMember(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	orgID, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	memberID, statusCode, err := utils.ParseIDParam(c, "userId")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.organizationService.RemoveMember(orgID, memberID, userID); err != nil {
+		return c.Status(organizationErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Member removed successfully",
+	})
+}
+
+// GetQuizzes ดึง quiz ที่แชร์ในองค์กร รองรับตัวกรองเดียวกับรายการ quiz ทั่วไป
+func (h *OrganizationHandler) GetQuizzes(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	orgID, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	search := c.Query("search", "")
+
+	var categories []uint
+	if categoriesStr := c.Query("categories", ""); categoriesStr != "" {
+		for _, catStr := range strings.Split(categoriesStr, ",") {
+			catID, err := strconv.ParseUint(catStr, 10, 32)
+			if err == nil {
+				categories = append(categories, uint(catID))
+			}
+		}
+	}
+
+	quizzes, count, err := h.organizationService.ListQuizzes(orgID, userID, offset, limit, search, categories)
+	if err != nil {
+		return c.Status(organizationErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// สมาชิกองค์กรเป็นเพียง viewer ของ quiz ที่แชร์ จึงซ่อนเฉลยในรายการ
+	for i := range quizzes {
+		quizzes[i].HideAnswers()
+	}
+
+	return c.JSON(fiber.Map{
+		"data": quizzes,
+		"meta": fiber.Map{
+			"total":  count,
+			"offset": offset,
+			"limit":  limit,
+		},
+	})
+}
+
+// ShareQuiz แชร์ quiz ให้องค์กร หรือยกเลิกการแชร์
+func (h *OrganizationHandler) ShareQuiz(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	quizID, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var req dto.ShareQuizRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if err := h.organizationService.ShareQuiz(quizID, req.OrganizationID, userID); err != nil {
+		return c.Status(organizationErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	message := "Quiz shared with organization successfully"
+	if req.OrganizationID == nil {
+		message = "Quiz is no longer shared with an organization"
+	}
+	return c.JSON(fiber.Map{
+		"message": message,
+	})
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+
+	"github.com/patiphanak/league-of-quiz/dto"
+	"github.com/patiphanak/league-of-quiz/services"
+	"github.com/patiphanak/league-of-quiz/utils"
+)
+
+// PersonalAccessTokenHandler สำหรับจัดการ personal access token ของผู้ใช้
+type PersonalAccessTokenHandler struct {
+	accessTokenService *services.PersonalAccessTokenService
+}
+
+// NewPersonalAccessTokenHandler สร้าง instance ใหม่ของ PersonalAccessTokenHandler
+func NewPersonalAccessTokenHandler(accessTokenService *services.PersonalAccessTokenService) *PersonalAccessTokenHandler {
+	return &PersonalAccessTokenHandler{
+		accessTokenService: accessTokenService,
+	}
+}
+
+// ListTokens ดึงรายการ token ของผู้ใช้ปัจจุบัน
+func (h *PersonalAccessTokenHandler) ListTokens(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	tokens, err := h.accessTokenService.ListTokens(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch tokens"})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": tokens,
+	})
+}
+
+// CreateToken สร้าง token ใหม่ ค่า token แสดงใน response นี้ครั้งเดียว
+func (h *PersonalAccessTokenHandler) CreateToken(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var req dto.CreatePersonalAccessTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	token, err := h.accessTokenService.CreateToken(userID, req)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Token created successfully, copy it now as it will not be shown again",
+		"data":    token,
+	})
+}
+
+// RevokeToken เพิกถอน token
+func (h *PersonalAccessTokenHandler) RevokeToken(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	id, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.accessTokenService.RevokeToken(id, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Token not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to revoke token"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Token revoked successfully",
+	})
+}
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+
+	"github.com/patiphanak/league-of-quiz/dto"
+	"github.com/patiphanak/league-of-quiz/services"
+	"github.com/patiphanak/league-of-quiz/utils"
+)
+
+// QuestionBankHandler สำหรับการจัดการ API ของคลังข้อสอบ
+type QuestionBankHandler struct {
+	bankService *services.QuestionBankService
+}
+
+// NewQuestionBankHandler สร้าง instance ใหม่ของ QuestionBankHandler
+func NewQuestionBankHandler(bankService *services.QuestionBankService) *QuestionBankHandler {
+	return &QuestionBankHandler{
+		bankService: bankService,
+	}
+}
+
+// bankErrorStatus แปลงข้อผิดพลาดจาก service เป็น HTTP status
+func bankErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fiber.StatusNotFound
+	case strings.HasPrefix(err.Error(), "unauthorized"):
+		return fiber.StatusForbidden
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// parseBankQuestionForm อ่านข้อมูลคำถามในคลังจาก form field "questionData"
+func parseBankQuestionForm(c *fiber.Ctx) (*dto.BankQuestionFormData, error) {
+	questionDataStr := c.FormValue("questionData")
+	if questionDataStr == "" {
+		return nil, errors.New("Question data is required")
+	}
+
+	var formData dto.BankQuestionFormData
+	if err := json.Unmarshal([]byte(questionDataStr), &formData); err != nil {
+		log.Printf("Error parsing bank question data: %v", err)
+		return nil, errors.New("Invalid question data format")
+	}
+
+	if formData.Text == "" {
+		return nil, errors.New("Question text is required")
+	}
+	return &formData, nil
+}
+
+// SearchBankQuestions ค้นหาคำถามในคลังของผู้ใช้
+func (h *QuestionBankHandler) SearchBankQuestions(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	search := c.Query("search", "")
+
+	var tags []string
+	if tagsStr := c.Query("tags", ""); tagsStr != "" {
+		tags = strings.Split(tagsStr, ",")
+	}
+
+	questions, count, err := h.bankService.SearchBankQuestions(userID, search, tags, offset, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": questions,
+		"meta": fiber.Map{
+			"total":  count,
+			"offset": offset,
+			"limit":  limit,
+		},
+	})
+}
+
+// GetBankQuestion ดึงคำถามในคลังจาก ID
+func (h *QuestionBankHandler) GetBankQuestion(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	id, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	question, quizIDs, err := h.bankService.GetBankQuestion(id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Bank question not found"})
+		}
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"data":          question,
+		"linkedQuizIds": quizIDs,
+	})
+}
+
+// CreateBankQuestion สร้างคำถามใหม่ในคลัง
+func (h *QuestionBankHandler) CreateBankQuestion(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	formData, err := parseBankQuestionForm(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	imageFile, _ := c.FormFile("image")
+
+	question, err := h.bankService.CreateBankQuestion(*formData, imageFile, userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Bank question created successfully",
+		"data":    question,
+	})
+}
+
+// UpdateBankQuestion อัปเดตคำถามในคลัง
+func (h *QuestionBankHandler) UpdateBankQuestion(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	id, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	formData, err := parseBankQuestionForm(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	imageFile, _ := c.FormFile("image")
+
+	question, skipped, err := h.bankService.UpdateBankQuestion(id, *formData, imageFile, userID)
+	if err != nil {
+		return c.Status(bankErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// คำถามใน quiz ที่มีคำตอบจากเกมที่เล่นไปแล้วจะไม่ถูกซิงก์ เพื่อไม่ให้ผลเกมเดิมเปลี่ยน
+	return c.JSON(fiber.Map{
+		"message":            "Bank question updated successfully",
+		"data":               question,
+		"skippedQuestionIds": skipped,
+	})
+}
+
+// DeleteBankQuestion ลบคำถามในคลัง
+func (h *QuestionBankHandler) DeleteBankQuestion(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	id, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.bankService.DeleteBankQuestion(id, userID); err != nil {
+		return c.Status(bankErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Bank question deleted successfully",
+	})
+}
+
+// AddToQuiz นำคำถามจากคลังไปเพิ่มใน quiz
+func (h *QuestionBankHandler) AddToQuiz(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	quizID, statusCode, err := utils.ParseIDParam(c, "quizId")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var req dto.AddBankQuestionsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	questions, err := h.bankService.AddToQuiz(quizID, req.BankQuestionIDs, userID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Questions added from bank successfully",
+		"data":    questions,
+	})
+}
+
+// DrawQuiz สร้าง quiz ใหม่โดยสุ่มคำถามจากคลังตาม tag
+func (h *QuestionBankHandler) DrawQuiz(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var req dto.DrawQuizRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	quiz, err := h.bankService.DrawQuiz(req, userID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Quiz created from bank successfully",
+		"data": fiber.Map{
+			"id": quiz.ID,
+		},
+	})
+}
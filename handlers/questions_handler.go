@@ -6,12 +6,14 @@ import (
 	"fmt"
 	"log"
 	"mime/multipart"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 
 	"github.com/patiphanak/league-of-quiz/dto"
 	models "github.com/patiphanak/league-of-quiz/model"
+	"github.com/patiphanak/league-of-quiz/quizformat"
 	"github.com/patiphanak/league-of-quiz/services"
 	"github.com/patiphanak/league-of-quiz/utils"
 )
@@ -61,6 +63,12 @@ func (h *QuestionHandler) GetQuestionsByQuizID(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	if !canSeeAnswers(c) {
+		for i := range questions {
+			questions[i].HideAnswers()
+		}
+	}
+
 	return c.JSON(fiber.Map{"data": questions})
 }
 
@@ -82,6 +90,10 @@ func (h *QuestionHandler) GetQuestionByID(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal server error"})
 	}
 
+	if !canSeeAnswers(c) {
+		question.HideAnswers()
+	}
+
 	return c.JSON(fiber.Map{"data": question})
 }
 
@@ -110,20 +122,27 @@ func (h *QuestionHandler) CreateQuestion(c *fiber.Ctx) error {
 	}
 
 	questionImage, _ := c.FormFile("image")
+	explanationImage, _ := c.FormFile("explanationImage")
+	mediaFile, _ := c.FormFile("media")
 
 	choiceImages := getChoiceImages(c, len(formData.Choices))
 
 	question := &models.Question{
-		QuizID: uint(formData.QuizID),
-		Text:   formData.Text,
+		QuizID:      uint(formData.QuizID),
+		Text:        formData.Text,
+		Explanation: formData.Explanation,
 	}
 
 	// เรียกใช้ฟังก์ชันโดยส่ง question แทน
 	questionID, err := h.questionService.CreateQuestionWithChoices(
 		question,
 		formData.Choices,
-		questionImage,
-		choiceImages,
+		services.QuestionFiles{
+			Image:            questionImage,
+			ExplanationImage: explanationImage,
+			Media:            mediaFile,
+			ChoiceImages:     choiceImages,
+		},
 		userID,
 	)
 
@@ -171,8 +190,10 @@ func (h *QuestionHandler) PatchQuestion(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Question text is required"})
 	}
 
-	// รับไฟล์รูปภาพคำถาม (ถ้ามี)
+	// รับไฟล์รูปภาพคำถามและรูปภาพคำอธิบายเฉลย (ถ้ามี)
 	questionImage, _ := c.FormFile("image")
+	explanationImage, _ := c.FormFile("explanationImage")
+	mediaFile, _ := c.FormFile("media")
 
 	// รับไฟล์รูปภาพตัวเลือก
 	choiceImages := make(map[int]*multipart.FileHeader)
@@ -186,10 +207,13 @@ func (h *QuestionHandler) PatchQuestion(c *fiber.Ctx) error {
 	// เรียกใช้ service เพื่ออัปเดตคำถามและตัวเลือกทั้งหมดในครั้งเดียว
 	err = h.questionService.UpdateQuestionWithChoices(
 		questionID,
-		formData.Text,
-		formData.Choices,
-		questionImage,
-		choiceImages,
+		formData,
+		services.QuestionFiles{
+			Image:            questionImage,
+			ExplanationImage: explanationImage,
+			Media:            mediaFile,
+			ChoiceImages:     choiceImages,
+		},
 		userID,
 	)
 
@@ -229,3 +253,117 @@ func (h *QuestionHandler) DeleteQuestion(c *fiber.Ctx) error {
 		"message": "Question deleted successfully",
 	})
 }
+
+// ReorderQuestions จัดลำดับคำถามใน quiz ใหม่
+func (h *QuestionHandler) ReorderQuestions(c *fiber.Ctx) error {
+	// ตรวจสอบว่าผู้ใช้ล็อกอินแล้ว
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// รับ quizID จาก parameter
+	quizID, statusCode, err := utils.ParseIDParam(c, "quizId")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var req dto.QuestionOrderRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if err := h.questionService.ReorderQuestions(quizID, req.QuestionIDs, userID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// ส่งคำถามตามลำดับใหม่กลับไป
+	questions, err := h.questionService.GetQuestionsByQuizID(quizID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Questions reordered successfully",
+		"data":    questions,
+	})
+}
+
+// ImportQuestions นำเข้าคำถามจากไฟล์ CSV หรือ XLSX (ส่ง dryRun=true เพื่อตรวจสอบอย่างเดียว)
+func (h *QuestionHandler) ImportQuestions(c *fiber.Ctx) error {
+	// ตรวจสอบว่าผู้ใช้ล็อกอินแล้ว
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// รับ quizID จาก parameter
+	quizID, statusCode, err := utils.ParseIDParam(c, "quizId")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Import file is required"})
+	}
+
+	result, err := h.questionService.ImportQuestions(quizID, file, c.QueryBool("dryRun", false), userID)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "unauthorized") {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	status := fiber.StatusCreated
+	if result.DryRun {
+		status = fiber.StatusOK
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"message": "Questions import processed",
+		"data":    result,
+	})
+}
+
+// ImportFormattedQuestions นำเข้าคำถามจากไฟล์ข้อความรูปแบบ GIFT หรือ Aiken
+func (h *QuestionHandler) ImportFormattedQuestions(c *fiber.Ctx) error {
+	// ตรวจสอบว่าผู้ใช้ล็อกอินแล้ว
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// รับ quizID จาก parameter
+	quizID, statusCode, err := utils.ParseIDParam(c, "quizId")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	format, err := quizformat.ParseFormat(c.Params("format"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Import file is required"})
+	}
+
+	result, err := h.questionService.ImportFormattedQuestions(quizID, format, file, c.QueryBool("dryRun", false), userID)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "unauthorized") {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	status := fiber.StatusCreated
+	if result.DryRun {
+		status = fiber.StatusOK
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"message": "Questions import processed",
+		"data":    result,
+	})
+}
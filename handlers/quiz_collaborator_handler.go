@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+
+	"github.com/patiphanak/league-of-quiz/dto"
+	"github.com/patiphanak/league-of-quiz/services"
+	"github.com/patiphanak/league-of-quiz/utils"
+)
+
+// QuizCollaboratorHandler สำหรับการจัดการ API ผู้ร่วมแก้ไข quiz
+type QuizCollaboratorHandler struct {
+	collaboratorService *services.QuizCollaboratorService
+}
+
+// NewQuizCollaboratorHandler สร้าง instance ใหม่ของ QuizCollaboratorHandler
+func NewQuizCollaboratorHandler(collaboratorService *services.QuizCollaboratorService) *QuizCollaboratorHandler {
+	return &QuizCollaboratorHandler{
+		collaboratorService: collaboratorService,
+	}
+}
+
+// collaboratorErrorStatus แปลงข้อผิดพลาดจาก service เป็น HTTP status
+func collaboratorErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fiber.StatusNotFound
+	case strings.HasPrefix(err.Error(), "unauthorized"):
+		return fiber.StatusForbidden
+	default:
+		return fiber.StatusBadRequest
+	}
+}
+
+// GetCollaborators ดึงรายชื่อผู้ร่วมแก้ไข quiz
+func (h *QuizCollaboratorHandler) GetCollaborators(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	quizID, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	collaborators, err := h.collaboratorService.ListCollaborators(quizID, userID)
+	if err != nil {
+		return c.Status(collaboratorErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": collaborators,
+	})
+}
+
+// InviteCollaborator เชิญผู้ร่วมแก้ไขด้วยอีเมล หรือเปลี่ยนบทบาทของผู้ร่วมแก้ไขเดิม
+func (h *QuizCollaboratorHandler) InviteCollaborator(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	quizID, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var req dto.InviteCollaboratorRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	collaborator, err := h.collaboratorService.InviteCollaborator(quizID, req.Email, req.Role, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User or quiz not found"})
+		}
+		return c.Status(collaboratorErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Collaborator invited successfully",
+		"data":    collaborator,
+	})
+}
+
+// RemoveCollaborator ลบผู้ร่วมแก้ไขด้วยอีเมลจาก query "email"
+func (h *QuizCollaboratorHandler) RemoveCollaborator(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	quizID, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.collaboratorService.RemoveCollaborator(quizID, c.Query("email"), userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Collaborator not found"})
+		}
+		return c.Status(collaboratorErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Collaborator removed successfully",
+	})
+}
+
+// GetSharedQuizzes ดึง quiz ที่ผู้อื่นแชร์ให้ผู้ใช้ปัจจุบัน
+func (h *QuizCollaboratorHandler) GetSharedQuizzes(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+
+	quizzes, count, err := h.collaboratorService.GetSharedQuizzes(userID, page, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": quizzes,
+		"meta": fiber.Map{
+			"total": count,
+			"page":  page,
+			"limit": limit,
+		},
+	})
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
 	"log"
 	"strconv"
 	"strings"
@@ -10,6 +11,7 @@ import (
 	"gorm.io/gorm"
 
 	models "github.com/patiphanak/league-of-quiz/model"
+	"github.com/patiphanak/league-of-quiz/quizformat"
 	"github.com/patiphanak/league-of-quiz/services"
 	"github.com/patiphanak/league-of-quiz/utils"
 )
@@ -33,8 +35,7 @@ func (h *QuizHandler) GetQuizzes(c *fiber.Ctx) error {
 	offset, _ := strconv.Atoi(c.Query("offset", "0"))
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
 
-	// รับ filter parameters
-	isPublished := c.Query("isPublished", "")
+	// รับ filter parameters (รายการสาธารณะมีเฉพาะ quiz ที่เผยแพร่แล้ว)
 	search := c.Query("search", "")
 
 	// รับ categories (อาจเป็น comma-separated values)
@@ -55,7 +56,7 @@ func (h *QuizHandler) GetQuizzes(c *fiber.Ctx) error {
 	var err error
 
 	// เรียกใช้ service
-	quizzes, count, err = h.quizService.GetFilteredQuizzes(offset, limit, isPublished, search, categories)
+	quizzes, count, err = h.quizService.GetFilteredQuizzes(offset, limit, search, categories)
 
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -63,6 +64,11 @@ func (h *QuizHandler) GetQuizzes(c *fiber.Ctx) error {
 		})
 	}
 
+	// รายการสาธารณะไม่มีบทบาทของผู้เรียก จึงซ่อนเฉลยเสมอ
+	for i := range quizzes {
+		quizzes[i].HideAnswers()
+	}
+
 	return c.JSON(fiber.Map{
 		"data": quizzes,
 		"meta": fiber.Map{
@@ -92,9 +98,20 @@ func (h *QuizHandler) GetQuizByID(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal server error"})
 	}
 
+	if !canSeeAnswers(c) {
+		quiz.HideAnswers()
+	}
+
 	return c.JSON(fiber.Map{"data": quiz})
 }
 
+// canSeeAnswers ตรวจสอบว่าผู้เรียกเห็นเฉลยได้หรือไม่ จากบทบาทใน quiz ที่ policy เก็บไว้
+// เฉพาะ editor ขึ้นไปเท่านั้นที่เห็นเฉลยและคำอธิบาย
+func canSeeAnswers(c *fiber.Ctx) bool {
+	role, _ := c.Locals("quizRole").(string)
+	return models.QuizRoleAllows(role, models.QuizRoleEditor)
+}
+
 // CreateQuiz สร้าง quiz ใหม่ (รองรับ multipart form)
 func (h *QuizHandler) CreateQuiz(c *fiber.Ctx) error {
 	// ตรวจสอบว่าผู้ใช้ล็อกอินแล้ว
@@ -186,13 +203,13 @@ func (h *QuizHandler) UpdateQuiz(c *fiber.Ctx) error {
 		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	// ตรวจสอบว่าผู้ใช้เป็นเจ้าของ quiz หรือไม่
+	// ตรวจสอบว่าผู้ใช้มีสิทธิ์แก้ไข quiz หรือไม่
 	quiz, err := h.quizService.GetQuizByID(quizID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Quiz not found"})
 	}
 
-	if quiz.CreatorID != userID {
+	if err := h.quizService.CheckQuizAccess(quizID, userID, models.QuizRoleEditor); err != nil {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "You don't have permission to modify this quiz"})
 	}
 
@@ -283,6 +300,9 @@ func (h *QuizHandler) DeleteQuiz(c *fiber.Ctx) error {
 
 	// ลบ quiz
 	if err := h.quizService.DeleteQuiz(quizID, userID); err != nil {
+		if strings.HasPrefix(err.Error(), "unauthorized") {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -336,3 +356,211 @@ func (h *QuizHandler) GetCategories(c *fiber.Ctx) error {
 		"categories": categories,
 	})
 }
+
+// ExportQuiz ส่งออก quiz เป็นไฟล์ zip สำหรับนำไปนำเข้าในระบบอื่น
+func (h *QuizHandler) ExportQuiz(c *fiber.Ctx) error {
+	// ตรวจสอบว่าผู้ใช้ล็อกอินแล้ว
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// รับ ID จาก parameter
+	quizID, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	archive, err := h.quizService.ExportQuiz(quizID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Quiz not found"})
+		}
+		if strings.HasPrefix(err.Error(), "unauthorized") {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="quiz-%d.zip"`, quizID))
+	return c.Send(archive)
+}
+
+// ImportQuiz นำเข้า quiz จากไฟล์ zip ที่ได้จากการส่งออก โดยผู้ใช้ปัจจุบันเป็นเจ้าของ
+func (h *QuizHandler) ImportQuiz(c *fiber.Ctx) error {
+	// ตรวจสอบว่าผู้ใช้ล็อกอินแล้ว
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	bundleFile, err := c.FormFile("bundle")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Bundle file is required"})
+	}
+
+	quiz, warnings, err := h.quizService.ImportQuiz(bundleFile, userID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// ดึงข้อมูล quiz ที่สร้างใหม่พร้อมความสัมพันธ์
+	createdQuiz, err := h.quizService.GetQuizByID(quiz.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Quiz imported but failed to retrieve",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message":  "Quiz imported successfully",
+		"data":     createdQuiz,
+		"warnings": warnings,
+	})
+}
+
+// ExportQuizAsText ส่งออกคำถามของ quiz เป็นรูปแบบ GIFT หรือ Aiken พร้อมรายการข้อมูลที่แปลงไม่ได้
+func (h *QuizHandler) ExportQuizAsText(c *fiber.Ctx) error {
+	// ตรวจสอบว่าผู้ใช้ล็อกอินแล้ว
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// รับ ID จาก parameter
+	quizID, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	format, err := quizformat.ParseFormat(c.Params("format"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	content, issues, err := h.quizService.ExportQuizAsText(quizID, format, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Quiz not found"})
+		}
+		if strings.HasPrefix(err.Error(), "unauthorized") {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": fiber.Map{
+			"format":   format,
+			"filename": fmt.Sprintf("quiz-%d.%s.txt", quizID, format),
+			"content":  content,
+			"issues":   issues,
+		},
+	})
+}
+
+// ExportQuizQTI ส่งออก quiz เป็นแพ็กเกจ QTI 2.1
+func (h *QuizHandler) ExportQuizQTI(c *fiber.Ctx) error {
+	// ตรวจสอบว่าผู้ใช้ล็อกอินแล้ว
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// รับ ID จาก parameter
+	quizID, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	archive, issues, err := h.quizService.ExportQuizQTI(quizID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Quiz not found"})
+		}
+		if strings.HasPrefix(err.Error(), "unauthorized") {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	for _, issue := range issues {
+		log.Printf("QTI export of quiz %d, question %d: %s", quizID, issue.Question, issue.Message)
+	}
+
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="quiz-%d-qti.zip"`, quizID))
+	return c.Send(archive)
+}
+
+// ImportQuizQTI นำเข้าแพ็กเกจ QTI 2.1 เป็น quiz ใหม่ของผู้ใช้ปัจจุบัน
+func (h *QuizHandler) ImportQuizQTI(c *fiber.Ctx) error {
+	// ตรวจสอบว่าผู้ใช้ล็อกอินแล้ว
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	packageFile, err := c.FormFile("package")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "QTI package file is required"})
+	}
+
+	quiz, issues, err := h.quizService.ImportQuizQTI(packageFile, userID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error(), "issues": issues})
+	}
+
+	// ดึงข้อมูล quiz ที่สร้างใหม่พร้อมความสัมพันธ์
+	createdQuiz, err := h.quizService.GetQuizByID(quiz.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Quiz imported but failed to retrieve",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Quiz imported successfully",
+		"data":    createdQuiz,
+		"issues":  issues,
+	})
+}
+
+// DuplicateQuiz คัดลอก quiz เป็นของผู้ใช้ปัจจุบัน
+func (h *QuizHandler) DuplicateQuiz(c *fiber.Ctx) error {
+	// ตรวจสอบว่าผู้ใช้ล็อกอินแล้ว
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// รับ ID จาก parameter
+	quizID, statusCode, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	quiz, err := h.quizService.DuplicateQuiz(quizID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Quiz not found"})
+		}
+		if strings.HasPrefix(err.Error(), "unauthorized") {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// ดึงข้อมูล quiz ที่สร้างใหม่พร้อมความสัมพันธ์
+	createdQuiz, err := h.quizService.GetQuizByID(quiz.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Quiz duplicated but failed to retrieve",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Quiz duplicated successfully",
+		"data":    createdQuiz,
+	})
+}
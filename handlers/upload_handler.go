@@ -5,24 +5,25 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/patiphanak/league-of-quiz/services"
+	"github.com/patiphanak/league-of-quiz/utils"
 )
 
 // UploadHandler สำหรับจัดการการอัปโหลดไฟล์
 type UploadHandler struct {
-	fileService *services.FileService
+	uploadService *services.UploadService
 }
 
 // NewUploadHandler สร้าง instance ใหม่ของ UploadHandler
-func NewUploadHandler(fileService *services.FileService) *UploadHandler {
+func NewUploadHandler(uploadService *services.UploadService) *UploadHandler {
 	return &UploadHandler{
-		fileService: fileService,
+		uploadService: uploadService,
 	}
 }
 
 // validateFileType ตรวจสอบประเภทของไฟล์
 func validateFileType(fileType string) (string, error) {
 	switch fileType {
-	case "quiz", "question", "choice":
+	case "quiz", "question", "choice", "explanation", "media":
 		return fileType, nil
 	default:
 		return "", fmt.Errorf("invalid file type: %s", fileType)
@@ -31,6 +32,11 @@ func validateFileType(fileType string) (string, error) {
 
 // UploadFile อัปโหลดไฟล์
 func (h *UploadHandler) UploadFile(c *fiber.Ctx) error {
+	userID, statusCode, err := utils.GetAuthenticatedUserID(c)
+	if err != nil {
+		return c.Status(statusCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
 	// รับประเภทของไฟล์จาก path parameter
 	fileType := c.Params("type")
 	
@@ -50,16 +56,10 @@ func (h *UploadHandler) UploadFile(c *fiber.Ctx) error {
 		})
 	}
 
-	// ถ้ามี old_file_url ให้อัปเดตแทนการสร้างใหม่
+	// ถ้ามี old_file_url ให้แทนที่ไฟล์เดิม (ลบได้เฉพาะไฟล์ที่ผู้ใช้อัปโหลดเอง)
 	oldFileURL := c.FormValue("old_file_url", "")
 
-	var fileURL string
-	if oldFileURL != "" {
-		fileURL, err = h.fileService.UpdateFile(file, oldFileURL, validFileType)
-	} else {
-		fileURL, err = h.fileService.UploadFile(file, validFileType)
-	}
-
+	fileURL, err := h.uploadService.UploadFile(file, validFileType, oldFileURL, userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -93,7 +93,7 @@ func (h *UploadHandler) DeleteFile(c *fiber.Ctx) error {
 	}
 
 	// ลบไฟล์
-	if err := h.fileService.DeleteFile(filename, validFileType); err != nil {
+	if err := h.uploadService.DeleteFile(filename, validFileType); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
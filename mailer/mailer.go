@@ -0,0 +1,142 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"mime"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/patiphanak/league-of-quiz/config"
+)
+
+// Message อีเมลหนึ่งฉบับ (เนื้อหาเป็นข้อความธรรมดา)
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer เป็น interface สำหรับส่งอีเมลออกจากระบบ
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// SMTPMailer ส่งอีเมลผ่าน SMTP server
+type SMTPMailer struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+// NewSMTPMailer สร้าง instance ใหม่ของ SMTPMailer ถ้าไม่กำหนด username จะส่งโดยไม่ยืนยันตัวตน
+func NewSMTPMailer(host, port, username, password, from string) *SMTPMailer {
+	return &SMTPMailer{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+// Send implements Mailer
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(m.host+":"+m.port, auth, m.from, []string{msg.To}, buildMessage(m.from, msg))
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("sending mail to %s: %w", msg.To, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// FileMailer เขียนอีเมลเป็นไฟล์ .eml ในโฟลเดอร์ที่กำหนด สำหรับการพัฒนาและทดสอบ
+// ถ้าไม่กำหนดโฟลเดอร์จะพิมพ์อีเมลลง log แทน
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer สร้าง instance ใหม่ของ FileMailer
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("creating mail directory: %w", err)
+		}
+	}
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+// Send implements Mailer
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	data := buildMessage(m.from, msg)
+	if m.dir == "" {
+		log.Printf("📧 Mail to %s\n%s", msg.To, data)
+		return nil
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405"), uuid.New().String()[:8])
+	if err := os.WriteFile(filepath.Join(m.dir, name), data, 0644); err != nil {
+		return fmt.Errorf("writing mail file: %w", err)
+	}
+	return nil
+}
+
+// buildMessage สร้างอีเมลรูปแบบ RFC 5322 อย่างง่าย
+func buildMessage(from string, msg Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + headerValue(from) + "\r\n")
+	b.WriteString("To: " + headerValue(msg.To) + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", headerValue(msg.Subject)) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// headerValue ตัดขึ้นบรรทัดใหม่ออกจากค่า header เพื่อป้องกัน header injection
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
+
+// NewFromConfig สร้าง Mailer ตาม MAIL_DRIVER ใน config
+func NewFromConfig(cfg *config.Config) (Mailer, error) {
+	switch cfg.MailDriver {
+	case "smtp":
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("SMTP_HOST is required when MAIL_DRIVER is smtp")
+		}
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom), nil
+	case "file":
+		if cfg.MailDir == "" {
+			return nil, fmt.Errorf("MAIL_DIR is required when MAIL_DRIVER is file")
+		}
+		return NewFileMailer(cfg.MailDir, cfg.MailFrom)
+	case "log", "":
+		return NewFileMailer("", cfg.MailFrom)
+	default:
+		return nil, fmt.Errorf("unknown MAIL_DRIVER %q", cfg.MailDriver)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
@@ -14,6 +15,7 @@ import (
 	"github.com/patiphanak/league-of-quiz/config"
 	"github.com/patiphanak/league-of-quiz/database"
 	"github.com/patiphanak/league-of-quiz/handlers"
+	"github.com/patiphanak/league-of-quiz/mailer"
 	middleware "github.com/patiphanak/league-of-quiz/middlewares"
 	"github.com/patiphanak/league-of-quiz/repositories"
 	routes "github.com/patiphanak/league-of-quiz/routes"
@@ -41,8 +43,32 @@ func main() {
 	// Initialize Repository
 	repos := repositories.InitRepositories(database.DB)
 
+	// Initialize auth components
+	providers := oauth.NewRegistry()
+	providers.Register("google", oauth.NewGoogleOAuth(cfg))
+	for _, providerCfg := range cfg.OIDCProviders {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		provider, err := oauth.NewOIDCProvider(ctx, providerCfg)
+		cancel()
+		if err != nil {
+			log.Printf("Skipping OIDC provider %s: %v", providerCfg.Name, err)
+			continue
+		}
+		providers.Register(providerCfg.Name, provider)
+	}
+	if cfg.DevAuthEnabled {
+		log.Println("⚠️  DEV_AUTH_ENABLED is set: anyone can log in as any user via /auth/dev/login")
+		providers.Register("dev", oauth.NewDevProvider(cfg.BackendURL))
+	}
+	jwtService := jwt.NewJWTService(cfg)
+
+	mail, err := mailer.NewFromConfig(cfg)
+	if err != nil {
+		log.Fatalf("Error creating mailer: %v", err)
+	}
+
 	// Initialize Services with proper storage path
-	services, err := services.InitServices(repos, storageBasePath)
+	services, err := services.InitServices(repos, storageBasePath, jwtService, mail, cfg.FrontendURL)
 	if err != nil {
 		log.Fatalf("Failed to initialize services: %v", err)
 	}
@@ -52,14 +78,12 @@ func main() {
 	if err != nil {
 		log.Fatalf("Error creating WebSocket manager: %v", err)
 	}
-	// Initialize auth components
-	googleAuth := oauth.NewGoogleOAuth(cfg)
-	jwtService := jwt.NewJWTService(cfg)
-
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
 		ReadTimeout:  30 * time.Second,
 		WriteTimeout: 30 * time.Second,
+		// รองรับการอัปโหลดไฟล์วิดีโอและการนำเข้า quiz bundle
+		BodyLimit: 100 * 1024 * 1024,
 	})
 
 	// Set up middlewares
@@ -73,13 +97,17 @@ func main() {
 	}))
 
 	// Initialize auth middleware
-	authMiddleware := middleware.NewAuthMiddleware(database.DB, jwtService)
+	authMiddleware := middleware.NewAuthMiddleware(database.DB, jwtService, services.Session, services.AccessToken)
+	policy := middleware.NewPolicy(repos)
 
 	// Set up routes
-	allHandlers := handlers.InitHandlers(services, database.DB, jwtService, googleAuth)
-	routes.SetupRoutes(app, allHandlers, authMiddleware, wsManager)
+	allHandlers := handlers.InitHandlers(services, database.DB, providers, cfg.AdminEmails, cfg.FrontendURL)
+	routes.SetupRoutes(app, allHandlers, authMiddleware, policy, wsManager)
 
-	app.Static("/storage", storageBasePath)
+	// เปิด byte range เพื่อให้ผู้เล่นเลื่อนตำแหน่งเสียง/วิดีโอได้
+	app.Static("/storage", storageBasePath, fiber.Static{
+		ByteRange: true,
+	})
 
 	// Set up graceful shutdown
 	c := make(chan os.Signal, 1)
@@ -2,30 +2,80 @@ package middleware
 
 import (
 	"errors"
+	"log"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/patiphanak/league-of-quiz/auth/jwt"
 	models "github.com/patiphanak/league-of-quiz/model"
+	"github.com/patiphanak/league-of-quiz/services"
 	"gorm.io/gorm"
 )
 
 type AuthMiddleware struct {
-	db         *gorm.DB
-	jwtService *jwt.JWTService
+	db                 *gorm.DB
+	jwtService         *jwt.JWTService
+	sessionService     *services.SessionService
+	accessTokenService *services.PersonalAccessTokenService
 }
 
-func NewAuthMiddleware(db *gorm.DB, jwtService *jwt.JWTService) *AuthMiddleware {
+func NewAuthMiddleware(
+	db *gorm.DB,
+	jwtService *jwt.JWTService,
+	sessionService *services.SessionService,
+	accessTokenService *services.PersonalAccessTokenService,
+) *AuthMiddleware {
 	return &AuthMiddleware{
-		db:         db,
-		jwtService: jwtService,
+		db:                 db,
+		jwtService:         jwtService,
+		sessionService:     sessionService,
+		accessTokenService: accessTokenService,
 	}
 }
 
 // RequireAuth middleware ที่ตรวจสอบว่าผู้ใช้ได้เข้าสู่ระบบหรือไม่
+// รับเฉพาะ session การเข้าสู่ระบบ (cookie หรือ JWT ใน Authorization: Bearer) ไม่รับ personal access token
 func (m *AuthMiddleware) RequireAuth() fiber.Handler {
+	return m.authenticate("")
+}
+
+// RequireScope เหมือน RequireAuth แต่รับ personal access token ที่มี scope ที่กำหนดด้วย
+func (m *AuthMiddleware) RequireScope(scope string) fiber.Handler {
+	return m.authenticate(scope)
+}
+
+// OptionalScope ตรวจการเข้าสู่ระบบเหมือน RequireScope ถ้า request มี token
+// ถ้าไม่มี token เลยจะผ่านไปแบบไม่ระบุตัวตน ใช้กับ route สาธารณะที่ให้ข้อมูลเพิ่มตามสิทธิ์ของผู้ใช้
+func (m *AuthMiddleware) OptionalScope(scope string) fiber.Handler {
+	authenticate := m.authenticate(scope)
 	return func(c *fiber.Ctx) error {
-		// ดึง token จาก cookie
-		tokenString := c.Cookies("auth_token")
+		if bearerToken(c) == "" && c.Cookies("auth_token") == "" {
+			return c.Next()
+		}
+		return authenticate(c)
+	}
+}
+
+// bearerToken ดึง token จาก Authorization: Bearer header
+func bearerToken(c *fiber.Ctx) string {
+	header := c.Get(fiber.HeaderAuthorization)
+	if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return ""
+}
+
+// authenticate ตรวจการเข้าสู่ระบบ ถ้า scope ว่างจะไม่รับ personal access token
+func (m *AuthMiddleware) authenticate(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// ดึง token จาก Authorization header หรือ cookie
+		tokenString := bearerToken(c)
+		if strings.HasPrefix(tokenString, models.PersonalAccessTokenPrefix) {
+			return m.authenticateAccessToken(c, tokenString, scope)
+		}
+		if tokenString == "" {
+			tokenString = c.Cookies("auth_token")
+		}
 
 		// ตรวจสอบว่ามี token หรือไม่
 		if tokenString == "" {
@@ -47,6 +97,18 @@ func (m *AuthMiddleware) RequireAuth() fiber.Handler {
 			})
 		}
 
+		// ตรวจว่า session ของ token ยังไม่ถูกเพิกถอน (ใช้ cache ภายใน process)
+		if err := m.sessionService.ValidateSession(claims.SessionID, claims.UserID); err != nil {
+			if errors.Is(err, services.ErrSessionRevoked) {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error": "Session revoked",
+				})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to verify session",
+			})
+		}
+
 		// ดึงข้อมูลผู้ใช้จากฐานข้อมูล
 		var user models.User
 		if err := m.db.First(&user, claims.UserID).Error; err != nil {
@@ -55,12 +117,86 @@ func (m *AuthMiddleware) RequireAuth() fiber.Handler {
 			})
 		}
 
+		// บัญชีที่ถูกระงับใช้งานไม่ได้แม้ token ยังไม่หมดอายุ
+		if user.IsDisabled() {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Account disabled",
+			})
+		}
+
 		// เก็บข้อมูลผู้ใช้ใน locals ของ context
 		c.Locals("user", user)
 		// เก็บรหัสผู้ใช้ใน locals ของ context เพื่อให้ handler สามารถเข้าถึงได้
 		c.Locals("userID", user.ID)
+		c.Locals("sessionID", claims.SessionID)
+
+		if err := m.sessionService.Touch(claims.SessionID, c.IP()); err != nil {
+			log.Printf("Failed to update session %d: %v", claims.SessionID, err)
+		}
 
 		// ดำเนินการต่อไปยัง handler ถัดไป
 		return c.Next()
 	}
 }
+
+// authenticateAccessToken ตรวจ personal access token และ scope ที่ route ต้องการ
+func (m *AuthMiddleware) authenticateAccessToken(c *fiber.Ctx, tokenString, scope string) error {
+	if scope == "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Personal access tokens cannot be used for this endpoint",
+		})
+	}
+
+	token, err := m.accessTokenService.Authenticate(tokenString)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidAccessToken) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid token",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to verify token",
+		})
+	}
+
+	if !token.HasScope(scope) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Token is missing required scope: " + scope,
+		})
+	}
+
+	user := token.User
+	if user.IsDisabled() {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Account disabled",
+		})
+	}
+
+	c.Locals("user", user)
+	c.Locals("userID", user.ID)
+	c.Locals("accessTokenID", token.ID)
+
+	return c.Next()
+}
+
+// RequireRole middleware ที่ตรวจสอบว่าผู้ใช้มีบทบาทตามที่กำหนด ต้องใช้หลัง RequireAuth เสมอ
+func (m *AuthMiddleware) RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Unauthorized",
+			})
+		}
+
+		for _, role := range roles {
+			if user.Role == role {
+				return c.Next()
+			}
+		}
+
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Insufficient role",
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package middleware
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	models "github.com/patiphanak/league-of-quiz/model"
+	"github.com/patiphanak/league-of-quiz/repositories"
+	"github.com/patiphanak/league-of-quiz/services"
+	"gorm.io/gorm"
+)
+
+// QuizIDResolver ดึง ID ของ quiz ที่ request อ้างถึง จาก path parameter หรือทรัพยากรลูกของ quiz
+type QuizIDResolver func(c *fiber.Ctx) (uint, error)
+
+// ข้อมูลที่ Policy ต้องใช้จาก repository แยกเป็น interface เพื่อให้ทดสอบได้โดยไม่ต้องมีฐานข้อมูล
+type (
+	policyQuizStore interface {
+		GetQuizRole(quizID uint, userID uint) (string, error)
+		IsQuizPublic(quizID uint) (bool, error)
+		GetQuizIDsByFileURL(path string) ([]uint, error)
+	}
+	policyQuestionStore interface {
+		GetQuizIDByQuestionID(questionID uint) (uint, error)
+	}
+	policyChoiceStore interface {
+		GetChoiceByID(id uint) (*models.Choice, error)
+	}
+	policyBankStore interface {
+		GetOwnerIDsByFileURL(path string) ([]uint, error)
+	}
+	policyUploadStore interface {
+		GetUploaderID(path string) (uint, error)
+	}
+)
+
+// Policy ตรวจสอบสิทธิ์ต่อทรัพยากรในระดับ route ต้องใช้หลัง RequireAuth เสมอ
+// service ยังตรวจสิทธิ์ซ้ำอีกชั้น เพื่อไม่ให้ความปลอดภัยขึ้นกับการลงทะเบียน route เพียงอย่างเดียว
+type Policy struct {
+	quizRepo     policyQuizStore
+	questionRepo policyQuestionStore
+	choiceRepo   policyChoiceStore
+	bankRepo     policyBankStore
+	uploadRepo   policyUploadStore
+}
+
+// NewPolicy สร้าง instance ใหม่ของ Policy
+func NewPolicy(repos *repositories.Repositories) *Policy {
+	return &Policy{
+		quizRepo:     repos.Quiz,
+		questionRepo: repos.Question,
+		choiceRepo:   repos.Choice,
+		bankRepo:     repos.QuestionBank,
+		uploadRepo:   repos.UploadedFile,
+	}
+}
+
+// errInvalidID path parameter ไม่ใช่ ID ที่ถูกต้อง
+var errInvalidID = errors.New("Invalid ID parameter")
+
+// parseParamID แปลง path parameter เป็น ID
+func parseParamID(c *fiber.Ctx, name string) (uint, error) {
+	id, err := strconv.ParseUint(c.Params(name), 10, 32)
+	if err != nil {
+		return 0, errInvalidID
+	}
+	return uint(id), nil
+}
+
+// QuizParam ใช้ path parameter เป็น ID ของ quiz โดยตรง
+func (p *Policy) QuizParam(name string) QuizIDResolver {
+	return func(c *fiber.Ctx) (uint, error) {
+		return parseParamID(c, name)
+	}
+}
+
+// QuestionParam หา quiz จาก ID ของคำถามใน path parameter
+func (p *Policy) QuestionParam(name string) QuizIDResolver {
+	return func(c *fiber.Ctx) (uint, error) {
+		questionID, err := parseParamID(c, name)
+		if err != nil {
+			return 0, err
+		}
+		return p.questionRepo.GetQuizIDByQuestionID(questionID)
+	}
+}
+
+// ChoiceParam หา quiz จาก ID ของตัวเลือกใน path parameter
+func (p *Policy) ChoiceParam(name string) QuizIDResolver {
+	return func(c *fiber.Ctx) (uint, error) {
+		choiceID, err := parseParamID(c, name)
+		if err != nil {
+			return 0, err
+		}
+		choice, err := p.choiceRepo.GetChoiceByID(choiceID)
+		if err != nil {
+			return 0, err
+		}
+		return p.questionRepo.GetQuizIDByQuestionID(choice.QuestionID)
+	}
+}
+
+// RequireQuizRole middleware ที่ตรวจสอบว่าผู้ใช้มีบทบาทใน quiz อย่างน้อยเท่ากับ role
+// บทบาทของผู้ใช้จะถูกเก็บใน locals "quizRole" ให้ handler ใช้ต่อได้
+func (p *Policy) RequireQuizRole(role string, resolve QuizIDResolver) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, ok := c.Locals("userID").(uint)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Unauthorized",
+			})
+		}
+
+		quizID, err := resolve(c)
+		if err != nil {
+			return policyError(c, err)
+		}
+
+		userRole, err := p.quizRepo.GetQuizRole(quizID, userID)
+		if err != nil {
+			return policyError(c, err)
+		}
+		if !models.QuizRoleAllows(userRole, role) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "You don't have permission to access this quiz",
+			})
+		}
+
+		c.Locals("quizRole", userRole)
+		return c.Next()
+	}
+}
+
+// RequireQuizRead middleware ที่ตรวจสอบสิทธิ์อ่าน quiz ใช้หลัง OptionalScope
+// ผู้ที่มีบทบาท viewer ขึ้นไปอ่านได้เสมอ ส่วนผู้ที่ไม่มีบทบาทหรือไม่ได้เข้าสู่ระบบอ่านได้เฉพาะ quiz ที่เผยแพร่แล้ว
+// บทบาทของผู้ใช้ (หรือ "" ถ้าไม่มี) จะถูกเก็บใน locals "quizRole" ให้ handler ซ่อนเฉลยจากผู้ที่ต่ำกว่า editor
+func (p *Policy) RequireQuizRead(resolve QuizIDResolver) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		quizID, err := resolve(c)
+		if err != nil {
+			return policyError(c, err)
+		}
+
+		userRole := ""
+		if userID, ok := c.Locals("userID").(uint); ok {
+			if userRole, err = p.quizRepo.GetQuizRole(quizID, userID); err != nil {
+				return policyError(c, err)
+			}
+		}
+
+		if !models.QuizRoleAllows(userRole, models.QuizRoleViewer) {
+			public, err := p.quizRepo.IsQuizPublic(quizID)
+			if err != nil {
+				return policyError(c, err)
+			}
+			if !public {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+					"error": "You don't have permission to access this quiz",
+				})
+			}
+		}
+
+		c.Locals("quizRole", userRole)
+		return c.Next()
+	}
+}
+
+// RequireFileAccess middleware ที่ตรวจสอบสิทธิ์ลบไฟล์ใน storage
+// ไฟล์ที่ quiz อ้างอิงอยู่ต้องเป็น editor ของทุก quiz นั้น ไฟล์ในคลังข้อสอบต้องเป็นเจ้าของคลัง
+// ส่วนไฟล์ที่ยังไม่มีใครอ้างอิง (เพิ่งอัปโหลดและยังไม่ได้บันทึก) ลบได้เฉพาะผู้อัปโหลด
+// รูปโปรไฟล์จัดการผ่าน /api/v1/account/avatar เท่านั้น จึงลบผ่าน route นี้ไม่ได้
+func (p *Policy) RequireFileAccess(typeParam string, filenameParam string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, ok := c.Locals("userID").(uint)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Unauthorized",
+			})
+		}
+
+		if c.Params(typeParam) == string(services.AvatarType) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "You don't have permission to delete this file",
+			})
+		}
+
+		path := "/storage/" + c.Params(typeParam) + "/" + c.Params(filenameParam)
+
+		quizIDs, err := p.quizRepo.GetQuizIDsByFileURL(path)
+		if err != nil {
+			return policyError(c, err)
+		}
+		for _, quizID := range quizIDs {
+			role, err := p.quizRepo.GetQuizRole(quizID, userID)
+			if err != nil {
+				return policyError(c, err)
+			}
+			if !models.QuizRoleAllows(role, models.QuizRoleEditor) {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+					"error": "You don't have permission to delete this file",
+				})
+			}
+		}
+
+		ownerIDs, err := p.bankRepo.GetOwnerIDsByFileURL(path)
+		if err != nil {
+			return policyError(c, err)
+		}
+		for _, ownerID := range ownerIDs {
+			if ownerID != userID {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+					"error": "You don't have permission to delete this file",
+				})
+			}
+		}
+
+		if len(quizIDs) == 0 && len(ownerIDs) == 0 {
+			uploaderID, err := p.uploadRepo.GetUploaderID(path)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return policyError(c, err)
+			}
+			if err != nil || uploaderID != userID {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+					"error": "You don't have permission to delete this file",
+				})
+			}
+		}
+
+		return c.Next()
+	}
+}
+
+// policyError แปลงข้อผิดพลาดระหว่างตรวจสิทธิ์เป็น HTTP response
+func policyError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, errInvalidID):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Resource not found"})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal server error"})
+	}
+}
@@ -0,0 +1,255 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	models "github.com/patiphanak/league-of-quiz/model"
+	"gorm.io/gorm"
+)
+
+// ผู้ใช้ในข้อมูลทดสอบ
+const (
+	ownerID    uint = 1
+	editorID   uint = 2
+	viewerID   uint = 3
+	outsiderID uint = 4 // ไม่มีบทบาทใน quiz และไม่ได้เป็นสมาชิกองค์กร
+)
+
+// fakeQuiz quiz ในข้อมูลทดสอบ พร้อมบทบาทของผู้ใช้แต่ละคน
+type fakeQuiz struct {
+	roles  map[uint]string
+	public bool
+	files  []string
+}
+
+// fakeStore แทน repository ทั้งหมดที่ Policy ใช้ เก็บข้อมูลไว้ในหน่วยความจำ
+type fakeStore struct {
+	quizzes   map[uint]fakeQuiz
+	questions map[uint]uint // questionID -> quizID
+	choices   map[uint]uint // choiceID -> questionID
+	bankFiles map[string][]uint
+	uploads   map[string]uint
+}
+
+func (s *fakeStore) GetQuizRole(quizID uint, userID uint) (string, error) {
+	quiz, ok := s.quizzes[quizID]
+	if !ok {
+		return "", gorm.ErrRecordNotFound
+	}
+	return quiz.roles[userID], nil
+}
+
+func (s *fakeStore) IsQuizPublic(quizID uint) (bool, error) {
+	quiz, ok := s.quizzes[quizID]
+	if !ok {
+		return false, gorm.ErrRecordNotFound
+	}
+	return quiz.public, nil
+}
+
+func (s *fakeStore) GetQuizIDsByFileURL(path string) ([]uint, error) {
+	var quizIDs []uint
+	for quizID, quiz := range s.quizzes {
+		for _, file := range quiz.files {
+			if file == path {
+				quizIDs = append(quizIDs, quizID)
+			}
+		}
+	}
+	return quizIDs, nil
+}
+
+func (s *fakeStore) GetQuizIDByQuestionID(questionID uint) (uint, error) {
+	quizID, ok := s.questions[questionID]
+	if !ok {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return quizID, nil
+}
+
+func (s *fakeStore) GetChoiceByID(id uint) (*models.Choice, error) {
+	questionID, ok := s.choices[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &models.Choice{ID: id, QuestionID: questionID}, nil
+}
+
+func (s *fakeStore) GetOwnerIDsByFileURL(path string) ([]uint, error) {
+	return s.bankFiles[path], nil
+}
+
+func (s *fakeStore) GetUploaderID(path string) (uint, error) {
+	uploaderID, ok := s.uploads[path]
+	if !ok {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return uploaderID, nil
+}
+
+// newFakePolicy สร้าง Policy จากข้อมูลทดสอบ
+// quiz 1 ยังไม่เผยแพร่ quiz 2 เผยแพร่แล้ว และ quiz 3 เผยแพร่แล้วแต่แชร์ในองค์กรที่ viewer เป็นสมาชิก
+func newFakePolicy() *Policy {
+	roles := map[uint]string{
+		ownerID:  models.QuizRoleOwner,
+		editorID: models.QuizRoleEditor,
+		viewerID: models.QuizRoleViewer,
+	}
+	store := &fakeStore{
+		quizzes: map[uint]fakeQuiz{
+			1: {roles: roles, files: []string{"/storage/question/quiz.png"}},
+			2: {roles: roles, public: true},
+			3: {roles: map[uint]string{ownerID: models.QuizRoleOwner, viewerID: models.QuizRoleViewer}},
+		},
+		questions: map[uint]uint{10: 1, 20: 2, 30: 3},
+		choices:   map[uint]uint{100: 10},
+		bankFiles: map[string][]uint{"/storage/question/bank.png": {ownerID}},
+		uploads:   map[string]uint{"/storage/question/new.png": editorID},
+	}
+	return &Policy{
+		quizRepo:     store,
+		questionRepo: store,
+		choiceRepo:   store,
+		bankRepo:     store,
+		uploadRepo:   store,
+	}
+}
+
+// newPolicyApp สร้างแอปที่ใช้ header X-User-ID แทนการเข้าสู่ระบบ แล้วตอบบทบาทใน quiz ที่ policy เก็บไว้
+func newPolicyApp(method string, path string, handler fiber.Handler) *fiber.App {
+	app := fiber.New()
+	app.Add(method, path, func(c *fiber.Ctx) error {
+		if raw := c.Get("X-User-ID"); raw != "" {
+			userID, _ := strconv.ParseUint(raw, 10, 32)
+			c.Locals("userID", uint(userID))
+		}
+		return c.Next()
+	}, handler, func(c *fiber.Ctx) error {
+		role, _ := c.Locals("quizRole").(string)
+		return c.SendString(role)
+	})
+	return app
+}
+
+// request ส่ง request ในนามผู้ใช้ (0 คือไม่ได้เข้าสู่ระบบ) และคืนค่า status
+func request(t *testing.T, app *fiber.App, method string, path string, userID uint) int {
+	t.Helper()
+	req := httptest.NewRequest(method, path, nil)
+	if userID != 0 {
+		req.Header.Set("X-User-ID", strconv.FormatUint(uint64(userID), 10))
+	}
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestRequireQuizRole(t *testing.T) {
+	policy := newFakePolicy()
+	editQuiz := newPolicyApp(http.MethodPatch, "/quizzes/:id", policy.RequireQuizRole(models.QuizRoleEditor, policy.QuizParam("id")))
+	editChoice := newPolicyApp(http.MethodPatch, "/choices/:id", policy.RequireQuizRole(models.QuizRoleEditor, policy.ChoiceParam("id")))
+
+	cases := []struct {
+		name   string
+		app    *fiber.App
+		path   string
+		userID uint
+		want   int
+	}{
+		{"owner edits quiz", editQuiz, "/quizzes/1", ownerID, fiber.StatusOK},
+		{"editor edits quiz", editQuiz, "/quizzes/1", editorID, fiber.StatusOK},
+		{"viewer cannot edit quiz", editQuiz, "/quizzes/1", viewerID, fiber.StatusForbidden},
+		{"outsider cannot edit quiz", editQuiz, "/quizzes/1", outsiderID, fiber.StatusForbidden},
+		{"outsider cannot edit published quiz", editQuiz, "/quizzes/2", outsiderID, fiber.StatusForbidden},
+		{"organization member cannot edit quiz", editQuiz, "/quizzes/3", viewerID, fiber.StatusForbidden},
+		{"anonymous cannot edit quiz", editQuiz, "/quizzes/1", 0, fiber.StatusUnauthorized},
+		{"missing quiz", editQuiz, "/quizzes/99", ownerID, fiber.StatusNotFound},
+		{"invalid quiz ID", editQuiz, "/quizzes/abc", ownerID, fiber.StatusBadRequest},
+		{"editor edits choice", editChoice, "/choices/100", editorID, fiber.StatusOK},
+		{"viewer cannot edit choice", editChoice, "/choices/100", viewerID, fiber.StatusForbidden},
+		{"missing choice", editChoice, "/choices/99", editorID, fiber.StatusNotFound},
+	}
+	for _, tc := range cases {
+		if got := request(t, tc.app, http.MethodPatch, tc.path, tc.userID); got != tc.want {
+			t.Errorf("%s: got status %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestRequireQuizRead(t *testing.T) {
+	policy := newFakePolicy()
+	readQuestion := newPolicyApp(http.MethodGet, "/questions/:id", policy.RequireQuizRead(policy.QuestionParam("id")))
+
+	cases := []struct {
+		name     string
+		path     string
+		userID   uint
+		want     int
+		wantRole string
+	}{
+		{"viewer reads unpublished quiz", "/questions/10", viewerID, fiber.StatusOK, models.QuizRoleViewer},
+		{"editor reads unpublished quiz", "/questions/10", editorID, fiber.StatusOK, models.QuizRoleEditor},
+		{"outsider cannot read unpublished quiz", "/questions/10", outsiderID, fiber.StatusForbidden, ""},
+		{"anonymous cannot read unpublished quiz", "/questions/10", 0, fiber.StatusForbidden, ""},
+		{"outsider reads published quiz", "/questions/20", outsiderID, fiber.StatusOK, ""},
+		{"anonymous reads published quiz", "/questions/20", 0, fiber.StatusOK, ""},
+		{"organization member reads organization quiz", "/questions/30", viewerID, fiber.StatusOK, models.QuizRoleViewer},
+		{"non-member cannot read organization quiz", "/questions/30", outsiderID, fiber.StatusForbidden, ""},
+		{"missing question", "/questions/99", viewerID, fiber.StatusNotFound, ""},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		if tc.userID != 0 {
+			req.Header.Set("X-User-ID", strconv.FormatUint(uint64(tc.userID), 10))
+		}
+		resp, err := readQuestion.Test(req, -1)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		body := make([]byte, 32)
+		n, _ := resp.Body.Read(body)
+		resp.Body.Close()
+
+		if resp.StatusCode != tc.want {
+			t.Errorf("%s: got status %d, want %d", tc.name, resp.StatusCode, tc.want)
+			continue
+		}
+		if tc.want == fiber.StatusOK && string(body[:n]) != tc.wantRole {
+			t.Errorf("%s: got quiz role %q, want %q", tc.name, body[:n], tc.wantRole)
+		}
+	}
+}
+
+func TestRequireFileAccess(t *testing.T) {
+	policy := newFakePolicy()
+	deleteFile := newPolicyApp(http.MethodDelete, "/upload/:type/:filename", policy.RequireFileAccess("type", "filename"))
+
+	cases := []struct {
+		name   string
+		path   string
+		userID uint
+		want   int
+	}{
+		{"editor deletes quiz file", "/upload/question/quiz.png", editorID, fiber.StatusOK},
+		{"viewer cannot delete quiz file", "/upload/question/quiz.png", viewerID, fiber.StatusForbidden},
+		{"outsider cannot delete quiz file", "/upload/question/quiz.png", outsiderID, fiber.StatusForbidden},
+		{"bank owner deletes bank file", "/upload/question/bank.png", ownerID, fiber.StatusOK},
+		{"editor cannot delete another user's bank file", "/upload/question/bank.png", editorID, fiber.StatusForbidden},
+		{"uploader deletes unreferenced file", "/upload/question/new.png", editorID, fiber.StatusOK},
+		{"outsider cannot delete another user's upload", "/upload/question/new.png", outsiderID, fiber.StatusForbidden},
+		{"file without uploader cannot be deleted", "/upload/question/unknown.png", ownerID, fiber.StatusForbidden},
+		{"avatars cannot be deleted here", "/upload/avatar/new.png", ownerID, fiber.StatusForbidden},
+		{"anonymous cannot delete files", "/upload/question/new.png", 0, fiber.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		if got := request(t, deleteFile, http.MethodDelete, tc.path, tc.userID); got != tc.want {
+			t.Errorf("%s: got status %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
@@ -5,16 +5,103 @@ import (
 )
 
 type GameSession struct {
-	ID         string `gorm:"primaryKey"` // ใช้ string แบบธรรมดา ไม่ใช่ uuid
-	QuizID     uint   `gorm:"not null;index"`
-	Quiz       Quiz   `gorm:"foreignKey:QuizID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
-	HostID     uint   `gorm:"not null;index"`
-	Host       User   `gorm:"foreignKey:HostID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
-	Status     string `gorm:"not null"`
-	StartedAt  *time.Time
-	FinishedAt *time.Time
-	CreatedAt  time.Time
-	Players    []GamePlayer `gorm:"foreignKey:SessionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	ID              string `gorm:"primaryKey"` // ใช้ string แบบธรรมดา ไม่ใช่ uuid
+	QuizID          uint   `gorm:"not null;index"`
+	Quiz            Quiz   `gorm:"foreignKey:QuizID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	HostID          uint   `gorm:"not null;index"`
+	Host            User   `gorm:"foreignKey:HostID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Status          string `gorm:"not null"`
+	CurrentQuestion int    `gorm:"default:0"` // ลำดับคำถามที่กำลังเล่น (เริ่มที่ 1, 0 = ยังไม่เริ่มคำถามแรก)
+	StartedAt       *time.Time
+	FinishedAt      *time.Time
+	CreatedAt       time.Time
+	Players         []GamePlayer `gorm:"foreignKey:SessionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	// ตัวเลือกการสุ่มลำดับ seed ถูกเก็บไว้เพื่อสร้างลำดับที่ผู้เล่นเห็นได้ซ้ำ
+	ShuffleQuestions bool   `gorm:"default:false"`
+	ShuffleChoices   bool   `gorm:"default:false"`
+	ShuffleMode      string `gorm:"default:'room'"` // room หรือ player
+	ShuffleSeed      int64  `gorm:"not null;default:0"`
+	// ห้องเรียนที่เกมนี้จำกัดให้เข้าร่วม ถ้าเป็น nil และไม่มีรายชื่อที่อนุญาต ใครก็เข้าร่วมได้
+	ClassroomID *uint      `gorm:"index"`
+	Classroom   *Classroom `gorm:"foreignKey:ClassroomID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL" json:"-"`
+	// การควบคุมการเข้าร่วม รายชื่อที่อนุญาตอยู่ใน GameSessionAllowedUser
+	// JoinPasswordHash เก็บเฉพาะค่า bcrypt ส่วน RequireApproval ให้ผู้เล่นรอในคิวจนกว่าโฮสต์จะอนุมัติ
+	JoinPasswordHash string `json:"-"`
+	RequireApproval  bool   `gorm:"default:false"`
+	// LobbyLocked ปิดรับผู้เล่นใหม่ ผู้เล่นที่อยู่ในเกมแล้วยังเชื่อมต่อใหม่ได้
+	LobbyLocked bool `gorm:"default:false"`
+	// MaxPlayers จำนวนผู้เล่นสูงสุดไม่นับโฮสต์ 0 หมายถึงไม่จำกัด
+	MaxPlayers int `gorm:"not null;default:0"`
+	// โหมดทีม คะแนนทีมคิดจากผลรวมหรือค่าเฉลี่ยคะแนนของสมาชิก
+	// TeamTalkSeconds ช่วงเวลาให้คุยกันในทีมตอนเริ่มแต่ละคำถาม ระหว่างนี้ยังส่งคำตอบไม่ได้ (0 = ไม่มี)
+	TeamMode          bool   `gorm:"default:false"`
+	TeamScoring       string `gorm:"default:'sum'"` // sum หรือ average
+	TeamTalkSeconds   uint   `gorm:"default:0"`
+	QuestionStartedAt *time.Time
+}
+
+// วิธีคิดคะแนนทีม
+const (
+	TeamScoringSum     = "sum"
+	TeamScoringAverage = "average"
+)
+
+// TeamTalkEndsAt คืนค่าเวลาที่ช่วงคุยกันในทีมของคำถามปัจจุบันจบ หรือ nil ถ้าไม่มีช่วงคุยกัน
+func (s *GameSession) TeamTalkEndsAt() *time.Time {
+	if !s.TeamMode || s.TeamTalkSeconds == 0 || s.QuestionStartedAt == nil {
+		return nil
+	}
+	endsAt := s.QuestionStartedAt.Add(time.Duration(s.TeamTalkSeconds) * time.Second)
+	return &endsAt
+}
+
+// GameTeam ทีมในเกมโหมดทีม ผู้เล่นอยู่ได้ทีละหนึ่งทีมผ่าน GamePlayer.TeamID
+type GameTeam struct {
+	ID        uint        `gorm:"primaryKey"`
+	SessionID string      `gorm:"not null;uniqueIndex:idx_team_session_name"`
+	Session   GameSession `gorm:"foreignKey:SessionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	Name      string      `gorm:"not null;uniqueIndex:idx_team_session_name"`
+	CreatedAt time.Time
+}
+
+// IsFull ตรวจสอบว่าจำนวนผู้เล่น (ไม่นับโฮสต์) ถึงจำนวนสูงสุดของเกมแล้วหรือไม่
+func (s *GameSession) IsFull(playerCount int64) bool {
+	return s.MaxPlayers > 0 && playerCount >= int64(s.MaxPlayers)
+}
+
+// HasJoinPassword ตรวจสอบว่าเกมต้องใช้รหัสผ่านในการเข้าร่วมหรือไม่
+func (s *GameSession) HasJoinPassword() bool {
+	return s.JoinPasswordHash != ""
+}
+
+// GameSessionAllowedUser ผู้ใช้ที่โฮสต์อนุญาตให้เข้าร่วมเกม
+// ถ้าเกมมีรายชื่อนี้อย่างน้อยหนึ่งคน เฉพาะคนในรายชื่อ (หรือในห้องเรียนของเกม) เท่านั้นที่เข้าร่วมได้
+type GameSessionAllowedUser struct {
+	SessionID string      `gorm:"primaryKey"`
+	Session   GameSession `gorm:"foreignKey:SessionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	UserID    uint        `gorm:"primaryKey;index"`
+	User      User        `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
+
+// สถานะของคำขอเข้าร่วมเกมที่ต้องรอโฮสต์อนุมัติ
+const (
+	JoinRequestPending  = "pending"
+	JoinRequestApproved = "approved"
+	JoinRequestDenied   = "denied"
+)
+
+// GameJoinRequest คำขอเข้าร่วมเกมที่รอโฮสต์อนุมัติ
+type GameJoinRequest struct {
+	ID        uint        `gorm:"primaryKey"`
+	SessionID string      `gorm:"not null;uniqueIndex:idx_join_request_session_user"`
+	Session   GameSession `gorm:"foreignKey:SessionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	UserID    uint        `gorm:"not null;uniqueIndex:idx_join_request_session_user;index"`
+	User      User        `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	Nickname  string      `gorm:"not null"`
+	Status    string      `gorm:"not null;default:'pending'"`
+	CreatedAt time.Time
+	DecidedAt *time.Time
 }
 
 type GamePlayer struct {
@@ -26,4 +113,7 @@ type GamePlayer struct {
 	Nickname  string      `gorm:"not null"`
 	Score     uint        `gorm:"default:0"`
 	JoinedAt  time.Time
+	// ทีมของผู้เล่นในโหมดทีม nil หมายถึงยังไม่มีทีม
+	TeamID *uint     `gorm:"index"`
+	Team   *GameTeam `gorm:"foreignKey:TeamID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL" json:"-"`
 }
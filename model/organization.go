@@ -0,0 +1,77 @@
+package models
+
+import "time"
+
+// บทบาทของสมาชิกในองค์กร เรียงจากสิทธิ์น้อยไปมาก
+// teacher สร้างห้องเรียนและแชร์ quiz เข้าองค์กรได้ ส่วน admin และ owner จัดการสมาชิกได้
+const (
+	OrgRoleMember  = "member"
+	OrgRoleTeacher = "teacher"
+	OrgRoleAdmin   = "admin"
+	OrgRoleOwner   = "owner"
+)
+
+// orgRoleRanks ลำดับสิทธิ์ของแต่ละบทบาทในองค์กร
+var orgRoleRanks = map[string]int{
+	OrgRoleMember:  1,
+	OrgRoleTeacher: 2,
+	OrgRoleAdmin:   3,
+	OrgRoleOwner:   4,
+}
+
+// IsValidOrgRole ตรวจสอบว่าเป็นบทบาทในองค์กรที่รองรับหรือไม่
+func IsValidOrgRole(role string) bool {
+	_, ok := orgRoleRanks[role]
+	return ok
+}
+
+// OrgRoleAllows ตรวจสอบว่าบทบาท role มีสิทธิ์อย่างน้อยเท่ากับ required หรือไม่
+func OrgRoleAllows(role string, required string) bool {
+	rank, ok := orgRoleRanks[role]
+	return ok && rank >= orgRoleRanks[required]
+}
+
+// Organization องค์กร เช่น โรงเรียน ที่มีสมาชิกและห้องเรียนของตัวเอง
+type Organization struct {
+	ID          uint   `gorm:"primaryKey"`
+	Name        string `gorm:"not null"`
+	Slug        string `gorm:"not null;uniqueIndex"`
+	CreatedByID uint   `gorm:"not null"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// OrganizationMember สมาชิกขององค์กรพร้อมบทบาท
+type OrganizationMember struct {
+	ID             uint         `gorm:"primaryKey"`
+	OrganizationID uint         `gorm:"not null;uniqueIndex:idx_org_member"`
+	Organization   Organization `gorm:"foreignKey:OrganizationID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	UserID         uint         `gorm:"not null;uniqueIndex:idx_org_member;index"`
+	User           User         `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Role           string       `gorm:"not null;default:'member'"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Classroom ห้องเรียนภายในองค์กร มีครูประจำห้องและรายชื่อนักเรียน
+// เกมที่ผูกกับห้องเรียนให้เฉพาะผู้ที่อยู่ในรายชื่อเข้าร่วมได้
+type Classroom struct {
+	ID             uint         `gorm:"primaryKey"`
+	OrganizationID uint         `gorm:"not null;index"`
+	Organization   Organization `gorm:"foreignKey:OrganizationID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	Name           string       `gorm:"not null"`
+	TeacherID      uint         `gorm:"not null;index"`
+	Teacher        User         `gorm:"foreignKey:TeacherID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// ClassroomMember ผู้ใช้ที่อยู่ในรายชื่อของห้องเรียน
+type ClassroomMember struct {
+	ID          uint      `gorm:"primaryKey"`
+	ClassroomID uint      `gorm:"not null;uniqueIndex:idx_classroom_member"`
+	Classroom   Classroom `gorm:"foreignKey:ClassroomID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	UserID      uint      `gorm:"not null;uniqueIndex:idx_classroom_member;index"`
+	User        User      `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	CreatedAt   time.Time
+}
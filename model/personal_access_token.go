@@ -0,0 +1,63 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// PersonalAccessTokenPrefix นำหน้า personal access token ทุกตัว ใช้แยกจาก JWT ใน Authorization header
+const PersonalAccessTokenPrefix = "loq_pat_"
+
+// scope ของ personal access token
+const (
+	ScopeQuizzesRead  = "quizzes:read"
+	ScopeQuizzesWrite = "quizzes:write"
+	ScopeGamesHost    = "games:host"
+)
+
+// IsValidTokenScope ตรวจสอบว่าเป็น scope ที่รองรับหรือไม่
+func IsValidTokenScope(scope string) bool {
+	switch scope {
+	case ScopeQuizzesRead, ScopeQuizzesWrite, ScopeGamesHost:
+		return true
+	default:
+		return false
+	}
+}
+
+// PersonalAccessToken token สำหรับเรียก API จากสคริปต์ผ่าน Authorization: Bearer
+// เก็บเฉพาะ hash ของ token ส่วน TokenPrefix ใช้แสดงให้ผู้ใช้จำได้ว่าเป็น token ไหน
+type PersonalAccessToken struct {
+	ID          uint   `gorm:"primaryKey"`
+	UserID      uint   `gorm:"not null;index"`
+	User        User   `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	Name        string `gorm:"not null"`
+	TokenPrefix string
+	TokenHash   string `gorm:"not null;uniqueIndex"`
+	Scopes      string `gorm:"not null"` // คั่นด้วยช่องว่าง
+	ExpiresAt   *time.Time
+	LastUsedAt  *time.Time
+	RevokedAt   *time.Time
+	CreatedAt   time.Time
+}
+
+// ScopeList คืนรายการ scope ของ token
+func (t *PersonalAccessToken) ScopeList() []string {
+	return strings.Fields(t.Scopes)
+}
+
+// HasScope ตรวจสอบว่า token มี scope ที่ระบุหรือไม่
+// quizzes:write ครอบคลุม quizzes:read ด้วย
+func (t *PersonalAccessToken) HasScope(scope string) bool {
+	for _, s := range t.ScopeList() {
+		if s == scope || (s == ScopeQuizzesWrite && scope == ScopeQuizzesRead) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActive ตรวจสอบว่า token ยังไม่ถูกเพิกถอนและยังไม่หมดอายุ
+func (t *PersonalAccessToken) IsActive(now time.Time) bool {
+	return t.RevokedAt == nil && (t.ExpiresAt == nil || now.Before(*t.ExpiresAt))
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// BankQuestion คำถามในคลังข้อสอบส่วนตัวของผู้ใช้ ใช้ซ้ำได้ในหลาย quiz
+type BankQuestion struct {
+	ID          uint         `gorm:"primaryKey"`
+	OwnerID     uint         `gorm:"not null;index"`
+	Owner       User         `gorm:"foreignKey:OwnerID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	Text        string       `gorm:"not null"`
+	ImageURL    string       `gorm:"default:null"`
+	Explanation string       `gorm:"type:text"`
+	Choices     []BankChoice `gorm:"foreignKey:BankQuestionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Tags        []Tag        `gorm:"many2many:bank_question_tags;"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// BankChoice ตัวเลือกของคำถามในคลังข้อสอบ
+type BankChoice struct {
+	ID             uint   `gorm:"primaryKey"`
+	BankQuestionID uint   `gorm:"not null;index"`
+	Text           string `gorm:"not null"`
+	ImageURL       string `gorm:"default:null"`
+	IsCorrect      bool   `gorm:"not null"`
+	Position       int    `gorm:"not null;default:0"`
+}
+
+// Tag ป้ายกำกับสำหรับค้นหาคำถามในคลังข้อสอบ
+type Tag struct {
+	ID   uint   `gorm:"primaryKey"`
+	Name string `gorm:"not null;uniqueIndex"`
+}
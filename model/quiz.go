@@ -17,6 +17,15 @@ type Quiz struct {
 	UpdatedAt  time.Time
 	Questions  []Question `gorm:"foreignKey:QuizID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 	Categories []Category `gorm:"many2many:quiz_categories;"`
+
+	// ที่มาของ quiz ที่คัดลอกจาก quiz ที่เผยแพร่แล้วของผู้อื่น
+	// เก็บผู้สร้างต้นฉบับไว้ด้วย เพื่อให้ยังให้เครดิตได้แม้ต้นฉบับถูกลบ
+	ForkedFromQuizID    *uint `gorm:"index"`
+	ForkedFromQuiz      *Quiz `gorm:"foreignKey:ForkedFromQuizID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL" json:"-"`
+	ForkedFromCreatorID *uint
+	// องค์กรที่แชร์ quiz นี้ให้ สมาชิกทุกคนขององค์กรดู quiz และใช้จัดเกมได้
+	OrganizationID *uint         `gorm:"index"`
+	Organization   *Organization `gorm:"foreignKey:OrganizationID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL" json:"-"`
 }
 
 type Question struct {
@@ -25,7 +34,22 @@ type Question struct {
 	Quiz     Quiz     `gorm:"foreignKey:QuizID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"` // เพิ่ม foreignKey
 	Text     string   `gorm:"not null"`
 	ImageURL string   `gorm:"default:null"`
+	Position int      `gorm:"not null;default:0;index"` // ลำดับของคำถามใน quiz
 	Choices  []Choice `gorm:"foreignKey:QuestionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	// ไฟล์เสียงหรือวิดีโอประกอบคำถาม
+	MediaURL  string `gorm:"default:null"`
+	MediaType string `gorm:"default:null"` // audio หรือ video
+
+	// คำอธิบายเฉลย แสดงให้ผู้เล่นเห็นหลังจบคำถามเท่านั้น
+	Explanation         string `gorm:"type:text" json:",omitempty"`
+	ExplanationImageURL string `gorm:"default:null" json:",omitempty"`
+
+	// คำถามต้นฉบับในคลังข้อสอบ (ถ้าสร้างมาจากคลัง)
+	BankQuestionID *uint `gorm:"index"`
+
+	// เวลาตอบของคำถามนี้เป็นวินาที ถ้าเป็น 0 จะใช้เวลาของ quiz
+	TimeLimit uint `gorm:"not null;default:0"`
 }
 
 type Choice struct {
@@ -34,7 +58,35 @@ type Choice struct {
 	ImageURL   string   `gorm:"default:null"`
 	Question   Question `gorm:"foreignKey:QuestionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"` // เพิ่ม foreignKey
 	Text       string   `gorm:"not null"`
-	IsCorrect  bool     `gorm:"not null"`
+	IsCorrect  bool     `gorm:"not null" json:",omitempty"`
+	Position   int      `gorm:"not null;default:0"` // ลำดับของตัวเลือกในคำถาม
+}
+
+// IsPublic ตรวจสอบว่าผู้ที่ไม่มีบทบาทใน quiz อ่านได้หรือไม่
+// quiz ที่แชร์ในองค์กรเปิดให้เฉพาะสมาชิกแม้จะเผยแพร่แล้ว
+func (q *Quiz) IsPublic() bool {
+	return q.IsPublished && q.OrganizationID == nil
+}
+
+// HideAnswers ลบเฉลยและคำอธิบายของทุกคำถาม ใช้ก่อนส่ง quiz ให้ผู้ที่ไม่มีสิทธิ์แก้ไข
+func (q *Quiz) HideAnswers() {
+	for i := range q.Questions {
+		q.Questions[i].HideAnswers()
+	}
+}
+
+// HideAnswers ลบเฉลยและคำอธิบายของคำถาม
+func (q *Question) HideAnswers() {
+	q.Explanation = ""
+	q.ExplanationImageURL = ""
+	for i := range q.Choices {
+		q.Choices[i].HideAnswers()
+	}
+}
+
+// HideAnswers ลบเฉลยของตัวเลือก
+func (c *Choice) HideAnswers() {
+	c.IsCorrect = false
 }
 
 type Category struct {
@@ -44,6 +96,12 @@ type Category struct {
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 	Quizzes     []Quiz `gorm:"many2many:quiz_categories;"`
+
+	// หมวดหมู่ซ้อนกันได้ เช่น Science > Physics ถ้าลบหมวดหมู่แม่ หมวดหมู่ลูกจะขึ้นไปอยู่ระดับบน
+	ParentID *uint      `gorm:"index"`
+	Children []Category `gorm:"foreignKey:ParentID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL" json:",omitempty"`
+	Slug     string     `gorm:"index:idx_categories_slug,unique,where:slug <> ''"`
+	Icon     string     `gorm:"default:''"`
 }
 
 // QuizCategory represents the many-to-many relationship between quizzes and categories
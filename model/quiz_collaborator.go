@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// บทบาทของผู้ร่วมแก้ไข quiz เรียงจากสิทธิ์น้อยไปมาก
+const (
+	QuizRoleViewer = "viewer"
+	QuizRoleEditor = "editor"
+	QuizRoleOwner  = "owner"
+)
+
+// quizRoleRanks ลำดับสิทธิ์ของแต่ละบทบาท ใช้เปรียบเทียบว่าบทบาทหนึ่งครอบคลุมอีกบทบาทหรือไม่
+var quizRoleRanks = map[string]int{
+	QuizRoleViewer: 1,
+	QuizRoleEditor: 2,
+	QuizRoleOwner:  3,
+}
+
+// IsValidQuizRole ตรวจสอบว่าเป็นบทบาทที่รองรับหรือไม่
+func IsValidQuizRole(role string) bool {
+	_, ok := quizRoleRanks[role]
+	return ok
+}
+
+// QuizRoleAllows ตรวจสอบว่าบทบาท role มีสิทธิ์อย่างน้อยเท่ากับ required หรือไม่
+func QuizRoleAllows(role string, required string) bool {
+	rank, ok := quizRoleRanks[role]
+	return ok && rank >= quizRoleRanks[required]
+}
+
+// QuizCollaborator ผู้ใช้ที่ได้รับสิทธิ์เข้าถึง quiz ของผู้อื่น
+// ผู้สร้าง quiz เป็น owner เสมอโดยไม่ต้องมีแถวในตารางนี้
+type QuizCollaborator struct {
+	ID          uint   `gorm:"primaryKey"`
+	QuizID      uint   `gorm:"not null;uniqueIndex:idx_quiz_collaborator"`
+	Quiz        Quiz   `gorm:"foreignKey:QuizID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	UserID      uint   `gorm:"not null;uniqueIndex:idx_quiz_collaborator;index"`
+	User        User   `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Role        string `gorm:"not null;default:'viewer'"`
+	InvitedByID uint   `gorm:"not null"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
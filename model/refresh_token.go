@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// RefreshToken refresh token ที่ออกให้ผู้ใช้ เก็บเฉพาะค่า hash ไม่เก็บ token จริง
+// token ที่หมุนต่อกันจากการเข้าสู่ระบบครั้งเดียวกันจะอยู่ใน family เดียวกัน
+// ถ้า token ที่ถูกหมุนไปแล้วถูกนำมาใช้ซ้ำ ทั้ง family จะถูกเพิกถอน
+type RefreshToken struct {
+	ID           uint      `gorm:"primaryKey"`
+	UserID       uint      `gorm:"not null;index"`
+	User         User      `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	FamilyID     string    `gorm:"not null;index"`
+	TokenHash    string    `gorm:"not null;uniqueIndex"`
+	ExpiresAt    time.Time `gorm:"not null"`
+	RevokedAt    *time.Time
+	ReplacedByID *uint
+	CreatedAt    time.Time
+}
+
+// IsActive ตรวจสอบว่า token ยังใช้งานได้ (ยังไม่ถูกเพิกถอนและยังไม่หมดอายุ)
+func (t *RefreshToken) IsActive(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
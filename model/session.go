@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Session การเข้าสู่ระบบหนึ่งครั้งของผู้ใช้บนอุปกรณ์หนึ่ง ผูกกับ family ของ refresh token
+// access token ทุกตัวอ้างถึง session ผ่าน claim "sid" เมื่อ session ถูกเพิกถอน token จะใช้ไม่ได้ทันที
+type Session struct {
+	ID         uint   `gorm:"primaryKey"`
+	UserID     uint   `gorm:"not null;index"`
+	User       User   `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	FamilyID   string `gorm:"not null;uniqueIndex"`
+	Device     string
+	IP         string
+	UserAgent  string
+	LastSeenAt time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
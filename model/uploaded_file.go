@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// UploadedFile ผู้อัปโหลดไฟล์ผ่าน route อัปโหลดทั่วไป
+// ใช้ตรวจสิทธิ์ลบไฟล์ที่ยังไม่มีทรัพยากรใดอ้างอิง ให้ลบได้เฉพาะผู้อัปโหลดเท่านั้น
+type UploadedFile struct {
+	ID         uint   `gorm:"primaryKey"`
+	Path       string `gorm:"not null;uniqueIndex"` // /storage/<type>/<filename>
+	UploaderID uint   `gorm:"not null;index"`
+	Uploader   User   `gorm:"foreignKey:UploaderID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	CreatedAt  time.Time
+}
@@ -1,14 +1,74 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
+// บทบาทของผู้ใช้ในระบบ
+const (
+	UserRoleUser    = "user"
+	UserRoleTeacher = "teacher"
+	UserRoleAdmin   = "admin"
+)
+
+// IsValidUserRole ตรวจสอบว่าเป็นบทบาทผู้ใช้ที่รองรับหรือไม่
+func IsValidUserRole(role string) bool {
+	switch role {
+	case UserRoleUser, UserRoleTeacher, UserRoleAdmin:
+		return true
+	default:
+		return false
+	}
+}
+
 type User struct {
 	gorm.Model
-	GoogleID    string `gorm:"unique"`
 	Email       string
 	DisplayName string
 	PictureURL  string
-	Quiz        []Quiz `gorm:"foreignKey:CreatorID" json:"-"`
+	Quiz        []Quiz         `gorm:"foreignKey:CreatorID" json:"-"`
+	Identities  []UserIdentity `gorm:"foreignKey:UserID" json:"-"`
+
+	// บทบาทและสถานะบัญชี บัญชีที่ถูกระงับจะใช้งานไม่ได้แม้ยังมี token ที่ถูกต้อง
+	Role       string `gorm:"not null;default:'user';index"`
+	DisabledAt *time.Time
+
+	// บัญชีแบบอีเมลและรหัสผ่าน PasswordHash ว่างหมายถึงเข้าสู่ระบบผ่าน provider ภายนอกเท่านั้น
+	PasswordHash    string `json:"-"`
+	EmailVerifiedAt *time.Time
+
+	// ข้อมูลโปรไฟล์ที่ผู้ใช้ตั้งเอง Nickname เป็นชื่อเริ่มต้นเมื่อเข้าร่วมเกม
+	// AvatarURL เป็นรูปที่อัปโหลดเอง ใช้แทน PictureURL จาก provider
+	Nickname  string
+	AvatarURL string
+}
+
+// Picture คืนค่า URL รูปโปรไฟล์ที่ใช้แสดง รูปที่อัปโหลดเองมาก่อนรูปจาก provider
+func (u *User) Picture() string {
+	if u.AvatarURL != "" {
+		return u.AvatarURL
+	}
+	return u.PictureURL
+}
+
+// IsAdmin ตรวจสอบว่าผู้ใช้เป็นผู้ดูแลระบบหรือไม่
+func (u *User) IsAdmin() bool {
+	return u.Role == UserRoleAdmin
+}
+
+// IsDisabled ตรวจสอบว่าบัญชีถูกระงับหรือไม่
+func (u *User) IsDisabled() bool {
+	return u.DisabledAt != nil
+}
+
+// HasPassword ตรวจสอบว่าผู้ใช้ตั้งรหัสผ่านสำหรับเข้าสู่ระบบแบบอีเมลแล้วหรือไม่
+func (u *User) HasPassword() bool {
+	return u.PasswordHash != ""
+}
+
+// IsEmailVerified ตรวจสอบว่ายืนยันอีเมลแล้วหรือไม่
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
 }
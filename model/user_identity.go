@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// UserIdentity บัญชีภายนอก (Google, OIDC provider ของโรงเรียน ฯลฯ) ที่เชื่อมกับผู้ใช้
+// ผู้ใช้หนึ่งคนเชื่อมได้หลาย identity แต่ identity หนึ่งเป็นของผู้ใช้ได้คนเดียว
+type UserIdentity struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	User      User   `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	Provider  string `gorm:"not null;uniqueIndex:idx_identity_provider_subject"`
+	Subject   string `gorm:"not null;uniqueIndex:idx_identity_provider_subject"`
+	Email     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// จุดประสงค์ของ token ที่ส่งให้ผู้ใช้ทางอีเมล
+const (
+	UserTokenVerifyEmail   = "verify_email"
+	UserTokenResetPassword = "reset_password"
+)
+
+// UserToken token แบบใช้ครั้งเดียวที่ส่งทางอีเมล เก็บเฉพาะค่า hash ในฐานข้อมูล
+type UserToken struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	User      User   `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	Purpose   string `gorm:"not null;index"`
+	TokenHash string `gorm:"not null;uniqueIndex"`
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
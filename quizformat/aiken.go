@@ -0,0 +1,131 @@
+package quizformat
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	models "github.com/patiphanak/league-of-quiz/model"
+)
+
+var (
+	aikenOptionPattern = regexp.MustCompile(`^([A-Z])[.)]\s+(.+)$`)
+	aikenAnswerPattern = regexp.MustCompile(`^ANSWER:\s*([A-Z])\s*$`)
+)
+
+// maxAikenChoices จำนวนตัวเลือกสูงสุดที่ Aiken เขียนได้ (A-Z)
+const maxAikenChoices = 26
+
+// ParseAiken อ่านคำถามจากเนื้อหารูปแบบ Moodle Aiken
+// Aiken รองรับเฉพาะปรนัยที่มีคำตอบถูกข้อเดียว คำถามที่รูปแบบไม่ถูกต้องจะถูกรายงานเป็น Issue
+func ParseAiken(content string) ([]ParsedQuestion, []Issue) {
+	var questions []ParsedQuestion
+	var issues []Issue
+
+	for _, block := range splitBlocks(content, nil) {
+		question, err := parseAikenQuestion(block.lines)
+		if err != nil {
+			issues = append(issues, Issue{Line: block.line, Message: err.Error(), Skipped: true})
+			continue
+		}
+		questions = append(questions, ParsedQuestion{Line: block.line, Question: *question})
+	}
+	return questions, issues
+}
+
+// parseAikenQuestion แปลงบรรทัดของหนึ่งคำถาม Aiken
+func parseAikenQuestion(lines []string) (*models.Question, error) {
+	var stem []string
+	question := &models.Question{}
+	letters := make(map[string]int)
+	answer := ""
+
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+
+		if match := aikenAnswerPattern.FindStringSubmatch(line); match != nil {
+			if i != len(lines)-1 {
+				return nil, fmt.Errorf("ANSWER line must be the last line of the question")
+			}
+			answer = match[1]
+			break
+		}
+
+		if match := aikenOptionPattern.FindStringSubmatch(line); match != nil && len(stem) > 0 {
+			if _, exists := letters[match[1]]; exists {
+				return nil, fmt.Errorf("option %s is defined more than once", match[1])
+			}
+			letters[match[1]] = len(question.Choices)
+			question.Choices = append(question.Choices, models.Choice{
+				Text:     strings.TrimSpace(match[2]),
+				Position: len(question.Choices) + 1,
+			})
+			continue
+		}
+
+		if len(question.Choices) > 0 {
+			return nil, fmt.Errorf("unexpected line after options: %q", line)
+		}
+		stem = append(stem, line)
+	}
+
+	question.Text = strings.Join(stem, "\n")
+	switch {
+	case question.Text == "":
+		return nil, fmt.Errorf("question text is empty")
+	case len(question.Choices) < 2:
+		return nil, fmt.Errorf("question must have at least two options")
+	case answer == "":
+		return nil, fmt.Errorf("question has no ANSWER line")
+	}
+
+	index, ok := letters[answer]
+	if !ok {
+		return nil, fmt.Errorf("ANSWER %s does not match any option", answer)
+	}
+	question.Choices[index].IsCorrect = true
+	return question, nil
+}
+
+// WriteAiken เขียนคำถามเป็นรูปแบบ Moodle Aiken
+// คำถามที่มีคำตอบถูกมากกว่าหรือน้อยกว่าหนึ่งข้อจะถูกข้ามและรายงานเป็น Issue
+func WriteAiken(questions []models.Question) (string, []Issue) {
+	var b strings.Builder
+	var issues []Issue
+
+	for i, question := range questions {
+		index := i + 1
+
+		correct := -1
+		correctCount := 0
+		for j, choice := range question.Choices {
+			if choice.IsCorrect {
+				correct = j
+				correctCount++
+			}
+		}
+		if correctCount != 1 {
+			issues = append(issues, Issue{Question: index, Message: "Aiken supports exactly one correct answer; question was skipped", Skipped: true})
+			continue
+		}
+		if len(question.Choices) > maxAikenChoices {
+			issues = append(issues, Issue{Question: index, Message: fmt.Sprintf("Aiken supports at most %d options; question was skipped", maxAikenChoices), Skipped: true})
+			continue
+		}
+
+		issues = append(issues, exportIssues(index, question, Aiken, false)...)
+
+		b.WriteString(aikenLine(question.Text))
+		b.WriteString("\n")
+		for j, choice := range question.Choices {
+			fmt.Fprintf(&b, "%c. %s\n", 'A'+j, aikenLine(choice.Text))
+		}
+		fmt.Fprintf(&b, "ANSWER: %c\n\n", 'A'+correct)
+	}
+	return b.String(), issues
+}
+
+// aikenLine รวมข้อความหลายบรรทัดเป็นบรรทัดเดียว เพราะบรรทัดว่างใน Aiken คือตัวคั่นคำถาม
+func aikenLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
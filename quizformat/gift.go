@@ -0,0 +1,359 @@
+package quizformat
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	models "github.com/patiphanak/league-of-quiz/model"
+)
+
+// giftSpecialChars อักขระที่ต้อง escape ในรูปแบบ GIFT
+const giftSpecialChars = `~=#{}:\`
+
+// giftAnswer คำตอบหนึ่งรายการในบล็อกคำตอบของ GIFT
+type giftAnswer struct {
+	correct  bool
+	wrong    bool // ขึ้นต้นด้วย ~ (คำตอบที่ผิดหรือมีน้ำหนักคะแนน)
+	text     string
+	feedback bool
+}
+
+// ParseGIFT อ่านคำถามจากเนื้อหารูปแบบ Moodle GIFT
+// รองรับปรนัย (รวมถึงหลายคำตอบที่ใช้น้ำหนักคะแนน) ถูก/ผิด และเติมคำในช่องว่างแบบมีตัวเลือก
+// คำถามแบบอื่น เช่น อัตนัย ตัวเลข จับคู่ และคำตอบสั้น จะถูกรายงานเป็น Issue
+func ParseGIFT(content string) ([]ParsedQuestion, []Issue) {
+	var questions []ParsedQuestion
+	var issues []Issue
+
+	blocks := splitBlocks(content, func(line string) bool {
+		return strings.HasPrefix(line, "//")
+	})
+	for _, block := range blocks {
+		text := strings.TrimSpace(strings.Join(block.lines, "\n"))
+		if strings.HasPrefix(text, "$CATEGORY:") {
+			issues = append(issues, Issue{Line: block.line, Message: "category directives are not supported and were ignored"})
+			continue
+		}
+
+		question, blockIssues := parseGIFTQuestion(text)
+		for _, issue := range blockIssues {
+			issue.Line = block.line
+			issue.Skipped = question == nil
+			issues = append(issues, issue)
+		}
+		if question != nil {
+			questions = append(questions, ParsedQuestion{Line: block.line, Question: *question})
+		}
+	}
+	return questions, issues
+}
+
+// parseGIFTQuestion แปลงข้อความหนึ่งคำถามของ GIFT คืนค่า nil ถ้าแปลงไม่ได้
+func parseGIFTQuestion(text string) (*models.Question, []Issue) {
+	var issues []Issue
+	unsupported := func(kind string) (*models.Question, []Issue) {
+		return nil, append(issues, Issue{Message: kind + " questions are not supported and were skipped"})
+	}
+
+	// ชื่อคำถาม ::title::
+	title := ""
+	if strings.HasPrefix(text, "::") {
+		end := indexUnescaped(text[2:], "::")
+		if end < 0 {
+			return nil, []Issue{{Message: "unterminated question title"}}
+		}
+		title = unescapeGIFT(strings.TrimSpace(text[2 : 2+end]))
+		text = strings.TrimSpace(text[2+end+2:])
+	}
+
+	// ตัดรูปแบบข้อความ เช่น [html] [moodle] [markdown] [plain]
+	if strings.HasPrefix(text, "[") {
+		if end := strings.Index(text, "]"); end > 0 {
+			switch strings.ToLower(text[1:end]) {
+			case "html", "moodle", "markdown", "plain":
+				text = strings.TrimSpace(text[end+1:])
+			}
+		}
+	}
+
+	open := indexUnescaped(text, "{")
+	if open < 0 {
+		return unsupported("description")
+	}
+	closeIndex := indexUnescaped(text[open+1:], "}")
+	if closeIndex < 0 {
+		return nil, []Issue{{Message: "unterminated answer block"}}
+	}
+	closeIndex += open + 1
+
+	stem := strings.TrimSpace(text[:open])
+	after := strings.TrimSpace(text[closeIndex+1:])
+	if after != "" {
+		// คำถามเติมคำในช่องว่าง
+		stem = strings.TrimSpace(stem + " _____ " + after)
+	}
+	stem = unescapeGIFT(stem)
+	if stem == "" {
+		stem = title
+	}
+	if stem == "" {
+		return nil, []Issue{{Message: "question text is empty"}}
+	}
+
+	body := strings.TrimSpace(text[open+1 : closeIndex])
+
+	// คำอธิบายทั่วไป ####feedback
+	explanation := ""
+	if i := indexUnescaped(body, "####"); i >= 0 {
+		explanation = unescapeGIFT(strings.TrimSpace(body[i+4:]))
+		body = strings.TrimSpace(body[:i])
+	}
+
+	question := &models.Question{Text: stem, Explanation: explanation}
+
+	switch {
+	case body == "":
+		return unsupported("essay")
+	case strings.HasPrefix(body, "#"):
+		return unsupported("numeric")
+	}
+
+	// คำถามถูก/ผิด
+	tf, feedback := body, ""
+	if i := indexUnescaped(body, "#"); i >= 0 {
+		tf, feedback = strings.TrimSpace(body[:i]), body[i:]
+	}
+	switch strings.ToUpper(tf) {
+	case "T", "TRUE", "F", "FALSE":
+		isTrue := strings.HasPrefix(strings.ToUpper(tf), "T")
+		question.Choices = []models.Choice{
+			{Text: "True", IsCorrect: isTrue, Position: 1},
+			{Text: "False", IsCorrect: !isTrue, Position: 2},
+		}
+		if feedback != "" {
+			issues = append(issues, Issue{Message: "answer feedback is not supported and was omitted"})
+		}
+		return question, issues
+	}
+
+	answers, err := splitGIFTAnswers(body)
+	if err != nil {
+		return nil, []Issue{{Message: err.Error()}}
+	}
+
+	hasWrong := false
+	for _, answer := range answers {
+		if strings.Contains(answer.text, "->") {
+			return unsupported("matching")
+		}
+		hasWrong = hasWrong || answer.wrong
+	}
+	if !hasWrong {
+		return unsupported("short answer")
+	}
+
+	hasCorrect, hasFeedback := false, false
+	for i, answer := range answers {
+		if answer.text == "" {
+			return nil, []Issue{{Message: fmt.Sprintf("answer %d is empty", i+1)}}
+		}
+		hasCorrect = hasCorrect || answer.correct
+		hasFeedback = hasFeedback || answer.feedback
+		question.Choices = append(question.Choices, models.Choice{
+			Text:      answer.text,
+			IsCorrect: answer.correct,
+			Position:  i + 1,
+		})
+	}
+	if !hasCorrect {
+		return nil, []Issue{{Message: "question has no correct answer"}}
+	}
+	if hasFeedback {
+		issues = append(issues, Issue{Message: "answer feedback is not supported and was omitted"})
+	}
+	return question, issues
+}
+
+// splitGIFTAnswers แยกคำตอบที่ขึ้นต้นด้วย = หรือ ~ ในบล็อกคำตอบ
+func splitGIFTAnswers(body string) ([]giftAnswer, error) {
+	var answers []giftAnswer
+	start := -1
+	flush := func(end int) error {
+		if start < 0 {
+			if strings.TrimSpace(body[:end]) != "" {
+				return fmt.Errorf("unexpected text before first answer: %q", strings.TrimSpace(body[:end]))
+			}
+			return nil
+		}
+		answer, err := parseGIFTAnswer(body[start], body[start+1:end])
+		if err != nil {
+			return err
+		}
+		answers = append(answers, answer)
+		return nil
+	}
+
+	for i := 0; i < len(body); i++ {
+		switch body[i] {
+		case '\\':
+			i++
+		case '=', '~':
+			if err := flush(i); err != nil {
+				return nil, err
+			}
+			start = i
+		}
+	}
+	if err := flush(len(body)); err != nil {
+		return nil, err
+	}
+	if len(answers) == 0 {
+		return nil, fmt.Errorf("answer block has no answers")
+	}
+	return answers, nil
+}
+
+// parseGIFTAnswer แปลงคำตอบหนึ่งรายการ รวมถึงน้ำหนัก %n% และ feedback #...
+func parseGIFTAnswer(marker byte, raw string) (giftAnswer, error) {
+	answer := giftAnswer{correct: marker == '=', wrong: marker == '~'}
+	raw = strings.TrimSpace(raw)
+
+	if strings.HasPrefix(raw, "%") {
+		end := strings.Index(raw[1:], "%")
+		if end < 0 {
+			return answer, fmt.Errorf("invalid answer weight in %q", raw)
+		}
+		weight, err := strconv.ParseFloat(raw[1:1+end], 64)
+		if err != nil {
+			return answer, fmt.Errorf("invalid answer weight in %q", raw)
+		}
+		answer.correct = weight > 0
+		raw = strings.TrimSpace(raw[end+2:])
+	}
+
+	if i := indexUnescaped(raw, "#"); i >= 0 {
+		answer.feedback = strings.TrimSpace(raw[i+1:]) != ""
+		raw = raw[:i]
+	}
+	answer.text = unescapeGIFT(strings.TrimSpace(raw))
+	return answer, nil
+}
+
+// WriteGIFT เขียนคำถามเป็นรูปแบบ Moodle GIFT
+func WriteGIFT(questions []models.Question) (string, []Issue) {
+	var b strings.Builder
+	var issues []Issue
+
+	for i, question := range questions {
+		index := i + 1
+		issues = append(issues, exportIssues(index, question, GIFT, true)...)
+
+		fmt.Fprintf(&b, "// question: %d\n", index)
+		fmt.Fprintf(&b, "::Q%d:: %s {", index, escapeGIFT(question.Text))
+
+		if value, ok := trueFalseAnswer(question.Choices); ok {
+			if value {
+				b.WriteString("T")
+			} else {
+				b.WriteString("F")
+			}
+			if question.Explanation != "" {
+				fmt.Fprintf(&b, "####%s", escapeGIFT(question.Explanation))
+			}
+			b.WriteString("}\n\n")
+			continue
+		}
+
+		correctCount := 0
+		for _, choice := range question.Choices {
+			if choice.IsCorrect {
+				correctCount++
+			}
+		}
+
+		b.WriteString("\n")
+		for _, choice := range question.Choices {
+			switch {
+			case correctCount <= 1 && choice.IsCorrect:
+				fmt.Fprintf(&b, "\t=%s\n", escapeGIFT(choice.Text))
+			case correctCount <= 1:
+				fmt.Fprintf(&b, "\t~%s\n", escapeGIFT(choice.Text))
+			case choice.IsCorrect:
+				// หลายคำตอบที่ถูกต้อง ใช้น้ำหนักคะแนนแบ่งเท่าๆ กัน
+				weight := strconv.FormatFloat(100/float64(correctCount), 'f', 5, 64)
+				weight = strings.TrimRight(strings.TrimRight(weight, "0"), ".")
+				fmt.Fprintf(&b, "\t~%%%s%%%s\n", weight, escapeGIFT(choice.Text))
+			default:
+				fmt.Fprintf(&b, "\t~%%-100%%%s\n", escapeGIFT(choice.Text))
+			}
+		}
+		if question.Explanation != "" {
+			fmt.Fprintf(&b, "\t####%s\n", escapeGIFT(question.Explanation))
+		}
+		b.WriteString("}\n\n")
+	}
+	return b.String(), issues
+}
+
+// trueFalseAnswer ตรวจสอบว่าตัวเลือกเป็นคำถามถูก/ผิดหรือไม่ และคืนค่าคำตอบที่ถูกต้อง
+func trueFalseAnswer(choices []models.Choice) (bool, bool) {
+	if len(choices) != 2 || choices[0].IsCorrect == choices[1].IsCorrect {
+		return false, false
+	}
+	first, second := strings.ToLower(choices[0].Text), strings.ToLower(choices[1].Text)
+	if first != "true" || second != "false" {
+		return false, false
+	}
+	return choices[0].IsCorrect, true
+}
+
+// escapeGIFT escape อักขระพิเศษของ GIFT และขึ้นบรรทัดใหม่
+func escapeGIFT(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+		case strings.ContainsRune(giftSpecialChars, r):
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// unescapeGIFT แปลงอักขระที่ถูก escape กลับเป็นข้อความปกติ
+func unescapeGIFT(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' && i+1 < len(text) {
+			i++
+			if text[i] == 'n' {
+				b.WriteByte('\n')
+			} else {
+				b.WriteByte(text[i])
+			}
+			continue
+		}
+		b.WriteByte(text[i])
+	}
+	return b.String()
+}
+
+// indexUnescaped หาตำแหน่งแรกของ sub ที่ไม่ได้ถูก escape ด้วย backslash
+func indexUnescaped(s, sub string) int {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(s[i:], sub) {
+			return i
+		}
+	}
+	return -1
+}
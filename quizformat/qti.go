@@ -0,0 +1,668 @@
+package quizformat
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strings"
+
+	models "github.com/patiphanak/league-of-quiz/model"
+)
+
+const (
+	qtiNamespace      = "http://www.imsglobal.org/xsd/imsqti_v2p1"
+	qtiManifestNS     = "http://www.imsglobal.org/xsd/imscp_v1p1"
+	qtiManifestName   = "imsmanifest.xml"
+	qtiTestFile       = "assessment-test.xml"
+	qtiItemType       = "imsqti_item_xmlv2p1"
+	qtiTestType       = "imsqti_test_xmlv2p1"
+	qtiMatchCorrect   = "http://www.imsglobal.org/question/qti_v2p1/rp_templates/match_correct"
+	qtiResponseID     = "RESPONSE"
+	qtiExplanationID  = "EXPLANATION"
+	maxQTIXMLFileSize = 10 * 1024 * 1024
+)
+
+// QTIPackage ข้อมูล quiz ที่อ่านได้จากแพ็กเกจ QTI 2.1
+// ไฟล์ที่อ้างอิงใน Question และ Choice เป็น path ภายในแพ็กเกจ หรือ URL ภายนอกแบบ http(s)
+type QTIPackage struct {
+	Title     string
+	Questions []models.Question
+}
+
+// IsPackagePath ตรวจสอบว่าไฟล์อ้างอิงเป็น path ภายในแพ็กเกจ (ไม่ใช่ URL ภายนอก)
+func IsPackagePath(ref string) bool {
+	return ref != "" && !strings.HasPrefix(ref, "http://") && !strings.HasPrefix(ref, "https://")
+}
+
+// ---- โครงสร้าง XML สำหรับการเขียน ----
+
+type qtiManifest struct {
+	XMLName    xml.Name          `xml:"manifest"`
+	Xmlns      string            `xml:"xmlns,attr"`
+	Identifier string            `xml:"identifier,attr"`
+	Resources  []qtiManifestItem `xml:"resources>resource"`
+}
+
+type qtiManifestItem struct {
+	Identifier string            `xml:"identifier,attr"`
+	Type       string            `xml:"type,attr"`
+	Href       string            `xml:"href,attr"`
+	Files      []qtiManifestFile `xml:"file"`
+}
+
+type qtiManifestFile struct {
+	Href string `xml:"href,attr"`
+}
+
+type qtiAssessmentTest struct {
+	XMLName    xml.Name `xml:"assessmentTest"`
+	Xmlns      string   `xml:"xmlns,attr"`
+	Identifier string   `xml:"identifier,attr"`
+	Title      string   `xml:"title,attr"`
+	TestPart   struct {
+		Identifier     string `xml:"identifier,attr"`
+		NavigationMode string `xml:"navigationMode,attr"`
+		SubmissionMode string `xml:"submissionMode,attr"`
+		Section        struct {
+			Identifier string       `xml:"identifier,attr"`
+			Title      string       `xml:"title,attr"`
+			Visible    bool         `xml:"visible,attr"`
+			ItemRefs   []qtiItemRef `xml:"assessmentItemRef"`
+		} `xml:"assessmentSection"`
+	} `xml:"testPart"`
+}
+
+type qtiItemRef struct {
+	Identifier string `xml:"identifier,attr"`
+	Href       string `xml:"href,attr"`
+}
+
+type qtiAssessmentItem struct {
+	XMLName             xml.Name                `xml:"assessmentItem"`
+	Xmlns               string                  `xml:"xmlns,attr"`
+	Identifier          string                  `xml:"identifier,attr"`
+	Title               string                  `xml:"title,attr"`
+	Adaptive            bool                    `xml:"adaptive,attr"`
+	TimeDependent       bool                    `xml:"timeDependent,attr"`
+	ResponseDeclaration qtiResponseDeclaration  `xml:"responseDeclaration"`
+	OutcomeDeclarations []qtiOutcomeDeclaration `xml:"outcomeDeclaration"`
+	ItemBody            qtiItemBody             `xml:"itemBody"`
+	ResponseProcessing  struct {
+		Template string `xml:"template,attr"`
+	} `xml:"responseProcessing"`
+	ModalFeedback *qtiModalFeedback `xml:"modalFeedback"`
+}
+
+type qtiResponseDeclaration struct {
+	Identifier      string   `xml:"identifier,attr"`
+	Cardinality     string   `xml:"cardinality,attr"`
+	BaseType        string   `xml:"baseType,attr"`
+	CorrectResponse []string `xml:"correctResponse>value"`
+}
+
+type qtiOutcomeDeclaration struct {
+	Identifier  string `xml:"identifier,attr"`
+	Cardinality string `xml:"cardinality,attr"`
+	BaseType    string `xml:"baseType,attr"`
+}
+
+type qtiItemBody struct {
+	Paragraphs  []qtiParagraph       `xml:"p"`
+	Interaction qtiChoiceInteraction `xml:"choiceInteraction"`
+}
+
+type qtiParagraph struct {
+	Text   string     `xml:",chardata"`
+	Image  *qtiImage  `xml:"img"`
+	Object *qtiObject `xml:"object"`
+}
+
+type qtiImage struct {
+	Src string `xml:"src,attr"`
+	Alt string `xml:"alt,attr"`
+}
+
+type qtiObject struct {
+	Data string `xml:"data,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type qtiChoiceInteraction struct {
+	ResponseIdentifier string            `xml:"responseIdentifier,attr"`
+	Shuffle            bool              `xml:"shuffle,attr"`
+	MaxChoices         int               `xml:"maxChoices,attr"`
+	Choices            []qtiSimpleChoice `xml:"simpleChoice"`
+}
+
+type qtiSimpleChoice struct {
+	Identifier string    `xml:"identifier,attr"`
+	Text       string    `xml:",chardata"`
+	Image      *qtiImage `xml:"img"`
+}
+
+type qtiModalFeedback struct {
+	OutcomeIdentifier string `xml:"outcomeIdentifier,attr"`
+	ShowHide          string `xml:"showHide,attr"`
+	Identifier        string `xml:"identifier,attr"`
+	Text              string `xml:",chardata"`
+}
+
+// WriteQTIPackage เขียน quiz เป็นแพ็กเกจ QTI 2.1 ลงใน zip
+// addFile ต้องเขียนไฟล์จาก URL ลงใน zip และคืนค่า path ภายในแพ็กเกจ
+func WriteQTIPackage(zw *zip.Writer, quiz *models.Quiz, addFile func(fileURL string) (string, error)) ([]Issue, error) {
+	var issues []Issue
+
+	manifest := qtiManifest{Xmlns: qtiManifestNS, Identifier: fmt.Sprintf("quiz-%d", quiz.ID)}
+	test := qtiAssessmentTest{Xmlns: qtiNamespace, Identifier: fmt.Sprintf("test-%d", quiz.ID), Title: quiz.Title}
+	test.TestPart.Identifier = "part-1"
+	test.TestPart.NavigationMode = "linear"
+	test.TestPart.SubmissionMode = "individual"
+	test.TestPart.Section.Identifier = "section-1"
+	test.TestPart.Section.Title = quiz.Title
+	test.TestPart.Section.Visible = true
+
+	for i, question := range quiz.Questions {
+		index := i + 1
+		identifier := fmt.Sprintf("item-%d", index)
+		href := identifier + ".xml"
+		resource := qtiManifestItem{Identifier: identifier, Type: qtiItemType, Href: href}
+		resource.Files = append(resource.Files, qtiManifestFile{Href: href})
+
+		addResourceFile := func(fileURL string) (string, error) {
+			ref, err := addFile(fileURL)
+			if err != nil || ref == "" {
+				return ref, err
+			}
+			resource.Files = append(resource.Files, qtiManifestFile{Href: ref})
+			return ref, nil
+		}
+
+		item, itemIssues, err := buildQTIItem(identifier, index, question, addResourceFile)
+		if err != nil {
+			return nil, fmt.Errorf("question %d: %w", index, err)
+		}
+		issues = append(issues, itemIssues...)
+
+		if err := writeXMLFile(zw, href, item); err != nil {
+			return nil, err
+		}
+		manifest.Resources = append(manifest.Resources, resource)
+		test.TestPart.Section.ItemRefs = append(test.TestPart.Section.ItemRefs, qtiItemRef{Identifier: identifier, Href: href})
+	}
+
+	if err := writeXMLFile(zw, qtiTestFile, test); err != nil {
+		return nil, err
+	}
+	manifest.Resources = append([]qtiManifestItem{{
+		Identifier: "test",
+		Type:       qtiTestType,
+		Href:       qtiTestFile,
+		Files:      []qtiManifestFile{{Href: qtiTestFile}},
+	}}, manifest.Resources...)
+	if err := writeXMLFile(zw, qtiManifestName, manifest); err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+// buildQTIItem แปลงคำถามเป็น assessmentItem แบบ choiceInteraction
+func buildQTIItem(identifier string, index int, question models.Question, addFile func(string) (string, error)) (*qtiAssessmentItem, []Issue, error) {
+	var issues []Issue
+	add := func(message string) {
+		issues = append(issues, Issue{Question: index, Message: message})
+	}
+
+	item := &qtiAssessmentItem{
+		Xmlns:      qtiNamespace,
+		Identifier: identifier,
+		Title:      fmt.Sprintf("Question %d", index),
+		OutcomeDeclarations: []qtiOutcomeDeclaration{
+			{Identifier: "SCORE", Cardinality: "single", BaseType: "float"},
+		},
+	}
+	item.ResponseProcessing.Template = qtiMatchCorrect
+
+	for _, line := range strings.Split(question.Text, "\n") {
+		item.ItemBody.Paragraphs = append(item.ItemBody.Paragraphs, qtiParagraph{Text: line})
+	}
+
+	if question.ImageURL != "" {
+		src, err := addFile(question.ImageURL)
+		if err != nil {
+			return nil, nil, err
+		}
+		item.ItemBody.Paragraphs = append(item.ItemBody.Paragraphs, qtiParagraph{Image: &qtiImage{Src: src}})
+	}
+	if question.MediaURL != "" {
+		data, err := addFile(question.MediaURL)
+		if err != nil {
+			return nil, nil, err
+		}
+		mimeType := mime.TypeByExtension(path.Ext(data))
+		if mimeType == "" {
+			mimeType = question.MediaType + "/*"
+		}
+		item.ItemBody.Paragraphs = append(item.ItemBody.Paragraphs, qtiParagraph{Object: &qtiObject{Data: data, Type: mimeType}})
+	}
+
+	item.ResponseDeclaration = qtiResponseDeclaration{
+		Identifier:  qtiResponseID,
+		Cardinality: "single",
+		BaseType:    "identifier",
+	}
+	item.ItemBody.Interaction = qtiChoiceInteraction{ResponseIdentifier: qtiResponseID, MaxChoices: 1}
+
+	for j, choice := range question.Choices {
+		simpleChoice := qtiSimpleChoice{Identifier: fmt.Sprintf("choice-%d", j+1), Text: choice.Text}
+		if choice.ImageURL != "" {
+			src, err := addFile(choice.ImageURL)
+			if err != nil {
+				return nil, nil, err
+			}
+			simpleChoice.Image = &qtiImage{Src: src}
+		}
+		if choice.IsCorrect {
+			item.ResponseDeclaration.CorrectResponse = append(item.ResponseDeclaration.CorrectResponse, simpleChoice.Identifier)
+		}
+		item.ItemBody.Interaction.Choices = append(item.ItemBody.Interaction.Choices, simpleChoice)
+	}
+
+	// หลายคำตอบที่ถูกต้องใช้ cardinality multiple และเลือกได้ไม่จำกัด
+	if len(item.ResponseDeclaration.CorrectResponse) > 1 {
+		item.ResponseDeclaration.Cardinality = "multiple"
+		item.ItemBody.Interaction.MaxChoices = 0
+	}
+
+	if question.Explanation != "" {
+		item.OutcomeDeclarations = append(item.OutcomeDeclarations, qtiOutcomeDeclaration{
+			Identifier: "FEEDBACK", Cardinality: "single", BaseType: "identifier",
+		})
+		item.ModalFeedback = &qtiModalFeedback{
+			OutcomeIdentifier: "FEEDBACK",
+			ShowHide:          "show",
+			Identifier:        qtiExplanationID,
+			Text:              question.Explanation,
+		}
+	}
+
+	if question.ExplanationImageURL != "" {
+		add("explanation image is not supported by qti and was omitted")
+	}
+	if question.TimeLimit > 0 {
+		add("time limit is not supported by qti and was omitted")
+	}
+	return item, issues, nil
+}
+
+// writeXMLFile เขียนโครงสร้าง XML ลงเป็นไฟล์ใน zip
+func writeXMLFile(zw *zip.Writer, name string, v interface{}) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	return encoder.Encode(v)
+}
+
+// ---- การอ่านแพ็กเกจ ----
+
+// xmlNode โหนดของเอกสาร XML แบบทั่วไป ใช้อ่านเนื้อหาผสมใน itemBody
+// โหนดที่ name เป็นค่าว่างคือข้อความ
+type xmlNode struct {
+	name     string
+	attrs    map[string]string
+	text     string
+	children []*xmlNode
+}
+
+// parseXMLTree อ่านเอกสาร XML เป็นต้นไม้ของ xmlNode
+func parseXMLTree(r io.Reader) (*xmlNode, error) {
+	decoder := xml.NewDecoder(io.LimitReader(r, maxQTIXMLFileSize))
+	root := &xmlNode{}
+	stack := []*xmlNode{root}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		parent := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: t.Name.Local, attrs: make(map[string]string, len(t.Attr))}
+			for _, attr := range t.Attr {
+				node.attrs[attr.Name.Local] = attr.Value
+			}
+			parent.children = append(parent.children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			parent.children = append(parent.children, &xmlNode{text: string(t)})
+		}
+	}
+
+	for _, child := range root.children {
+		if child.name != "" {
+			return child, nil
+		}
+	}
+	return nil, fmt.Errorf("empty XML document")
+}
+
+// findAll หาโหนดลูกหลานทั้งหมดที่มีชื่อตามที่กำหนด
+func (n *xmlNode) findAll(name string) []*xmlNode {
+	var found []*xmlNode
+	for _, child := range n.children {
+		if child.name == name {
+			found = append(found, child)
+		}
+		found = append(found, child.findAll(name)...)
+	}
+	return found
+}
+
+// find หาโหนดลูกหลานแรกที่มีชื่อตามที่กำหนด
+func (n *xmlNode) find(name string) *xmlNode {
+	if found := n.findAll(name); len(found) > 0 {
+		return found[0]
+	}
+	return nil
+}
+
+// lines ดึงข้อความของโหนดเป็นบรรทัด โดยแยกบรรทัดตาม block element และ <br>
+// โหนดที่ skip คืนค่า true จะไม่ถูกนำมารวม
+func (n *xmlNode) lines(skip func(*xmlNode) bool) []string {
+	var result []string
+	var current strings.Builder
+	flush := func() {
+		if line := strings.Join(strings.Fields(current.String()), " "); line != "" {
+			result = append(result, line)
+		}
+		current.Reset()
+	}
+
+	var walk func(node *xmlNode)
+	walk = func(node *xmlNode) {
+		for _, child := range node.children {
+			switch {
+			case child.name == "":
+				current.WriteString(child.text)
+			case skip != nil && skip(child):
+			case child.name == "br":
+				flush()
+			case isQTIBlockElement(child.name):
+				flush()
+				walk(child)
+				flush()
+			default:
+				walk(child)
+			}
+		}
+	}
+	walk(n)
+	flush()
+	return result
+}
+
+// isQTIBlockElement ตรวจสอบว่าเป็น element ที่ขึ้นบรรทัดใหม่
+func isQTIBlockElement(name string) bool {
+	switch name {
+	case "p", "div", "prompt", "li", "blockquote", "pre", "h1", "h2", "h3", "h4", "h5", "h6":
+		return true
+	}
+	return false
+}
+
+// ReadQTIPackage อ่านแพ็กเกจ QTI 2.1 จาก zip
+// รองรับเฉพาะ choiceInteraction ส่วน interaction แบบอื่นจะถูกรายงานเป็น Issue
+func ReadQTIPackage(files map[string]*zip.File) (*QTIPackage, []Issue, error) {
+	manifestFile, ok := files[qtiManifestName]
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid QTI package: %s not found", qtiManifestName)
+	}
+	manifest, err := readXMLTree(manifestFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pkg := &QTIPackage{}
+	var itemHrefs []string
+	for _, resource := range manifest.findAll("resource") {
+		if strings.HasPrefix(resource.attrs["type"], "imsqti_item") {
+			itemHrefs = append(itemHrefs, resource.attrs["href"])
+		}
+	}
+
+	// ชื่อและลำดับคำถามจาก assessmentTest มีความสำคัญกว่าลำดับใน manifest
+	for _, resource := range manifest.findAll("resource") {
+		if !strings.HasPrefix(resource.attrs["type"], "imsqti_test") {
+			continue
+		}
+		if testFile, ok := files[resource.attrs["href"]]; ok {
+			test, err := readXMLTree(testFile)
+			if err != nil {
+				return nil, nil, err
+			}
+			pkg.Title = test.attrs["title"]
+
+			var ordered []string
+			for _, ref := range test.findAll("assessmentItemRef") {
+				ordered = append(ordered, resolveQTIHref(resource.attrs["href"], ref.attrs["href"]))
+			}
+			if len(ordered) > 0 {
+				itemHrefs = ordered
+			}
+		}
+		break
+	}
+
+	var issues []Issue
+	for i, href := range itemHrefs {
+		index := i + 1
+		itemFile, ok := files[href]
+		if !ok {
+			issues = append(issues, Issue{Question: index, Message: fmt.Sprintf("item %s not found in package", href), Skipped: true})
+			continue
+		}
+		item, err := readXMLTree(itemFile)
+		if err != nil {
+			issues = append(issues, Issue{Question: index, Message: err.Error(), Skipped: true})
+			continue
+		}
+
+		question, itemIssues := parseQTIItem(item, href, files)
+		for _, issue := range itemIssues {
+			issue.Question = index
+			issue.Skipped = question == nil
+			issues = append(issues, issue)
+		}
+		if question != nil {
+			pkg.Questions = append(pkg.Questions, *question)
+		}
+	}
+	return pkg, issues, nil
+}
+
+// parseQTIItem แปลง assessmentItem เป็นคำถาม คืนค่า nil ถ้าแปลงไม่ได้
+func parseQTIItem(item *xmlNode, href string, files map[string]*zip.File) (*models.Question, []Issue) {
+	var issues []Issue
+	if item.name != "assessmentItem" {
+		return nil, []Issue{{Message: fmt.Sprintf("%s is not an assessmentItem", href)}}
+	}
+
+	body := item.find("itemBody")
+	if body == nil {
+		return nil, []Issue{{Message: "item has no itemBody"}}
+	}
+
+	var interactions []*xmlNode
+	var collect func(node *xmlNode)
+	collect = func(node *xmlNode) {
+		for _, child := range node.children {
+			if strings.HasSuffix(child.name, "Interaction") {
+				interactions = append(interactions, child)
+				continue
+			}
+			collect(child)
+		}
+	}
+	collect(body)
+
+	switch {
+	case len(interactions) == 0:
+		return nil, []Issue{{Message: "items without interactions are not supported"}}
+	case len(interactions) > 1:
+		return nil, []Issue{{Message: "items with more than one interaction are not supported"}}
+	case interactions[0].name != "choiceInteraction":
+		return nil, []Issue{{Message: fmt.Sprintf("%s is not supported", interactions[0].name)}}
+	}
+	interaction := interactions[0]
+
+	// เฉลยจาก responseDeclaration ที่ตรงกับ interaction
+	correct := make(map[string]bool)
+	for _, declaration := range item.findAll("responseDeclaration") {
+		if declaration.attrs["identifier"] != interaction.attrs["responseIdentifier"] {
+			continue
+		}
+		for _, value := range declaration.findAll("value") {
+			correct[strings.TrimSpace(strings.Join(value.lines(nil), ""))] = true
+		}
+	}
+	if len(correct) == 0 {
+		return nil, []Issue{{Message: "item has no correct response"}}
+	}
+
+	resolveFile := func(ref string) string {
+		if ref == "" || !IsPackagePath(ref) {
+			return ref
+		}
+		resolved := resolveQTIHref(href, ref)
+		if _, ok := files[resolved]; !ok {
+			issues = append(issues, Issue{Message: fmt.Sprintf("referenced file %s not found in package and was omitted", resolved)})
+			return ""
+		}
+		return resolved
+	}
+
+	skipMedia := func(node *xmlNode) bool {
+		return node == interaction || node.name == "img" || node.name == "object"
+	}
+	lines := body.lines(skipMedia)
+	if prompt := interaction.find("prompt"); prompt != nil {
+		lines = append(lines, prompt.lines(nil)...)
+	}
+	question := &models.Question{Text: strings.Join(lines, "\n")}
+
+	// รูปภาพและสื่อนอก interaction เป็นของคำถาม
+	var images, objects []*xmlNode
+	var collectMedia func(node *xmlNode)
+	collectMedia = func(node *xmlNode) {
+		for _, child := range node.children {
+			switch {
+			case child == interaction:
+				if prompt := child.find("prompt"); prompt != nil {
+					collectMedia(prompt)
+				}
+			case child.name == "img":
+				images = append(images, child)
+			case child.name == "object":
+				objects = append(objects, child)
+			default:
+				collectMedia(child)
+			}
+		}
+	}
+	collectMedia(body)
+
+	if len(images) > 0 {
+		question.ImageURL = resolveFile(images[0].attrs["src"])
+		if len(images) > 1 {
+			issues = append(issues, Issue{Message: "only the first question image is supported; other images were omitted"})
+		}
+	}
+	for _, object := range objects {
+		mimeType := object.attrs["type"]
+		if !strings.HasPrefix(mimeType, "audio/") && !strings.HasPrefix(mimeType, "video/") {
+			issues = append(issues, Issue{Message: fmt.Sprintf("embedded object of type %q is not supported and was omitted", mimeType)})
+			continue
+		}
+		if question.MediaURL != "" {
+			issues = append(issues, Issue{Message: "only one audio or video attachment is supported; others were omitted"})
+			continue
+		}
+		question.MediaURL = resolveFile(object.attrs["data"])
+		if question.MediaURL != "" {
+			question.MediaType = strings.SplitN(mimeType, "/", 2)[0]
+		}
+	}
+
+	if question.Text == "" && question.ImageURL == "" {
+		return nil, append(issues, Issue{Message: "question text is empty"})
+	}
+
+	hasCorrect := false
+	for i, simpleChoice := range interaction.findAll("simpleChoice") {
+		choice := models.Choice{
+			Text:      strings.Join(simpleChoice.lines(func(node *xmlNode) bool { return node.name == "img" }), " "),
+			IsCorrect: correct[simpleChoice.attrs["identifier"]],
+			Position:  i + 1,
+		}
+		if img := simpleChoice.find("img"); img != nil {
+			choice.ImageURL = resolveFile(img.attrs["src"])
+		}
+		if choice.Text == "" && choice.ImageURL == "" {
+			return nil, append(issues, Issue{Message: fmt.Sprintf("choice %d is empty", i+1)})
+		}
+		hasCorrect = hasCorrect || choice.IsCorrect
+		question.Choices = append(question.Choices, choice)
+	}
+	if len(question.Choices) == 0 {
+		return nil, append(issues, Issue{Message: "choiceInteraction has no choices"})
+	}
+	if !hasCorrect {
+		return nil, append(issues, Issue{Message: "correct response does not match any choice"})
+	}
+
+	for _, feedback := range item.findAll("modalFeedback") {
+		if text := strings.Join(feedback.lines(nil), "\n"); text != "" {
+			if question.Explanation != "" {
+				question.Explanation += "\n"
+			}
+			question.Explanation += text
+		}
+	}
+
+	return question, issues
+}
+
+// readXMLTree อ่านไฟล์ XML ใน zip เป็นต้นไม้
+func readXMLTree(f *zip.File) (*xmlNode, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	node, err := parseXMLTree(rc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid QTI package: %s: %w", f.Name, err)
+	}
+	return node, nil
+}
+
+// resolveQTIHref แปลง path ที่อ้างอิงจากไฟล์ base เป็น path จาก root ของแพ็กเกจ
+func resolveQTIHref(base, ref string) string {
+	return path.Clean(path.Join(path.Dir(base), ref))
+}
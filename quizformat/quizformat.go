@@ -0,0 +1,128 @@
+// Package quizformat แปลงคำถามระหว่างรูปแบบไฟล์ข้อสอบมาตรฐานกับ model ของระบบ
+package quizformat
+
+import (
+	"fmt"
+	"strings"
+
+	models "github.com/patiphanak/league-of-quiz/model"
+)
+
+// Format รูปแบบไฟล์ข้อสอบแบบข้อความที่รองรับ
+type Format string
+
+const (
+	GIFT  Format = "gift"
+	Aiken Format = "aiken"
+)
+
+// ParseFormat แปลงชื่อรูปแบบไฟล์เป็น Format
+func ParseFormat(name string) (Format, error) {
+	switch Format(strings.ToLower(name)) {
+	case GIFT:
+		return GIFT, nil
+	case Aiken:
+		return Aiken, nil
+	default:
+		return "", fmt.Errorf("unsupported format: %s", name)
+	}
+}
+
+// Issue รายการที่ไม่สามารถแปลงได้ จะถูกรายงานแทนการทิ้งไปเงียบๆ
+// Line ใช้ตอนนำเข้า (บรรทัดในไฟล์) ส่วน Question ใช้ตอนส่งออก (ลำดับคำถามเริ่มจาก 1)
+// Skipped เป็น true เมื่อทั้งคำถามถูกข้าม ถ้าเป็น false คือข้อมูลบางส่วนของคำถามหายไป
+type Issue struct {
+	Line     int    `json:"line,omitempty"`
+	Question int    `json:"question,omitempty"`
+	Message  string `json:"message"`
+	Skipped  bool   `json:"skipped"`
+}
+
+// ParsedQuestion คำถามที่อ่านได้จากไฟล์พร้อมบรรทัดเริ่มต้น
+type ParsedQuestion struct {
+	Line     int
+	Question models.Question
+}
+
+// Parse อ่านคำถามจากเนื้อหาตามรูปแบบที่กำหนด
+func Parse(format Format, content string) ([]ParsedQuestion, []Issue) {
+	switch format {
+	case Aiken:
+		return ParseAiken(content)
+	default:
+		return ParseGIFT(content)
+	}
+}
+
+// Write เขียนคำถามเป็นเนื้อหาตามรูปแบบที่กำหนด
+func Write(format Format, questions []models.Question) (string, []Issue) {
+	switch format {
+	case Aiken:
+		return WriteAiken(questions)
+	default:
+		return WriteGIFT(questions)
+	}
+}
+
+// textBlock กลุ่มบรรทัดที่คั่นด้วยบรรทัดว่าง พร้อมหมายเลขบรรทัดแรก
+type textBlock struct {
+	line  int
+	lines []string
+}
+
+// splitBlocks แบ่งเนื้อหาเป็นกลุ่มบรรทัดที่คั่นด้วยบรรทัดว่าง
+// บรรทัดที่ isComment คืนค่า true จะถูกข้ามไป
+func splitBlocks(content string, isComment func(string) bool) []textBlock {
+	content = strings.TrimPrefix(content, "\ufeff")
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	var blocks []textBlock
+	var current *textBlock
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			current = nil
+			continue
+		}
+		if isComment != nil && isComment(trimmed) {
+			continue
+		}
+		if current == nil {
+			blocks = append(blocks, textBlock{line: i + 1})
+			current = &blocks[len(blocks)-1]
+		}
+		current.lines = append(current.lines, line)
+	}
+	return blocks
+}
+
+// exportIssues รายงานข้อมูลของคำถามที่รูปแบบข้อความเก็บไม่ได้
+func exportIssues(index int, question models.Question, format Format, explanation bool) []Issue {
+	var issues []Issue
+	add := func(message string) {
+		issues = append(issues, Issue{Question: index, Message: message})
+	}
+
+	if question.ImageURL != "" {
+		add(fmt.Sprintf("question image is not supported by %s and was omitted", format))
+	}
+	if question.MediaURL != "" {
+		add(fmt.Sprintf("question %s is not supported by %s and was omitted", question.MediaType, format))
+	}
+	if question.ExplanationImageURL != "" {
+		add(fmt.Sprintf("explanation image is not supported by %s and was omitted", format))
+	}
+	if !explanation && question.Explanation != "" {
+		add(fmt.Sprintf("explanation is not supported by %s and was omitted", format))
+	}
+	if question.TimeLimit > 0 {
+		add(fmt.Sprintf("time limit is not supported by %s and was omitted", format))
+	}
+	for _, choice := range question.Choices {
+		if choice.ImageURL != "" {
+			add(fmt.Sprintf("choice images are not supported by %s and were omitted", format))
+			break
+		}
+	}
+	return issues
+}
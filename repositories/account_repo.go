@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"time"
+
+	models "github.com/patiphanak/league-of-quiz/model"
+	"gorm.io/gorm"
+)
+
+// anonymizedPlayerName ชื่อที่ใช้แทนผู้เล่นที่ลบบัญชีไปแล้วในประวัติเกม
+const anonymizedPlayerName = "Deleted player"
+
+// AccountRepository ดึงข้อมูลทั้งหมดของผู้ใช้สำหรับส่งออก และลบบัญชีแบบไม่ระบุตัวตน
+type AccountRepository struct {
+	db *gorm.DB
+}
+
+// NewAccountRepository สร้าง instance ใหม่ของ AccountRepository
+func NewAccountRepository(db *gorm.DB) *AccountRepository {
+	return &AccountRepository{db: db}
+}
+
+// GetQuizzesByCreator ดึง quiz ทั้งหมดของผู้สร้างพร้อมคำถาม ตัวเลือก และหมวดหมู่
+func (r *AccountRepository) GetQuizzesByCreator(userID uint) ([]models.Quiz, error) {
+	var quizzes []models.Quiz
+	err := r.db.Where("creator_id = ?", userID).
+		Preload("Questions", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC, id ASC") }).
+		Preload("Questions.Choices", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC, id ASC") }).
+		Preload("Categories").
+		Order("id").
+		Find(&quizzes).Error
+	return quizzes, err
+}
+
+// GetBankQuestionsByOwner ดึงคำถามในคลังข้อสอบของผู้ใช้พร้อมตัวเลือกและป้ายกำกับ
+func (r *AccountRepository) GetBankQuestionsByOwner(userID uint) ([]models.BankQuestion, error) {
+	var questions []models.BankQuestion
+	err := r.db.Where("owner_id = ?", userID).
+		Preload("Choices", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC, id ASC") }).
+		Preload("Tags").
+		Order("id").
+		Find(&questions).Error
+	return questions, err
+}
+
+// GetHostedSessions ดึงเกมที่ผู้ใช้เป็นผู้จัด
+func (r *AccountRepository) GetHostedSessions(userID uint) ([]models.GameSession, error) {
+	var sessions []models.GameSession
+	err := r.db.Where("host_id = ?", userID).Order("created_at").Find(&sessions).Error
+	return sessions, err
+}
+
+// GetPlayerRecords ดึงการเข้าร่วมเกมของผู้ใช้พร้อมข้อมูล session
+func (r *AccountRepository) GetPlayerRecords(userID uint) ([]models.GamePlayer, error) {
+	var players []models.GamePlayer
+	err := r.db.Where("user_id = ?", userID).Preload("Session").Order("joined_at").Find(&players).Error
+	return players, err
+}
+
+// GetAnswersByPlayer ดึงคำตอบทั้งหมดของผู้ใช้
+func (r *AccountRepository) GetAnswersByPlayer(userID uint) ([]models.PlayerAnswer, error) {
+	var answers []models.PlayerAnswer
+	err := r.db.Where("player_id = ?", userID).Order("created_at").Find(&answers).Error
+	return answers, err
+}
+
+// GetSoleOwnedOrganizationIDs ดึง ID ขององค์กรที่ผู้ใช้เป็น owner เพียงคนเดียว
+func (r *AccountRepository) GetSoleOwnedOrganizationIDs(userID uint) ([]uint, error) {
+	var orgIDs []uint
+	otherOwners := r.db.Model(&models.OrganizationMember{}).
+		Select("1").
+		Where("organization_members.organization_id = owned.organization_id AND role = ? AND user_id <> ?", models.OrgRoleOwner, userID)
+	err := r.db.Table("organization_members AS owned").
+		Where("owned.user_id = ? AND owned.role = ?", userID, models.OrgRoleOwner).
+		Where("NOT EXISTS (?)", otherOwners).
+		Pluck("owned.organization_id", &orgIDs).Error
+	return orgIDs, err
+}
+
+// AnonymizeUser ลบข้อมูลส่วนตัวของผู้ใช้โดยไม่ลบประวัติเกม
+// แถวของผู้ใช้ยังอยู่ (soft delete) เพื่อให้ GamePlayer และ PlayerAnswer ที่อ้างถึงไม่ถูกลบตาม
+// ส่วนข้อมูลที่ใช้ระบุตัวตนหรือเข้าสู่ระบบได้จะถูกลบทิ้ง
+func (r *AccountRepository) AnonymizeUser(userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.GamePlayer{}).
+			Where("user_id = ?", userID).
+			Update("nickname", anonymizedPlayerName).Error
+		if err != nil {
+			return err
+		}
+
+		for _, model := range []interface{}{
+			&models.UserIdentity{},
+			&models.PersonalAccessToken{},
+			&models.UserToken{},
+			&models.RefreshToken{},
+			&models.Session{},
+			&models.QuizCollaborator{},
+			&models.OrganizationMember{},
+			&models.ClassroomMember{},
+			&models.GameSessionAllowedUser{},
+			&models.GameJoinRequest{},
+		} {
+			if err := tx.Where("user_id = ?", userID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		// ลบคลังข้อสอบเหมือน DeleteBankQuestion: ยกเลิกการเชื่อมโยงป้ายกำกับและคำถามใน quiz ก่อนลบ
+		ownedBankQuestions := func() *gorm.DB {
+			return tx.Model(&models.BankQuestion{}).Select("id").Where("owner_id = ?", userID)
+		}
+		if err := tx.Exec("DELETE FROM bank_question_tags WHERE bank_question_id IN (?)", ownedBankQuestions()).Error; err != nil {
+			return err
+		}
+		err = tx.Model(&models.Question{}).
+			Where("bank_question_id IN (?)", ownedBankQuestions()).
+			Update("bank_question_id", nil).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("bank_question_id IN (?)", ownedBankQuestions()).Delete(&models.BankChoice{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("owner_id = ?", userID).Delete(&models.BankQuestion{}).Error; err != nil {
+			return err
+		}
+
+		// quiz ยังอยู่เพื่อไม่ให้ประวัติเกมของผู้เล่นคนอื่นถูกลบตาม แต่ไม่เผยแพร่และไม่แชร์ในองค์กรอีก
+		err = tx.Model(&models.Quiz{}).
+			Where("creator_id = ?", userID).
+			Updates(map[string]interface{}{
+				"is_published":    false,
+				"organization_id": nil,
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&models.User{}).
+			Where("id = ?", userID).
+			Updates(map[string]interface{}{
+				"email":             "",
+				"display_name":      anonymizedPlayerName,
+				"nickname":          "",
+				"picture_url":       "",
+				"avatar_url":        "",
+				"password_hash":     "",
+				"email_verified_at": nil,
+				"role":              models.UserRoleUser,
+				"disabled_at":       time.Now(),
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Delete(&models.User{}, userID).Error
+	})
+}
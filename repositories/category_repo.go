@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	models "github.com/patiphanak/league-of-quiz/model"
+	"gorm.io/gorm"
+)
+
+// CategoryRepository จัดการข้อมูลหมวดหมู่ของ quiz
+type CategoryRepository struct {
+	db *gorm.DB
+}
+
+// NewCategoryRepository สร้าง instance ใหม่ของ CategoryRepository
+func NewCategoryRepository(db *gorm.DB) *CategoryRepository {
+	return &CategoryRepository{db: db}
+}
+
+// categoryTreeIDs คืนค่า ID ของหมวดหมู่ที่กำหนดรวมกับหมวดหมู่ลูกหลานทั้งหมด
+func categoryTreeIDs(db *gorm.DB, categoryIDs []uint) ([]uint, error) {
+	var ids []uint
+	err := db.Raw(`
+		WITH RECURSIVE category_tree AS (
+			SELECT id FROM categories WHERE id IN ?
+			UNION
+			SELECT categories.id FROM categories
+			JOIN category_tree ON categories.parent_id = category_tree.id
+		)
+		SELECT id FROM category_tree`, categoryIDs).
+		Scan(&ids).Error
+	return ids, err
+}
+
+// GetAllCategories ดึงหมวดหมู่ทั้งหมดเรียงตามชื่อ
+func (r *CategoryRepository) GetAllCategories() ([]models.Category, error) {
+	var categories []models.Category
+	if err := r.db.Order("name ASC").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// GetCategoryByID ดึงหมวดหมู่จาก ID
+func (r *CategoryRepository) GetCategoryByID(id uint) (*models.Category, error) {
+	var category models.Category
+	if err := r.db.First(&category, id).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// GetCategoryBySlug ดึงหมวดหมู่จาก slug
+func (r *CategoryRepository) GetCategoryBySlug(slug string) (*models.Category, error) {
+	var category models.Category
+	if err := r.db.Where("slug = ?", slug).First(&category).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// GetDescendantIDs ดึง ID ของหมวดหมู่ลูกหลานทั้งหมด (ไม่รวมตัวเอง)
+func (r *CategoryRepository) GetDescendantIDs(id uint) ([]uint, error) {
+	ids, err := categoryTreeIDs(r.db, []uint{id})
+	if err != nil {
+		return nil, err
+	}
+	descendants := make([]uint, 0, len(ids))
+	for _, categoryID := range ids {
+		if categoryID != id {
+			descendants = append(descendants, categoryID)
+		}
+	}
+	return descendants, nil
+}
+
+// ExistsByNameOrSlug ตรวจสอบว่ามีหมวดหมู่อื่นใช้ชื่อหรือ slug นี้แล้วหรือไม่
+func (r *CategoryRepository) ExistsByNameOrSlug(name string, slug string, excludeID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Category{}).
+		Where("(LOWER(name) = LOWER(?) OR slug = ?) AND id <> ?", name, slug, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// CreateCategory สร้างหมวดหมู่ใหม่
+func (r *CategoryRepository) CreateCategory(category *models.Category) error {
+	return r.db.Create(category).Error
+}
+
+// UpdateCategory อัปเดตข้อมูลหมวดหมู่ ParentID ที่เป็น nil จะย้ายหมวดหมู่ไปอยู่ระดับบนสุด
+func (r *CategoryRepository) UpdateCategory(category *models.Category) error {
+	return r.db.Model(category).
+		Select("name", "description", "slug", "icon", "parent_id").
+		Updates(category).Error
+}
+
+// DeleteCategory ลบหมวดหมู่ หมวดหมู่ลูกจะย้ายไปอยู่ใต้หมวดหมู่แม่ของหมวดหมู่ที่ถูกลบ
+func (r *CategoryRepository) DeleteCategory(category *models.Category) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Category{}).
+			Where("parent_id = ?", category.ID).
+			Update("parent_id", category.ParentID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("category_id = ?", category.ID).Delete(&models.QuizCategory{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Category{}, category.ID).Error
+	})
+}
@@ -14,6 +14,11 @@ func NewChoiceRepository(db *gorm.DB) *ChoiceRepository {
 	return &ChoiceRepository{db: db}
 }
 
+// WithTx คืนค่า repository ที่ทำงานภายใน transaction ที่กำหนด
+func (r *ChoiceRepository) WithTx(tx *gorm.DB) *ChoiceRepository {
+	return &ChoiceRepository{db: tx}
+}
+
 // CreateChoice สร้างตัวเลือกใหม่
 func (r *ChoiceRepository) CreateChoice(choice *models.Choice) error {
 	return r.db.Create(choice).Error
@@ -32,7 +37,7 @@ func (r *ChoiceRepository) GetChoiceByID(id uint) (*models.Choice, error) {
 // GetChoicesByQuestionID ดึงตัวเลือกทั้งหมดของคำถาม
 func (r *ChoiceRepository) GetChoicesByQuestionID(questionID uint) ([]models.Choice, error) {
 	var choices []models.Choice
-	err := r.db.Where("question_id = ?", questionID).Find(&choices).Error
+	err := r.db.Where("question_id = ?", questionID).Scopes(orderedChoices).Find(&choices).Error
 	if err != nil {
 		return nil, err
 	}
@@ -62,4 +67,19 @@ func (r *ChoiceRepository) GetQuestionIDByChoiceID(choiceID uint) (uint, error)
 		return 0, err
 	}
 	return choice.QuestionID, nil
-}
\ No newline at end of file
+}
+// GetNextChoicePosition หาลำดับถัดไปสำหรับตัวเลือกใหม่ในคำถาม
+func (r *ChoiceRepository) GetNextChoicePosition(questionID uint) (int, error) {
+	var maxPosition *int
+	err := r.db.Model(&models.Choice{}).
+		Where("question_id = ?", questionID).
+		Select("MAX(position)").
+		Scan(&maxPosition).Error
+	if err != nil {
+		return 0, err
+	}
+	if maxPosition == nil {
+		return 1, nil
+	}
+	return *maxPosition + 1, nil
+}
@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	models "github.com/patiphanak/league-of-quiz/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ClassroomRepository จัดการข้อมูลห้องเรียน รายชื่อนักเรียน และเกมของห้องเรียน
+type ClassroomRepository struct {
+	db *gorm.DB
+}
+
+// NewClassroomRepository สร้าง instance ใหม่ของ ClassroomRepository
+func NewClassroomRepository(db *gorm.DB) *ClassroomRepository {
+	return &ClassroomRepository{db: db}
+}
+
+// CreateClassroom สร้างห้องเรียนใหม่
+func (r *ClassroomRepository) CreateClassroom(classroom *models.Classroom) error {
+	return r.db.Create(classroom).Error
+}
+
+// GetClassroomByID ดึงข้อมูลห้องเรียนจาก ID
+func (r *ClassroomRepository) GetClassroomByID(id uint) (*models.Classroom, error) {
+	var classroom models.Classroom
+	if err := r.db.First(&classroom, id).Error; err != nil {
+		return nil, err
+	}
+	return &classroom, nil
+}
+
+// GetClassroomsByOrganization ดึงห้องเรียนทั้งหมดขององค์กร
+func (r *ClassroomRepository) GetClassroomsByOrganization(orgID uint) ([]models.Classroom, error) {
+	var classrooms []models.Classroom
+	err := r.db.Where("organization_id = ?", orgID).Order("name ASC").Find(&classrooms).Error
+	return classrooms, err
+}
+
+// GetClassroomsForUser ดึงห้องเรียนที่ผู้ใช้เป็นครูประจำห้องหรืออยู่ในรายชื่อ
+func (r *ClassroomRepository) GetClassroomsForUser(userID uint) ([]models.Classroom, error) {
+	var classrooms []models.Classroom
+	rostered := r.db.Model(&models.ClassroomMember{}).Select("classroom_id").Where("user_id = ?", userID)
+	err := r.db.Where("teacher_id = ? OR id IN (?)", userID, rostered).
+		Order("name ASC").
+		Find(&classrooms).Error
+	return classrooms, err
+}
+
+// UpdateClassroom แก้ไขข้อมูลห้องเรียน
+func (r *ClassroomRepository) UpdateClassroom(id uint, updates map[string]interface{}) error {
+	return r.db.Model(&models.Classroom{ID: id}).Updates(updates).Error
+}
+
+// DeleteClassroom ลบห้องเรียนและรายชื่อ เกมที่เคยผูกกับห้องเรียนยังอยู่แต่ไม่จำกัดผู้เข้าร่วมอีกต่อไป
+func (r *ClassroomRepository) DeleteClassroom(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("classroom_id = ?", id).Delete(&models.ClassroomMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.GameSession{}).Where("classroom_id = ?", id).Update("classroom_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Classroom{}, id).Error
+	})
+}
+
+// GetRoster ดึงรายชื่อนักเรียนของห้องเรียนพร้อมข้อมูลผู้ใช้
+func (r *ClassroomRepository) GetRoster(classroomID uint) ([]models.ClassroomMember, error) {
+	var members []models.ClassroomMember
+	err := r.db.Preload("User").
+		Where("classroom_id = ?", classroomID).
+		Order("created_at ASC").
+		Find(&members).Error
+	return members, err
+}
+
+// AddRosterMember เพิ่มผู้ใช้เข้ารายชื่อ ถ้ามีอยู่แล้วจะไม่ทำอะไร
+func (r *ClassroomRepository) AddRosterMember(classroomID uint, userID uint) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.ClassroomMember{
+		ClassroomID: classroomID,
+		UserID:      userID,
+	}).Error
+}
+
+// RemoveRosterMember ลบผู้ใช้ออกจากรายชื่อ คืนค่า gorm.ErrRecordNotFound ถ้าไม่พบ
+func (r *ClassroomRepository) RemoveRosterMember(classroomID uint, userID uint) error {
+	result := r.db.Where("classroom_id = ? AND user_id = ?", classroomID, userID).
+		Delete(&models.ClassroomMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// IsRosterMember ตรวจสอบว่าผู้ใช้อยู่ในรายชื่อของห้องเรียนหรือไม่
+func (r *ClassroomRepository) IsRosterMember(classroomID uint, userID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.ClassroomMember{}).
+		Where("classroom_id = ? AND user_id = ?", classroomID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetClassroomSessions ดึงเกมทั้งหมดที่ผูกกับห้องเรียน พร้อม quiz และผู้เล่น
+func (r *ClassroomRepository) GetClassroomSessions(classroomID uint) ([]models.GameSession, error) {
+	var sessions []models.GameSession
+	err := r.db.Preload("Quiz").
+		Preload("Players", func(db *gorm.DB) *gorm.DB { return db.Order("score DESC, joined_at ASC") }).
+		Where("classroom_id = ?", classroomID).
+		Order("created_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
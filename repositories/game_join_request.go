@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"time"
+
+	models "github.com/patiphanak/league-of-quiz/model"
+	"gorm.io/gorm"
+)
+
+// GameJoinRequestRepository handles database operations for join requests waiting for host approval
+type GameJoinRequestRepository struct {
+	db *gorm.DB
+}
+
+// NewGameJoinRequestRepository creates a new join request repository
+func NewGameJoinRequestRepository(db *gorm.DB) *GameJoinRequestRepository {
+	return &GameJoinRequestRepository{
+		db: db,
+	}
+}
+
+// CreateJoinRequest creates a new join request
+func (r *GameJoinRequestRepository) CreateJoinRequest(request *models.GameJoinRequest) error {
+	return r.db.Create(request).Error
+}
+
+// GetJoinRequest gets the join request of a user in a session
+func (r *GameJoinRequestRepository) GetJoinRequest(sessionID string, userID uint) (*models.GameJoinRequest, error) {
+	var request models.GameJoinRequest
+	err := r.db.Where("session_id = ? AND user_id = ?", sessionID, userID).First(&request).Error
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// GetPendingJoinRequests gets all join requests of a session that are still waiting, oldest first
+func (r *GameJoinRequestRepository) GetPendingJoinRequests(sessionID string) ([]models.GameJoinRequest, error) {
+	var requests []models.GameJoinRequest
+	err := r.db.Where("session_id = ? AND status = ?", sessionID, models.JoinRequestPending).
+		Order("created_at ASC").
+		Find(&requests).Error
+	return requests, err
+}
+
+// DecideJoinRequest marks a pending join request as approved or denied
+// Returns gorm.ErrRecordNotFound when there is no pending request to decide
+func (r *GameJoinRequestRepository) DecideJoinRequest(sessionID string, userID uint, status string) error {
+	result := r.db.Model(&models.GameJoinRequest{}).
+		Where("session_id = ? AND user_id = ? AND status = ?", sessionID, userID, models.JoinRequestPending).
+		Updates(map[string]interface{}{"status": status, "decided_at": time.Now()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -52,3 +52,12 @@ func (r *GamePlayerRepository) GetPlayersBySessionID(sessionID string) ([]models
 	err := r.db.Where("session_id = ?", sessionID).Find(&players).Error
 	return players, err
 }
+
+// CountPlayersExcludingUser counts the players in a session, leaving out one user (usually the host)
+func (r *GamePlayerRepository) CountPlayersExcludingUser(sessionID string, userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.GamePlayer{}).
+		Where("session_id = ? AND user_id <> ?", sessionID, userID).
+		Count(&count).Error
+	return count, err
+}
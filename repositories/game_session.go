@@ -55,3 +55,52 @@ func (r *GameSessionRepository) GetActiveGameSessions() ([]models.GameSession, e
 	err := r.db.Where("status = ?", "lobby").Preload("Quiz").Preload("Host").Find(&sessions).Error
 	return sessions, err
 }
+
+// UpdateSessionAccess updates the join restrictions and lobby settings of a game session
+func (r *GameSessionRepository) UpdateSessionAccess(id string, updates map[string]interface{}) error {
+	return r.db.Model(&models.GameSession{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// ReplaceAllowedUsers replaces the allow-list of a game session
+func (r *GameSessionRepository) ReplaceAllowedUsers(sessionID string, userIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("session_id = ?", sessionID).Delete(&models.GameSessionAllowedUser{}).Error; err != nil {
+			return err
+		}
+		if len(userIDs) == 0 {
+			return nil
+		}
+
+		allowed := make([]models.GameSessionAllowedUser, 0, len(userIDs))
+		for _, userID := range userIDs {
+			allowed = append(allowed, models.GameSessionAllowedUser{SessionID: sessionID, UserID: userID})
+		}
+		return tx.Create(&allowed).Error
+	})
+}
+
+// GetAllowedUserIDs gets the user IDs on the allow-list of a game session
+func (r *GameSessionRepository) GetAllowedUserIDs(sessionID string) ([]uint, error) {
+	var userIDs []uint
+	err := r.db.Model(&models.GameSessionAllowedUser{}).
+		Where("session_id = ?", sessionID).
+		Order("user_id").
+		Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
+// CountAllowedUsers counts the users on the allow-list of a game session
+func (r *GameSessionRepository) CountAllowedUsers(sessionID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.GameSessionAllowedUser{}).Where("session_id = ?", sessionID).Count(&count).Error
+	return count, err
+}
+
+// IsAllowedUser checks whether a user is on the allow-list of a game session
+func (r *GameSessionRepository) IsAllowedUser(sessionID string, userID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.GameSessionAllowedUser{}).
+		Where("session_id = ? AND user_id = ?", sessionID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
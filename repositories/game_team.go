@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	models "github.com/patiphanak/league-of-quiz/model"
+	"gorm.io/gorm"
+)
+
+// GameTeamRepository handles database operations for teams in team mode games
+type GameTeamRepository struct {
+	db *gorm.DB
+}
+
+// NewGameTeamRepository creates a new game team repository
+func NewGameTeamRepository(db *gorm.DB) *GameTeamRepository {
+	return &GameTeamRepository{
+		db: db,
+	}
+}
+
+// CreateTeam creates a new team
+func (r *GameTeamRepository) CreateTeam(team *models.GameTeam) error {
+	return r.db.Create(team).Error
+}
+
+// CreateTeams creates one or more teams
+func (r *GameTeamRepository) CreateTeams(teams []models.GameTeam) error {
+	if len(teams) == 0 {
+		return nil
+	}
+	return r.db.Create(&teams).Error
+}
+
+// GetTeamsBySessionID gets all teams of a session in the order they were created
+func (r *GameTeamRepository) GetTeamsBySessionID(sessionID string) ([]models.GameTeam, error) {
+	var teams []models.GameTeam
+	err := r.db.Where("session_id = ?", sessionID).Order("id ASC").Find(&teams).Error
+	return teams, err
+}
+
+// GetTeam gets a team that belongs to a session
+func (r *GameTeamRepository) GetTeam(sessionID string, teamID uint) (*models.GameTeam, error) {
+	var team models.GameTeam
+	err := r.db.Where("session_id = ? AND id = ?", sessionID, teamID).First(&team).Error
+	if err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// DeleteTeam deletes a team and leaves its players without a team
+func (r *GameTeamRepository) DeleteTeam(sessionID string, teamID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.GamePlayer{}).
+			Where("session_id = ? AND team_id = ?", sessionID, teamID).
+			Update("team_id", nil).Error; err != nil {
+			return err
+		}
+
+		result := tx.Where("session_id = ? AND id = ?", sessionID, teamID).Delete(&models.GameTeam{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// SetPlayerTeam moves a player to a team, a nil team leaves the player without a team
+// Returns gorm.ErrRecordNotFound when the user is not a player of the session
+func (r *GameTeamRepository) SetPlayerTeam(sessionID string, userID uint, teamID *uint) error {
+	result := r.db.Model(&models.GamePlayer{}).
+		Where("session_id = ? AND user_id = ?", sessionID, userID).
+		Update("team_id", teamID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ReplaceTeamAssignments clears every team assignment of a session and applies the given user ID -> team ID map
+func (r *GameTeamRepository) ReplaceTeamAssignments(sessionID string, assignments map[uint]uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.GamePlayer{}).
+			Where("session_id = ?", sessionID).
+			Update("team_id", nil).Error; err != nil {
+			return err
+		}
+
+		for userID, teamID := range assignments {
+			if err := tx.Model(&models.GamePlayer{}).
+				Where("session_id = ? AND user_id = ?", sessionID, userID).
+				Update("team_id", teamID).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -14,6 +14,21 @@ type Repositories struct {
 	GameSession  *GameSessionRepository
 	GamePlayer   *GamePlayerRepository
 	PlayerAnswer *PlayerAnswerRepository
+	JoinRequest  *GameJoinRequestRepository
+	GameTeam     *GameTeamRepository
+	QuestionBank *QuestionBankRepository
+	Collaborator *QuizCollaboratorRepository
+	User         *UserRepository
+	Category     *CategoryRepository
+	RefreshToken *RefreshTokenRepository
+	Session      *SessionRepository
+	Identity     *UserIdentityRepository
+	UserToken    *UserTokenRepository
+	AccessToken  *PersonalAccessTokenRepository
+	Account      *AccountRepository
+	Organization *OrganizationRepository
+	Classroom    *ClassroomRepository
+	UploadedFile *UploadedFileRepository
 }
 
 func InitRepositories(db *gorm.DB) *Repositories {
@@ -27,6 +42,21 @@ func InitRepositories(db *gorm.DB) *Repositories {
 		GameSession:  NewGameSessionRepository(db),
 		GamePlayer:   NewGamePlayerRepository(db),
 		PlayerAnswer: NewPlayerAnswerRepository(db),
+		JoinRequest:  NewGameJoinRequestRepository(db),
+		GameTeam:     NewGameTeamRepository(db),
+		QuestionBank: NewQuestionBankRepository(db),
+		Collaborator: NewQuizCollaboratorRepository(db),
+		User:         NewUserRepository(db),
+		Category:     NewCategoryRepository(db),
+		RefreshToken: NewRefreshTokenRepository(db),
+		Session:      NewSessionRepository(db),
+		Identity:     NewUserIdentityRepository(db),
+		UserToken:    NewUserTokenRepository(db),
+		AccessToken:  NewPersonalAccessTokenRepository(db),
+		Account:      NewAccountRepository(db),
+		Organization: NewOrganizationRepository(db),
+		Classroom:    NewClassroomRepository(db),
+		UploadedFile: NewUploadedFileRepository(db),
 	}
 }
 
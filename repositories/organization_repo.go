@@ -0,0 +1,182 @@
+package repositories
+
+import (
+	"errors"
+
+	models "github.com/patiphanak/league-of-quiz/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OrganizationRepository จัดการข้อมูลองค์กรและสมาชิก
+type OrganizationRepository struct {
+	db *gorm.DB
+}
+
+// NewOrganizationRepository สร้าง instance ใหม่ของ OrganizationRepository
+func NewOrganizationRepository(db *gorm.DB) *OrganizationRepository {
+	return &OrganizationRepository{db: db}
+}
+
+// CreateOrganization สร้างองค์กรใหม่ และเพิ่มผู้สร้างเป็น owner ใน transaction เดียวกัน
+func (r *OrganizationRepository) CreateOrganization(org *models.Organization) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(org).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.OrganizationMember{
+			OrganizationID: org.ID,
+			UserID:         org.CreatedByID,
+			Role:           models.OrgRoleOwner,
+		}).Error
+	})
+}
+
+// GetOrganizationByID ดึงข้อมูลองค์กรจาก ID
+func (r *OrganizationRepository) GetOrganizationByID(id uint) (*models.Organization, error) {
+	var org models.Organization
+	if err := r.db.First(&org, id).Error; err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+// SlugExists ตรวจสอบว่ามีองค์กรที่ใช้ slug นี้แล้วหรือไม่
+func (r *OrganizationRepository) SlugExists(slug string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Organization{}).Where("slug = ?", slug).Count(&count).Error
+	return count > 0, err
+}
+
+// UpdateOrganization แก้ไขข้อมูลองค์กร
+func (r *OrganizationRepository) UpdateOrganization(id uint, updates map[string]interface{}) error {
+	return r.db.Model(&models.Organization{ID: id}).Updates(updates).Error
+}
+
+// DeleteOrganization ลบองค์กร สมาชิกและห้องเรียนถูกลบตาม ส่วน quiz ที่แชร์ไว้จะกลับเป็นของผู้สร้างอย่างเดียว
+func (r *OrganizationRepository) DeleteOrganization(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		classrooms := tx.Model(&models.Classroom{}).Select("id").Where("organization_id = ?", id)
+		if err := tx.Where("classroom_id IN (?)", classrooms).Delete(&models.ClassroomMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.GameSession{}).Where("classroom_id IN (?)", classrooms).Update("classroom_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("organization_id = ?", id).Delete(&models.Classroom{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("organization_id = ?", id).Delete(&models.OrganizationMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Quiz{}).Where("organization_id = ?", id).Update("organization_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Organization{}, id).Error
+	})
+}
+
+// GetMembershipsByUserID ดึงองค์กรทั้งหมดที่ผู้ใช้เป็นสมาชิกพร้อมบทบาท
+func (r *OrganizationRepository) GetMembershipsByUserID(userID uint) ([]models.OrganizationMember, error) {
+	var members []models.OrganizationMember
+	err := r.db.Preload("Organization").
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&members).Error
+	return members, err
+}
+
+// GetMemberRole ดึงบทบาทของผู้ใช้ในองค์กร ผู้ดูแลระบบเป็น owner ของทุกองค์กร
+// คืนค่า "" ถ้าไม่ได้เป็นสมาชิก และ gorm.ErrRecordNotFound ถ้าไม่พบองค์กร
+func (r *OrganizationRepository) GetMemberRole(orgID uint, userID uint) (string, error) {
+	var org models.Organization
+	if err := r.db.Select("id").First(&org, orgID).Error; err != nil {
+		return "", err
+	}
+
+	var user models.User
+	err := r.db.Select("id", "role").First(&user, userID).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+	if user.IsAdmin() {
+		return models.OrgRoleOwner, nil
+	}
+
+	var member models.OrganizationMember
+	err = r.db.Where("organization_id = ? AND user_id = ?", orgID, userID).First(&member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return member.Role, nil
+}
+
+// GetMembers ดึงสมาชิกทั้งหมดขององค์กรพร้อมข้อมูลผู้ใช้
+func (r *OrganizationRepository) GetMembers(orgID uint) ([]models.OrganizationMember, error) {
+	var members []models.OrganizationMember
+	err := r.db.Preload("User").
+		Where("organization_id = ?", orgID).
+		Order("created_at ASC").
+		Find(&members).Error
+	return members, err
+}
+
+// GetMember ดึงสมาชิกขององค์กรจาก user ID
+func (r *OrganizationRepository) GetMember(orgID uint, userID uint) (*models.OrganizationMember, error) {
+	var member models.OrganizationMember
+	err := r.db.Preload("User").
+		Where("organization_id = ? AND user_id = ?", orgID, userID).
+		First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// UpsertMember เพิ่มสมาชิก ถ้ามีอยู่แล้วจะอัปเดตบทบาทแทน
+func (r *OrganizationRepository) UpsertMember(member *models.OrganizationMember) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organization_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "updated_at"}),
+	}).Create(member).Error
+}
+
+// EnsureMember เพิ่มผู้ใช้เป็นสมาชิกด้วยบทบาท member ถ้ายังไม่ได้เป็นสมาชิก บทบาทเดิมไม่เปลี่ยน
+func (r *OrganizationRepository) EnsureMember(orgID uint, userID uint) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.OrganizationMember{
+		OrganizationID: orgID,
+		UserID:         userID,
+		Role:           models.OrgRoleMember,
+	}).Error
+}
+
+// CountOwners นับจำนวน owner ขององค์กร
+func (r *OrganizationRepository) CountOwners(orgID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.OrganizationMember{}).
+		Where("organization_id = ? AND role = ?", orgID, models.OrgRoleOwner).
+		Count(&count).Error
+	return count, err
+}
+
+// DeleteMember ลบสมาชิกออกจากองค์กรและจากทุกห้องเรียนขององค์กร
+// คืนค่า gorm.ErrRecordNotFound ถ้าไม่พบ
+func (r *OrganizationRepository) DeleteMember(orgID uint, userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("organization_id = ? AND user_id = ?", orgID, userID).
+			Delete(&models.OrganizationMember{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		classrooms := tx.Model(&models.Classroom{}).Select("id").Where("organization_id = ?", orgID)
+		return tx.Where("user_id = ? AND classroom_id IN (?)", userID, classrooms).
+			Delete(&models.ClassroomMember{}).Error
+	})
+}
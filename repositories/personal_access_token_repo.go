@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"time"
+
+	models "github.com/patiphanak/league-of-quiz/model"
+	"gorm.io/gorm"
+)
+
+// PersonalAccessTokenRepository จัดการ personal access token
+type PersonalAccessTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewPersonalAccessTokenRepository สร้าง instance ใหม่ของ PersonalAccessTokenRepository
+func NewPersonalAccessTokenRepository(db *gorm.DB) *PersonalAccessTokenRepository {
+	return &PersonalAccessTokenRepository{db: db}
+}
+
+// CreateToken บันทึก token ใหม่
+func (r *PersonalAccessTokenRepository) CreateToken(token *models.PersonalAccessToken) error {
+	return r.db.Create(token).Error
+}
+
+// GetTokenByHash ดึง token จาก hash พร้อมข้อมูลผู้ใช้
+func (r *PersonalAccessTokenRepository) GetTokenByHash(hash string) (*models.PersonalAccessToken, error) {
+	var token models.PersonalAccessToken
+	if err := r.db.Preload("User").Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// GetActiveTokensByUserID ดึง token ที่ยังไม่ถูกเพิกถอนของผู้ใช้
+func (r *PersonalAccessTokenRepository) GetActiveTokensByUserID(userID uint) ([]models.PersonalAccessToken, error) {
+	var tokens []models.PersonalAccessToken
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// RevokeToken เพิกถอน token ของผู้ใช้ คืนค่า ErrRecordNotFound ถ้าไม่พบหรือถูกเพิกถอนไปแล้ว
+func (r *PersonalAccessTokenRepository) RevokeToken(id, userID uint) error {
+	result := r.db.Model(&models.PersonalAccessToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// UpdateLastUsed บันทึกเวลาที่ใช้ token ล่าสุด
+func (r *PersonalAccessTokenRepository) UpdateLastUsed(id uint, usedAt time.Time) error {
+	return r.db.Model(&models.PersonalAccessToken{}).
+		Where("id = ?", id).
+		Update("last_used_at", usedAt).Error
+}
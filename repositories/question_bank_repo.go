@@ -0,0 +1,238 @@
+package repositories
+
+import (
+	"strings"
+
+	models "github.com/patiphanak/league-of-quiz/model"
+	"gorm.io/gorm"
+)
+
+// QuestionBankRepository จัดการข้อมูลคลังข้อสอบส่วนตัว
+type QuestionBankRepository struct {
+	db *gorm.DB
+}
+
+// NewQuestionBankRepository สร้าง instance ใหม่ของ QuestionBankRepository
+func NewQuestionBankRepository(db *gorm.DB) *QuestionBankRepository {
+	return &QuestionBankRepository{db: db}
+}
+
+// normalizeTagNames ตัดช่องว่าง แปลงเป็นตัวพิมพ์เล็ก และตัดชื่อซ้ำ
+func normalizeTagNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	var normalized []string
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+	return normalized
+}
+
+// findOrCreateTags ดึง tag ตามชื่อ ถ้ายังไม่มีจะสร้างใหม่
+func findOrCreateTags(tx *gorm.DB, names []string) ([]models.Tag, error) {
+	var tags []models.Tag
+	for _, name := range normalizeTagNames(names) {
+		tag := models.Tag{Name: name}
+		if err := tx.Where("name = ?", name).FirstOrCreate(&tag).Error; err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// bankQuestionsWithTags กรองคำถามที่มี tag ใด tag หนึ่งในรายการ
+func bankQuestionsWithTags(tagNames []string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("bank_questions.id IN (?)", db.Session(&gorm.Session{NewDB: true}).
+			Table("bank_question_tags").
+			Select("bank_question_tags.bank_question_id").
+			Joins("JOIN tags ON tags.id = bank_question_tags.tag_id").
+			Where("tags.name IN ?", tagNames))
+	}
+}
+
+// CreateBankQuestion สร้างคำถามในคลังพร้อมตัวเลือกและ tag
+func (r *QuestionBankRepository) CreateBankQuestion(question *models.BankQuestion, tagNames []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		tags, err := findOrCreateTags(tx, tagNames)
+		if err != nil {
+			return err
+		}
+		question.Tags = tags
+		return tx.Create(question).Error
+	})
+}
+
+// GetBankQuestionByID ดึงคำถามในคลังจาก ID
+func (r *QuestionBankRepository) GetBankQuestionByID(id uint) (*models.BankQuestion, error) {
+	var question models.BankQuestion
+	err := r.db.Preload("Choices", orderedChoices).Preload("Tags").First(&question, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &question, nil
+}
+
+// GetBankQuestionsByIDs ดึงคำถามในคลังหลายข้อของเจ้าของ โดยคงลำดับตาม ids
+func (r *QuestionBankRepository) GetBankQuestionsByIDs(ownerID uint, ids []uint) ([]models.BankQuestion, error) {
+	var questions []models.BankQuestion
+	err := r.db.Where("owner_id = ? AND id IN ?", ownerID, ids).
+		Preload("Choices", orderedChoices).
+		Find(&questions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]models.BankQuestion, len(questions))
+	for _, question := range questions {
+		byID[question.ID] = question
+	}
+	ordered := make([]models.BankQuestion, 0, len(ids))
+	for _, id := range ids {
+		if question, ok := byID[id]; ok {
+			ordered = append(ordered, question)
+		}
+	}
+	return ordered, nil
+}
+
+// SearchBankQuestions ค้นหาคำถามในคลังของผู้ใช้ตามข้อความและ tag
+func (r *QuestionBankRepository) SearchBankQuestions(ownerID uint, search string, tagNames []string, offset, limit int) ([]models.BankQuestion, int64, error) {
+	var questions []models.BankQuestion
+	var count int64
+
+	query := r.db.Model(&models.BankQuestion{}).Where("owner_id = ?", ownerID)
+
+	if search != "" {
+		searchPattern := "%" + search + "%"
+		query = query.Where("text LIKE ? OR explanation LIKE ?", searchPattern, searchPattern)
+	}
+
+	if tags := normalizeTagNames(tagNames); len(tags) > 0 {
+		query = query.Scopes(bankQuestionsWithTags(tags))
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Preload("Choices", orderedChoices).
+		Preload("Tags").
+		Offset(offset).
+		Limit(limit).
+		Order("created_at DESC").
+		Find(&questions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return questions, count, nil
+}
+
+// GetRandomBankQuestions สุ่มคำถามจากคลังของผู้ใช้ที่มี tag ใด tag หนึ่งในรายการ
+func (r *QuestionBankRepository) GetRandomBankQuestions(ownerID uint, tagNames []string, count int) ([]models.BankQuestion, error) {
+	var questions []models.BankQuestion
+
+	query := r.db.Where("owner_id = ?", ownerID)
+	if tags := normalizeTagNames(tagNames); len(tags) > 0 {
+		query = query.Scopes(bankQuestionsWithTags(tags))
+	}
+
+	err := query.
+		Preload("Choices", orderedChoices).
+		Order("RANDOM()").
+		Limit(count).
+		Find(&questions).Error
+	if err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+// UpdateBankQuestion อัปเดตคำถามในคลัง แทนที่ตัวเลือกและ tag ทั้งหมดใน transaction เดียว
+func (r *QuestionBankRepository) UpdateBankQuestion(question *models.BankQuestion, tagNames []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.BankQuestion{ID: question.ID}).Updates(map[string]interface{}{
+			"text":        question.Text,
+			"image_url":   question.ImageURL,
+			"explanation": question.Explanation,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("bank_question_id = ?", question.ID).Delete(&models.BankChoice{}).Error; err != nil {
+			return err
+		}
+		for i := range question.Choices {
+			question.Choices[i].ID = 0
+			question.Choices[i].BankQuestionID = question.ID
+		}
+		if len(question.Choices) > 0 {
+			if err := tx.Create(&question.Choices).Error; err != nil {
+				return err
+			}
+		}
+
+		tags, err := findOrCreateTags(tx, tagNames)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.BankQuestion{ID: question.ID}).Association("Tags").Replace(tags)
+	})
+}
+
+// DeleteBankQuestion ลบคำถามในคลัง (คำถามใน quiz ที่สร้างจากคลังจะยังอยู่)
+func (r *QuestionBankRepository) DeleteBankQuestion(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.BankQuestion{ID: id}).Association("Tags").Clear(); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Question{}).Where("bank_question_id = ?", id).Update("bank_question_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("bank_question_id = ?", id).Delete(&models.BankChoice{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.BankQuestion{}, id).Error
+	})
+}
+
+// CheckBankQuestionOwnership ตรวจสอบว่า user เป็นเจ้าของคำถามในคลังหรือไม่
+func (r *QuestionBankRepository) CheckBankQuestionOwnership(id uint, userID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.BankQuestion{}).
+		Where("id = ? AND owner_id = ?", id, userID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetLinkedQuizIDs ดึง quiz ทั้งหมดที่ใช้คำถามนี้จากคลัง
+func (r *QuestionBankRepository) GetLinkedQuizIDs(id uint) ([]uint, error) {
+	var quizIDs []uint
+	err := r.db.Model(&models.Question{}).
+		Where("bank_question_id = ?", id).
+		Distinct().
+		Pluck("quiz_id", &quizIDs).Error
+	return quizIDs, err
+}
+
+// GetOwnerIDsByFileURL ดึงเจ้าของคำถามในคลังที่อ้างอิงไฟล์ซึ่ง URL ลงท้ายด้วย path
+func (r *QuestionBankRepository) GetOwnerIDsByFileURL(path string) ([]uint, error) {
+	pattern := "%" + path
+	var ownerIDs []uint
+	err := r.db.Model(&models.BankQuestion{}).
+		Where("image_url LIKE ?", pattern).
+		Or("id IN (?)", r.db.Model(&models.BankChoice{}).Select("bank_question_id").Where("image_url LIKE ?", pattern)).
+		Distinct().
+		Pluck("owner_id", &ownerIDs).Error
+	return ownerIDs, err
+}
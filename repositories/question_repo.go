@@ -3,17 +3,33 @@ package repositories
 import (
 	models "github.com/patiphanak/league-of-quiz/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type QuestionRepository struct {
 	db *gorm.DB
 }
 
+// orderedQuestions เรียงคำถามตามลำดับที่ผู้สร้างกำหนด
+func orderedQuestions(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC, id ASC")
+}
+
+// orderedChoices เรียงตัวเลือกตามลำดับที่ผู้สร้างกำหนด
+func orderedChoices(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC, id ASC")
+}
+
 // NewQuestionRepository สร้าง instance ใหม่ของ QuestionRepository
 func NewQuestionRepository(db *gorm.DB) *QuestionRepository {
 	return &QuestionRepository{db: db}
 }
 
+// WithTx คืนค่า repository ที่ทำงานภายใน transaction ที่กำหนด
+func (r *QuestionRepository) WithTx(tx *gorm.DB) *QuestionRepository {
+	return &QuestionRepository{db: tx}
+}
+
 // CreateQuestion สร้างคำถามใหม่
 func (r *QuestionRepository) CreateQuestion(question *models.Question) error {
 	return r.db.Create(question).Error
@@ -22,7 +38,7 @@ func (r *QuestionRepository) CreateQuestion(question *models.Question) error {
 // GetQuestionByID ดึงข้อมูลคำถามจาก ID
 func (r *QuestionRepository) GetQuestionByID(id uint) (*models.Question, error) {
 	var question models.Question
-	err := r.db.Preload("Choices").First(&question, id).Error
+	err := r.db.Preload("Choices", orderedChoices).First(&question, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -32,13 +48,76 @@ func (r *QuestionRepository) GetQuestionByID(id uint) (*models.Question, error)
 // GetQuestionsByQuizID ดึงคำถามทั้งหมดของ quiz
 func (r *QuestionRepository) GetQuestionsByQuizID(quizID uint) ([]models.Question, error) {
 	var questions []models.Question
-	err := r.db.Where("quiz_id = ?", quizID).Preload("Choices").Find(&questions).Error
+	err := r.db.Where("quiz_id = ?", quizID).
+		Scopes(orderedQuestions).
+		Preload("Choices", orderedChoices).
+		Find(&questions).Error
 	if err != nil {
 		return nil, err
 	}
 	return questions, nil
 }
 
+// CreateQuestionsInQuiz เพิ่มคำถามหลายข้อพร้อมตัวเลือกต่อท้าย quiz ใน transaction เดียว
+func (r *QuestionRepository) CreateQuestionsInQuiz(quizID uint, questions []models.Question) error {
+	if len(questions) == 0 {
+		return nil
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var maxPosition *int
+		if err := tx.Model(&models.Question{}).
+			Where("quiz_id = ?", quizID).
+			Select("MAX(position)").
+			Scan(&maxPosition).Error; err != nil {
+			return err
+		}
+		next := 1
+		if maxPosition != nil {
+			next = *maxPosition + 1
+		}
+
+		for i := range questions {
+			questions[i].QuizID = quizID
+			questions[i].Position = next + i
+		}
+		return tx.Create(&questions).Error
+	})
+}
+
+// GetNextQuestionPosition หาลำดับถัดไปสำหรับคำถามใหม่ใน quiz
+func (r *QuestionRepository) GetNextQuestionPosition(quizID uint) (int, error) {
+	var maxPosition *int
+	err := r.db.Model(&models.Question{}).
+		Where("quiz_id = ?", quizID).
+		Select("MAX(position)").
+		Scan(&maxPosition).Error
+	if err != nil {
+		return 0, err
+	}
+	if maxPosition == nil {
+		return 1, nil
+	}
+	return *maxPosition + 1, nil
+}
+
+// ReorderQuestions กำหนดลำดับคำถามใหม่ทั้งหมดใน transaction เดียว
+func (r *QuestionRepository) ReorderQuestions(quizID uint, questionIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i, questionID := range questionIDs {
+			result := tx.Model(&models.Question{}).
+				Where("id = ? AND quiz_id = ?", questionID, quizID).
+				Update("position", i+1)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return gorm.ErrRecordNotFound
+			}
+		}
+		return nil
+	})
+}
+
 // UpdateQuestion อัปเดตข้อมูลคำถาม
 func (r *QuestionRepository) UpdateQuestion(question *models.Question) error {
 	return r.db.Model(question).Updates(question).Error
@@ -62,4 +141,73 @@ func (r *QuestionRepository) GetQuizIDByQuestionID(questionID uint) (uint, error
 		return 0, err
 	}
 	return question.QuizID, nil
-}
\ No newline at end of file
+}
+
+// GetQuestionsByBankQuestionID ดึงคำถามใน quiz ทั้งหมดที่ลิงก์กับคำถามในคลัง
+func (r *QuestionRepository) GetQuestionsByBankQuestionID(bankQuestionID uint) ([]models.Question, error) {
+	var questions []models.Question
+	err := r.db.Where("bank_question_id = ?", bankQuestionID).
+		Preload("Choices", orderedChoices).
+		Find(&questions).Error
+	return questions, err
+}
+
+// SyncQuestionFromBank อัปเดตคำถามใน quiz ให้ตรงกับคำถามในคลังใน transaction เดียว
+// ตัวเลือกถูกอัปเดตตามลำดับเพื่อให้ ID เดิมยังอยู่ ตัวเลือกที่เกินจากคลังจะถูกลบ และตัวเลือกที่ขาดจะถูกเพิ่มต่อท้าย
+// คำถามที่มีคำตอบจากเกมที่เล่นไปแล้วจะไม่ถูกแก้ไข เพื่อไม่ให้ผลเกมเดิมเปลี่ยน และคืนค่า synced เป็น false
+func (r *QuestionRepository) SyncQuestionFromBank(questionID uint, updates map[string]interface{}, bankChoices []models.BankChoice) (bool, error) {
+	synced := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// ล็อกแถวของคำถามไว้ เพื่อไม่ให้มีคำตอบใหม่เข้ามาระหว่างตรวจกับแก้ไข
+		var question models.Question
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&question, questionID).Error; err != nil {
+			return err
+		}
+		var answerCount int64
+		if err := tx.Model(&models.PlayerAnswer{}).Where("question_id = ?", questionID).Count(&answerCount).Error; err != nil {
+			return err
+		}
+		if answerCount > 0 {
+			return nil
+		}
+
+		if err := tx.Model(&models.Question{}).Where("id = ?", questionID).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		var choices []models.Choice
+		if err := tx.Where("question_id = ?", questionID).Scopes(orderedChoices).Find(&choices).Error; err != nil {
+			return err
+		}
+
+		for i, bankChoice := range bankChoices {
+			if i < len(choices) {
+				if err := tx.Model(&models.Choice{}).Where("id = ?", choices[i].ID).Updates(map[string]interface{}{
+					"text":       bankChoice.Text,
+					"is_correct": bankChoice.IsCorrect,
+					"position":   i + 1,
+				}).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err := tx.Create(&models.Choice{
+				QuestionID: questionID,
+				Text:       bankChoice.Text,
+				IsCorrect:  bankChoice.IsCorrect,
+				Position:   i + 1,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, surplus := range choices[min(len(bankChoices), len(choices)):] {
+			if err := tx.Delete(&models.Choice{}, surplus.ID).Error; err != nil {
+				return err
+			}
+		}
+		synced = true
+		return nil
+	})
+	return synced, err
+}
@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	models "github.com/patiphanak/league-of-quiz/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// QuizCollaboratorRepository จัดการข้อมูลผู้ร่วมแก้ไข quiz
+type QuizCollaboratorRepository struct {
+	db *gorm.DB
+}
+
+// NewQuizCollaboratorRepository สร้าง instance ใหม่ของ QuizCollaboratorRepository
+func NewQuizCollaboratorRepository(db *gorm.DB) *QuizCollaboratorRepository {
+	return &QuizCollaboratorRepository{db: db}
+}
+
+// GetCollaboratorsByQuizID ดึงผู้ร่วมแก้ไขทั้งหมดของ quiz พร้อมข้อมูลผู้ใช้
+func (r *QuizCollaboratorRepository) GetCollaboratorsByQuizID(quizID uint) ([]models.QuizCollaborator, error) {
+	var collaborators []models.QuizCollaborator
+	err := r.db.Preload("User").
+		Where("quiz_id = ?", quizID).
+		Order("created_at ASC").
+		Find(&collaborators).Error
+	if err != nil {
+		return nil, err
+	}
+	return collaborators, nil
+}
+
+// UpsertCollaborator เพิ่มผู้ร่วมแก้ไข ถ้ามีอยู่แล้วจะอัปเดตบทบาทแทน
+func (r *QuizCollaboratorRepository) UpsertCollaborator(collaborator *models.QuizCollaborator) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "quiz_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "updated_at"}),
+	}).Create(collaborator).Error
+}
+
+// DeleteCollaborator ลบผู้ร่วมแก้ไขออกจาก quiz คืนค่า gorm.ErrRecordNotFound ถ้าไม่พบ
+func (r *QuizCollaboratorRepository) DeleteCollaborator(quizID uint, userID uint) error {
+	result := r.db.Where("quiz_id = ? AND user_id = ?", quizID, userID).
+		Delete(&models.QuizCollaborator{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetUserByEmail ดึงผู้ใช้จากอีเมล (ไม่สนใจตัวพิมพ์เล็กใหญ่)
+func (r *QuizCollaboratorRepository) GetUserByEmail(email string) (*models.User, error) {
+	var user models.User
+	if err := r.db.Where("LOWER(email) = LOWER(?)", email).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetQuizzesSharedWithUser ดึง quiz ที่ผู้ใช้ได้รับเชิญเป็นผู้ร่วมแก้ไข
+func (r *QuizCollaboratorRepository) GetQuizzesSharedWithUser(userID uint, page, limit int) ([]models.Quiz, int64, error) {
+	var quizzes []models.Quiz
+	var count int64
+
+	offset := (page - 1) * limit
+	shared := r.db.Model(&models.QuizCollaborator{}).Select("quiz_id").Where("user_id = ?", userID)
+
+	if err := r.db.Model(&models.Quiz{}).Where("id IN (?)", shared).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := r.db.Where("id IN (?)", shared).
+		Preload("Categories").
+		Offset(offset).
+		Limit(limit).
+		Order("created_at DESC").
+		Find(&quizzes).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return quizzes, count, nil
+}
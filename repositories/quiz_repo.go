@@ -22,6 +22,11 @@ func NewQuizRepository(db *gorm.DB) *QuizRepository {
 	return &QuizRepository{db: db}
 }
 
+// WithTx คืนค่า repository ที่ทำงานภายใน transaction ที่กำหนด
+func (r *QuizRepository) WithTx(tx *gorm.DB) *QuizRepository {
+	return &QuizRepository{db: tx}
+}
+
 // CreateQuiz สร้าง quiz ใหม่
 func (r *QuizRepository) CreateQuiz(quiz *models.Quiz) error {
 	return r.db.Create(quiz).Error
@@ -30,7 +35,10 @@ func (r *QuizRepository) CreateQuiz(quiz *models.Quiz) error {
 // GetQuizByID ดึงข้อมูล quiz จาก ID
 func (r *QuizRepository) GetQuizByID(id uint) (*models.Quiz, error) {
 	var quiz models.Quiz
-	err := r.db.Preload("Questions.Choices").Preload("Categories").First(&quiz, id).Error
+	err := r.db.Preload("Questions", orderedQuestions).
+		Preload("Questions.Choices", orderedChoices).
+		Preload("Categories").
+		First(&quiz, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -61,7 +69,7 @@ func (r *QuizRepository) GetAllQuizzes(page, limit int) ([]models.Quiz, int64, e
 	return quizzes, count, nil
 }
 
-// GetPublishedQuizzes ดึงข้อมูล quizzes ที่เผยแพร่แล้ว
+// GetPublishedQuizzes ดึงข้อมูล quizzes ที่เผยแพร่แล้ว ไม่รวม quiz ที่แชร์ในองค์กร
 func (r *QuizRepository) GetPublishedQuizzes(page, limit int) ([]models.Quiz, int64, error) {
 	var quizzes []models.Quiz
 	var count int64
@@ -69,10 +77,10 @@ func (r *QuizRepository) GetPublishedQuizzes(page, limit int) ([]models.Quiz, in
 	offset := (page - 1) * limit
 
 	// นับจำนวน quizzes ที่เผยแพร่
-	r.db.Model(&models.Quiz{}).Where("is_published = ?", true).Count(&count)
+	r.db.Model(&models.Quiz{}).Where("is_published = ? AND organization_id IS NULL", true).Count(&count)
 
 	// ดึงข้อมูล quizzes
-	err := r.db.Where("is_published = ?", true).
+	err := r.db.Where("is_published = ? AND organization_id IS NULL", true).
 		Preload("Categories").
 		Offset(offset).
 		Limit(limit).
@@ -116,18 +124,59 @@ func (r *QuizRepository) UpdateQuiz(quiz *models.Quiz) error {
 	return r.db.Model(quiz).Updates(quiz).Error
 }
 
-// CheckQuizOwnership ตรวจสอบว่า user เป็นเจ้าของ quiz หรือไม่
-func (r *QuizRepository) CheckQuizOwnership(quizID uint, userID uint) (bool, error) {
-	var count int64
-	err := r.db.Model(&models.Quiz{}).
-		Where("id = ? AND creator_id = ?", quizID, userID).
-		Count(&count).Error
-
-	if err != nil {
+// IsQuizPublic ตรวจสอบว่า quiz เปิดให้ผู้ที่ไม่มีบทบาทอ่านได้หรือไม่
+// คืนค่า gorm.ErrRecordNotFound ถ้าไม่พบ quiz
+func (r *QuizRepository) IsQuizPublic(quizID uint) (bool, error) {
+	var quiz models.Quiz
+	if err := r.db.Select("id", "is_published", "organization_id").First(&quiz, quizID).Error; err != nil {
 		return false, err
 	}
+	return quiz.IsPublic(), nil
+}
+
+// GetQuizRole ดึงบทบาทของ user ใน quiz ผู้สร้างและผู้ดูแลระบบเป็น owner เสมอ
+// สมาชิกขององค์กรที่ quiz ถูกแชร์ให้เป็น viewer ถ้าไม่ได้เป็นผู้ร่วมแก้ไข
+// คืนค่า "" ถ้า user ไม่มีสิทธิ์ใดๆ และ gorm.ErrRecordNotFound ถ้าไม่พบ quiz
+func (r *QuizRepository) GetQuizRole(quizID uint, userID uint) (string, error) {
+	var quiz models.Quiz
+	if err := r.db.Select("id", "creator_id", "organization_id").First(&quiz, quizID).Error; err != nil {
+		return "", err
+	}
+	if quiz.CreatorID == userID {
+		return models.QuizRoleOwner, nil
+	}
+
+	var user models.User
+	err := r.db.Select("id", "role").First(&user, userID).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+	if user.IsAdmin() {
+		return models.QuizRoleOwner, nil
+	}
 
-	return count > 0, nil
+	var collaborator models.QuizCollaborator
+	err = r.db.Where("quiz_id = ? AND user_id = ?", quizID, userID).First(&collaborator).Error
+	if err == nil {
+		return collaborator.Role, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+
+	if quiz.OrganizationID != nil {
+		var members int64
+		err := r.db.Model(&models.OrganizationMember{}).
+			Where("organization_id = ? AND user_id = ?", *quiz.OrganizationID, userID).
+			Count(&members).Error
+		if err != nil {
+			return "", err
+		}
+		if members > 0 {
+			return models.QuizRoleViewer, nil
+		}
+	}
+	return "", nil
 }
 
 // DeleteQuiz ลบ quiz
@@ -205,7 +254,8 @@ func (r *QuizRepository) UpdateQuizCategories(quizID uint, categoryIDs []uint) e
 
 // Modified function in repositories/quiz_repo.go
 
-func (r *QuizRepository) GetFilteredQuizzes(offset, limit int, isPublished string, search string, categories []uint) ([]models.Quiz, int64, error) {
+// organizationID จำกัดผลลัพธ์เฉพาะ quiz ที่แชร์ให้องค์กรนั้น (0 คือเฉพาะ quiz ที่ไม่ได้แชร์ในองค์กรใด)
+func (r *QuizRepository) GetFilteredQuizzes(offset, limit int, isPublished string, search string, categories []uint, organizationID uint) ([]models.Quiz, int64, error) {
 	var quizzes []models.Quiz
 	var count int64
 
@@ -224,6 +274,16 @@ func (r *QuizRepository) GetFilteredQuizzes(offset, limit int, isPublished strin
 		countQuery = countQuery.Where("is_published = ?", false)
 	}
 
+	// จำกัดเฉพาะ quiz ขององค์กร ถ้าไม่ระบุองค์กรจะไม่รวม quiz ที่แชร์ในองค์กร
+	// เพราะรายการทั่วไปเปิดให้ผู้ที่ไม่ได้เป็นสมาชิกดูได้ สมาชิกดูผ่านรายการ quiz ขององค์กรแทน
+	if organizationID != 0 {
+		query = query.Where("organization_id = ?", organizationID)
+		countQuery = countQuery.Where("organization_id = ?", organizationID)
+	} else {
+		query = query.Where("organization_id IS NULL")
+		countQuery = countQuery.Where("organization_id IS NULL")
+	}
+
 	// ค้นหาจากชื่อหรือคำอธิบาย
 	if search != "" {
 		searchPattern := "%" + search + "%"
@@ -231,16 +291,19 @@ func (r *QuizRepository) GetFilteredQuizzes(offset, limit int, isPublished strin
 		countQuery = countQuery.Where("title LIKE ? OR description LIKE ?", searchPattern, searchPattern)
 	}
 
-	// กรองตามหมวดหมู่
+	// กรองตามหมวดหมู่ หมวดหมู่แม่จะรวม quiz ในหมวดหมู่ลูกหลานทั้งหมดด้วย
 	if len(categories) > 0 {
-		// ใช้ joins หรือ subquery เพื่อกรองตามหมวดหมู่
-		query = query.Joins("JOIN quiz_categories ON quizzes.id = quiz_categories.quiz_id").
-			Where("quiz_categories.category_id IN ?", categories).
-			Group("quizzes.id")
-
-		countQuery = countQuery.Joins("JOIN quiz_categories ON quizzes.id = quiz_categories.quiz_id").
-			Where("quiz_categories.category_id IN ?", categories).
-			Group("quizzes.id")
+		categoryIDs, err := categoryTreeIDs(r.db, categories)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		// ใช้ subquery เพื่อไม่ให้ quiz ที่อยู่หลายหมวดหมู่ถูกนับซ้ำ
+		inCategories := r.db.Model(&models.QuizCategory{}).
+			Select("quiz_id").
+			Where("category_id IN ?", categoryIDs)
+		query = query.Where("quizzes.id IN (?)", inCategories)
+		countQuery = countQuery.Where("quizzes.id IN (?)", inCategories)
 	}
 
 	// นับจำนวน quizzes
@@ -251,8 +314,8 @@ func (r *QuizRepository) GetFilteredQuizzes(offset, limit int, isPublished strin
 	// ดึงข้อมูล quizzes with Questions and Choices
 	err := query.
 		Preload("Categories").
-		Preload("Questions").         // Add preloading of Questions
-		Preload("Questions.Choices"). // Add preloading of Choices
+		Preload("Questions", orderedQuestions).       // Add preloading of Questions
+		Preload("Questions.Choices", orderedChoices). // Add preloading of Choices
 		Offset(offset).
 		Limit(limit).
 		Order("created_at DESC").
@@ -265,6 +328,88 @@ func (r *QuizRepository) GetFilteredQuizzes(offset, limit int, isPublished strin
 	return quizzes, count, nil
 }
 
+// SetQuizOrganization แชร์ quiz ให้องค์กร (ส่ง nil เพื่อยกเลิกการแชร์)
+func (r *QuizRepository) SetQuizOrganization(quizID uint, organizationID *uint) error {
+	return r.db.Model(&models.Quiz{ID: quizID}).Update("organization_id", organizationID).Error
+}
+
 func (r *QuizRepository) UpdateQuizWithMap(quizID uint, updates map[string]interface{}) error {
 	return r.db.Model(&models.Quiz{ID: quizID}).Updates(updates).Error
 }
+
+// CreateQuizWithCategories สร้าง quiz พร้อมคำถาม ตัวเลือก และเชื่อมกับหมวดหมู่ที่มีอยู่แล้วใน quiz.Categories
+func (r *QuizRepository) CreateQuizWithCategories(quiz *models.Quiz) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return tx.Omit("Categories.*").Create(quiz).Error
+	})
+}
+
+// CreateQuizWithCategoryNames สร้าง quiz พร้อมคำถาม ตัวเลือก และหมวดหมู่ตามชื่อภายใน transaction เดียว
+// คืนค่าชื่อหมวดหมู่ที่ไม่พบในระบบ
+func (r *QuizRepository) CreateQuizWithCategoryNames(quiz *models.Quiz, categoryNames []string) ([]string, error) {
+	var missing []string
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var categories []models.Category
+		if len(categoryNames) > 0 {
+			if err := tx.Where("name IN ?", categoryNames).Find(&categories).Error; err != nil {
+				return err
+			}
+		}
+
+		found := make(map[string]bool, len(categories))
+		for _, category := range categories {
+			found[category.Name] = true
+		}
+		for _, name := range categoryNames {
+			if !found[name] {
+				missing = append(missing, name)
+			}
+		}
+
+		quiz.Categories = categories
+		return r.WithTx(tx).CreateQuizWithCategories(quiz)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return missing, nil
+}
+
+// GetQuizIDsByFileURL ดึง quiz ทั้งหมดที่อ้างอิงไฟล์ซึ่ง URL ลงท้ายด้วย path
+// รวมรูปภาพของ quiz คำถาม คำอธิบายเฉลย สื่อประกอบ และตัวเลือก
+func (r *QuizRepository) GetQuizIDsByFileURL(path string) ([]uint, error) {
+	pattern := "%" + path
+	seen := make(map[uint]bool)
+	var quizIDs []uint
+	collect := func(query *gorm.DB) error {
+		var ids []uint
+		if err := query.Distinct().Pluck("quiz_id", &ids).Error; err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if !seen[id] {
+				seen[id] = true
+				quizIDs = append(quizIDs, id)
+			}
+		}
+		return nil
+	}
+
+	if err := collect(r.db.Model(&models.Quiz{}).
+		Select("id AS quiz_id").
+		Where("image_url LIKE ?", pattern)); err != nil {
+		return nil, err
+	}
+	if err := collect(r.db.Model(&models.Question{}).
+		Where("image_url LIKE ? OR media_url LIKE ? OR explanation_image_url LIKE ?", pattern, pattern, pattern)); err != nil {
+		return nil, err
+	}
+	if err := collect(r.db.Model(&models.Choice{}).
+		Joins("JOIN questions ON questions.id = choices.question_id").
+		Select("questions.quiz_id").
+		Where("choices.image_url LIKE ?", pattern)); err != nil {
+		return nil, err
+	}
+	return quizIDs, nil
+}
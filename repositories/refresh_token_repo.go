@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"time"
+
+	models "github.com/patiphanak/league-of-quiz/model"
+	"gorm.io/gorm"
+)
+
+// RefreshTokenRepository จัดการข้อมูล refresh token
+type RefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewRefreshTokenRepository สร้าง instance ใหม่ของ RefreshTokenRepository
+func NewRefreshTokenRepository(db *gorm.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+// CreateRefreshToken บันทึก refresh token ใหม่
+func (r *RefreshTokenRepository) CreateRefreshToken(token *models.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+// GetRefreshTokenByHash ดึง refresh token จาก hash
+func (r *RefreshTokenRepository) GetRefreshTokenByHash(hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := r.db.Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RotateRefreshToken เพิกถอน token เดิมและบันทึก token ใหม่ใน family เดียวกันภายใน transaction เดียว
+// คืนค่า gorm.ErrRecordNotFound ถ้า token เดิมถูกเพิกถอนไปก่อนแล้ว (เช่น ถูกใช้พร้อมกันสองครั้ง)
+func (r *RefreshTokenRepository) RotateRefreshToken(old *models.RefreshToken, next *models.RefreshToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", old.ID).
+			Updates(map[string]interface{}{
+				"revoked_at":     time.Now(),
+				"replaced_by_id": next.ID,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// RevokeFamily เพิกถอน refresh token ที่ยังใช้งานได้ทั้งหมดใน family
+func (r *RefreshTokenRepository) RevokeFamily(familyID string) error {
+	return r.db.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAllForUser เพิกถอน refresh token ที่ยังใช้งานได้ทั้งหมดของผู้ใช้
+func (r *RefreshTokenRepository) RevokeAllForUser(userID uint) error {
+	return r.db.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// DeleteExpired ลบ refresh token ที่หมดอายุแล้ว
+func (r *RefreshTokenRepository) DeleteExpired(before time.Time) error {
+	return r.db.Where("expires_at < ?", before).Delete(&models.RefreshToken{}).Error
+}
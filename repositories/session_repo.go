@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"time"
+
+	models "github.com/patiphanak/league-of-quiz/model"
+	"gorm.io/gorm"
+)
+
+// SessionRepository จัดการข้อมูล session การเข้าสู่ระบบ
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+// NewSessionRepository สร้าง instance ใหม่ของ SessionRepository
+func NewSessionRepository(db *gorm.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// CreateSession บันทึก session ใหม่
+func (r *SessionRepository) CreateSession(session *models.Session) error {
+	return r.db.Create(session).Error
+}
+
+// GetSessionByID ดึง session จาก ID
+func (r *SessionRepository) GetSessionByID(id uint) (*models.Session, error) {
+	var session models.Session
+	if err := r.db.First(&session, id).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetSessionByFamilyID ดึง session ของ family ของ refresh token
+func (r *SessionRepository) GetSessionByFamilyID(familyID string) (*models.Session, error) {
+	var session models.Session
+	if err := r.db.Where("family_id = ?", familyID).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetActiveSessionsByUserID ดึง session ที่ยังไม่ถูกเพิกถอนของผู้ใช้ เรียงจากใช้งานล่าสุด
+func (r *SessionRepository) GetActiveSessionsByUserID(userID uint) ([]models.Session, error) {
+	var sessions []models.Session
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// GetActiveSessionIDsByUserID ดึง ID ของ session ที่ยังไม่ถูกเพิกถอนของผู้ใช้
+func (r *SessionRepository) GetActiveSessionIDsByUserID(userID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// TouchSession อัปเดตเวลาใช้งานล่าสุดและ IP ของ session
+func (r *SessionRepository) TouchSession(id uint, ip string, seenAt time.Time) error {
+	return r.db.Model(&models.Session{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"last_seen_at": seenAt, "ip": ip}).Error
+}
+
+// RevokeSession เพิกถอน session
+func (r *SessionRepository) RevokeSession(id uint) error {
+	return r.db.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAllForUser เพิกถอน session ทั้งหมดของผู้ใช้
+func (r *SessionRepository) RevokeAllForUser(userID uint) error {
+	return r.db.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	models "github.com/patiphanak/league-of-quiz/model"
+	"gorm.io/gorm"
+)
+
+// UploadedFileRepository จัดการข้อมูลผู้อัปโหลดไฟล์
+type UploadedFileRepository struct {
+	db *gorm.DB
+}
+
+// NewUploadedFileRepository สร้าง instance ใหม่ของ UploadedFileRepository
+func NewUploadedFileRepository(db *gorm.DB) *UploadedFileRepository {
+	return &UploadedFileRepository{db: db}
+}
+
+// CreateUploadedFile บันทึกผู้อัปโหลดไฟล์
+func (r *UploadedFileRepository) CreateUploadedFile(file *models.UploadedFile) error {
+	return r.db.Create(file).Error
+}
+
+// GetUploaderID ดึง ID ของผู้อัปโหลดไฟล์จาก path คืนค่า ErrRecordNotFound ถ้าไม่มีบันทึก
+func (r *UploadedFileRepository) GetUploaderID(path string) (uint, error) {
+	var file models.UploadedFile
+	if err := r.db.Select("uploader_id").Where("path = ?", path).First(&file).Error; err != nil {
+		return 0, err
+	}
+	return file.UploaderID, nil
+}
+
+// DeleteByPath ลบบันทึกผู้อัปโหลดของไฟล์
+func (r *UploadedFileRepository) DeleteByPath(path string) error {
+	return r.db.Where("path = ?", path).Delete(&models.UploadedFile{}).Error
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	models "github.com/patiphanak/league-of-quiz/model"
+	"gorm.io/gorm"
+)
+
+// UserIdentityRepository จัดการข้อมูลบัญชีภายนอกที่เชื่อมกับผู้ใช้
+type UserIdentityRepository struct {
+	db *gorm.DB
+}
+
+// NewUserIdentityRepository สร้าง instance ใหม่ของ UserIdentityRepository
+func NewUserIdentityRepository(db *gorm.DB) *UserIdentityRepository {
+	return &UserIdentityRepository{db: db}
+}
+
+// GetIdentity ดึง identity จาก provider และ subject พร้อมข้อมูลผู้ใช้
+func (r *UserIdentityRepository) GetIdentity(provider, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := r.db.Preload("User").
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// GetIdentitiesByUserID ดึง identity ทั้งหมดของผู้ใช้
+func (r *UserIdentityRepository) GetIdentitiesByUserID(userID uint) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	if err := r.db.Where("user_id = ?", userID).Order("created_at").Find(&identities).Error; err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+// GetUserByEmail ดึงผู้ใช้จากอีเมล (ไม่สนตัวพิมพ์เล็ก/ใหญ่)
+func (r *UserIdentityRepository) GetUserByEmail(email string) (*models.User, error) {
+	var user models.User
+	if err := r.db.Where("LOWER(email) = LOWER(?)", email).Order("id").First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// CreateIdentity เชื่อม identity กับผู้ใช้ที่มีอยู่แล้ว
+func (r *UserIdentityRepository) CreateIdentity(identity *models.UserIdentity) error {
+	return r.db.Create(identity).Error
+}
+
+// CreateUserWithIdentity สร้างผู้ใช้ใหม่พร้อม identity แรกใน transaction เดียว
+func (r *UserIdentityRepository) CreateUserWithIdentity(user *models.User, identity *models.UserIdentity) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		identity.UserID = user.ID
+		return tx.Create(identity).Error
+	})
+}
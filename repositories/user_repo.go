@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	models "github.com/patiphanak/league-of-quiz/model"
+	"gorm.io/gorm"
+)
+
+// UserRepository จัดการข้อมูลผู้ใช้
+type UserRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRepository สร้าง instance ใหม่ของ UserRepository
+func NewUserRepository(db *gorm.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+// GetUserByID ดึงข้อมูลผู้ใช้จาก ID
+func (r *UserRepository) GetUserByID(id uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ListUsers ค้นหาผู้ใช้จากอีเมลหรือชื่อ และกรองตามบทบาท (ถ้ากำหนด)
+func (r *UserRepository) ListUsers(search string, role string, page, limit int) ([]models.User, int64, error) {
+	var users []models.User
+	var count int64
+
+	query := r.db.Model(&models.User{})
+	if search != "" {
+		pattern := "%" + search + "%"
+		query = query.Where("email ILIKE ? OR display_name ILIKE ?", pattern, pattern)
+	}
+	if role != "" {
+		query = query.Where("role = ?", role)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	err := query.Offset(offset).
+		Limit(limit).
+		Order("created_at DESC").
+		Find(&users).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return users, count, nil
+}
+
+// UpdateUserRole เปลี่ยนบทบาทของผู้ใช้
+func (r *UserRepository) UpdateUserRole(id uint, role string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Update("role", role).Error
+}
+
+// SetUserDisabledAt ระงับบัญชี (ส่งเวลา) หรือยกเลิกการระงับ (ส่ง nil)
+func (r *UserRepository) SetUserDisabledAt(id uint, disabledAt *time.Time) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Update("disabled_at", disabledAt).Error
+}
+
+// GetUserByEmail ดึงผู้ใช้จากอีเมล (ไม่สนตัวพิมพ์เล็ก/ใหญ่)
+func (r *UserRepository) GetUserByEmail(email string) (*models.User, error) {
+	var user models.User
+	if err := r.db.Where("LOWER(email) = LOWER(?)", email).Order("id").First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// IsUniqueViolation ตรวจว่าข้อผิดพลาดเกิดจากการชน unique constraint ของ Postgres
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// CreateUser สร้างผู้ใช้ใหม่
+func (r *UserRepository) CreateUser(user *models.User) error {
+	return r.db.Create(user).Error
+}
+
+// MarkEmailVerified บันทึกว่ายืนยันอีเมลแล้ว ถ้า clearPassword เป็น true จะลบรหัสผ่านเดิมด้วย
+// ใช้เมื่อเจ้าของอีเมลตัวจริงยืนยันผ่าน provider ภายนอก เพื่อไม่ให้รหัสผ่านที่ผู้อื่นตั้งไว้ก่อนยังใช้ได้
+func (r *UserRepository) MarkEmailVerified(id uint, clearPassword bool) error {
+	updates := map[string]interface{}{"email_verified_at": time.Now()}
+	if clearPassword {
+		updates["password_hash"] = ""
+	}
+	return r.db.Model(&models.User{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// UpdateProfile แก้ไขข้อมูลโปรไฟล์ของผู้ใช้ตามฟิลด์ที่ส่งมา
+func (r *UserRepository) UpdateProfile(id uint, updates map[string]interface{}) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// SetAvatarURL เปลี่ยนรูปโปรไฟล์ที่อัปโหลดเอง (ส่งค่าว่างเพื่อลบ)
+func (r *UserRepository) SetAvatarURL(id uint, avatarURL string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Update("avatar_url", avatarURL).Error
+}
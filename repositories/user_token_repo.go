@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"time"
+
+	models "github.com/patiphanak/league-of-quiz/model"
+	"gorm.io/gorm"
+)
+
+// UserTokenRepository จัดการ token ยืนยันอีเมลและรีเซ็ตรหัสผ่าน
+type UserTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewUserTokenRepository สร้าง instance ใหม่ของ UserTokenRepository
+func NewUserTokenRepository(db *gorm.DB) *UserTokenRepository {
+	return &UserTokenRepository{db: db}
+}
+
+// ReplaceToken สร้าง token ใหม่และยกเลิก token เดิมที่ยังไม่ถูกใช้ของผู้ใช้ในจุดประสงค์เดียวกัน
+func (r *UserTokenRepository) ReplaceToken(token *models.UserToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ? AND purpose = ? AND used_at IS NULL", token.UserID, token.Purpose).
+			Delete(&models.UserToken{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+// GetActiveToken ดึง token ที่ยังไม่ถูกใช้และยังไม่หมดอายุ
+func (r *UserTokenRepository) GetActiveToken(hash, purpose string, now time.Time) (*models.UserToken, error) {
+	var token models.UserToken
+	err := r.db.Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", hash, purpose, now).
+		First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// ConsumeToken ทำเครื่องหมายว่า token ถูกใช้แล้ว และรัน fn ใน transaction เดียวกัน
+// คืนค่า ErrRecordNotFound ถ้า token ถูกใช้ไปแล้วโดย request อื่น
+func (r *UserTokenRepository) ConsumeToken(token *models.UserToken, fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.UserToken{}).
+			Where("id = ? AND used_at IS NULL", token.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return fn(tx)
+	})
+}
+
+// DeleteExpired ลบ token ที่หมดอายุแล้ว
+func (r *UserTokenRepository) DeleteExpired(now time.Time) error {
+	return r.db.Where("expires_at < ?", now).Delete(&models.UserToken{}).Error
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/patiphanak/league-of-quiz/handlers"
+	middleware "github.com/patiphanak/league-of-quiz/middlewares"
+)
+
+// SetupAccountRoute ลงทะเบียน routes สำหรับจัดการบัญชีของผู้ใช้เอง
+// ต้องเข้าสู่ระบบด้วย session เท่านั้น personal access token จึงลบหรือส่งออกข้อมูลบัญชีไม่ได้
+func SetupAccountRoute(app *fiber.App, accountHandler *handlers.AccountHandler, authMiddleware *middleware.AuthMiddleware) {
+	account := app.Group("/api/v1/account")
+	requireAuth := authMiddleware.RequireAuth()
+
+	account.Get("/profile", requireAuth, accountHandler.GetProfile)
+	account.Patch("/profile", requireAuth, accountHandler.UpdateProfile)
+	account.Put("/avatar", requireAuth, accountHandler.UpdateAvatar)
+	account.Delete("/avatar", requireAuth, accountHandler.RemoveAvatar)
+	account.Get("/export", requireAuth, accountHandler.ExportData)
+	account.Delete("/", requireAuth, accountHandler.DeleteAccount)
+}
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/patiphanak/league-of-quiz/handlers"
+	middleware "github.com/patiphanak/league-of-quiz/middlewares"
+	models "github.com/patiphanak/league-of-quiz/model"
+)
+
+// SetupAdminRoute ลงทะเบียน routes สำหรับผู้ดูแลระบบ
+func SetupAdminRoute(app *fiber.App, adminHandler *handlers.AdminHandler, authMiddleware *middleware.AuthMiddleware) {
+	apiV1 := app.Group("/api/v1")
+
+	// ทุกเส้นทางต้องล็อกอินและมีบทบาท admin
+	admin := apiV1.Group("/admin", authMiddleware.RequireAuth(), authMiddleware.RequireRole(models.UserRoleAdmin))
+
+	// จัดการบัญชีผู้ใช้
+	admin.Get("/users", adminHandler.ListUsers)
+	admin.Patch("/users/:id/role", adminHandler.UpdateUserRole)
+	admin.Post("/users/:id/disable", adminHandler.DisableUser)
+	admin.Post("/users/:id/enable", adminHandler.EnableUser)
+
+	// ดูแลเนื้อหา
+	admin.Post("/quizzes/:id/unpublish", adminHandler.UnpublishQuiz)
+	admin.Delete("/quizzes/:id", adminHandler.DeleteQuiz)
+}
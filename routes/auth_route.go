@@ -6,7 +6,6 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/patiphanak/league-of-quiz/handlers"
 	middleware "github.com/patiphanak/league-of-quiz/middlewares"
-	models "github.com/patiphanak/league-of-quiz/model"
 )
 
 func SetupAuthRoute(app *fiber.App, authHandler *handlers.AuthHandler, authMiddleware *middleware.AuthMiddleware) {
@@ -16,27 +15,13 @@ func SetupAuthRoute(app *fiber.App, authHandler *handlers.AuthHandler, authMiddl
 	apiV1 := app.Group("/api/v1")
 
 	auth := app.Group("/auth")
-	auth.Get("/google", authHandler.GoogleLogin)
-	auth.Get("/google/callback", authHandler.GoogleCallback)
+	auth.Get("/:provider/login", authHandler.Login)
+	auth.Get("/:provider/callback", authHandler.Callback)
+	auth.Post("/refresh", authHandler.Refresh)
 	auth.Post("/logout", authHandler.Logout)
 
 	authProtected := apiV1.Group("/auth", authMiddleware.RequireAuth())
-	authProtected.Get("/me", func(c *fiber.Ctx) error {
-		userLocal := c.Locals("user")
-		user, ok := userLocal.(models.User)
-		if !ok {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Unauthorized - invalid user format",
-			})
-		}
-	
-    return c.JSON(fiber.Map{
-        "user": fiber.Map{
-            "id":      user.ID,
-            "email":   user.Email,
-            "name":    user.DisplayName,
-            "picture": user.PictureURL,
-        },
-    })
-	})
+	authProtected.Get("/sessions", authHandler.GetSessions)
+	authProtected.Delete("/sessions/:id", authHandler.RevokeSession)
+	authProtected.Get("/me", authHandler.GetCurrentUser)
 }
\ No newline at end of file
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/patiphanak/league-of-quiz/handlers"
+	middleware "github.com/patiphanak/league-of-quiz/middlewares"
+	models "github.com/patiphanak/league-of-quiz/model"
+)
+
+// SetupCategoryRoute ลงทะเบียน routes สำหรับหมวดหมู่
+func SetupCategoryRoute(app *fiber.App, categoryHandler *handlers.CategoryHandler, authMiddleware *middleware.AuthMiddleware) {
+	apiV1 := app.Group("/api/v1")
+	categories := apiV1.Group("/categories")
+
+	// Routes that don't require auth
+	categories.Get("/", categoryHandler.GetCategories)
+	categories.Get("/:id", categoryHandler.GetCategory)
+
+	// จัดการหมวดหมู่ได้เฉพาะ admin
+	requireAuth := authMiddleware.RequireAuth()
+	requireAdmin := authMiddleware.RequireRole(models.UserRoleAdmin)
+	categories.Post("/", requireAuth, requireAdmin, categoryHandler.CreateCategory)
+	categories.Put("/:id", requireAuth, requireAdmin, categoryHandler.UpdateCategory)
+	categories.Delete("/:id", requireAuth, requireAdmin, categoryHandler.DeleteCategory)
+}
@@ -1 +1,28 @@
-package routes
\ No newline at end of file
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/patiphanak/league-of-quiz/handlers"
+	middleware "github.com/patiphanak/league-of-quiz/middlewares"
+	models "github.com/patiphanak/league-of-quiz/model"
+)
+
+// SetupChoiceRoute ลงทะเบียน routes สำหรับตัวเลือกของคำถาม
+func SetupChoiceRoute(app *fiber.App, choiceHandler *handlers.ChoiceHandler, authMiddleware *middleware.AuthMiddleware, policy *middleware.Policy) {
+	apiV1 := app.Group("/api/v1")
+
+	// Routes that don't require auth for published quizzes, other quizzes need viewer access
+	canRead := authMiddleware.OptionalScope(models.ScopeQuizzesRead)
+	apiV1.Get("/questions/:questionId/choices", canRead, policy.RequireQuizRead(policy.QuestionParam("questionId")), choiceHandler.GetChoicesByQuestionID)
+	apiV1.Get("/choices/:id", canRead, policy.RequireQuizRead(policy.ChoiceParam("id")), choiceHandler.GetChoiceByID)
+
+	// Routes that require auth (or a quizzes:write token) and editor access to the quiz of the choice
+	canWrite := authMiddleware.RequireScope(models.ScopeQuizzesWrite)
+	canEditChoice := policy.RequireQuizRole(models.QuizRoleEditor, policy.ChoiceParam("id"))
+
+	// คำถามของตัวเลือกใหม่มาจาก request body service จึงเป็นผู้ตรวจสิทธิ์
+	apiV1.Post("/choices", canWrite, choiceHandler.CreateChoice)
+	apiV1.Patch("/choices/:id", canWrite, canEditChoice, choiceHandler.UpdateChoice)
+	apiV1.Delete("/choices/:id", canWrite, canEditChoice, choiceHandler.DeleteChoice)
+	apiV1.Post("/choices/:id/image", canWrite, canEditChoice, choiceHandler.UploadChoiceImage)
+}
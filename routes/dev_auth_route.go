@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/patiphanak/league-of-quiz/handlers"
+)
+
+// SetupDevAuthRoute ลงทะเบียนหน้าเข้าสู่ระบบของ provider "dev" เมื่อเปิดใช้ใน config เท่านั้น
+func SetupDevAuthRoute(app *fiber.App, devAuthHandler *handlers.DevAuthHandler) {
+	if devAuthHandler == nil {
+		return
+	}
+
+	auth := app.Group("/auth")
+	auth.Get("/dev/authorize", devAuthHandler.AuthorizePage)
+	auth.Post("/dev/authorize", devAuthHandler.Authorize)
+}
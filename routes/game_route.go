@@ -4,6 +4,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/patiphanak/league-of-quiz/handlers"
 	middleware "github.com/patiphanak/league-of-quiz/middlewares"
+	models "github.com/patiphanak/league-of-quiz/model"
 )
 
 // SetupGameRoute ลงทะเบียน routes สำหรับเกม
@@ -12,19 +13,37 @@ func SetupGameRoute(app *fiber.App, gameHandler *handlers.GameHandler, authMiddl
 	apiV1 := app.Group("/api/v1")
 
 	// เส้นทางที่ต้องการการยืนยันตัวตน
-	gameAPI := apiV1.Group("/games", authMiddleware.RequireAuth())
+	// ฝั่งผู้จัดเกมใช้ personal access token ที่มี scope games:host ได้ ส่วนการเล่นต้องเข้าสู่ระบบ
+	requireAuth := authMiddleware.RequireAuth()
+	canHost := authMiddleware.RequireScope(models.ScopeGamesHost)
+	gameAPI := apiV1.Group("/games")
 
 	// จัดการ session
-	gameAPI.Post("/sessions", gameHandler.CreateGameSession)
-	gameAPI.Get("/sessions", gameHandler.GetGameSessions)
-	gameAPI.Get("/sessions/:id", gameHandler.GetGameSessionDetail)
-	gameAPI.Post("/sessions/:id/join", gameHandler.JoinGameSession)
-	gameAPI.Post("/sessions/:id/start", gameHandler.StartGameSession)
-	gameAPI.Post("/sessions/:id/end", gameHandler.EndGameSession)
+	gameAPI.Post("/sessions", canHost, gameHandler.CreateGameSession)
+	gameAPI.Get("/sessions", canHost, gameHandler.GetGameSessions)
+	gameAPI.Get("/sessions/:id", canHost, gameHandler.GetGameSessionDetail)
+	gameAPI.Post("/sessions/:id/join", requireAuth, gameHandler.JoinGameSession)
+	gameAPI.Post("/sessions/:id/start", canHost, gameHandler.StartGameSession)
+	gameAPI.Post("/sessions/:id/end", canHost, gameHandler.EndGameSession)
+
+	// จำกัดการเข้าร่วมและคำขอเข้าร่วมที่รอโฮสต์อนุมัติ
+	gameAPI.Get("/sessions/:id/access", canHost, gameHandler.GetSessionAccess)
+	gameAPI.Patch("/sessions/:id/access", canHost, gameHandler.UpdateSessionAccess)
+	gameAPI.Post("/sessions/:id/lock", canHost, gameHandler.LockLobby)
+	gameAPI.Post("/sessions/:id/unlock", canHost, gameHandler.UnlockLobby)
+	gameAPI.Get("/sessions/:id/join-requests", canHost, gameHandler.GetJoinRequests)
+	gameAPI.Post("/sessions/:id/join-requests/:userId/approve", canHost, gameHandler.ApproveJoinRequest)
+	gameAPI.Post("/sessions/:id/join-requests/:userId/deny", canHost, gameHandler.DenyJoinRequest)
+
+	// โหมดทีม การสร้างทีมและจัดผู้เล่นลงทีมทำผ่าน WebSocket
+	gameAPI.Patch("/sessions/:id/team-settings", canHost, gameHandler.UpdateTeamSettings)
+	gameAPI.Get("/sessions/:id/teams", requireAuth, gameHandler.GetTeams)
+	gameAPI.Get("/sessions/:id/teams/leaderboard", requireAuth, gameHandler.GetTeamLeaderboard)
 
 	// จัดการคำตอบ
-	gameAPI.Post("/sessions/:id/answers", gameHandler.SubmitAnswer)
+	gameAPI.Post("/sessions/:id/answers", requireAuth, gameHandler.SubmitAnswer)
 
 	// ดูผลลัพธ์
-	gameAPI.Get("/sessions/:id/results", gameHandler.GetGameResults)
+	gameAPI.Get("/sessions/:id/results", canHost, gameHandler.GetGameResults)
+	gameAPI.Get("/sessions/:id/review", requireAuth, gameHandler.GetGameReview)
 }
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/patiphanak/league-of-quiz/handlers"
+)
+
+// SetupLocalAuthRoute ลงทะเบียน routes สำหรับบัญชีแบบอีเมลและรหัสผ่าน
+func SetupLocalAuthRoute(app *fiber.App, localAuthHandler *handlers.LocalAuthHandler) {
+	auth := app.Group("/auth")
+
+	auth.Post("/register", localAuthHandler.Register)
+	auth.Post("/login", localAuthHandler.Login)
+	auth.Post("/verify-email", localAuthHandler.VerifyEmail)
+	auth.Post("/verify-email/resend", localAuthHandler.ResendVerification)
+	auth.Post("/password/forgot", localAuthHandler.ForgotPassword)
+	auth.Post("/password/reset", localAuthHandler.ResetPassword)
+}
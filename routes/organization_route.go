@@ -0,0 +1,43 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/patiphanak/league-of-quiz/handlers"
+	middleware "github.com/patiphanak/league-of-quiz/middlewares"
+	models "github.com/patiphanak/league-of-quiz/model"
+)
+
+// SetupOrganizationRoute ลงทะเบียน routes สำหรับองค์กร ห้องเรียน และการแชร์ quiz ในองค์กร
+// บทบาทในองค์กรและห้องเรียนถูกตรวจใน service เพราะขึ้นอยู่กับทรัพยากรที่อ้างถึง
+func SetupOrganizationRoute(app *fiber.App, orgHandler *handlers.OrganizationHandler, classroomHandler *handlers.ClassroomHandler, authMiddleware *middleware.AuthMiddleware) {
+	apiV1 := app.Group("/api/v1")
+	requireAuth := authMiddleware.RequireAuth()
+	// สร้างองค์กรได้เฉพาะครูและผู้ดูแลระบบ
+	requireTeacher := authMiddleware.RequireRole(models.UserRoleTeacher, models.UserRoleAdmin)
+
+	orgs := apiV1.Group("/organizations")
+	orgs.Get("/", requireAuth, orgHandler.GetMyOrganizations)
+	orgs.Post("/", requireAuth, requireTeacher, orgHandler.CreateOrganization)
+	orgs.Get("/:id", requireAuth, orgHandler.GetOrganization)
+	orgs.Patch("/:id", requireAuth, orgHandler.UpdateOrganization)
+	orgs.Delete("/:id", requireAuth, orgHandler.DeleteOrganization)
+	orgs.Get("/:id/members", requireAuth, orgHandler.GetMembers)
+	orgs.Post("/:id/members", requireAuth, orgHandler.AddMember)
+	orgs.Delete("/:id/members/:userId", requireAuth, orgHandler.RemoveMember)
+	orgs.Get("/:id/quizzes", requireAuth, orgHandler.GetQuizzes)
+	orgs.Get("/:id/classrooms", requireAuth, classroomHandler.GetOrganizationClassrooms)
+	orgs.Post("/:id/classrooms", requireAuth, classroomHandler.CreateClassroom)
+
+	// แชร์ quiz ให้องค์กร ต้องเป็น owner ของ quiz และเป็น teacher ขึ้นไปในองค์กร
+	apiV1.Put("/quizzes/:id/organization", requireAuth, orgHandler.ShareQuiz)
+
+	classrooms := apiV1.Group("/classrooms")
+	classrooms.Get("/my", requireAuth, classroomHandler.GetMyClassrooms)
+	classrooms.Get("/:id", requireAuth, classroomHandler.GetClassroom)
+	classrooms.Patch("/:id", requireAuth, classroomHandler.UpdateClassroom)
+	classrooms.Delete("/:id", requireAuth, classroomHandler.DeleteClassroom)
+	classrooms.Get("/:id/members", requireAuth, classroomHandler.GetRoster)
+	classrooms.Post("/:id/members", requireAuth, classroomHandler.AddRosterMember)
+	classrooms.Delete("/:id/members/:userId", requireAuth, classroomHandler.RemoveRosterMember)
+	classrooms.Get("/:id/results", requireAuth, classroomHandler.GetResults)
+}
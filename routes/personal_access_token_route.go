@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/patiphanak/league-of-quiz/handlers"
+	middleware "github.com/patiphanak/league-of-quiz/middlewares"
+)
+
+// SetupPersonalAccessTokenRoute ลงทะเบียน routes สำหรับจัดการ personal access token
+// จัดการได้เฉพาะจาก session การเข้าสู่ระบบ token จึงสร้าง token ใหม่เองไม่ได้
+func SetupPersonalAccessTokenRoute(app *fiber.App, tokenHandler *handlers.PersonalAccessTokenHandler, authMiddleware *middleware.AuthMiddleware) {
+	apiV1 := app.Group("/api/v1")
+	requireAuth := authMiddleware.RequireAuth()
+
+	apiV1.Get("/auth/tokens", requireAuth, tokenHandler.ListTokens)
+	apiV1.Post("/auth/tokens", requireAuth, tokenHandler.CreateToken)
+	apiV1.Delete("/auth/tokens/:id", requireAuth, tokenHandler.RevokeToken)
+}
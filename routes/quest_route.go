@@ -4,21 +4,28 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/patiphanak/league-of-quiz/handlers"
 	middleware "github.com/patiphanak/league-of-quiz/middlewares"
+	models "github.com/patiphanak/league-of-quiz/model"
 )
 
-func SetupQuestionRoute(app *fiber.App, questionHandler *handlers.QuestionHandler, authMiddleware *middleware.AuthMiddleware) {
+func SetupQuestionRoute(app *fiber.App, questionHandler *handlers.QuestionHandler, authMiddleware *middleware.AuthMiddleware, policy *middleware.Policy) {
 	apiV1 := app.Group("/api/v1")
 	quizzes := apiV1.Group("/quizzes")
 
-	// Routes that don't require auth
-	quizzes.Get("/:quizId/questions", questionHandler.GetQuestionsByQuizID)
-	quizzes.Get("/:quizId/questions/:id", questionHandler.GetQuestionByID)
+	// Routes that don't require auth for published quizzes, other quizzes need viewer access
+	canRead := authMiddleware.OptionalScope(models.ScopeQuizzesRead)
+	quizzes.Get("/:quizId/questions", canRead, policy.RequireQuizRead(policy.QuizParam("quizId")), questionHandler.GetQuestionsByQuizID)
+	quizzes.Get("/:quizId/questions/:id", canRead, policy.RequireQuizRead(policy.QuestionParam("id")), questionHandler.GetQuestionByID)
 
-	// Routes that require auth
-	questionsWithAuth := quizzes.Group("/:quizId/questions")
-	questionsWithAuth.Use(authMiddleware.RequireAuth())
+	// Routes that require auth (or a quizzes:write token) and editor access to the quiz
+	canWrite := authMiddleware.RequireScope(models.ScopeQuizzesWrite)
+	canEditQuiz := policy.RequireQuizRole(models.QuizRoleEditor, policy.QuizParam("quizId"))
+	canEditQuestion := policy.RequireQuizRole(models.QuizRoleEditor, policy.QuestionParam("id"))
 
-	questionsWithAuth.Post("/", questionHandler.CreateQuestion)
-	questionsWithAuth.Patch("/:id", questionHandler.PatchQuestion)
-	questionsWithAuth.Delete("/:id", questionHandler.DeleteQuestion)
+	questions := quizzes.Group("/:quizId/questions")
+	questions.Post("/", canWrite, canEditQuiz, questionHandler.CreateQuestion)
+	questions.Put("/order", canWrite, canEditQuiz, questionHandler.ReorderQuestions)
+	questions.Post("/import", canWrite, canEditQuiz, questionHandler.ImportQuestions)
+	questions.Post("/import/:format", canWrite, canEditQuiz, questionHandler.ImportFormattedQuestions)
+	questions.Patch("/:id", canWrite, canEditQuestion, questionHandler.PatchQuestion)
+	questions.Delete("/:id", canWrite, canEditQuestion, questionHandler.DeleteQuestion)
 }
@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/patiphanak/league-of-quiz/handlers"
+	middleware "github.com/patiphanak/league-of-quiz/middlewares"
+	models "github.com/patiphanak/league-of-quiz/model"
+)
+
+// SetupQuestionBankRoute ลงทะเบียน routes สำหรับคลังข้อสอบส่วนตัว
+func SetupQuestionBankRoute(app *fiber.App, bankHandler *handlers.QuestionBankHandler, authMiddleware *middleware.AuthMiddleware, policy *middleware.Policy) {
+	apiV1 := app.Group("/api/v1")
+
+	// คลังข้อสอบเป็นข้อมูลส่วนตัว ต้องล็อกอินหรือใช้ token ที่มี scope ของ quiz ทุกเส้นทาง
+	canRead := authMiddleware.RequireScope(models.ScopeQuizzesRead)
+	canWrite := authMiddleware.RequireScope(models.ScopeQuizzesWrite)
+
+	bank := apiV1.Group("/bank")
+	bank.Get("/questions", canRead, bankHandler.SearchBankQuestions)
+	bank.Post("/questions", canWrite, bankHandler.CreateBankQuestion)
+	bank.Get("/questions/:id", canRead, bankHandler.GetBankQuestion)
+	bank.Patch("/questions/:id", canWrite, bankHandler.UpdateBankQuestion)
+	bank.Delete("/questions/:id", canWrite, bankHandler.DeleteBankQuestion)
+	bank.Post("/draw-quiz", canWrite, bankHandler.DrawQuiz)
+
+	// นำคำถามจากคลังไปใส่ quiz
+	apiV1.Post("/quizzes/:quizId/questions/from-bank", canWrite,
+		policy.RequireQuizRole(models.QuizRoleEditor, policy.QuizParam("quizId")), bankHandler.AddToQuiz)
+}
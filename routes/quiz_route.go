@@ -4,19 +4,40 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/patiphanak/league-of-quiz/handlers"
 	middleware "github.com/patiphanak/league-of-quiz/middlewares"
+	models "github.com/patiphanak/league-of-quiz/model"
 )
 
-func SetupQuizRoute(app *fiber.App, quizHandler *handlers.QuizHandler, authMiddleware *middleware.AuthMiddleware) {
+func SetupQuizRoute(app *fiber.App, quizHandler *handlers.QuizHandler, collaboratorHandler *handlers.QuizCollaboratorHandler, authMiddleware *middleware.AuthMiddleware, policy *middleware.Policy) {
 	apiV1 := app.Group("/api/v1")
 	quizRoutes := apiV1.Group("/quizzes")
 	quizRoutes.Get("/", quizHandler.GetQuizzes)
 	quizRoutes.Get("/categories", quizHandler.GetCategories)
-	quizRoutes.Get("/my", authMiddleware.RequireAuth(), quizHandler.GetMyQuizzes)
-	quizRoutes.Get("/:id", quizHandler.GetQuizByID)
+	quizRoutes.Get("/my", authMiddleware.RequireScope(models.ScopeQuizzesRead), quizHandler.GetMyQuizzes)
+	quizRoutes.Get("/shared", authMiddleware.RequireScope(models.ScopeQuizzesRead), collaboratorHandler.GetSharedQuizzes)
+	// quiz ที่เผยแพร่แล้วอ่านได้โดยไม่ต้องเข้าสู่ระบบ quiz อื่นต้องมีบทบาท viewer ขึ้นไป
+	quizRoutes.Get("/:id", authMiddleware.OptionalScope(models.ScopeQuizzesRead), policy.RequireQuizRead(policy.QuizParam("id")), quizHandler.GetQuizByID)
 
-	// ต้องมีการตรวจสอบ authentication
-	quizRoutes.Use(authMiddleware.RequireAuth())
-	quizRoutes.Post("/", quizHandler.CreateQuiz)
-	quizRoutes.Patch("/:id", quizHandler.UpdateQuiz)
-	quizRoutes.Delete("/:id", quizHandler.DeleteQuiz)
+	// ต้องมีการตรวจสอบ authentication ทุกเส้นทาง และตรวจบทบาทใน quiz ตามทรัพยากรที่อ้างถึง
+	// ลงทะเบียน middleware ราย route แทน Use() ของ group เพื่อไม่ให้กระทบ route สาธารณะอื่นที่ใช้ prefix เดียวกัน
+	// personal access token ใช้ได้ตาม scope ส่วนการจัดการผู้ร่วมแก้ไขต้องเข้าสู่ระบบเท่านั้น
+	requireAuth := authMiddleware.RequireAuth()
+	canRead := authMiddleware.RequireScope(models.ScopeQuizzesRead)
+	canWrite := authMiddleware.RequireScope(models.ScopeQuizzesWrite)
+	quizID := policy.QuizParam("id")
+
+	quizRoutes.Post("/", canWrite, quizHandler.CreateQuiz)
+	quizRoutes.Post("/import", canWrite, quizHandler.ImportQuiz)
+	quizRoutes.Post("/import/qti", canWrite, quizHandler.ImportQuizQTI)
+	quizRoutes.Get("/:id/export", canRead, policy.RequireQuizRole(models.QuizRoleViewer, quizID), quizHandler.ExportQuiz)
+	quizRoutes.Get("/:id/export/qti", canRead, policy.RequireQuizRole(models.QuizRoleViewer, quizID), quizHandler.ExportQuizQTI)
+	quizRoutes.Get("/:id/export/:format", canRead, policy.RequireQuizRole(models.QuizRoleViewer, quizID), quizHandler.ExportQuizAsText)
+	// quiz ที่เผยแพร่แล้วคัดลอกได้โดยไม่ต้องมีบทบาท service จึงเป็นผู้ตรวจสิทธิ์
+	quizRoutes.Post("/:id/duplicate", canWrite, quizHandler.DuplicateQuiz)
+	quizRoutes.Patch("/:id", canWrite, policy.RequireQuizRole(models.QuizRoleEditor, quizID), quizHandler.UpdateQuiz)
+	quizRoutes.Delete("/:id", canWrite, policy.RequireQuizRole(models.QuizRoleOwner, quizID), quizHandler.DeleteQuiz)
+
+	// ผู้ร่วมแก้ไข quiz (ผู้ร่วมแก้ไขลบตัวเองออกได้ service จึงเป็นผู้ตรวจสิทธิ์การลบ)
+	quizRoutes.Get("/:id/collaborators", canRead, policy.RequireQuizRole(models.QuizRoleViewer, quizID), collaboratorHandler.GetCollaborators)
+	quizRoutes.Post("/:id/collaborators", requireAuth, policy.RequireQuizRole(models.QuizRoleOwner, quizID), collaboratorHandler.InviteCollaborator)
+	quizRoutes.Delete("/:id/collaborators", requireAuth, collaboratorHandler.RemoveCollaborator)
 }
@@ -9,7 +9,7 @@ import (
 	"github.com/patiphanak/league-of-quiz/websocket"
 )
 
-func SetupRoutes(app *fiber.App, handlers *handlers.AllHandlers, authMiddleware *middleware.AuthMiddleware, wsManager *websocket.Manager) {
+func SetupRoutes(app *fiber.App, handlers *handlers.AllHandlers, authMiddleware *middleware.AuthMiddleware, policy *middleware.Policy, wsManager *websocket.Manager) {
 	// Middleware
 	app.Use(logger.New())
 	app.Use(recover.New())
@@ -17,9 +17,18 @@ func SetupRoutes(app *fiber.App, handlers *handlers.AllHandlers, authMiddleware
 
 	// routes
 	SetupAuthRoute(app, handlers.Auth, authMiddleware)
-	SetupUploadRoutes(app, handlers.Upload, authMiddleware)
-	SetupQuizRoute(app, handlers.Quiz, authMiddleware)
-	SetupQuestionRoute(app, handlers.Question, authMiddleware)
+	SetupLocalAuthRoute(app, handlers.LocalAuth)
+	SetupPersonalAccessTokenRoute(app, handlers.AccessToken, authMiddleware)
+	SetupDevAuthRoute(app, handlers.DevAuth)
+	SetupAccountRoute(app, handlers.Account, authMiddleware)
+	SetupUploadRoutes(app, handlers.Upload, authMiddleware, policy)
+	SetupQuizRoute(app, handlers.Quiz, handlers.Collaborator, authMiddleware, policy)
+	SetupQuestionRoute(app, handlers.Question, authMiddleware, policy)
+	SetupChoiceRoute(app, handlers.Choice, authMiddleware, policy)
+	SetupQuestionBankRoute(app, handlers.QuestionBank, authMiddleware, policy)
 	SetupGameRoute(app, handlers.Game, authMiddleware)
-	SetupWebSocketRoute(app, wsManager)
+	SetupOrganizationRoute(app, handlers.Organization, handlers.Classroom, authMiddleware)
+	SetupAdminRoute(app, handlers.Admin, authMiddleware)
+	SetupCategoryRoute(app, handlers.Category, authMiddleware)
+	SetupWebSocketRoute(app, wsManager, authMiddleware)
 }
@@ -0,0 +1,654 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/patiphanak/league-of-quiz/auth/jwt"
+	"github.com/patiphanak/league-of-quiz/auth/oauth"
+	"github.com/patiphanak/league-of-quiz/config"
+	"github.com/patiphanak/league-of-quiz/database"
+	"github.com/patiphanak/league-of-quiz/dto"
+	"github.com/patiphanak/league-of-quiz/handlers"
+	"github.com/patiphanak/league-of-quiz/mailer"
+	middleware "github.com/patiphanak/league-of-quiz/middlewares"
+	models "github.com/patiphanak/league-of-quiz/model"
+	"github.com/patiphanak/league-of-quiz/repositories"
+	"github.com/patiphanak/league-of-quiz/services"
+	"github.com/patiphanak/league-of-quiz/websocket"
+)
+
+// ผู้เรียก API ที่ทดสอบกับทุก route
+const (
+	actorAnonymous = "anonymous"
+	actorOutsider  = "outsider" // ผู้ใช้ที่ไม่ได้เป็นผู้ร่วมแก้ไข quiz
+	actorViewer    = "viewer"
+	actorEditor    = "editor"
+	actorOwner     = "owner"
+	actorAdmin     = "admin"
+	actorPATRead   = "pat-read"  // token ของ owner ที่มี scope quizzes:read
+	actorPATWrite  = "pat-write" // token ของ owner ที่มี scope quizzes:write
+	actorPATHost   = "pat-host"  // token ของ owner ที่มี scope games:host
+)
+
+var allActors = []string{
+	actorAnonymous, actorOutsider, actorViewer, actorEditor, actorOwner, actorAdmin,
+	actorPATRead, actorPATWrite, actorPATHost,
+}
+
+// กลุ่มผู้เรียกที่ใช้บ่อย
+var (
+	sessionUsers = []string{actorOutsider, actorViewer, actorEditor, actorOwner, actorAdmin}
+	quizWriters  = append(append([]string{}, sessionUsers...), actorPATWrite)
+	quizEditors  = []string{actorEditor, actorOwner, actorAdmin, actorPATWrite}
+	quizOwners   = []string{actorOwner, actorAdmin, actorPATWrite}
+	gameHosts    = append(append([]string{}, sessionUsers...), actorPATHost)
+	adminsOnly   = []string{actorAdmin}
+)
+
+// middlewareDenials ข้อความผิดพลาดที่ middleware ใช้ปฏิเสธ request
+var middlewareDenials = []string{
+	"Unauthorized",
+	"Invalid token",
+	"Session revoked",
+	"Insufficient role",
+	"Personal access tokens cannot be used for this endpoint",
+	"Token is missing required scope",
+	"You don't have permission",
+}
+
+// testServer แอปที่ลงทะเบียน route ทั้งหมดเหมือน main.go พร้อมฐานข้อมูลทดสอบ
+type testServer struct {
+	app      *fiber.App
+	db       *gorm.DB
+	services *services.Services
+}
+
+// policyWorld ข้อมูลตั้งต้นของแต่ละกรณีทดสอบ สร้างใหม่ทุกครั้งเพราะ route ที่ทดสอบแก้ไขหรือลบข้อมูล
+type policyWorld struct {
+	users        map[string]models.User
+	target       models.User
+	quiz         models.Quiz
+	question     models.Question
+	choice       models.Choice
+	bankQuestion models.BankQuestion
+	category     models.Category
+	// ไฟล์ที่คำถามใน quiz อ้างอิง ไฟล์ที่ owner อัปโหลดแต่ยังไม่มีใครอ้างอิง และไฟล์ที่ไม่มีบันทึกผู้อัปโหลด
+	referencedFile string
+	uploadedFile   string
+	orphanFile     string
+	avatarFile     string // รูปโปรไฟล์ของ owner
+	tokens         map[string]string
+}
+
+// routeCase route ที่แก้ไขข้อมูลหนึ่งเส้นทางกับผู้เรียกที่ได้รับอนุญาต
+// ถ้า authOnly เป็นจริง route ตรวจแค่การเข้าสู่ระบบและ scope ส่วนสิทธิ์ต่อทรัพยากรอยู่ใน service
+// จึงตรวจเพียงว่าผู้เรียกที่ได้รับอนุญาตผ่าน middleware
+type routeCase struct {
+	name     string
+	method   string
+	path     func(w *policyWorld) string
+	body     func(w *policyWorld) (contentType string, body string)
+	allowed  []string
+	authOnly bool
+}
+
+var uniqueCounter atomic.Int64
+
+// unique คืนค่าข้อความที่ไม่ซ้ำกันระหว่างการทดสอบแต่ละครั้ง
+func unique(prefix string) string {
+	return fmt.Sprintf("%s-%d-%d", prefix, time.Now().UnixNano(), uniqueCounter.Add(1))
+}
+
+// newTestServer สร้างแอปทดสอบจากฐานข้อมูลใน TEST_DATABASE_URL ข้ามการทดสอบถ้าไม่ได้กำหนด
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set; skipping route access tests")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	database.AutoMigration(db)
+
+	cfg := &config.Config{
+		JWTSecret:        "test-jwt-secret",
+		JWTRefreshSecret: "test-jwt-refresh-secret",
+		FrontendURL:      "http://localhost:4000",
+		BackendURL:       "http://localhost:3000",
+		MailFrom:         "League of Quiz <no-reply@localhost>",
+	}
+	jwtService := jwt.NewJWTService(cfg)
+	mail, err := mailer.NewFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to create mailer: %v", err)
+	}
+
+	repos := repositories.InitRepositories(db)
+	svcs, err := services.InitServices(repos, t.TempDir(), jwtService, mail, cfg.FrontendURL)
+	if err != nil {
+		t.Fatalf("failed to initialize services: %v", err)
+	}
+	wsManager, err := websocket.NewManager(svcs.GameService)
+	if err != nil {
+		t.Fatalf("failed to create websocket manager: %v", err)
+	}
+
+	app := fiber.New()
+	authMiddleware := middleware.NewAuthMiddleware(db, jwtService, svcs.Session, svcs.AccessToken)
+	policy := middleware.NewPolicy(repos)
+	SetupRoutes(app, handlers.InitHandlers(svcs, db, oauth.NewRegistry(), nil, cfg.FrontendURL), authMiddleware, policy, wsManager)
+
+	return &testServer{app: app, db: db, services: svcs}
+}
+
+// mustCreate บันทึกข้อมูลตั้งต้นและหยุดการทดสอบถ้าล้มเหลว
+func (s *testServer) mustCreate(t *testing.T, value interface{}) {
+	t.Helper()
+	if err := s.db.Create(value).Error; err != nil {
+		t.Fatalf("failed to create %T: %v", value, err)
+	}
+}
+
+// newUser สร้างผู้ใช้ใหม่ตามบทบาทที่กำหนด
+func (s *testServer) newUser(t *testing.T, name, role string) models.User {
+	t.Helper()
+	user := models.User{
+		Email:       unique(name) + "@example.test",
+		DisplayName: name,
+		Role:        role,
+	}
+	s.mustCreate(t, &user)
+	return user
+}
+
+// newWorld สร้างผู้ใช้ quiz คำถาม ตัวเลือก คำถามในคลัง หมวดหมู่ ไฟล์ และ token ของผู้เรียกทุกแบบ
+func (s *testServer) newWorld(t *testing.T) *policyWorld {
+	t.Helper()
+
+	w := &policyWorld{
+		users:  make(map[string]models.User),
+		tokens: make(map[string]string),
+	}
+	for _, actor := range sessionUsers {
+		role := models.UserRoleUser
+		if actor == actorAdmin {
+			role = models.UserRoleAdmin
+		}
+		w.users[actor] = s.newUser(t, actor, role)
+	}
+	w.target = s.newUser(t, "target", models.UserRoleUser)
+	owner := w.users[actorOwner]
+
+	w.referencedFile = unique("referenced") + ".png"
+	w.uploadedFile = unique("uploaded") + ".png"
+	w.orphanFile = unique("orphan") + ".png"
+	w.avatarFile = unique("avatar") + ".png"
+	owner.AvatarURL = "localhost:3000/storage/avatar/" + w.avatarFile
+	if err := s.db.Model(&owner).Update("avatar_url", owner.AvatarURL).Error; err != nil {
+		t.Fatalf("failed to set avatar: %v", err)
+	}
+
+	w.quiz = models.Quiz{Title: unique("quiz"), Description: "policy test", CreatorID: owner.ID}
+	s.mustCreate(t, &w.quiz)
+	w.question = models.Question{
+		QuizID:   w.quiz.ID,
+		Text:     "Question",
+		Position: 1,
+		ImageURL: "localhost:3000/storage/question/" + w.referencedFile,
+	}
+	s.mustCreate(t, &w.question)
+	w.choice = models.Choice{QuestionID: w.question.ID, Text: "Choice", IsCorrect: true, Position: 1}
+	s.mustCreate(t, &w.choice)
+
+	for actor, role := range map[string]string{actorEditor: models.QuizRoleEditor, actorViewer: models.QuizRoleViewer} {
+		s.mustCreate(t, &models.QuizCollaborator{
+			QuizID:      w.quiz.ID,
+			UserID:      w.users[actor].ID,
+			Role:        role,
+			InvitedByID: owner.ID,
+		})
+	}
+
+	w.bankQuestion = models.BankQuestion{
+		OwnerID: owner.ID,
+		Text:    "Bank question",
+		Choices: []models.BankChoice{{Text: "Bank choice", IsCorrect: true, Position: 1}},
+	}
+	s.mustCreate(t, &w.bankQuestion)
+
+	w.category = models.Category{Name: unique("category")}
+	s.mustCreate(t, &w.category)
+
+	s.mustCreate(t, &models.UploadedFile{Path: "/storage/question/" + w.uploadedFile, UploaderID: owner.ID})
+
+	for _, actor := range sessionUsers {
+		user := w.users[actor]
+		tokens, err := s.services.Token.IssueTokens(&user, services.SessionInfo{})
+		if err != nil {
+			t.Fatalf("failed to issue tokens for %s: %v", actor, err)
+		}
+		w.tokens[actor] = tokens.AccessToken
+	}
+	for actor, scope := range map[string]string{
+		actorPATRead:  models.ScopeQuizzesRead,
+		actorPATWrite: models.ScopeQuizzesWrite,
+		actorPATHost:  models.ScopeGamesHost,
+	} {
+		created, err := s.services.AccessToken.CreateToken(owner.ID, dto.CreatePersonalAccessTokenRequest{
+			Name:   actor,
+			Scopes: []string{scope},
+		})
+		if err != nil {
+			t.Fatalf("failed to create %s token: %v", actor, err)
+		}
+		w.tokens[actor] = created.Token
+	}
+	return w
+}
+
+// do ส่ง request ในนามผู้เรียก คืนค่า status และข้อความผิดพลาดใน response (ถ้ามี)
+func (s *testServer) do(t *testing.T, w *policyWorld, rc routeCase, actor string) (int, string) {
+	t.Helper()
+
+	var body io.Reader
+	contentType := ""
+	if rc.body != nil {
+		var raw string
+		contentType, raw = rc.body(w)
+		body = strings.NewReader(raw)
+	}
+
+	req := httptest.NewRequest(rc.method, rc.path(w), body)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if token, ok := w.tokens[actor]; ok {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := s.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var payload struct {
+		Error string `json:"error"`
+	}
+	raw, _ := io.ReadAll(resp.Body)
+	_ = json.Unmarshal(raw, &payload)
+	return resp.StatusCode, payload.Error
+}
+
+// isMiddlewareDenial ตรวจว่าข้อความผิดพลาดมาจาก middleware ตรวจสิทธิ์หรือไม่
+func isMiddlewareDenial(message string) bool {
+	for _, denial := range middlewareDenials {
+		if strings.HasPrefix(message, denial) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(actors []string, actor string) bool {
+	for _, a := range actors {
+		if a == actor {
+			return true
+		}
+	}
+	return false
+}
+
+func id(v uint) string {
+	return fmt.Sprint(v)
+}
+
+func jsonBody(raw string) func(w *policyWorld) (string, string) {
+	return func(w *policyWorld) (string, string) {
+		return fiber.MIMEApplicationJSON, raw
+	}
+}
+
+// mutatingRoutes route ที่แก้ไขข้อมูลทั้งหมด พร้อมผู้เรียกที่ได้รับอนุญาต
+func mutatingRoutes() []routeCase {
+	const missingID = "999999999"
+	quizPath := func(suffix string) func(w *policyWorld) string {
+		return func(w *policyWorld) string { return "/api/v1/quizzes/" + id(w.quiz.ID) + suffix }
+	}
+	questionPath := func(w *policyWorld) string {
+		return "/api/v1/quizzes/" + id(w.quiz.ID) + "/questions/" + id(w.question.ID)
+	}
+	fixed := func(path string) func(w *policyWorld) string {
+		return func(w *policyWorld) string { return path }
+	}
+
+	return []routeCase{
+		// quiz
+		{name: "create quiz", method: http.MethodPost, path: fixed("/api/v1/quizzes"), allowed: quizWriters},
+		{name: "import quiz", method: http.MethodPost, path: fixed("/api/v1/quizzes/import"), allowed: quizWriters},
+		{name: "import quiz QTI", method: http.MethodPost, path: fixed("/api/v1/quizzes/import/qti"), allowed: quizWriters},
+		{name: "duplicate quiz", method: http.MethodPost, path: quizPath("/duplicate"),
+			allowed: []string{actorViewer, actorEditor, actorOwner, actorAdmin, actorPATWrite}},
+		{name: "update quiz", method: http.MethodPatch, path: quizPath(""), allowed: quizEditors},
+		{name: "delete quiz", method: http.MethodDelete, path: quizPath(""), allowed: quizOwners},
+		{name: "invite collaborator", method: http.MethodPost, path: quizPath("/collaborators"),
+			allowed: []string{actorOwner, actorAdmin}},
+		{name: "remove collaborator", method: http.MethodDelete,
+			path: func(w *policyWorld) string {
+				return quizPath("/collaborators?email=")(w) + url.QueryEscape(w.users[actorEditor].Email)
+			},
+			allowed: []string{actorEditor, actorOwner, actorAdmin}},
+		{name: "share quiz with organization", method: http.MethodPut, path: quizPath("/organization"),
+			allowed: sessionUsers, authOnly: true},
+
+		// คำถามและตัวเลือก
+		{name: "create question", method: http.MethodPost, path: quizPath("/questions"), allowed: quizEditors},
+		{name: "reorder questions", method: http.MethodPut, path: quizPath("/questions/order"), allowed: quizEditors},
+		{name: "import questions", method: http.MethodPost, path: quizPath("/questions/import"), allowed: quizEditors},
+		{name: "import GIFT questions", method: http.MethodPost, path: quizPath("/questions/import/gift"), allowed: quizEditors},
+		{name: "update question", method: http.MethodPatch, path: questionPath, allowed: quizEditors},
+		{name: "delete question", method: http.MethodDelete, path: questionPath, allowed: quizEditors},
+		{name: "create choice", method: http.MethodPost, path: fixed("/api/v1/choices"),
+			body: func(w *policyWorld) (string, string) {
+				return fiber.MIMEApplicationJSON, fmt.Sprintf(`{"QuestionID":%d,"Text":"New choice"}`, w.question.ID)
+			},
+			allowed: quizEditors},
+		{name: "update choice", method: http.MethodPatch,
+			path: func(w *policyWorld) string { return "/api/v1/choices/" + id(w.choice.ID) }, allowed: quizEditors},
+		{name: "delete choice", method: http.MethodDelete,
+			path: func(w *policyWorld) string { return "/api/v1/choices/" + id(w.choice.ID) }, allowed: quizEditors},
+		{name: "upload choice image", method: http.MethodPost,
+			path: func(w *policyWorld) string { return "/api/v1/choices/" + id(w.choice.ID) + "/image" }, allowed: quizEditors},
+
+		// คลังข้อสอบ
+		{name: "create bank question", method: http.MethodPost, path: fixed("/api/v1/bank/questions"), allowed: quizWriters},
+		{name: "update bank question", method: http.MethodPatch,
+			path: func(w *policyWorld) string { return "/api/v1/bank/questions/" + id(w.bankQuestion.ID) },
+			body: func(w *policyWorld) (string, string) {
+				form := url.Values{"questionData": {`{"text":"Updated","choices":[{"text":"A","isCorrect":true}]}`}}
+				return fiber.MIMEApplicationForm, form.Encode()
+			},
+			allowed: []string{actorOwner, actorPATWrite}},
+		{name: "delete bank question", method: http.MethodDelete,
+			path:    func(w *policyWorld) string { return "/api/v1/bank/questions/" + id(w.bankQuestion.ID) },
+			allowed: []string{actorOwner, actorPATWrite}},
+		{name: "draw quiz from bank", method: http.MethodPost, path: fixed("/api/v1/bank/draw-quiz"), body: jsonBody(`{}`), allowed: quizWriters},
+		{name: "add bank questions to quiz", method: http.MethodPost, path: quizPath("/questions/from-bank"), body: jsonBody(`{}`), allowed: quizEditors},
+
+		// ไฟล์
+		{name: "upload file", method: http.MethodPost, path: fixed("/api/upload/question"), allowed: quizWriters},
+		{name: "delete file referenced by quiz", method: http.MethodDelete,
+			path: func(w *policyWorld) string { return "/api/upload/question/" + w.referencedFile }, allowed: quizEditors},
+		{name: "delete unreferenced file uploaded by owner", method: http.MethodDelete,
+			path:    func(w *policyWorld) string { return "/api/upload/question/" + w.uploadedFile },
+			allowed: []string{actorOwner, actorPATWrite}},
+		{name: "delete unreferenced file without uploader", method: http.MethodDelete,
+			path: func(w *policyWorld) string { return "/api/upload/question/" + w.orphanFile }, allowed: nil},
+		{name: "delete avatar file", method: http.MethodDelete,
+			path: func(w *policyWorld) string { return "/api/upload/avatar/" + w.avatarFile }, allowed: nil},
+
+		// หมวดหมู่และผู้ดูแลระบบ
+		{name: "create category", method: http.MethodPost, path: fixed("/api/v1/categories"), allowed: adminsOnly},
+		{name: "update category", method: http.MethodPut,
+			path: func(w *policyWorld) string { return "/api/v1/categories/" + id(w.category.ID) }, allowed: adminsOnly},
+		{name: "delete category", method: http.MethodDelete,
+			path: func(w *policyWorld) string { return "/api/v1/categories/" + id(w.category.ID) }, allowed: adminsOnly},
+		{name: "update user role", method: http.MethodPatch,
+			path: func(w *policyWorld) string { return "/api/v1/admin/users/" + id(w.target.ID) + "/role" }, allowed: adminsOnly},
+		{name: "disable user", method: http.MethodPost,
+			path: func(w *policyWorld) string { return "/api/v1/admin/users/" + id(w.target.ID) + "/disable" }, allowed: adminsOnly},
+		{name: "enable user", method: http.MethodPost,
+			path: func(w *policyWorld) string { return "/api/v1/admin/users/" + id(w.target.ID) + "/enable" }, allowed: adminsOnly},
+		{name: "admin unpublish quiz", method: http.MethodPost,
+			path: func(w *policyWorld) string { return "/api/v1/admin/quizzes/" + id(w.quiz.ID) + "/unpublish" }, allowed: adminsOnly},
+		{name: "admin delete quiz", method: http.MethodDelete,
+			path: func(w *policyWorld) string { return "/api/v1/admin/quizzes/" + id(w.quiz.ID) }, allowed: adminsOnly},
+
+		// บัญชีและ personal access token (ใช้ได้เฉพาะ session การเข้าสู่ระบบ)
+		{name: "update profile", method: http.MethodPatch, path: fixed("/api/v1/account/profile"), allowed: sessionUsers, authOnly: true},
+		{name: "update avatar", method: http.MethodPut, path: fixed("/api/v1/account/avatar"), allowed: sessionUsers, authOnly: true},
+		{name: "remove avatar", method: http.MethodDelete, path: fixed("/api/v1/account/avatar"), allowed: sessionUsers, authOnly: true},
+		{name: "delete account", method: http.MethodDelete, path: fixed("/api/v1/account"), allowed: sessionUsers, authOnly: true},
+		{name: "create access token", method: http.MethodPost, path: fixed("/api/v1/auth/tokens"), allowed: sessionUsers, authOnly: true},
+		{name: "revoke access token", method: http.MethodDelete, path: fixed("/api/v1/auth/tokens/" + missingID), allowed: sessionUsers, authOnly: true},
+
+		// องค์กรและห้องเรียน
+		{name: "create organization", method: http.MethodPost, path: fixed("/api/v1/organizations"), allowed: adminsOnly},
+		{name: "update organization", method: http.MethodPatch, path: fixed("/api/v1/organizations/" + missingID), allowed: sessionUsers, authOnly: true},
+		{name: "delete organization", method: http.MethodDelete, path: fixed("/api/v1/organizations/" + missingID), allowed: sessionUsers, authOnly: true},
+		{name: "add organization member", method: http.MethodPost, path: fixed("/api/v1/organizations/" + missingID + "/members"), allowed: sessionUsers, authOnly: true},
+		{name: "remove organization member", method: http.MethodDelete, path: fixed("/api/v1/organizations/" + missingID + "/members/" + missingID), allowed: sessionUsers, authOnly: true},
+		{name: "create classroom", method: http.MethodPost, path: fixed("/api/v1/organizations/" + missingID + "/classrooms"), allowed: sessionUsers, authOnly: true},
+		{name: "update classroom", method: http.MethodPatch, path: fixed("/api/v1/classrooms/" + missingID), allowed: sessionUsers, authOnly: true},
+		{name: "delete classroom", method: http.MethodDelete, path: fixed("/api/v1/classrooms/" + missingID), allowed: sessionUsers, authOnly: true},
+		{name: "add classroom member", method: http.MethodPost, path: fixed("/api/v1/classrooms/" + missingID + "/members"), allowed: sessionUsers, authOnly: true},
+		{name: "remove classroom member", method: http.MethodDelete, path: fixed("/api/v1/classrooms/" + missingID + "/members/" + missingID), allowed: sessionUsers, authOnly: true},
+
+		// เกม
+		{name: "create game session", method: http.MethodPost, path: fixed("/api/v1/games/sessions"), allowed: gameHosts, authOnly: true},
+		{name: "join game session", method: http.MethodPost, path: fixed("/api/v1/games/sessions/" + missingID + "/join"), allowed: sessionUsers, authOnly: true},
+		{name: "start game session", method: http.MethodPost, path: fixed("/api/v1/games/sessions/" + missingID + "/start"), allowed: gameHosts, authOnly: true},
+		{name: "end game session", method: http.MethodPost, path: fixed("/api/v1/games/sessions/" + missingID + "/end"), allowed: gameHosts, authOnly: true},
+		{name: "update session access", method: http.MethodPatch, path: fixed("/api/v1/games/sessions/" + missingID + "/access"), allowed: gameHosts, authOnly: true},
+		{name: "lock lobby", method: http.MethodPost, path: fixed("/api/v1/games/sessions/" + missingID + "/lock"), allowed: gameHosts, authOnly: true},
+		{name: "unlock lobby", method: http.MethodPost, path: fixed("/api/v1/games/sessions/" + missingID + "/unlock"), allowed: gameHosts, authOnly: true},
+		{name: "approve join request", method: http.MethodPost, path: fixed("/api/v1/games/sessions/" + missingID + "/join-requests/" + missingID + "/approve"), allowed: gameHosts, authOnly: true},
+		{name: "deny join request", method: http.MethodPost, path: fixed("/api/v1/games/sessions/" + missingID + "/join-requests/" + missingID + "/deny"), allowed: gameHosts, authOnly: true},
+		{name: "update team settings", method: http.MethodPatch, path: fixed("/api/v1/games/sessions/" + missingID + "/team-settings"), allowed: gameHosts, authOnly: true},
+		{name: "submit answer", method: http.MethodPost, path: fixed("/api/v1/games/sessions/" + missingID + "/answers"), allowed: sessionUsers, authOnly: true},
+	}
+}
+
+// TestMutatingRouteAccess ตรวจว่าทุก route ที่แก้ไขข้อมูลยอมให้เฉพาะผู้เรียกที่ได้รับอนุญาต
+// ผู้ที่ไม่ได้เข้าสู่ระบบได้ 401 ผู้เรียกอื่นที่ไม่ได้รับอนุญาตได้ 403
+func TestMutatingRouteAccess(t *testing.T) {
+	server := newTestServer(t)
+
+	for _, rc := range mutatingRoutes() {
+		rc := rc
+		t.Run(rc.name, func(t *testing.T) {
+			for _, actor := range allActors {
+				w := server.newWorld(t)
+				status, message := server.do(t, w, rc, actor)
+
+				switch {
+				case !contains(rc.allowed, actor):
+					want := fiber.StatusForbidden
+					if actor == actorAnonymous {
+						want = fiber.StatusUnauthorized
+					}
+					if status != want {
+						t.Errorf("%s %s as %s: got status %d (%q), want %d", rc.method, rc.name, actor, status, message, want)
+					}
+				case rc.authOnly:
+					if status == fiber.StatusUnauthorized || isMiddlewareDenial(message) {
+						t.Errorf("%s %s as %s: rejected by middleware with status %d (%q)", rc.method, rc.name, actor, status, message)
+					}
+				default:
+					if status == fiber.StatusUnauthorized || status == fiber.StatusForbidden {
+						t.Errorf("%s %s as %s: got status %d (%q), want access", rc.method, rc.name, actor, status, message)
+					}
+				}
+			}
+		})
+	}
+}
+
+// quizReaders ผู้เรียกที่อ่าน quiz ที่ยังไม่เผยแพร่ได้ และผู้เรียกที่เห็นเฉลย
+var (
+	quizReaders   = []string{actorViewer, actorEditor, actorOwner, actorAdmin, actorPATRead, actorPATWrite}
+	answerReaders = []string{actorEditor, actorOwner, actorAdmin, actorPATRead, actorPATWrite}
+)
+
+// readCase route ที่อ่านเนื้อหาของ quiz
+// choicePath คือลำดับ key หรือ index ของตัวเลือกแรกใน "data" ของ response ใช้ตรวจว่ามีเฉลยหรือไม่
+type readCase struct {
+	name       string
+	path       func(w *policyWorld) string
+	choicePath []interface{}
+}
+
+// readRoutes route ที่อ่านเนื้อหาของ quiz ทั้งหมด
+func readRoutes() []readCase {
+	return []readCase{
+		{name: "get quiz", path: func(w *policyWorld) string { return "/api/v1/quizzes/" + id(w.quiz.ID) },
+			choicePath: []interface{}{"Questions", 0, "Choices", 0}},
+		{name: "get questions", path: func(w *policyWorld) string { return "/api/v1/quizzes/" + id(w.quiz.ID) + "/questions" },
+			choicePath: []interface{}{0, "Choices", 0}},
+		{name: "get question", path: func(w *policyWorld) string {
+			return "/api/v1/quizzes/" + id(w.quiz.ID) + "/questions/" + id(w.question.ID)
+		}, choicePath: []interface{}{"Choices", 0}},
+		{name: "get choices", path: func(w *policyWorld) string { return "/api/v1/questions/" + id(w.question.ID) + "/choices" },
+			choicePath: []interface{}{0}},
+		{name: "get choice", path: func(w *policyWorld) string { return "/api/v1/choices/" + id(w.choice.ID) },
+			choicePath: []interface{}{}},
+	}
+}
+
+// getData ส่ง GET request ในนามผู้เรียก คืนค่า status และ "data" ใน response
+func (s *testServer) getData(t *testing.T, w *policyWorld, path string, actor string) (int, interface{}) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if token, ok := w.tokens[actor]; ok {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := s.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var payload struct {
+		Data interface{} `json:"data"`
+	}
+	raw, _ := io.ReadAll(resp.Body)
+	_ = json.Unmarshal(raw, &payload)
+	return resp.StatusCode, payload.Data
+}
+
+// dig ดึงค่าใน JSON ตามลำดับ key หรือ index คืนค่า nil ถ้าไม่พบ
+func dig(value interface{}, path []interface{}) interface{} {
+	for _, step := range path {
+		switch key := step.(type) {
+		case string:
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			value = object[key]
+		case int:
+			list, ok := value.([]interface{})
+			if !ok || key >= len(list) {
+				return nil
+			}
+			value = list[key]
+		}
+	}
+	return value
+}
+
+// TestQuizReadAccess ตรวจว่า quiz ที่ยังไม่เผยแพร่อ่านได้เฉพาะผู้มีบทบาท และเฉลยแสดงเฉพาะ editor ขึ้นไป
+func TestQuizReadAccess(t *testing.T) {
+	server := newTestServer(t)
+
+	for _, published := range []bool{false, true} {
+		for _, rr := range readRoutes() {
+			rr := rr
+			t.Run(fmt.Sprintf("%s (published=%t)", rr.name, published), func(t *testing.T) {
+				for _, actor := range allActors {
+					if actor == actorPATHost {
+						continue
+					}
+					w := server.newWorld(t)
+					if err := server.db.Model(&w.quiz).Update("is_published", published).Error; err != nil {
+						t.Fatalf("failed to update quiz: %v", err)
+					}
+					status, data := server.getData(t, w, rr.path(w), actor)
+
+					if !published && !contains(quizReaders, actor) {
+						if status != fiber.StatusForbidden {
+							t.Errorf("%s as %s: got status %d, want %d", rr.name, actor, status, fiber.StatusForbidden)
+						}
+						continue
+					}
+					if status != fiber.StatusOK {
+						t.Errorf("%s as %s: got status %d, want %d", rr.name, actor, status, fiber.StatusOK)
+						continue
+					}
+
+					choice, ok := dig(data, rr.choicePath).(map[string]interface{})
+					if !ok {
+						t.Fatalf("%s as %s: choice not found in response", rr.name, actor)
+					}
+					_, hasAnswer := choice["IsCorrect"]
+					if hasAnswer != contains(answerReaders, actor) {
+						t.Errorf("%s as %s: answer visible = %t, want %t", rr.name, actor, hasAnswer, !hasAnswer)
+					}
+				}
+			})
+		}
+	}
+}
+
+// TestOrganizationQuizReadAccess ตรวจว่า quiz ที่แชร์ในองค์กรอ่านได้เฉพาะสมาชิก แม้จะเผยแพร่แล้ว
+func TestOrganizationQuizReadAccess(t *testing.T) {
+	server := newTestServer(t)
+
+	for _, rr := range readRoutes() {
+		rr := rr
+		t.Run(rr.name, func(t *testing.T) {
+			w := server.newWorld(t)
+			org := models.Organization{Name: "Policy school", Slug: unique("org"), CreatedByID: w.users[actorOwner].ID}
+			server.mustCreate(t, &org)
+			if err := server.db.Model(&w.quiz).Updates(map[string]interface{}{
+				"is_published":    true,
+				"organization_id": org.ID,
+			}).Error; err != nil {
+				t.Fatalf("failed to share quiz: %v", err)
+			}
+
+			for _, actor := range []string{actorAnonymous, actorOutsider} {
+				if status, _ := server.getData(t, w, rr.path(w), actor); status != fiber.StatusForbidden {
+					t.Errorf("%s as non-member %s: got status %d, want %d", rr.name, actor, status, fiber.StatusForbidden)
+				}
+			}
+
+			server.mustCreate(t, &models.OrganizationMember{
+				OrganizationID: org.ID,
+				UserID:         w.users[actorOutsider].ID,
+				Role:           models.OrgRoleMember,
+			})
+			status, data := server.getData(t, w, rr.path(w), actorOutsider)
+			if status != fiber.StatusOK {
+				t.Fatalf("%s as member: got status %d, want %d", rr.name, status, fiber.StatusOK)
+			}
+			choice, ok := dig(data, rr.choicePath).(map[string]interface{})
+			if !ok {
+				t.Fatalf("%s as member: choice not found in response", rr.name)
+			}
+			if _, hasAnswer := choice["IsCorrect"]; hasAnswer {
+				t.Errorf("%s as member: answer visible to a viewer", rr.name)
+			}
+		})
+	}
+}
@@ -4,19 +4,20 @@ import (
 	"github.com/gofiber/fiber/v2"
 	handler "github.com/patiphanak/league-of-quiz/handlers"
 	middleware "github.com/patiphanak/league-of-quiz/middlewares"
+	models "github.com/patiphanak/league-of-quiz/model"
 )
 
 // SetupUploadRoutes กำหนด routes สำหรับการอัปโหลดไฟล์
-func SetupUploadRoutes(app *fiber.App, uploadHandler *handler.UploadHandler, authMiddleware *middleware.AuthMiddleware) {
+func SetupUploadRoutes(app *fiber.App, uploadHandler *handler.UploadHandler, authMiddleware *middleware.AuthMiddleware, policy *middleware.Policy) {
 	// กำหนด route group สำหรับการอัปโหลด
 	uploadRoutes := app.Group("/api/upload")
 
-	// ต้องล็อกอินก่อนถึงจะอัปโหลดได้
-	uploadRoutes.Use(authMiddleware.RequireAuth())
+	// ต้องล็อกอินหรือใช้ token ที่มี scope quizzes:write ถึงจะอัปโหลดได้
+	canWrite := authMiddleware.RequireScope(models.ScopeQuizzesWrite)
 
 	// Route เดียวสำหรับการอัปโหลดไฟล์ทุกประเภท โดยใช้ path parameter
-	uploadRoutes.Post("/:type", uploadHandler.UploadFile)
+	uploadRoutes.Post("/:type", canWrite, uploadHandler.UploadFile)
 
-	// Route เดียวสำหรับการลบไฟล์ทุกประเภท
-	uploadRoutes.Delete("/:type/:filename", uploadHandler.DeleteFile)
+	// Route เดียวสำหรับการลบไฟล์ทุกประเภท ต้องมีสิทธิ์แก้ไขทรัพยากรที่อ้างอิงไฟล์อยู่
+	uploadRoutes.Delete("/:type/:filename", canWrite, policy.RequireFileAccess("type", "filename"), uploadHandler.DeleteFile)
 }
@@ -2,17 +2,29 @@ package routes
 
 import (
 	"log"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	middleware "github.com/patiphanak/league-of-quiz/middlewares"
 	"github.com/patiphanak/league-of-quiz/websocket"
 )
 
-func SetupWebSocketRoute(app *fiber.App, wsManager *websocket.Manager) {
+func SetupWebSocketRoute(app *fiber.App, wsManager *websocket.Manager, authMiddleware *middleware.AuthMiddleware) {
 	// แปลง http.Handler เป็น fiber.Handler ด้วย adaptor
 	log.Println("Setting up WebSocket routes")
 	socketHandler := adaptor.HTTPHandler(wsManager.HandleHTTP())
 
 	// ลงทะเบียน WebSocket routes
-	app.Get("/ws", socketHandler)
+	// ต้องเข้าสู่ระบบก่อน และส่ง ID ผู้ใช้ต่อให้ manager ผ่าน header ที่ตั้งฝั่ง server
+	app.Get("/ws", authMiddleware.RequireAuth(), func(c *fiber.Ctx) error {
+		userID, ok := c.Locals("userID").(uint)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "User not authenticated",
+			})
+		}
+		c.Request().Header.Set(websocket.AuthenticatedUserHeader, strconv.FormatUint(uint64(userID), 10))
+		return socketHandler(c)
+	})
 }
\ No newline at end of file
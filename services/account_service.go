@@ -0,0 +1,360 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"mime/multipart"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/patiphanak/league-of-quiz/dto"
+	models "github.com/patiphanak/league-of-quiz/model"
+	"github.com/patiphanak/league-of-quiz/repositories"
+)
+
+// ความยาวสูงสุดของชื่อที่แสดงและชื่อเล่นในเกม
+const (
+	maxDisplayNameLength = 64
+	maxNicknameLength    = 32
+)
+
+// ErrSoleOrganizationOwner ผู้ใช้เป็น owner คนเดียวขององค์กร จึงลบบัญชีไม่ได้จนกว่าจะโอนสิทธิ์ให้ผู้อื่น
+var ErrSoleOrganizationOwner = errors.New("you are the only owner of an organization; add another owner or delete the organization before deleting your account")
+
+// AccountService สำหรับจัดการโปรไฟล์ การส่งออกข้อมูล และการลบบัญชีของผู้ใช้เอง
+type AccountService struct {
+	userRepo       *repositories.UserRepository
+	accountRepo    *repositories.AccountRepository
+	identityRepo   *repositories.UserIdentityRepository
+	fileService    *FileService
+	sessionService *SessionService
+}
+
+// NewAccountService สร้าง instance ใหม่ของ AccountService
+func NewAccountService(
+	userRepo *repositories.UserRepository,
+	accountRepo *repositories.AccountRepository,
+	identityRepo *repositories.UserIdentityRepository,
+	fileService *FileService,
+	sessionService *SessionService,
+) *AccountService {
+	return &AccountService{
+		userRepo:       userRepo,
+		accountRepo:    accountRepo,
+		identityRepo:   identityRepo,
+		fileService:    fileService,
+		sessionService: sessionService,
+	}
+}
+
+// GetProfile ดึงโปรไฟล์ของผู้ใช้
+func (s *AccountService) GetProfile(userID uint) (*dto.ProfileResponse, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	profile := toProfileResponse(user)
+	return &profile, nil
+}
+
+// UpdateProfile แก้ไขชื่อที่แสดงและชื่อเล่นเริ่มต้นในเกม
+func (s *AccountService) UpdateProfile(userID uint, req dto.UpdateProfileRequest) (*dto.ProfileResponse, error) {
+	updates := map[string]interface{}{}
+
+	if req.DisplayName != nil {
+		name := strings.TrimSpace(*req.DisplayName)
+		if name == "" {
+			return nil, errors.New("display name cannot be empty")
+		}
+		if utf8.RuneCountInString(name) > maxDisplayNameLength {
+			return nil, errors.New("display name must be at most 64 characters")
+		}
+		updates["display_name"] = name
+	}
+
+	if req.Nickname != nil {
+		nickname := strings.TrimSpace(*req.Nickname)
+		if utf8.RuneCountInString(nickname) > maxNicknameLength {
+			return nil, errors.New("nickname must be at most 32 characters")
+		}
+		updates["nickname"] = nickname
+	}
+
+	if len(updates) > 0 {
+		if err := s.userRepo.UpdateProfile(userID, updates); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetProfile(userID)
+}
+
+// UpdateAvatar อัปโหลดรูปโปรไฟล์ใหม่ แล้วลบรูปเดิม
+func (s *AccountService) UpdateAvatar(userID uint, file *multipart.FileHeader) (*dto.ProfileResponse, error) {
+	if file == nil {
+		return nil, errors.New("avatar file is required")
+	}
+
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	avatarURL, err := s.fileService.UploadFile(file, string(AvatarType))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.SetAvatarURL(userID, avatarURL); err != nil {
+		_ = s.fileService.DeleteFileByURL(avatarURL)
+		return nil, err
+	}
+
+	if user.AvatarURL != "" {
+		_ = s.fileService.DeleteFileByURL(user.AvatarURL)
+	}
+	return s.GetProfile(userID)
+}
+
+// RemoveAvatar ลบรูปโปรไฟล์ที่อัปโหลด จะกลับไปใช้รูปจาก provider (ถ้ามี)
+func (s *AccountService) RemoveAvatar(userID uint) (*dto.ProfileResponse, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.AvatarURL != "" {
+		if err := s.userRepo.SetAvatarURL(userID, ""); err != nil {
+			return nil, err
+		}
+		_ = s.fileService.DeleteFileByURL(user.AvatarURL)
+	}
+	return s.GetProfile(userID)
+}
+
+// ExportData รวบรวมข้อมูลทั้งหมดของผู้ใช้ ได้แก่ โปรไฟล์ quiz คลังข้อสอบ เกม และคำตอบ
+func (s *AccountService) ExportData(userID uint) (*dto.AccountExport, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &dto.AccountExport{
+		ExportedAt: time.Now().UTC(),
+		Profile:    toProfileResponse(user),
+	}
+
+	identities, err := s.identityRepo.GetIdentitiesByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	export.Identities = make([]dto.ExportIdentity, 0, len(identities))
+	for _, identity := range identities {
+		export.Identities = append(export.Identities, dto.ExportIdentity{
+			Provider:  identity.Provider,
+			Email:     identity.Email,
+			CreatedAt: identity.CreatedAt,
+		})
+	}
+
+	quizzes, err := s.accountRepo.GetQuizzesByCreator(userID)
+	if err != nil {
+		return nil, err
+	}
+	export.Quizzes = make([]dto.ExportQuiz, 0, len(quizzes))
+	for _, quiz := range quizzes {
+		export.Quizzes = append(export.Quizzes, toExportQuiz(quiz))
+	}
+
+	bankQuestions, err := s.accountRepo.GetBankQuestionsByOwner(userID)
+	if err != nil {
+		return nil, err
+	}
+	export.BankQuestions = make([]dto.ExportBankQuestion, 0, len(bankQuestions))
+	for _, question := range bankQuestions {
+		export.BankQuestions = append(export.BankQuestions, toExportBankQuestion(question))
+	}
+
+	hosted, err := s.accountRepo.GetHostedSessions(userID)
+	if err != nil {
+		return nil, err
+	}
+	export.HostedGames = make([]dto.ExportHostedGame, 0, len(hosted))
+	for _, session := range hosted {
+		export.HostedGames = append(export.HostedGames, dto.ExportHostedGame{
+			SessionID:  session.ID,
+			QuizID:     session.QuizID,
+			Status:     session.Status,
+			CreatedAt:  session.CreatedAt,
+			StartedAt:  session.StartedAt,
+			FinishedAt: session.FinishedAt,
+		})
+	}
+
+	players, err := s.accountRepo.GetPlayerRecords(userID)
+	if err != nil {
+		return nil, err
+	}
+	export.PlayedGames = make([]dto.ExportPlayedGame, 0, len(players))
+	for _, player := range players {
+		export.PlayedGames = append(export.PlayedGames, dto.ExportPlayedGame{
+			SessionID: player.SessionID,
+			QuizID:    player.Session.QuizID,
+			Nickname:  player.Nickname,
+			Score:     player.Score,
+			JoinedAt:  player.JoinedAt,
+		})
+	}
+
+	answers, err := s.accountRepo.GetAnswersByPlayer(userID)
+	if err != nil {
+		return nil, err
+	}
+	export.Answers = make([]dto.ExportAnswer, 0, len(answers))
+	for _, answer := range answers {
+		export.Answers = append(export.Answers, dto.ExportAnswer{
+			SessionID:  answer.SessionID,
+			QuizID:     answer.QuizID,
+			QuestionID: answer.QuestionID,
+			ChoiceID:   answer.ChoiceID,
+			IsCorrect:  answer.IsCorrect,
+			Points:     answer.Points,
+			TimeSpent:  answer.TimeSpent,
+			CreatedAt:  answer.CreatedAt,
+		})
+	}
+
+	return export, nil
+}
+
+// DeleteAccount ลบบัญชีของผู้ใช้
+// คลังข้อสอบของผู้ใช้ถูกลบ ส่วน quiz ยังอยู่ภายใต้ผู้ใช้ที่ไม่ระบุตัวตนและถูกยกเลิกการเผยแพร่
+// เพราะเกมและคำตอบของผู้เล่นคนอื่นอ้างถึง quiz คำถาม และตัวเลือกเหล่านั้น
+// ประวัติการเล่นเกมของผู้ใช้ยังอยู่แต่ไม่ระบุตัวตน เพื่อให้ผลคะแนนของเกมที่ผู้อื่นจัดไม่เปลี่ยนไป
+// ถ้าผู้ใช้เป็น owner คนเดียวขององค์กรใดจะคืนค่า ErrSoleOrganizationOwner และไม่ลบอะไรเลย
+func (s *AccountService) DeleteAccount(userID uint) error {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	// องค์กรต้องมี owner เหลืออย่างน้อยหนึ่งคนเพื่อให้ยังจัดการได้
+	soleOwned, err := s.accountRepo.GetSoleOwnedOrganizationIDs(userID)
+	if err != nil {
+		return err
+	}
+	if len(soleOwned) > 0 {
+		return ErrSoleOrganizationOwner
+	}
+
+	// เพิกถอน session ก่อน เพื่อไม่ให้ token ที่ออกไปแล้วใช้งานได้ระหว่างลบ
+	if err := s.sessionService.RevokeAllForUser(userID); err != nil {
+		return err
+	}
+
+	bankQuestions, err := s.accountRepo.GetBankQuestionsByOwner(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.accountRepo.AnonymizeUser(userID); err != nil {
+		return err
+	}
+
+	// ลบไฟล์หลังจากข้อมูลในฐานข้อมูลถูกลบสำเร็จแล้ว
+	for _, question := range bankQuestions {
+		_ = s.fileService.DeleteFileByURL(question.ImageURL)
+		for _, choice := range question.Choices {
+			_ = s.fileService.DeleteFileByURL(choice.ImageURL)
+		}
+	}
+	if user.AvatarURL != "" {
+		_ = s.fileService.DeleteFileByURL(user.AvatarURL)
+	}
+
+	log.Printf("account %d deleted and anonymized", userID)
+	return nil
+}
+
+// toProfileResponse แปลงผู้ใช้เป็นข้อมูลโปรไฟล์
+func toProfileResponse(user *models.User) dto.ProfileResponse {
+	return dto.ProfileResponse{
+		ID:            user.ID,
+		Email:         user.Email,
+		DisplayName:   user.DisplayName,
+		Nickname:      user.Nickname,
+		Picture:       user.Picture(),
+		AvatarURL:     user.AvatarURL,
+		Role:          user.Role,
+		EmailVerified: user.IsEmailVerified(),
+		HasPassword:   user.HasPassword(),
+		CreatedAt:     user.CreatedAt,
+	}
+}
+
+// toExportQuiz แปลง quiz เป็นรูปแบบเดียวกับ bundle โดยอ้างอิงไฟล์เป็น URL
+func toExportQuiz(quiz models.Quiz) dto.ExportQuiz {
+	result := dto.ExportQuiz{
+		ID:          quiz.ID,
+		IsPublished: quiz.IsPublished,
+		CreatedAt:   quiz.CreatedAt,
+		UpdatedAt:   quiz.UpdatedAt,
+		BundleQuiz: dto.BundleQuiz{
+			Title:       quiz.Title,
+			Description: quiz.Description,
+			TimeLimit:   quiz.TimeLimit,
+			Image:       quiz.ImageURL,
+			Categories:  make([]string, 0, len(quiz.Categories)),
+			Questions:   make([]dto.BundleQuestion, 0, len(quiz.Questions)),
+		},
+	}
+	for _, category := range quiz.Categories {
+		result.Categories = append(result.Categories, category.Name)
+	}
+
+	for _, question := range quiz.Questions {
+		bq := dto.BundleQuestion{
+			Text:             question.Text,
+			Image:            question.ImageURL,
+			Media:            question.MediaURL,
+			MediaType:        question.MediaType,
+			Explanation:      question.Explanation,
+			ExplanationImage: question.ExplanationImageURL,
+			TimeLimit:        question.TimeLimit,
+			Choices:          make([]dto.BundleChoice, 0, len(question.Choices)),
+		}
+		for _, choice := range question.Choices {
+			bq.Choices = append(bq.Choices, dto.BundleChoice{
+				Text:      choice.Text,
+				Image:     choice.ImageURL,
+				IsCorrect: choice.IsCorrect,
+			})
+		}
+		result.Questions = append(result.Questions, bq)
+	}
+	return result
+}
+
+// toExportBankQuestion แปลงคำถามในคลังข้อสอบเป็นรูปแบบสำหรับส่งออก
+func toExportBankQuestion(question models.BankQuestion) dto.ExportBankQuestion {
+	result := dto.ExportBankQuestion{
+		ID:          question.ID,
+		Text:        question.Text,
+		Image:       question.ImageURL,
+		Explanation: question.Explanation,
+		Tags:        make([]string, 0, len(question.Tags)),
+		Choices:     make([]dto.BundleChoice, 0, len(question.Choices)),
+		CreatedAt:   question.CreatedAt,
+	}
+	for _, tag := range question.Tags {
+		result.Tags = append(result.Tags, tag.Name)
+	}
+	for _, choice := range question.Choices {
+		result.Choices = append(result.Choices, dto.BundleChoice{
+			Text:      choice.Text,
+			Image:     choice.ImageURL,
+			IsCorrect: choice.IsCorrect,
+		})
+	}
+	return result
+}
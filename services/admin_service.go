@@ -0,0 +1,156 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/patiphanak/league-of-quiz/dto"
+	models "github.com/patiphanak/league-of-quiz/model"
+	"github.com/patiphanak/league-of-quiz/repositories"
+)
+
+// AdminService สำหรับงานของผู้ดูแลระบบ เช่น จัดการบัญชีผู้ใช้และดูแลเนื้อหา
+// route ของ service นี้ต้องผ่าน RequireRole(admin) เสมอ
+type AdminService struct {
+	userRepo     *repositories.UserRepository
+	quizRepo     *repositories.QuizRepository
+	quizService  *QuizService
+	tokenService *TokenService
+}
+
+// NewAdminService สร้าง instance ใหม่ของ AdminService
+func NewAdminService(
+	userRepo *repositories.UserRepository,
+	quizRepo *repositories.QuizRepository,
+	quizService *QuizService,
+	tokenService *TokenService,
+) *AdminService {
+	return &AdminService{
+		userRepo:     userRepo,
+		quizRepo:     quizRepo,
+		quizService:  quizService,
+		tokenService: tokenService,
+	}
+}
+
+// ListUsers ค้นหาผู้ใช้ทั้งหมดในระบบ
+func (s *AdminService) ListUsers(search string, role string, page, limit int) ([]dto.AdminUserResponse, int64, error) {
+	if role != "" && !models.IsValidUserRole(role) {
+		return nil, 0, errors.New("role must be one of user, teacher or admin")
+	}
+
+	users, count, err := s.userRepo.ListUsers(search, role, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]dto.AdminUserResponse, 0, len(users))
+	for _, user := range users {
+		result = append(result, toAdminUserResponse(user))
+	}
+	return result, count, nil
+}
+
+// UpdateUserRole เปลี่ยนบทบาทของผู้ใช้ ผู้ดูแลระบบเปลี่ยนบทบาทของตัวเองไม่ได้
+func (s *AdminService) UpdateUserRole(userID uint, role string, adminID uint) (*dto.AdminUserResponse, error) {
+	if !models.IsValidUserRole(role) {
+		return nil, errors.New("role must be one of user, teacher or admin")
+	}
+	if userID == adminID {
+		return nil, errors.New("you cannot change your own role")
+	}
+
+	if _, err := s.userRepo.GetUserByID(userID); err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.UpdateUserRole(userID, role); err != nil {
+		return nil, err
+	}
+	log.Printf("Admin %d changed role of user %d to %s", adminID, userID, role)
+
+	return s.getUserResponse(userID)
+}
+
+// DisableUser ระงับบัญชีผู้ใช้ ผู้ใช้จะถูกปฏิเสธทันทีแม้ยังมี token ที่ถูกต้อง
+func (s *AdminService) DisableUser(userID uint, adminID uint) (*dto.AdminUserResponse, error) {
+	if userID == adminID {
+		return nil, errors.New("you cannot disable your own account")
+	}
+
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsDisabled() {
+		now := time.Now()
+		if err := s.userRepo.SetUserDisabledAt(userID, &now); err != nil {
+			return nil, err
+		}
+		// เพิกถอน refresh token เพื่อไม่ให้ต่ออายุ access token ได้อีก
+		if err := s.tokenService.RevokeAllForUser(userID); err != nil {
+			return nil, err
+		}
+		log.Printf("Admin %d disabled user %d", adminID, userID)
+	}
+
+	return s.getUserResponse(userID)
+}
+
+// EnableUser ยกเลิกการระงับบัญชีผู้ใช้
+func (s *AdminService) EnableUser(userID uint, adminID uint) (*dto.AdminUserResponse, error) {
+	if _, err := s.userRepo.GetUserByID(userID); err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.SetUserDisabledAt(userID, nil); err != nil {
+		return nil, err
+	}
+	log.Printf("Admin %d enabled user %d", adminID, userID)
+
+	return s.getUserResponse(userID)
+}
+
+// UnpublishQuiz ยกเลิกการเผยแพร่ quiz ของผู้ใช้คนใดก็ได้
+func (s *AdminService) UnpublishQuiz(quizID uint, adminID uint) error {
+	if _, err := s.quizRepo.GetQuizByID(quizID); err != nil {
+		return err
+	}
+	if err := s.quizRepo.UpdateQuizWithMap(quizID, map[string]interface{}{"is_published": false}); err != nil {
+		return err
+	}
+	log.Printf("Admin %d unpublished quiz %d", adminID, quizID)
+	return nil
+}
+
+// DeleteQuiz ลบ quiz ที่ไม่เหมาะสมพร้อมไฟล์ที่เกี่ยวข้อง
+func (s *AdminService) DeleteQuiz(quizID uint, adminID uint) error {
+	// ผู้ดูแลระบบมีบทบาท owner ในทุก quiz จึงใช้ขั้นตอนลบเดียวกับเจ้าของได้
+	if err := s.quizService.DeleteQuiz(quizID, adminID); err != nil {
+		return err
+	}
+	log.Printf("Admin %d deleted quiz %d", adminID, quizID)
+	return nil
+}
+
+// getUserResponse ดึงข้อมูลผู้ใช้ล่าสุดในรูปแบบ response
+func (s *AdminService) getUserResponse(userID uint) (*dto.AdminUserResponse, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	response := toAdminUserResponse(*user)
+	return &response, nil
+}
+
+// toAdminUserResponse แปลงข้อมูลผู้ใช้เป็น response
+func toAdminUserResponse(user models.User) dto.AdminUserResponse {
+	return dto.AdminUserResponse{
+		ID:          user.ID,
+		Email:       user.Email,
+		DisplayName: user.DisplayName,
+		PictureURL:  user.PictureURL,
+		Role:        user.Role,
+		DisabledAt:  user.DisabledAt,
+		CreatedAt:   user.CreatedAt,
+	}
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/patiphanak/league-of-quiz/dto"
+	models "github.com/patiphanak/league-of-quiz/model"
+	"github.com/patiphanak/league-of-quiz/repositories"
+	"github.com/patiphanak/league-of-quiz/utils"
+)
+
+// CategoryService สำหรับการจัดการหมวดหมู่แบบลำดับชั้น
+type CategoryService struct {
+	categoryRepo *repositories.CategoryRepository
+}
+
+// NewCategoryService สร้าง instance ใหม่ของ CategoryService
+func NewCategoryService(categoryRepo *repositories.CategoryRepository) *CategoryService {
+	return &CategoryService{
+		categoryRepo: categoryRepo,
+	}
+}
+
+// GetCategories ดึงหมวดหมู่ทั้งหมดแบบรายการเดียว
+func (s *CategoryService) GetCategories() ([]models.Category, error) {
+	return s.categoryRepo.GetAllCategories()
+}
+
+// GetCategoryTree ดึงหมวดหมู่ทั้งหมดเป็นต้นไม้ โดยหมวดหมู่ลูกอยู่ใน Children
+func (s *CategoryService) GetCategoryTree() ([]models.Category, error) {
+	categories, err := s.categoryRepo.GetAllCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[uint][]models.Category)
+	exists := make(map[uint]bool, len(categories))
+	for _, category := range categories {
+		exists[category.ID] = true
+	}
+	var roots []models.Category
+	for _, category := range categories {
+		if category.ParentID != nil && exists[*category.ParentID] {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		} else {
+			roots = append(roots, category)
+		}
+	}
+
+	var attach func(nodes []models.Category) []models.Category
+	attach = func(nodes []models.Category) []models.Category {
+		for i := range nodes {
+			nodes[i].Children = attach(children[nodes[i].ID])
+		}
+		return nodes
+	}
+	return attach(roots), nil
+}
+
+// GetCategory ดึงหมวดหมู่จาก ID หรือ slug
+func (s *CategoryService) GetCategory(idOrSlug string) (*models.Category, error) {
+	if id, err := parseUintID(idOrSlug); err == nil {
+		return s.categoryRepo.GetCategoryByID(id)
+	}
+	return s.categoryRepo.GetCategoryBySlug(idOrSlug)
+}
+
+// CreateCategory สร้างหมวดหมู่ใหม่
+func (s *CategoryService) CreateCategory(req dto.CategoryRequest) (*models.Category, error) {
+	category := &models.Category{}
+	if err := s.applyCategoryRequest(category, req); err != nil {
+		return nil, err
+	}
+	if err := s.categoryRepo.CreateCategory(category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// UpdateCategory แก้ไขหมวดหมู่ รวมถึงย้ายไปอยู่ใต้หมวดหมู่แม่อื่น
+func (s *CategoryService) UpdateCategory(id uint, req dto.CategoryRequest) (*models.Category, error) {
+	category, err := s.categoryRepo.GetCategoryByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyCategoryRequest(category, req); err != nil {
+		return nil, err
+	}
+	if err := s.categoryRepo.UpdateCategory(category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// DeleteCategory ลบหมวดหมู่ หมวดหมู่ลูกจะย้ายขึ้นไปอยู่ใต้หมวดหมู่แม่ของหมวดหมู่ที่ถูกลบ
+func (s *CategoryService) DeleteCategory(id uint) error {
+	category, err := s.categoryRepo.GetCategoryByID(id)
+	if err != nil {
+		return err
+	}
+	return s.categoryRepo.DeleteCategory(category)
+}
+
+// applyCategoryRequest ตรวจสอบข้อมูลและกำหนดค่าให้หมวดหมู่
+func (s *CategoryService) applyCategoryRequest(category *models.Category, req dto.CategoryRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return errors.New("category name is required")
+	}
+
+	slug := utils.Slugify(req.Slug)
+	if slug == "" {
+		slug = utils.Slugify(name)
+	}
+	if slug == "" {
+		return errors.New("category slug is required")
+	}
+
+	duplicate, err := s.categoryRepo.ExistsByNameOrSlug(name, slug, category.ID)
+	if err != nil {
+		return err
+	}
+	if duplicate {
+		return errors.New("category name or slug already exists")
+	}
+
+	var parentID *uint
+	if req.ParentID != nil && *req.ParentID != 0 {
+		if category.ID != 0 && *req.ParentID == category.ID {
+			return errors.New("category cannot be its own parent")
+		}
+		if _, err := s.categoryRepo.GetCategoryByID(*req.ParentID); err != nil {
+			return errors.New("parent category not found")
+		}
+
+		// ป้องกันวงวน: หมวดหมู่แม่ใหม่ต้องไม่ใช่ลูกหลานของหมวดหมู่นี้
+		if category.ID != 0 {
+			descendants, err := s.categoryRepo.GetDescendantIDs(category.ID)
+			if err != nil {
+				return err
+			}
+			for _, descendantID := range descendants {
+				if descendantID == *req.ParentID {
+					return errors.New("category cannot be moved under its own descendant")
+				}
+			}
+		}
+		parentID = req.ParentID
+	}
+
+	category.Name = name
+	category.Description = strings.TrimSpace(req.Description)
+	category.Slug = slug
+	category.Icon = strings.TrimSpace(req.Icon)
+	category.ParentID = parentID
+	return nil
+}
+
+// parseUintID แปลงข้อความเป็น ID
+func parseUintID(value string) (uint, error) {
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
@@ -1,7 +1,6 @@
 package services
 
 import (
-	"errors"
 	"mime/multipart"
 
 	models "github.com/patiphanak/league-of-quiz/model"
@@ -39,13 +38,18 @@ func (s *ChoiceService) CreateChoice(choice *models.Choice, imageFile *multipart
 		return err
 	}
 
-	// ตรวจสอบว่าผู้ใช้เป็นเจ้าของ quiz หรือไม่
-	isOwner, err := s.quizRepo.CheckQuizOwnership(quizID, currentUserID)
-	if err != nil {
+	// ตรวจสอบว่าผู้ใช้มีสิทธิ์แก้ไข quiz หรือไม่
+	if err := authorizeQuiz(s.quizRepo, quizID, currentUserID, models.QuizRoleEditor); err != nil {
 		return err
 	}
-	if !isOwner {
-		return errors.New("unauthorized: you are not the owner of this quiz")
+
+	// กำหนดลำดับตัวเลือกต่อท้ายคำถาม ถ้าไม่ได้ระบุมา
+	if choice.Position == 0 {
+		position, err := s.choiceRepo.GetNextChoicePosition(choice.QuestionID)
+		if err != nil {
+			return err
+		}
+		choice.Position = position
 	}
 
 	// จัดการไฟล์รูปภาพ
@@ -85,14 +89,10 @@ func (s *ChoiceService) UpdateChoice(choice *models.Choice, imageFile *multipart
 		return err
 	}
 
-	// ตรวจสอบว่าผู้ใช้เป็นเจ้าของ quiz หรือไม่
-	isOwner, err := s.quizRepo.CheckQuizOwnership(quizID, currentUserID)
-	if err != nil {
+	// ตรวจสอบว่าผู้ใช้มีสิทธิ์แก้ไข quiz หรือไม่
+	if err := authorizeQuiz(s.quizRepo, quizID, currentUserID, models.QuizRoleEditor); err != nil {
 		return err
 	}
-	if !isOwner {
-		return errors.New("unauthorized: you are not the owner of this quiz")
-	}
 
 	// ป้องกันการเปลี่ยน QuestionID
 	choice.QuestionID = existingChoice.QuestionID
@@ -127,14 +127,10 @@ func (s *ChoiceService) DeleteChoice(choiceID uint, currentUserID uint) error {
 		return err
 	}
 
-	// ตรวจสอบว่าผู้ใช้เป็นเจ้าของ quiz หรือไม่
-	isOwner, err := s.quizRepo.CheckQuizOwnership(quizID, currentUserID)
-	if err != nil {
+	// ตรวจสอบว่าผู้ใช้มีสิทธิ์แก้ไข quiz หรือไม่
+	if err := authorizeQuiz(s.quizRepo, quizID, currentUserID, models.QuizRoleEditor); err != nil {
 		return err
 	}
-	if !isOwner {
-		return errors.New("unauthorized: you are not the owner of this quiz")
-	}
 
 	// ลบรูปภาพของตัวเลือกถ้ามี
 	if choice.ImageURL != "" {
@@ -0,0 +1,374 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/patiphanak/league-of-quiz/dto"
+	models "github.com/patiphanak/league-of-quiz/model"
+	"github.com/patiphanak/league-of-quiz/repositories"
+)
+
+// ErrNotInClassroom ผู้ใช้ไม่อยู่ในรายชื่อของห้องเรียนที่เกมจำกัดไว้
+var ErrNotInClassroom = errors.New("unauthorized: this game is restricted to a classroom roster")
+
+// ClassroomService สำหรับการจัดการห้องเรียน รายชื่อนักเรียน และผลเกมของห้องเรียน
+type ClassroomService struct {
+	classroomRepo *repositories.ClassroomRepository
+	orgRepo       *repositories.OrganizationRepository
+	userRepo      *repositories.UserRepository
+}
+
+// NewClassroomService สร้าง instance ใหม่ของ ClassroomService
+func NewClassroomService(
+	classroomRepo *repositories.ClassroomRepository,
+	orgRepo *repositories.OrganizationRepository,
+	userRepo *repositories.UserRepository,
+) *ClassroomService {
+	return &ClassroomService{
+		classroomRepo: classroomRepo,
+		orgRepo:       orgRepo,
+		userRepo:      userRepo,
+	}
+}
+
+// authorizeClassroom ตรวจสอบว่าผู้ใช้จัดการห้องเรียนได้ คือเป็นครูประจำห้อง หรือเป็น admin ขึ้นไปขององค์กร
+func (s *ClassroomService) authorizeClassroom(classroomID uint, userID uint) (*models.Classroom, error) {
+	classroom, err := s.classroomRepo.GetClassroomByID(classroomID)
+	if err != nil {
+		return nil, err
+	}
+	if classroom.TeacherID == userID {
+		return classroom, nil
+	}
+	if _, err := authorizeOrg(s.orgRepo, classroom.OrganizationID, userID, models.OrgRoleAdmin); err != nil {
+		return nil, errors.New("unauthorized: only the classroom teacher or an organization admin can manage this classroom")
+	}
+	return classroom, nil
+}
+
+// CanHost ตรวจสอบว่าผู้ใช้จัดเกมให้ห้องเรียนนี้ได้หรือไม่
+func (s *ClassroomService) CanHost(classroomID uint, userID uint) error {
+	_, err := s.authorizeClassroom(classroomID, userID)
+	return err
+}
+
+// CanJoin ตรวจสอบว่าผู้ใช้อยู่ในรายชื่อของห้องเรียน ครูประจำห้องเข้าร่วมได้เสมอ
+func (s *ClassroomService) CanJoin(classroomID uint, userID uint) error {
+	classroom, err := s.classroomRepo.GetClassroomByID(classroomID)
+	if err != nil {
+		return err
+	}
+	if classroom.TeacherID == userID {
+		return nil
+	}
+	member, err := s.classroomRepo.IsRosterMember(classroomID, userID)
+	if err != nil {
+		return err
+	}
+	if !member {
+		return ErrNotInClassroom
+	}
+	return nil
+}
+
+// CreateClassroom สร้างห้องเรียนในองค์กร ต้องเป็น teacher ขึ้นไป
+// การตั้งผู้อื่นเป็นครูประจำห้องต้องเป็น admin และครูคนนั้นต้องเป็น teacher ขึ้นไปในองค์กร
+func (s *ClassroomService) CreateClassroom(orgID uint, req dto.CreateClassroomRequest, userID uint) (*dto.ClassroomResponse, error) {
+	if _, err := authorizeOrg(s.orgRepo, orgID, userID, models.OrgRoleTeacher); err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("classroom name is required")
+	}
+
+	teacherID := userID
+	if req.TeacherID != 0 && req.TeacherID != userID {
+		if err := s.validateTeacher(orgID, req.TeacherID, userID); err != nil {
+			return nil, err
+		}
+		teacherID = req.TeacherID
+	}
+
+	classroom := &models.Classroom{
+		OrganizationID: orgID,
+		Name:           name,
+		TeacherID:      teacherID,
+	}
+	if err := s.classroomRepo.CreateClassroom(classroom); err != nil {
+		return nil, err
+	}
+
+	response := toClassroomResponse(*classroom)
+	return &response, nil
+}
+
+// validateTeacher ตรวจสอบการตั้งผู้ใช้อื่นเป็นครูประจำห้อง
+func (s *ClassroomService) validateTeacher(orgID uint, teacherID uint, userID uint) error {
+	if _, err := authorizeOrg(s.orgRepo, orgID, userID, models.OrgRoleAdmin); err != nil {
+		return errors.New("unauthorized: only an organization admin can assign another teacher")
+	}
+	member, err := s.orgRepo.GetMember(orgID, teacherID)
+	if err != nil || !models.OrgRoleAllows(member.Role, models.OrgRoleTeacher) {
+		return errors.New("teacher must be a teacher of this organization")
+	}
+	return nil
+}
+
+// ListClassrooms ดึงห้องเรียนทั้งหมดขององค์กร เฉพาะสมาชิกเท่านั้น
+func (s *ClassroomService) ListClassrooms(orgID uint, userID uint) ([]dto.ClassroomResponse, error) {
+	if _, err := authorizeOrg(s.orgRepo, orgID, userID, models.OrgRoleMember); err != nil {
+		return nil, err
+	}
+
+	classrooms, err := s.classroomRepo.GetClassroomsByOrganization(orgID)
+	if err != nil {
+		return nil, err
+	}
+	return toClassroomResponses(classrooms), nil
+}
+
+// ListMyClassrooms ดึงห้องเรียนที่ผู้ใช้สอนหรืออยู่ในรายชื่อ
+func (s *ClassroomService) ListMyClassrooms(userID uint) ([]dto.ClassroomResponse, error) {
+	classrooms, err := s.classroomRepo.GetClassroomsForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return toClassroomResponses(classrooms), nil
+}
+
+// GetClassroom ดึงข้อมูลห้องเรียน ผู้จัดการห้องเรียนและนักเรียนในรายชื่อดูได้
+func (s *ClassroomService) GetClassroom(classroomID uint, userID uint) (*dto.ClassroomResponse, error) {
+	classroom, err := s.authorizeClassroom(classroomID, userID)
+	if err != nil {
+		if joinErr := s.CanJoin(classroomID, userID); joinErr != nil {
+			return nil, err
+		}
+		if classroom, err = s.classroomRepo.GetClassroomByID(classroomID); err != nil {
+			return nil, err
+		}
+	}
+
+	response := toClassroomResponse(*classroom)
+	return &response, nil
+}
+
+// UpdateClassroom แก้ไขชื่อหรือครูประจำห้อง
+func (s *ClassroomService) UpdateClassroom(classroomID uint, req dto.UpdateClassroomRequest, userID uint) (*dto.ClassroomResponse, error) {
+	classroom, err := s.authorizeClassroom(classroomID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, errors.New("classroom name is required")
+		}
+		updates["name"] = name
+		classroom.Name = name
+	}
+	if req.TeacherID != nil && *req.TeacherID != classroom.TeacherID {
+		if err := s.validateTeacher(classroom.OrganizationID, *req.TeacherID, userID); err != nil {
+			return nil, err
+		}
+		updates["teacher_id"] = *req.TeacherID
+		classroom.TeacherID = *req.TeacherID
+	}
+
+	if len(updates) > 0 {
+		if err := s.classroomRepo.UpdateClassroom(classroomID, updates); err != nil {
+			return nil, err
+		}
+	}
+
+	response := toClassroomResponse(*classroom)
+	return &response, nil
+}
+
+// DeleteClassroom ลบห้องเรียน
+func (s *ClassroomService) DeleteClassroom(classroomID uint, userID uint) error {
+	if _, err := s.authorizeClassroom(classroomID, userID); err != nil {
+		return err
+	}
+	return s.classroomRepo.DeleteClassroom(classroomID)
+}
+
+// ListRoster ดึงรายชื่อนักเรียนของห้องเรียน
+func (s *ClassroomService) ListRoster(classroomID uint, userID uint) ([]dto.ClassroomMemberResponse, error) {
+	if _, err := s.authorizeClassroom(classroomID, userID); err != nil {
+		return nil, err
+	}
+
+	members, err := s.classroomRepo.GetRoster(classroomID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.ClassroomMemberResponse, 0, len(members))
+	for _, member := range members {
+		result = append(result, toClassroomMemberResponse(member))
+	}
+	return result, nil
+}
+
+// AddRosterMember เพิ่มผู้ใช้เข้ารายชื่อด้วยอีเมล ถ้ายังไม่เป็นสมาชิกขององค์กรจะถูกเพิ่มเป็น member ด้วย
+func (s *ClassroomService) AddRosterMember(classroomID uint, email string, userID uint) (*dto.ClassroomMemberResponse, error) {
+	classroom, err := s.authorizeClassroom(classroomID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return nil, errors.New("email is required")
+	}
+	user, err := s.userRepo.GetUserByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.orgRepo.EnsureMember(classroom.OrganizationID, user.ID); err != nil {
+		return nil, err
+	}
+	if err := s.classroomRepo.AddRosterMember(classroomID, user.ID); err != nil {
+		return nil, err
+	}
+
+	response := dto.ClassroomMemberResponse{
+		UserID:      user.ID,
+		Email:       user.Email,
+		DisplayName: user.DisplayName,
+	}
+	return &response, nil
+}
+
+// RemoveRosterMember ลบผู้ใช้ออกจากรายชื่อ
+func (s *ClassroomService) RemoveRosterMember(classroomID uint, memberID uint, userID uint) error {
+	if _, err := s.authorizeClassroom(classroomID, userID); err != nil {
+		return err
+	}
+	return s.classroomRepo.RemoveRosterMember(classroomID, memberID)
+}
+
+// GetResults รวบรวมผลของทุกเกมที่ผูกกับห้องเรียน พร้อมสรุปคะแนนรายนักเรียน
+// ผู้จัดเกมไม่นับเป็นผู้เล่น
+func (s *ClassroomService) GetResults(classroomID uint, userID uint) (*dto.ClassroomResults, error) {
+	if _, err := s.authorizeClassroom(classroomID, userID); err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.classroomRepo.GetClassroomSessions(classroomID)
+	if err != nil {
+		return nil, err
+	}
+	roster, err := s.classroomRepo.GetRoster(classroomID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := &dto.ClassroomResults{
+		ClassroomID: classroomID,
+		Games:       make([]dto.ClassroomGameResult, 0, len(sessions)),
+	}
+
+	// สรุปรายนักเรียน เริ่มจากทุกคนในรายชื่อ เพื่อให้เห็นนักเรียนที่ยังไม่เคยเล่นด้วย
+	students := make(map[uint]*dto.ClassroomStudentResult, len(roster))
+	for _, member := range roster {
+		students[member.UserID] = &dto.ClassroomStudentResult{
+			UserID:      member.UserID,
+			DisplayName: member.User.DisplayName,
+		}
+	}
+
+	for _, session := range sessions {
+		game := dto.ClassroomGameResult{
+			SessionID:  session.ID,
+			QuizID:     session.QuizID,
+			QuizTitle:  session.Quiz.Title,
+			HostID:     session.HostID,
+			Status:     session.Status,
+			StartedAt:  session.StartedAt,
+			FinishedAt: session.FinishedAt,
+			Players:    make([]dto.ClassroomGamePlayer, 0, len(session.Players)),
+		}
+
+		rank := 0
+		var lastScore uint
+		for _, player := range session.Players {
+			if player.UserID == session.HostID {
+				continue
+			}
+			if len(game.Players) == 0 || player.Score != lastScore {
+				rank = len(game.Players) + 1
+			}
+			lastScore = player.Score
+			game.Players = append(game.Players, dto.ClassroomGamePlayer{
+				UserID:   player.UserID,
+				Nickname: player.Nickname,
+				Score:    player.Score,
+				Rank:     rank,
+			})
+
+			student, ok := students[player.UserID]
+			if !ok {
+				// ผู้เล่นที่ถูกลบออกจากรายชื่อแล้วยังแสดงในสรุปเพื่อให้ผลย้อนหลังครบ
+				student = &dto.ClassroomStudentResult{UserID: player.UserID, DisplayName: player.Nickname}
+				students[player.UserID] = student
+			}
+			student.GamesPlayed++
+			student.TotalScore += player.Score
+		}
+		results.Games = append(results.Games, game)
+	}
+
+	results.Students = make([]dto.ClassroomStudentResult, 0, len(students))
+	for _, student := range students {
+		if student.GamesPlayed > 0 {
+			student.AverageScore = float64(student.TotalScore) / float64(student.GamesPlayed)
+		}
+		results.Students = append(results.Students, *student)
+	}
+	sort.Slice(results.Students, func(i, j int) bool {
+		if results.Students[i].TotalScore != results.Students[j].TotalScore {
+			return results.Students[i].TotalScore > results.Students[j].TotalScore
+		}
+		return results.Students[i].UserID < results.Students[j].UserID
+	})
+
+	return results, nil
+}
+
+// toClassroomResponse แปลงห้องเรียนเป็น response
+func toClassroomResponse(classroom models.Classroom) dto.ClassroomResponse {
+	return dto.ClassroomResponse{
+		ID:             classroom.ID,
+		OrganizationID: classroom.OrganizationID,
+		Name:           classroom.Name,
+		TeacherID:      classroom.TeacherID,
+		CreatedAt:      classroom.CreatedAt,
+	}
+}
+
+// toClassroomResponses แปลงรายการห้องเรียนเป็น response
+func toClassroomResponses(classrooms []models.Classroom) []dto.ClassroomResponse {
+	result := make([]dto.ClassroomResponse, 0, len(classrooms))
+	for _, classroom := range classrooms {
+		result = append(result, toClassroomResponse(classroom))
+	}
+	return result
+}
+
+// toClassroomMemberResponse แปลงนักเรียนในรายชื่อเป็น response
+func toClassroomMemberResponse(member models.ClassroomMember) dto.ClassroomMemberResponse {
+	return dto.ClassroomMemberResponse{
+		UserID:      member.UserID,
+		Email:       member.User.Email,
+		DisplayName: member.User.DisplayName,
+		CreatedAt:   member.CreatedAt,
+	}
+}
@@ -1,8 +1,10 @@
 package services
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"mime/multipart"
 	"os"
@@ -17,9 +19,21 @@ import (
 type FileType string
 
 const (
-	QuizType     FileType = "quiz"
-	QuestionType FileType = "question"
-	ChoiceType   FileType = "choice"
+	QuizType        FileType = "quiz"
+	QuestionType    FileType = "question"
+	ChoiceType      FileType = "choice"
+	ExplanationType FileType = "explanation"
+	MediaType       FileType = "media"
+	AvatarType      FileType = "avatar"
+)
+
+// MediaKind ประเภทของสื่อที่ตรวจพบจากเนื้อหาไฟล์
+type MediaKind string
+
+const (
+	ImageMedia MediaKind = "image"
+	AudioMedia MediaKind = "audio"
+	VideoMedia MediaKind = "video"
 )
 
 // FileService สำหรับการจัดการไฟล์
@@ -27,6 +41,10 @@ type FileService struct {
 	baseDir      string
 	allowedTypes []string
 	maxFileSize  int64
+	audioTypes   []string
+	maxAudioSize int64
+	videoTypes   []string
+	maxVideoSize int64
 	serverURL    string // เพิ่ม URL ของเซิร์ฟเวอร์
 }
 
@@ -40,8 +58,19 @@ func NewFileService(baseDir string) *FileService {
 			"image/gif",
 			"image/webp",
 		},
-		maxFileSize: 5 * 1024 * 1024,  // 5MB
-		serverURL:   "localhost:3000", // เก็บ URL ของเซิร์ฟเวอร์
+		maxFileSize: 5 * 1024 * 1024, // 5MB
+		audioTypes: []string{
+			"audio/mpeg",
+			"audio/ogg",
+			"audio/mp4",
+		},
+		maxAudioSize: 20 * 1024 * 1024, // 20MB
+		videoTypes: []string{
+			"video/mp4",
+			"video/webm",
+		},
+		maxVideoSize: 50 * 1024 * 1024, // 50MB
+		serverURL:    "localhost:3000", // เก็บ URL ของเซิร์ฟเวอร์
 	}
 }
 
@@ -55,71 +84,175 @@ func (s *FileService) IsAllowedFileType(contentType string) bool {
 	return false
 }
 
+// detectContentType ตรวจสอบประเภทไฟล์จาก magic bytes โดยไม่เชื่อ Content-Type ที่ client ส่งมา
+func detectContentType(header []byte) string {
+	switch {
+	case bytes.HasPrefix(header, []byte{0xFF, 0xD8, 0xFF}):
+		return "image/jpeg"
+	case bytes.HasPrefix(header, []byte("\x89PNG\r\n\x1a\n")):
+		return "image/png"
+	case bytes.HasPrefix(header, []byte("GIF87a")), bytes.HasPrefix(header, []byte("GIF89a")):
+		return "image/gif"
+	case len(header) >= 12 && bytes.Equal(header[0:4], []byte("RIFF")) && bytes.Equal(header[8:12], []byte("WEBP")):
+		return "image/webp"
+	case bytes.HasPrefix(header, []byte("ID3")):
+		return "audio/mpeg"
+	case len(header) >= 2 && header[0] == 0xFF && header[1]&0xE0 == 0xE0:
+		// MP3 frame sync ที่ไม่มี ID3 tag
+		return "audio/mpeg"
+	case bytes.HasPrefix(header, []byte("OggS")):
+		return "audio/ogg"
+	case bytes.HasPrefix(header, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return "video/webm"
+	case len(header) >= 12 && bytes.Equal(header[4:8], []byte("ftyp")):
+		// ISO base media file: แยกประเภทด้วย major brand ส่วน brand อื่น เช่น HEIC, AVIF และ 3GP ไม่รองรับ
+		brand := string(header[8:12])
+		if isoAudioBrands[brand] {
+			return "audio/mp4"
+		}
+		if isoVideoBrands[brand] {
+			return "video/mp4"
+		}
+	}
+	return "application/octet-stream"
+}
+
+// contentTypeExtensions นามสกุลไฟล์ที่ใช้บันทึกตาม content type ที่ตรวจพบ
+// ไม่ใช้นามสกุลจากชื่อไฟล์ของ client เพื่อไม่ให้ไฟล์ถูกเสิร์ฟเป็นประเภทอื่น เช่น .html หรือ .svg
+var contentTypeExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+	"audio/mpeg": ".mp3",
+	"audio/ogg":  ".ogg",
+	"audio/mp4":  ".m4a",
+	"video/mp4":  ".mp4",
+	"video/webm": ".webm",
+}
+
+// major brand ของไฟล์ ISO base media ที่รองรับ
+var (
+	isoAudioBrands = map[string]bool{"M4A ": true, "M4B ": true}
+	isoVideoBrands = map[string]bool{"isom": true, "iso2": true, "mp41": true, "mp42": true, "avc1": true, "M4V ": true}
+)
+
+// classifyContentType คืนค่าประเภทสื่อและขนาดสูงสุดที่อนุญาตของ content type นั้น
+func (s *FileService) classifyContentType(contentType string) (MediaKind, int64, bool) {
+	if s.IsAllowedFileType(contentType) {
+		return ImageMedia, s.maxFileSize, true
+	}
+	for _, allowed := range s.audioTypes {
+		if contentType == allowed {
+			return AudioMedia, s.maxAudioSize, true
+		}
+	}
+	for _, allowed := range s.videoTypes {
+		if contentType == allowed {
+			return VideoMedia, s.maxVideoSize, true
+		}
+	}
+	return "", 0, false
+}
+
 // UploadFile อัปโหลดไฟล์และคืนค่า URL
 func (s *FileService) UploadFile(file *multipart.FileHeader, fileType string) (string, error) {
+	fileURL, _, err := s.uploadFile(file, fileType)
+	return fileURL, err
+}
+
+// UploadMedia อัปโหลดไฟล์เสียงหรือวิดีโอ และคืนค่า URL พร้อมประเภทสื่อที่ตรวจพบ
+func (s *FileService) UploadMedia(file *multipart.FileHeader) (string, MediaKind, error) {
+	return s.uploadFile(file, string(MediaType))
+}
+
+// uploadFile ตรวจสอบและบันทึกไฟล์ที่อัปโหลดผ่าน multipart form ลง storage
+func (s *FileService) uploadFile(file *multipart.FileHeader, fileType string) (string, MediaKind, error) {
 	if file == nil {
-		return "", nil
+		return "", "", nil
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return "", "", err
 	}
+	defer src.Close()
 
+	return s.storeFile(src, file.Size, fileType)
+}
+
+// UploadFromReader ตรวจสอบและบันทึกไฟล์จาก reader ลง storage ด้วยกฎเดียวกับการอัปโหลดปกติ
+// ใช้กับไฟล์ที่ไม่ได้มาจาก multipart form เช่น ไฟล์ใน archive ที่นำเข้า
+func (s *FileService) UploadFromReader(src io.Reader, size int64, fileType string) (string, MediaKind, error) {
+	return s.storeFile(src, size, fileType)
+}
+
+// storeFile ตรวจสอบประเภทและขนาดไฟล์ แล้วบันทึกลง storage
+func (s *FileService) storeFile(src io.Reader, size int64, fileType string) (string, MediaKind, error) {
 	// ตรวจสอบว่า fileType ถูกต้อง
-	if fileType != string(QuizType) && fileType != string(QuestionType) && fileType != string(ChoiceType) {
-		return "", errors.New("invalid file type category")
+	if !isKnownFileType(fileType) {
+		return "", "", errors.New("invalid file type category")
+	}
+
+	// ตรวจสอบประเภทไฟล์จากเนื้อหาจริง
+	header := make([]byte, 512)
+	n, err := io.ReadFull(src, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", "", err
+	}
+	header = header[:n]
+	contentType := detectContentType(header)
+
+	kind, maxSize, ok := s.classifyContentType(contentType)
+	if !ok {
+		return "", "", errors.New("file type not allowed")
 	}
 
-	// ตรวจสอบขนาดไฟล์
-	if file.Size > s.maxFileSize {
-		return "", errors.New("file size exceeds the maximum limit of 5MB")
+	// ไฟล์ media ต้องเป็นเสียงหรือวิดีโอ ส่วนประเภทอื่นต้องเป็นรูปภาพ
+	if fileType == string(MediaType) && kind == ImageMedia {
+		return "", "", errors.New("media attachments must be audio or video")
+	}
+	if fileType != string(MediaType) && kind != ImageMedia {
+		return "", "", errors.New("file type not allowed")
 	}
 
-	// ตรวจสอบประเภทไฟล์
-	contentType := file.Header.Get("Content-Type")
-	if !s.IsAllowedFileType(contentType) {
-		return "", errors.New("file type not allowed")
+	// ตรวจสอบขนาดไฟล์ตามประเภทสื่อ
+	if size > maxSize {
+		return "", "", fmt.Errorf("file size exceeds the maximum limit of %dMB for %s", maxSize/(1024*1024), kind)
 	}
 
-	// สร้างชื่อไฟล์ไม่ซ้ำ
-	filename := uuid.New().String() + filepath.Ext(file.Filename)
+	// สร้างชื่อไฟล์ไม่ซ้ำ นามสกุลมาจากประเภทไฟล์ที่ตรวจพบ
+	filename := uuid.New().String() + contentTypeExtensions[contentType]
 
 	// สร้างเส้นทางโฟลเดอร์
 	uploadDir := filepath.Join(s.baseDir, fileType)
 	if err := os.MkdirAll(uploadDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create upload directory: %w", err)
+		return "", "", fmt.Errorf("failed to create upload directory: %w", err)
 	}
 
 	// บันทึกไฟล์
 	dst := filepath.Join(uploadDir, filename)
-	src, err := file.Open()
-	if err != nil {
-		return "", err
-	}
-	defer src.Close()
-
 	out, err := os.Create(dst)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 	defer out.Close()
 
-	// อ่านข้อมูลจาก source และเขียนไปยัง destination
-	buf := make([]byte, 1024)
-	for {
-		n, err := src.Read(buf)
-		if err != nil && err.Error() != "EOF" {
-			return "", err
-		}
-		if n == 0 {
-			break
-		}
-
-		if _, err := out.Write(buf[:n]); err != nil {
-			return "", err
-		}
+	// เขียนส่วนหัวที่อ่านไปแล้วตามด้วยข้อมูลที่เหลือ โดยจำกัดขนาดไม่ให้เกินที่อนุญาต
+	written, err := io.Copy(out, io.LimitReader(io.MultiReader(bytes.NewReader(header), src), maxSize+1))
+	if err != nil {
+		return "", "", err
+	}
+	if written > maxSize {
+		out.Close()
+		_ = os.Remove(dst)
+		return "", "", fmt.Errorf("file size exceeds the maximum limit of %dMB for %s", maxSize/(1024*1024), kind)
 	}
 
 	// สร้าง URL ในรูปแบบที่ถูกต้อง
 	fileURL := fmt.Sprintf("%s/storage/%s/%s", s.serverURL, fileType, filename)
 
-	return fileURL, nil
+	return fileURL, kind, nil
 }
 
 // DeleteFile ลบไฟล์จากชื่อไฟล์และประเภท
@@ -129,7 +262,10 @@ func (s *FileService) DeleteFile(filename string, fileType string) error {
 	}
 
 	// สร้างเส้นทางไฟล์
-	filePath := filepath.Join(s.baseDir, fileType, filename)
+	filePath, err := s.resolvePath(fileType, filename)
+	if err != nil {
+		return err
+	}
 
 	// ตรวจสอบว่าไฟล์มีอยู่หรือไม่
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
@@ -146,7 +282,8 @@ func (s *FileService) DeleteFile(filename string, fileType string) error {
 
 // DeleteFileByURL ลบไฟล์จาก URL
 func (s *FileService) DeleteFileByURL(fileURL string) error {
-	if fileURL == "" {
+	// ไฟล์ภายนอกไม่ได้อยู่ใน storage ของระบบ จึงไม่มีอะไรต้องลบ
+	if fileURL == "" || !s.IsStoredFile(fileURL) {
 		return nil
 	}
 
@@ -178,15 +315,23 @@ func (s *FileService) GetFilePath(fileURL string) (string, string, error) {
 	filename := parts[1]
 
 	// สร้างเส้นทางไฟล์
-	filePath := filepath.Join(s.baseDir, fileType, filename)
+	filePath, err := s.resolvePath(fileType, filename)
+	if err != nil {
+		return "", "", err
+	}
 
 	return filePath, fileType, nil
 }
 
+// ExtractInfoFromURL แยกชื่อไฟล์และประเภทจาก URL ของไฟล์ใน storage
+// URL ภายนอก หรือ URL ที่ชี้ออกนอกโฟลเดอร์ storage จะคืนค่า error
 func (s *FileService) ExtractInfoFromURL(fileURL string) (string, string, error) {
 	if fileURL == "" {
 		return "", "", nil
 	}
+	if !s.IsStoredFile(fileURL) {
+		return "", "", fmt.Errorf("file URL is not in storage: %s", fileURL)
+	}
 
 	// Regular expression to extract file type and filename from URL
 	// Pattern matches: [anything]/storage/[filetype]/[filename]
@@ -199,9 +344,56 @@ func (s *FileService) ExtractInfoFromURL(fileURL string) (string, string, error)
 
 	fileType := matches[1] // e.g., "quiz"
 	filename := matches[2] // e.g., "5f93c498-050d-4fb6-8a0f-416ec7d1876f.jpg"
+	if _, err := s.resolvePath(fileType, filename); err != nil {
+		return "", "", err
+	}
 	return filename, fileType, nil
 }
 
+// isKnownFileType ตรวจสอบว่าเป็นประเภทไฟล์ที่ระบบรู้จัก
+func isKnownFileType(fileType string) bool {
+	switch FileType(fileType) {
+	case QuizType, QuestionType, ChoiceType, ExplanationType, MediaType, AvatarType:
+		return true
+	default:
+		return false
+	}
+}
+
+// resolvePath สร้างเส้นทางไฟล์ใน storage จากประเภทและชื่อไฟล์
+// ปฏิเสธประเภทที่ไม่รู้จัก ชื่อไฟล์ที่เป็น . หรือ .. หรือมีตัวคั่นโฟลเดอร์ และเส้นทางที่อยู่นอก baseDir
+func (s *FileService) resolvePath(fileType, filename string) (string, error) {
+	if !isKnownFileType(fileType) {
+		return "", fmt.Errorf("invalid file type: %s", fileType)
+	}
+	if filename == "" || filename == "." || filename == ".." || strings.ContainsAny(filename, `/\`) {
+		return "", fmt.Errorf("invalid filename: %s", filename)
+	}
+
+	filePath := filepath.Join(s.baseDir, fileType, filename)
+	rel, err := filepath.Rel(s.baseDir, filePath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid file path: %s/%s", fileType, filename)
+	}
+	return filePath, nil
+}
+
+// ValidateStoredFileURL ตรวจสอบว่า URL ชี้ไปยังไฟล์ที่มีอยู่จริงใน storage ของระบบนี้
+func (s *FileService) ValidateStoredFileURL(fileURL string) error {
+	filename, fileType, err := s.ExtractInfoFromURL(fileURL)
+	if err != nil {
+		return err
+	}
+	filePath, err := s.resolvePath(fileType, filename)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(filePath); err != nil {
+		return fmt.Errorf("file not found: %s", fileURL)
+	}
+	return nil
+}
+
 // UpdateFile อัปเดตไฟล์โดยลบไฟล์เก่าและอัปโหลดไฟล์ใหม่
 func (s *FileService) UpdateFile(file *multipart.FileHeader, oldFileURL string, fileType string) (string, error) {
 	// ถ้าไม่มีไฟล์ใหม่และไม่มี URL เก่า
@@ -223,3 +415,88 @@ func (s *FileService) UpdateFile(file *multipart.FileHeader, oldFileURL string,
 	// อัปโหลดไฟล์ใหม่
 	return s.UploadFile(file, fileType)
 }
+
+// CopyFileByURL คัดลอกไฟล์จาก URL เดิมไปเป็นไฟล์ใหม่ในประเภทที่กำหนด และคืนค่า URL ใหม่
+// ใช้เมื่อข้อมูลที่คัดลอกต้องมีไฟล์เป็นของตัวเอง เพื่อไม่ให้การลบต้นฉบับกระทบสำเนา
+// URL ภายนอกที่ไม่ได้อยู่ใน storage ของระบบจะถูกคืนค่าเดิม
+func (s *FileService) CopyFileByURL(fileURL string, fileType string) (string, error) {
+	if fileURL == "" {
+		return "", nil
+	}
+	if !s.IsStoredFile(fileURL) {
+		return fileURL, nil
+	}
+
+	filename, sourceType, err := s.ExtractInfoFromURL(fileURL)
+	if err != nil {
+		return "", err
+	}
+
+	sourcePath, err := s.resolvePath(sourceType, filename)
+	if err != nil {
+		return "", err
+	}
+	if !isKnownFileType(fileType) {
+		return "", errors.New("invalid file type category")
+	}
+
+	src, err := os.Open(sourcePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open source file: %w", err)
+	}
+	defer src.Close()
+
+	uploadDir := filepath.Join(s.baseDir, fileType)
+	if err := os.MkdirAll(uploadDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create upload directory: %w", err)
+	}
+
+	// ใช้นามสกุลตามประเภทไฟล์ที่ตรวจพบ ถ้าตรวจไม่ได้จึงใช้นามสกุลของไฟล์ต้นฉบับ
+	header := make([]byte, 512)
+	n, err := io.ReadFull(src, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	header = header[:n]
+	ext, ok := contentTypeExtensions[detectContentType(header)]
+	if !ok {
+		ext = filepath.Ext(filename)
+	}
+
+	newFilename := uuid.New().String() + ext
+	out, err := os.Create(filepath.Join(uploadDir, newFilename))
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, io.MultiReader(bytes.NewReader(header), src)); err != nil {
+		return "", fmt.Errorf("failed to copy file: %w", err)
+	}
+
+	return fmt.Sprintf("%s/storage/%s/%s", s.serverURL, fileType, newFilename), nil
+}
+
+// OpenFileByURL เปิดไฟล์ใน storage จาก URL เพื่ออ่านเนื้อหา คืนค่า reader และชื่อไฟล์
+func (s *FileService) OpenFileByURL(fileURL string) (io.ReadCloser, string, error) {
+	filename, fileType, err := s.ExtractInfoFromURL(fileURL)
+	if err != nil {
+		return nil, "", err
+	}
+
+	filePath, err := s.resolvePath(fileType, filename)
+	if err != nil {
+		return nil, "", err
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open file: %w", err)
+	}
+	return file, filename, nil
+}
+
+// IsStoredFile ตรวจสอบว่า URL ชี้ไปยังไฟล์ใน storage ของระบบนี้หรือไม่
+func (s *FileService) IsStoredFile(fileURL string) bool {
+	return strings.HasPrefix(fileURL, s.serverURL+"/storage/") || strings.HasPrefix(fileURL, "/storage/")
+}
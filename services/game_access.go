@@ -0,0 +1,336 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/patiphanak/league-of-quiz/dto"
+	models "github.com/patiphanak/league-of-quiz/model"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// ข้อผิดพลาดของการเข้าร่วมเกมที่ถูกจำกัด ขึ้นต้นด้วย unauthorized เพื่อให้ handler ตอบ 403
+var (
+	ErrNotAllowedToJoin    = errors.New("unauthorized: you are not on the list of players allowed to join this game")
+	ErrInvalidJoinPassword = errors.New("unauthorized: invalid join password")
+	ErrJoinDenied          = errors.New("unauthorized: the host declined your join request")
+	ErrTooManyJoinAttempts = errors.New("too many wrong join passwords, please try again later")
+)
+
+// ข้อผิดพลาดของล็อบบี้ที่ปิดรับผู้เล่นหรือเต็มแล้ว
+var (
+	ErrLobbyLocked       = errors.New("the lobby is locked, the host is not accepting new players")
+	ErrSessionFull       = errors.New("the game session is full")
+	ErrInvalidMaxPlayers = errors.New("max players must be zero (no limit) or a positive number")
+)
+
+// maxJoinPasswordLength ความยาวสูงสุดของรหัสผ่านเข้าร่วมเกม (bcrypt ใช้แค่ 72 byte แรก)
+const maxJoinPasswordLength = 72
+
+// joinThrottleWindow ช่วงเวลาที่นับจำนวนครั้งที่ใส่รหัสผ่านเข้าร่วมเกมผิด
+const joinThrottleWindow = 10 * time.Minute
+
+// JoinEventListener รับเหตุการณ์ของคำขอเข้าร่วมเกมที่ต้องรอโฮสต์อนุมัติ
+// WebSocket manager ลงทะเบียนตัวเองเพื่อส่งคำขอไปยังโฮสต์และแจ้งผลให้ผู้เล่น
+// ไม่ว่าคำขอจะมาจาก REST หรือ WebSocket
+type JoinEventListener interface {
+	JoinRequested(request models.GameJoinRequest, hostID uint)
+	JoinDecided(request models.GameJoinRequest, player *models.GamePlayer)
+}
+
+// GameSessionAccess การจำกัดการเข้าร่วมที่โฮสต์กำหนดตอนสร้างเกม
+type GameSessionAccess struct {
+	AllowedUserIDs  []uint
+	Password        string
+	RequireApproval bool
+}
+
+// SetJoinEventListener ลงทะเบียนผู้รับเหตุการณ์ของคำขอเข้าร่วมเกม
+func (s *GameService) SetJoinEventListener(listener JoinEventListener) {
+	s.joinListener = listener
+}
+
+// hashJoinPassword สร้าง hash ของรหัสผ่านเข้าร่วมเกม รหัสผ่านว่างหมายถึงไม่ใช้รหัสผ่าน
+func hashJoinPassword(password string) (string, error) {
+	if password == "" {
+		return "", nil
+	}
+	if len(password) > maxJoinPasswordLength {
+		return "", fmt.Errorf("join password must be at most %d bytes", maxJoinPasswordLength)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// requireHost ดึงเกมและตรวจสอบว่าผู้ใช้เป็นโฮสต์ของเกม
+func (s *GameService) requireHost(sessionID string, hostID uint) (*models.GameSession, error) {
+	session, err := s.gameSessionRepo.GetGameSessionByID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.HostID != hostID {
+		return nil, errors.New("unauthorized: only the host can manage who joins this game")
+	}
+	return session, nil
+}
+
+// checkJoinAccess ตรวจรายชื่อที่อนุญาตและรหัสผ่านของเกม โฮสต์ผ่านเสมอ
+// ถ้าเกมมีทั้งห้องเรียนและรายชื่อที่อนุญาต ผู้ที่อยู่ในอย่างใดอย่างหนึ่งเข้าร่วมได้
+func (s *GameService) checkJoinAccess(session *models.GameSession, userID uint, password string) error {
+	if session.HostID == userID {
+		return nil
+	}
+
+	allowedCount, err := s.gameSessionRepo.CountAllowedUsers(session.ID)
+	if err != nil {
+		return err
+	}
+	if session.ClassroomID != nil || allowedCount > 0 {
+		allowed := false
+		if allowedCount > 0 {
+			if allowed, err = s.gameSessionRepo.IsAllowedUser(session.ID, userID); err != nil {
+				return err
+			}
+		}
+		if !allowed && session.ClassroomID != nil {
+			err := s.classroomService.CanJoin(*session.ClassroomID, userID)
+			if err != nil && !errors.Is(err, ErrNotInClassroom) {
+				return err
+			}
+			allowed = err == nil
+		}
+		if !allowed {
+			if allowedCount == 0 {
+				return ErrNotInClassroom
+			}
+			return ErrNotAllowedToJoin
+		}
+	}
+
+	if session.HasJoinPassword() {
+		// จำกัดการเดารหัสผ่าน 5 ครั้งต่อเกมและ 20 ครั้งต่อผู้ใช้
+		attemptKey := fmt.Sprintf("%s:%d", session.ID, userID)
+		userKey := fmt.Sprintf("%d", userID)
+		if wait := s.joinThrottle.Reserve(attemptKey, userKey); wait > 0 {
+			return ErrTooManyJoinAttempts
+		}
+		if bcrypt.CompareHashAndPassword([]byte(session.JoinPasswordHash), []byte(password)) != nil {
+			return ErrInvalidJoinPassword
+		}
+		s.joinThrottle.Release(attemptKey, userKey)
+	}
+	return nil
+}
+
+// requestApproval สร้างคำขอเข้าร่วมที่รอโฮสต์อนุมัติ หรือคืนค่าคำขอเดิมที่ยังรออยู่
+func (s *GameService) requestApproval(session *models.GameSession, userID uint, nickname string) (*models.GameJoinRequest, error) {
+	request, err := s.joinRequestRepo.GetJoinRequest(session.ID, userID)
+	if err == nil {
+		if request.Status == models.JoinRequestDenied {
+			return nil, ErrJoinDenied
+		}
+		return request, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	request = &models.GameJoinRequest{
+		SessionID: session.ID,
+		UserID:    userID,
+		Nickname:  nickname,
+		Status:    models.JoinRequestPending,
+	}
+	if err := s.joinRequestRepo.CreateJoinRequest(request); err != nil {
+		// คำขอซ้ำที่ส่งเข้ามาพร้อมกันจะชน unique index ให้ใช้คำขอที่บันทึกไว้แล้ว
+		if existing, getErr := s.joinRequestRepo.GetJoinRequest(session.ID, userID); getErr == nil {
+			return existing, nil
+		}
+		return nil, err
+	}
+
+	if s.joinListener != nil {
+		s.joinListener.JoinRequested(*request, session.HostID)
+	}
+	return request, nil
+}
+
+// GetSessionAccess ดึงการจำกัดการเข้าร่วมของเกม เฉพาะโฮสต์
+func (s *GameService) GetSessionAccess(sessionID string, hostID uint) (*dto.GameSessionAccessResponse, error) {
+	session, err := s.requireHost(sessionID, hostID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := s.gameSessionRepo.GetAllowedUserIDs(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	playerCount, err := s.gamePlayerRepo.CountPlayersExcludingUser(sessionID, session.HostID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.GameSessionAccessResponse{
+		SessionID:       session.ID,
+		ClassroomID:     session.ClassroomID,
+		AllowedUserIDs:  allowed,
+		HasPassword:     session.HasJoinPassword(),
+		RequireApproval: session.RequireApproval,
+		LobbyLocked:     session.LobbyLocked,
+		MaxPlayers:      session.MaxPlayers,
+		PlayerCount:     playerCount,
+	}, nil
+}
+
+// UpdateSessionAccess เปลี่ยนการจำกัดการเข้าร่วมของเกม เฉพาะโฮสต์และเฉพาะตอนที่ยังอยู่ใน lobby
+// ผู้เล่นที่เข้าร่วมแล้วไม่ถูกนำออก การเปลี่ยนมีผลกับผู้ที่เข้าร่วมหลังจากนี้
+func (s *GameService) UpdateSessionAccess(sessionID string, hostID uint, req dto.UpdateGameSessionAccessRequest) (*dto.GameSessionAccessResponse, error) {
+	session, err := s.requireHost(sessionID, hostID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != "lobby" {
+		return nil, errors.New("join restrictions can only be changed while the game is in the lobby")
+	}
+
+	updates := map[string]interface{}{}
+	if req.ClassroomID != nil {
+		if *req.ClassroomID == 0 {
+			updates["classroom_id"] = nil
+		} else {
+			if err := s.classroomService.CanHost(*req.ClassroomID, hostID); err != nil {
+				return nil, err
+			}
+			updates["classroom_id"] = *req.ClassroomID
+		}
+	}
+	if req.Password != nil {
+		hash, err := hashJoinPassword(*req.Password)
+		if err != nil {
+			return nil, err
+		}
+		updates["join_password_hash"] = hash
+	}
+	if req.RequireApproval != nil {
+		updates["require_approval"] = *req.RequireApproval
+	}
+	// ลดจำนวนสูงสุดให้ต่ำกว่าผู้เล่นปัจจุบันได้ ผู้เล่นเดิมไม่ถูกนำออกแต่จะรับผู้เล่นใหม่ไม่ได้
+	if req.MaxPlayers != nil {
+		if *req.MaxPlayers < 0 {
+			return nil, ErrInvalidMaxPlayers
+		}
+		updates["max_players"] = *req.MaxPlayers
+	}
+
+	if len(updates) > 0 {
+		if err := s.gameSessionRepo.UpdateSessionAccess(sessionID, updates); err != nil {
+			return nil, err
+		}
+	}
+	if req.AllowedUserIDs != nil {
+		if err := s.gameSessionRepo.ReplaceAllowedUsers(sessionID, uniqueUserIDs(*req.AllowedUserIDs, hostID)); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetSessionAccess(sessionID, hostID)
+}
+
+// SetLobbyLocked ล็อกหรือปลดล็อกล็อบบี้ เฉพาะโฮสต์และเฉพาะตอนที่ยังอยู่ใน lobby
+// ล็อบบี้ที่ถูกล็อกไม่รับผู้เล่นใหม่ แต่โฮสต์ยังอนุมัติคำขอที่รออยู่แล้วได้
+func (s *GameService) SetLobbyLocked(sessionID string, hostID uint, locked bool) (*models.GameSession, error) {
+	session, err := s.requireHost(sessionID, hostID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != "lobby" {
+		return nil, errors.New("the lobby can only be locked or unlocked before the game starts")
+	}
+
+	if err := s.gameSessionRepo.UpdateSessionAccess(sessionID, map[string]interface{}{"lobby_locked": locked}); err != nil {
+		return nil, err
+	}
+	session.LobbyLocked = locked
+	return session, nil
+}
+
+// ListJoinRequests ดึงคำขอเข้าร่วมที่รอโฮสต์อนุมัติ
+func (s *GameService) ListJoinRequests(sessionID string, hostID uint) ([]models.GameJoinRequest, error) {
+	if _, err := s.requireHost(sessionID, hostID); err != nil {
+		return nil, err
+	}
+	return s.joinRequestRepo.GetPendingJoinRequests(sessionID)
+}
+
+// ApproveJoinRequest อนุมัติคำขอเข้าร่วม และเพิ่มผู้เล่นเข้าเกมทันทีโดยไม่ต้องส่งคำขอใหม่
+func (s *GameService) ApproveJoinRequest(sessionID string, hostID uint, userID uint) (*models.GamePlayer, error) {
+	session, err := s.requireHost(sessionID, hostID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != "lobby" {
+		return nil, errors.New("ไม่สามารถเข้าร่วมได้: เกมได้เริ่มต้นหรือจบไปแล้ว")
+	}
+
+	request, err := s.joinRequestRepo.GetJoinRequest(sessionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != models.JoinRequestPending {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	// เพิ่มผู้เล่นก่อนเปลี่ยนสถานะ ถ้าเกมเต็มคำขอจะยังรออยู่ให้อนุมัติใหม่ได้เมื่อมีที่ว่าง
+	player, err := s.addPlayer(sessionID, userID, request.Nickname, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.joinRequestRepo.DecideJoinRequest(sessionID, userID, models.JoinRequestApproved); err != nil {
+		return nil, err
+	}
+	request.Status = models.JoinRequestApproved
+
+	if s.joinListener != nil {
+		s.joinListener.JoinDecided(*request, player)
+	}
+	return player, nil
+}
+
+// DenyJoinRequest ปฏิเสธคำขอเข้าร่วม ผู้ใช้ที่ถูกปฏิเสธส่งคำขอใหม่ในเกมเดิมไม่ได้
+func (s *GameService) DenyJoinRequest(sessionID string, hostID uint, userID uint) error {
+	if _, err := s.requireHost(sessionID, hostID); err != nil {
+		return err
+	}
+
+	request, err := s.joinRequestRepo.GetJoinRequest(sessionID, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.joinRequestRepo.DecideJoinRequest(sessionID, userID, models.JoinRequestDenied); err != nil {
+		return err
+	}
+	request.Status = models.JoinRequestDenied
+
+	if s.joinListener != nil {
+		s.joinListener.JoinDecided(*request, nil)
+	}
+	return nil
+}
+
+// uniqueUserIDs ตัด ID ที่ซ้ำและ ID ของโฮสต์ออกจากรายชื่อที่อนุญาต
+func uniqueUserIDs(userIDs []uint, hostID uint) []uint {
+	seen := make(map[uint]bool, len(userIDs))
+	result := make([]uint, 0, len(userIDs))
+	for _, id := range userIDs {
+		if id == 0 || id == hostID || seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+	}
+	return result
+}
@@ -3,11 +3,14 @@ package services
 import (
 	"errors"
 	"log"
+	"math/rand"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/patiphanak/league-of-quiz/dto"
 	models "github.com/patiphanak/league-of-quiz/model"
 	"github.com/patiphanak/league-of-quiz/repositories"
+	"gorm.io/gorm/clause"
 )
 
 // GameService จัดการ business logic ของเกม
@@ -17,6 +20,11 @@ type GameService struct {
 	gamePlayerRepo   *repositories.GamePlayerRepository
 	playerAnswerRepo *repositories.PlayerAnswerRepository
 	choiceRepo       *repositories.ChoiceRepository
+	joinRequestRepo  *repositories.GameJoinRequestRepository
+	gameTeamRepo     *repositories.GameTeamRepository
+	classroomService *ClassroomService
+	joinListener     JoinEventListener
+	joinThrottle     *LoginThrottle
 }
 
 // NewGameService สร้าง GameService ใหม่
@@ -26,6 +34,9 @@ func NewGameService(
 	gamePlayerRepo *repositories.GamePlayerRepository,
 	playerAnswerRepo *repositories.PlayerAnswerRepository,
 	choiceRepo *repositories.ChoiceRepository,
+	joinRequestRepo *repositories.GameJoinRequestRepository,
+	gameTeamRepo *repositories.GameTeamRepository,
+	classroomService *ClassroomService,
 ) *GameService {
 	return &GameService{
 		repos:            repos,
@@ -33,23 +44,93 @@ func NewGameService(
 		gamePlayerRepo:   gamePlayerRepo,
 		playerAnswerRepo: playerAnswerRepo,
 		choiceRepo:       choiceRepo,
+		joinRequestRepo:  joinRequestRepo,
+		gameTeamRepo:     gameTeamRepo,
+		classroomService: classroomService,
+		joinThrottle:     NewLoginThrottle(joinThrottleWindow, 5, 20),
 	}
 }
 
+// GameSessionOptions ตัวเลือกที่โฮสต์กำหนดตอนสร้างเกม
+type GameSessionOptions struct {
+	ShuffleQuestions bool
+	ShuffleChoices   bool
+	ShuffleMode      string // room หรือ player
+	ClassroomID      *uint  // จำกัดผู้เข้าร่วมเฉพาะรายชื่อของห้องเรียน
+	MaxPlayers       int    // จำนวนผู้เล่นสูงสุดไม่นับโฮสต์ 0 หมายถึงไม่จำกัด
+	Access           GameSessionAccess
+	Teams            GameTeamSettings
+}
+
 // CreateGameSession สร้าง session เกมใหม่ ด้วย transaction
-func (s *GameService) CreateGameSession(hostID uint, quizID uint) (*models.GameSession, error) {
+func (s *GameService) CreateGameSession(hostID uint, quizID uint, options GameSessionOptions) (*models.GameSession, error) {
 	// สร้าง ID สำหรับ session
 	log.Println("CreateGameSession Service layer")
 	sessionID := uuid.New().String()
 
+	shuffleMode := options.ShuffleMode
+	if shuffleMode == "" {
+		shuffleMode = ShuffleRoom
+	}
+	if shuffleMode != ShuffleRoom && shuffleMode != ShufflePlayer {
+		return nil, errors.New("shuffle mode ต้องเป็น room หรือ player")
+	}
+
+	// จัดเกมได้เฉพาะ quiz ที่โฮสต์มีบทบาท หรือ quiz ที่เปิดให้ทุกคนอ่านได้
+	role, err := s.repos.Quiz.GetQuizRole(quizID, hostID)
+	if err != nil {
+		return nil, err
+	}
+	if !models.QuizRoleAllows(role, models.QuizRoleViewer) {
+		public, err := s.repos.Quiz.IsQuizPublic(quizID)
+		if err != nil {
+			return nil, err
+		}
+		if !public {
+			return nil, errors.New("unauthorized: you cannot host a game of this quiz")
+		}
+	}
+
+	// เกมของห้องเรียนจัดได้เฉพาะครูประจำห้องหรือผู้ดูแลองค์กร
+	if options.ClassroomID != nil {
+		if err := s.classroomService.CanHost(*options.ClassroomID, hostID); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.MaxPlayers < 0 {
+		return nil, ErrInvalidMaxPlayers
+	}
+
+	passwordHash, err := hashJoinPassword(options.Access.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	teamScoring, err := validateTeamSettings(options.Teams.Scoring, options.Teams.TalkSeconds)
+	if err != nil {
+		return nil, err
+	}
+
 	// สร้าง GameSession
 	now := time.Now()
 	session := &models.GameSession{
-		ID:        sessionID,
-		QuizID:    quizID,
-		HostID:    hostID,
-		Status:    "lobby", // สถานะเริ่มต้นคือ lobby
-		CreatedAt: now,
+		ID:               sessionID,
+		QuizID:           quizID,
+		HostID:           hostID,
+		Status:           "lobby", // สถานะเริ่มต้นคือ lobby
+		ShuffleQuestions: options.ShuffleQuestions,
+		ShuffleChoices:   options.ShuffleChoices,
+		ShuffleMode:      shuffleMode,
+		ShuffleSeed:      rand.Int63(),
+		ClassroomID:      options.ClassroomID,
+		JoinPasswordHash: passwordHash,
+		RequireApproval:  options.Access.RequireApproval,
+		MaxPlayers:       options.MaxPlayers,
+		TeamMode:         options.Teams.Enabled,
+		TeamScoring:      teamScoring,
+		TeamTalkSeconds:  options.Teams.TalkSeconds,
+		CreatedAt:        now,
 	}
 
 	// ลงทะเบียนโฮสต์เป็นผู้เล่นด้วย
@@ -76,6 +157,15 @@ func (s *GameService) CreateGameSession(hostID uint, quizID uint) (*models.GameS
 		return nil, err
 	}
 
+	// บันทึกรายชื่อผู้ที่อนุญาตให้เข้าร่วม
+	for _, userID := range uniqueUserIDs(options.Access.AllowedUserIDs, hostID) {
+		allowed := &models.GameSessionAllowedUser{SessionID: sessionID, UserID: userID}
+		if err := tx.Create(allowed).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
 	// หากทุกอย่างเรียบร้อย commit transaction
 	if err := tx.Commit().Error; err != nil {
 		return nil, err
@@ -98,6 +188,11 @@ func (s *GameService) SubmitAnswer(sessionID string, playerID uint, questionID u
 		return nil, errors.New("ไม่สามารถส่งคำตอบได้: เกมไม่ได้อยู่ในสถานะกำลังเล่น")
 	}
 
+	// โหมดทีมที่มีช่วงคุยกัน รับคำตอบหลังช่วงคุยกันจบแล้วเท่านั้น
+	if err := checkTeamTalk(session); err != nil {
+		return nil, err
+	}
+
 	// ตรวจสอบว่าผู้เล่นได้ตอบคำถามนี้ไปแล้วหรือไม่
 	existingAnswer, err := s.playerAnswerRepo.GetPlayerAnswerBySessionAndQuestion(sessionID, questionID, playerID)
 	if err == nil && existingAnswer != nil {
@@ -179,24 +274,67 @@ func (s *GameService) GetGameSession(sessionID string) (*models.GameSession, err
 }
 
 // JoinGameSession ให้ผู้เล่นเข้าร่วม session (ปรับปรุงด้วย transaction)
-func (s *GameService) JoinGameSession(sessionID string, userID uint, nickname string) (*models.GamePlayer, error) {
+// ตรวจรายชื่อที่อนุญาต ห้องเรียน และรหัสผ่านของเกมก่อน ถ้าเกมต้องให้โฮสต์อนุมัติ
+// จะคืนค่าคำขอที่รออนุมัติแทนผู้เล่น และผู้เล่นจะถูกเพิ่มเมื่อโฮสต์อนุมัติ
+func (s *GameService) JoinGameSession(sessionID string, userID uint, nickname string, password string) (*models.GamePlayer, *models.GameJoinRequest, error) {
 	// ตรวจสอบว่า session มีอยู่จริงและยังอยู่ในสถานะ lobby
 	session, err := s.gameSessionRepo.GetGameSessionByID(sessionID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if session.Status != "lobby" {
-		return nil, errors.New("ไม่สามารถเข้าร่วมได้: เกมได้เริ่มต้นหรือจบไปแล้ว")
+		return nil, nil, errors.New("ไม่สามารถเข้าร่วมได้: เกมได้เริ่มต้นหรือจบไปแล้ว")
 	}
 
 	// ตรวจสอบว่าผู้เล่นอยู่ใน session นี้แล้วหรือไม่
 	existingPlayer, err := s.gamePlayerRepo.GetPlayerBySessionAndUserID(sessionID, userID)
 	if err == nil && existingPlayer != nil {
 		// ผู้เล่นอยู่ใน session นี้แล้ว
-		return existingPlayer, nil
+		return existingPlayer, nil, nil
+	}
+
+	// ล็อบบี้ที่ถูกล็อกไม่รับผู้เล่นใหม่และไม่รับคำขอเข้าร่วมใหม่
+	if session.LobbyLocked && session.HostID != userID {
+		return nil, nil, ErrLobbyLocked
+	}
+
+	if err := s.checkJoinAccess(session, userID, password); err != nil {
+		return nil, nil, err
+	}
+
+	// เกมที่ต้องให้โฮสต์อนุมัติ ผู้เล่นรอในคิวจนกว่าโฮสต์จะตัดสิน
+	// ไม่รับคำขอใหม่เมื่อเกมเต็มแล้ว จำนวนสูงสุดถูกตรวจซ้ำอีกครั้งตอนเพิ่มผู้เล่น
+	if session.RequireApproval && session.HostID != userID {
+		playerCount, err := s.gamePlayerRepo.CountPlayersExcludingUser(sessionID, session.HostID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if session.IsFull(playerCount) {
+			return nil, nil, ErrSessionFull
+		}
+
+		request, err := s.requestApproval(session, userID, nickname)
+		if err != nil {
+			return nil, nil, err
+		}
+		// คำขอที่โฮสต์อนุมัติแล้วเข้าร่วมต่อได้เลย
+		if request.Status != models.JoinRequestApproved {
+			return nil, request, nil
+		}
+	}
+
+	player, err := s.addPlayer(sessionID, userID, nickname, false)
+	if err != nil {
+		return nil, nil, err
 	}
+	return player, nil, nil
+}
 
+// addPlayer เพิ่มผู้เล่นเข้าเกม
+// ล็อกแถวของเกมไว้ระหว่างตรวจการล็อกล็อบบี้และนับผู้เล่น เพื่อไม่ให้การเข้าร่วมพร้อมกันหลุดผ่านการล็อกหรือเกินจำนวนสูงสุด
+// hostApproved คือผู้เล่นที่โฮสต์อนุมัติคำขอเอง จึงเข้าได้แม้ล็อบบี้ถูกล็อก
+func (s *GameService) addPlayer(sessionID string, userID uint, nickname string, hostApproved bool) (*models.GamePlayer, error) {
 	// สร้างผู้เล่นใหม่
 	player := &models.GamePlayer{
 		SessionID: sessionID,
@@ -208,6 +346,34 @@ func (s *GameService) JoinGameSession(sessionID string, userID uint, nickname st
 
 	// ใช้ transaction
 	tx := s.repos.BeginTx()
+
+	var session models.GameSession
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&session, "id = ?", sessionID).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if session.Status != "lobby" {
+		tx.Rollback()
+		return nil, errors.New("ไม่สามารถเข้าร่วมได้: เกมได้เริ่มต้นหรือจบไปแล้ว")
+	}
+	if session.LobbyLocked && !hostApproved && userID != session.HostID {
+		tx.Rollback()
+		return nil, ErrLobbyLocked
+	}
+	if session.MaxPlayers > 0 && userID != session.HostID {
+		var playerCount int64
+		if err := tx.Model(&models.GamePlayer{}).
+			Where("session_id = ? AND user_id <> ?", sessionID, session.HostID).
+			Count(&playerCount).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if session.IsFull(playerCount) {
+			tx.Rollback()
+			return nil, ErrSessionFull
+		}
+	}
+
 	if err := tx.Create(player).Error; err != nil {
 		tx.Rollback()
 		return nil, err
@@ -237,13 +403,23 @@ func (s *GameService) StartGameSession(sessionID string, hostID uint) error {
 	}
 
 	// อัพเดทสถานะเป็น "in_progress"
-	startTime := time.Now()
-	session.Status = "in_progress"
-	session.StartedAt = &startTime
-
-	// ใช้ transaction
+	// ล็อกแถวของเกมแล้วแก้เฉพาะสถานะและเวลาเริ่ม เพื่อไม่ให้ทับการตั้งค่าที่โฮสต์เปลี่ยนพร้อมกัน เช่น การล็อกล็อบบี้
 	tx := s.repos.BeginTx()
-	if err := tx.Save(session).Error; err != nil {
+	var locked models.GameSession
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, "id = ?", sessionID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if locked.Status != "lobby" {
+		tx.Rollback()
+		return errors.New("เกมได้เริ่มต้นหรือจบไปแล้ว")
+	}
+
+	err = tx.Model(&models.GameSession{}).Where("id = ?", sessionID).Updates(map[string]interface{}{
+		"status":     "in_progress",
+		"started_at": time.Now(),
+	}).Error
+	if err != nil {
 		tx.Rollback()
 		return err
 	}
@@ -271,14 +447,23 @@ func (s *GameService) EndGameSession(sessionID string, hostID uint) (*models.Gam
 		return nil, errors.New("เกมไม่ได้อยู่ในสถานะกำลังเล่น")
 	}
 
-	// อัพเดทสถานะเป็น "completed"
-	finishedTime := time.Now()
-	session.Status = "completed"
-	session.FinishedAt = &finishedTime
-
-	// ใช้ transaction
+	// อัพเดทสถานะเป็น "completed" แก้เฉพาะคอลัมน์ที่เปลี่ยนบนแถวที่ล็อกไว้ เหมือน StartGameSession
 	tx := s.repos.BeginTx()
-	if err := tx.Save(session).Error; err != nil {
+	var locked models.GameSession
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, "id = ?", sessionID).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if locked.Status != "in_progress" {
+		tx.Rollback()
+		return nil, errors.New("เกมไม่ได้อยู่ในสถานะกำลังเล่น")
+	}
+
+	err = tx.Model(&models.GameSession{}).Where("id = ?", sessionID).Updates(map[string]interface{}{
+		"status":      "completed",
+		"finished_at": time.Now(),
+	}).Error
+	if err != nil {
 		tx.Rollback()
 		return nil, err
 	}
@@ -305,3 +490,206 @@ func (s *GameService) GetSessionsByHostID(hostID uint) ([]models.GameSession, er
 func (s *GameService) GetPlayersBySessionID(sessionID string) ([]models.GamePlayer, error) {
 	return s.gamePlayerRepo.GetPlayersBySessionID(sessionID)
 }
+
+// getPlayerQuestions ดึงคำถามของเกมตามลำดับที่ผู้เล่นคนนั้นเห็น
+func (s *GameService) getPlayerQuestions(session *models.GameSession, userID uint) ([]models.Question, error) {
+	questions, err := s.repos.Question.GetQuestionsByQuizID(session.QuizID)
+	if err != nil {
+		return nil, err
+	}
+	return arrangeQuestions(session, userID, questions), nil
+}
+
+// GetSessionQuestions ดึงคำถามทั้งหมดของเกมตามลำดับเดิมของ quiz
+// ใช้โหลดคำถามครั้งเดียวแล้วส่งต่อให้ GetPlayerQuestion และ GetQuestionReveal ของผู้เล่นทุกคน
+func (s *GameService) GetSessionQuestions(session *models.GameSession) ([]models.Question, error) {
+	return s.repos.Question.GetQuestionsByQuizID(session.QuizID)
+}
+
+// getCurrentQuestion ดึงคำถามที่กำลังเล่นอยู่ของผู้เล่นจากคำถามของเกมที่โหลดไว้แล้ว
+func getCurrentQuestion(session *models.GameSession, questions []models.Question, userID uint) (*models.Question, int, error) {
+	questions = arrangeQuestions(session, userID, questions)
+
+	if session.CurrentQuestion < 1 || session.CurrentQuestion > len(questions) {
+		return nil, 0, errors.New("ยังไม่มีคำถามที่กำลังเล่นอยู่")
+	}
+
+	return &questions[session.CurrentQuestion-1], len(questions), nil
+}
+
+// NextQuestion เลื่อนเกมไปยังคำถามถัดไป (เฉพาะโฮสต์)
+func (s *GameService) NextQuestion(sessionID string, hostID uint) (*models.GameSession, error) {
+	session, err := s.gameSessionRepo.GetGameSessionByID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.HostID != hostID {
+		return nil, errors.New("เฉพาะโฮสต์เท่านั้นที่สามารถเปลี่ยนคำถามได้")
+	}
+
+	if session.Status != "in_progress" {
+		return nil, errors.New("เกมไม่ได้อยู่ในสถานะกำลังเล่น")
+	}
+
+	questions, err := s.repos.Question.GetQuestionsByQuizID(session.QuizID)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.CurrentQuestion >= len(questions) {
+		return nil, errors.New("ไม่มีคำถามเหลือแล้ว")
+	}
+
+	// อัพเดทลำดับคำถามปัจจุบัน และเวลาเริ่มคำถามสำหรับช่วงคุยกันในทีม
+	startedAt := time.Now()
+	session.CurrentQuestion++
+	session.QuestionStartedAt = &startedAt
+	if err := s.gameSessionRepo.UpdateGameSession(session); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// GetPlayerQuestion คืนค่าคำถามปัจจุบันของผู้เล่นในรูปแบบที่ไม่มีเฉลย
+// สำหรับส่งใน event question_started โดย questions มาจาก GetSessionQuestions
+func (s *GameService) GetPlayerQuestion(session *models.GameSession, questions []models.Question, userID uint) (*dto.PlayerQuestion, error) {
+	question, total, err := getCurrentQuestion(session, questions, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	playerQuestion := &dto.PlayerQuestion{
+		ID:        question.ID,
+		Index:     session.CurrentQuestion,
+		Total:     total,
+		Text:      question.Text,
+		ImageURL:  question.ImageURL,
+		MediaURL:  question.MediaURL,
+		MediaType: question.MediaType,
+		TimeLimit: question.TimeLimit,
+		Choices:   make([]dto.PlayerChoice, 0, len(question.Choices)),
+
+		TeamTalkEndsAt: session.TeamTalkEndsAt(),
+	}
+	if playerQuestion.TimeLimit == 0 {
+		playerQuestion.TimeLimit = session.Quiz.TimeLimit
+	}
+	for _, choice := range question.Choices {
+		playerQuestion.Choices = append(playerQuestion.Choices, dto.PlayerChoice{
+			ID:       choice.ID,
+			Text:     choice.Text,
+			ImageURL: choice.ImageURL,
+		})
+	}
+
+	return playerQuestion, nil
+}
+
+// EndQuestion จบคำถามปัจจุบัน (เฉพาะโฮสต์)
+func (s *GameService) EndQuestion(sessionID string, hostID uint) (*models.GameSession, error) {
+	session, err := s.gameSessionRepo.GetGameSessionByID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.HostID != hostID {
+		return nil, errors.New("เฉพาะโฮสต์เท่านั้นที่สามารถจบคำถามได้")
+	}
+
+	if session.Status != "in_progress" {
+		return nil, errors.New("เกมไม่ได้อยู่ในสถานะกำลังเล่น")
+	}
+
+	if session.CurrentQuestion < 1 {
+		return nil, errors.New("ยังไม่มีคำถามที่กำลังเล่นอยู่")
+	}
+
+	return session, nil
+}
+
+// GetQuestionReveal คืนค่าเฉลยพร้อมคำอธิบายของคำถามปัจจุบันของผู้เล่น
+// สำหรับส่งใน event question_ended โดย questions มาจาก GetSessionQuestions
+func (s *GameService) GetQuestionReveal(session *models.GameSession, questions []models.Question, userID uint) (*dto.QuestionReveal, error) {
+	question, _, err := getCurrentQuestion(session, questions, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	reveal := &dto.QuestionReveal{
+		QuestionID:          question.ID,
+		CorrectChoiceIDs:    []uint{},
+		Explanation:         question.Explanation,
+		ExplanationImageURL: question.ExplanationImageURL,
+	}
+	for _, choice := range question.Choices {
+		if choice.IsCorrect {
+			reveal.CorrectChoiceIDs = append(reveal.CorrectChoiceIDs, choice.ID)
+		}
+	}
+
+	return reveal, nil
+}
+
+// GetGameReview ดึงคำถาม เฉลย คำอธิบาย และคำตอบของผู้ใช้สำหรับทบทวนหลังจบเกม
+func (s *GameService) GetGameReview(sessionID string, userID uint) ([]dto.ReviewQuestion, error) {
+	session, err := s.gameSessionRepo.GetGameSessionByID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// ผู้เล่นดูเฉลยได้หลังจบเกมเท่านั้น ส่วนโฮสต์ดูได้ตลอด
+	if session.HostID != userID {
+		if session.Status != "completed" {
+			return nil, errors.New("สามารถดูเฉลยได้หลังจบเกมเท่านั้น")
+		}
+		if _, err := s.gamePlayerRepo.GetPlayerBySessionAndUserID(sessionID, userID); err != nil {
+			return nil, errors.New("คุณไม่ได้เป็นผู้เล่นในเกมนี้")
+		}
+	}
+
+	// เรียงคำถามและตัวเลือกตามที่ผู้เล่นเห็นจริงในเกม
+	questions, err := s.getPlayerQuestions(session, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	answers, err := s.playerAnswerRepo.GetPlayerAnswersBySessionAndPlayerID(sessionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	answerByQuestion := make(map[uint]models.PlayerAnswer, len(answers))
+	for _, answer := range answers {
+		answerByQuestion[answer.QuestionID] = answer
+	}
+
+	review := make([]dto.ReviewQuestion, 0, len(questions))
+	for _, question := range questions {
+		item := dto.ReviewQuestion{
+			QuestionID:          question.ID,
+			Text:                question.Text,
+			ImageURL:            question.ImageURL,
+			Choices:             make([]dto.ReviewChoice, 0, len(question.Choices)),
+			Explanation:         question.Explanation,
+			ExplanationImageURL: question.ExplanationImageURL,
+		}
+		for _, choice := range question.Choices {
+			item.Choices = append(item.Choices, dto.ReviewChoice{
+				ID:        choice.ID,
+				Text:      choice.Text,
+				ImageURL:  choice.ImageURL,
+				IsCorrect: choice.IsCorrect,
+			})
+		}
+		if answer, ok := answerByQuestion[question.ID]; ok {
+			choiceID := answer.ChoiceID
+			item.SelectedChoiceID = &choiceID
+			item.IsCorrect = answer.IsCorrect
+			item.Points = answer.Points
+		}
+		review = append(review, item)
+	}
+
+	return review, nil
+}
@@ -0,0 +1,353 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/patiphanak/league-of-quiz/dto"
+	models "github.com/patiphanak/league-of-quiz/model"
+)
+
+// ข้อผิดพลาดของโหมดทีม
+var (
+	ErrNotTeamMode         = errors.New("team mode is not enabled for this game session")
+	ErrTeamTalkInProgress  = errors.New("answers open after the team talk period ends")
+	ErrInvalidTeamSettings = errors.New("invalid team settings")
+)
+
+const (
+	// maxTeams จำนวนทีมสูงสุดต่อเกม
+	maxTeams = 20
+	// maxTeamNameLength ความยาวสูงสุดของชื่อทีม
+	maxTeamNameLength = 32
+	// maxTeamTalkSeconds ช่วงคุยกันในทีมยาวสุดต่อคำถาม
+	maxTeamTalkSeconds = 120
+)
+
+// GameTeamSettings การตั้งค่าโหมดทีมที่โฮสต์กำหนดตอนสร้างเกม
+type GameTeamSettings struct {
+	Enabled     bool
+	Scoring     string // sum หรือ average ค่าว่างหมายถึง sum
+	TalkSeconds uint
+}
+
+// validateTeamSettings ตรวจวิธีคิดคะแนนและช่วงคุยกันในทีม คืนค่าวิธีคิดคะแนนที่ใช้จริง
+func validateTeamSettings(scoring string, talkSeconds uint) (string, error) {
+	if scoring == "" {
+		scoring = models.TeamScoringSum
+	}
+	if scoring != models.TeamScoringSum && scoring != models.TeamScoringAverage {
+		return "", fmt.Errorf("%w: team scoring must be sum or average", ErrInvalidTeamSettings)
+	}
+	if talkSeconds > maxTeamTalkSeconds {
+		return "", fmt.Errorf("%w: team talk can be at most %d seconds", ErrInvalidTeamSettings, maxTeamTalkSeconds)
+	}
+	return scoring, nil
+}
+
+// requireTeamLobby ดึงเกมโหมดทีมที่ยังอยู่ใน lobby และตรวจสอบว่าผู้ใช้เป็นโฮสต์
+func (s *GameService) requireTeamLobby(sessionID string, hostID uint) (*models.GameSession, error) {
+	session, err := s.requireHost(sessionID, hostID)
+	if err != nil {
+		return nil, err
+	}
+	if !session.TeamMode {
+		return nil, ErrNotTeamMode
+	}
+	if session.Status != "lobby" {
+		return nil, errors.New("teams can only be changed while the game is in the lobby")
+	}
+	return session, nil
+}
+
+// UpdateTeamSettings เปิดหรือปิดโหมดทีมและเปลี่ยนวิธีคิดคะแนน เฉพาะโฮสต์และเฉพาะตอนที่ยังอยู่ใน lobby
+func (s *GameService) UpdateTeamSettings(sessionID string, hostID uint, req dto.UpdateTeamSettingsRequest) (*models.GameSession, error) {
+	session, err := s.requireHost(sessionID, hostID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != "lobby" {
+		return nil, errors.New("team settings can only be changed while the game is in the lobby")
+	}
+
+	scoring := session.TeamScoring
+	if req.TeamScoring != nil {
+		scoring = *req.TeamScoring
+	}
+	talkSeconds := session.TeamTalkSeconds
+	if req.TeamTalkSeconds != nil {
+		talkSeconds = *req.TeamTalkSeconds
+	}
+	if scoring, err = validateTeamSettings(scoring, talkSeconds); err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{
+		"team_scoring":      scoring,
+		"team_talk_seconds": talkSeconds,
+	}
+	if req.TeamMode != nil {
+		updates["team_mode"] = *req.TeamMode
+	}
+	if err := s.gameSessionRepo.UpdateSessionAccess(sessionID, updates); err != nil {
+		return nil, err
+	}
+	return s.gameSessionRepo.GetGameSessionByID(sessionID)
+}
+
+// CreateTeam สร้างทีมใหม่ในล็อบบี้
+func (s *GameService) CreateTeam(sessionID string, hostID uint, name string) (*models.GameTeam, error) {
+	if _, err := s.requireTeamLobby(sessionID, hostID); err != nil {
+		return nil, err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("team name is required")
+	}
+	if len([]rune(name)) > maxTeamNameLength {
+		return nil, fmt.Errorf("team name must be at most %d characters", maxTeamNameLength)
+	}
+
+	teams, err := s.gameTeamRepo.GetTeamsBySessionID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(teams) >= maxTeams {
+		return nil, fmt.Errorf("a game can have at most %d teams", maxTeams)
+	}
+	for _, team := range teams {
+		if strings.EqualFold(team.Name, name) {
+			return nil, errors.New("a team with this name already exists")
+		}
+	}
+
+	team := &models.GameTeam{SessionID: sessionID, Name: name}
+	if err := s.gameTeamRepo.CreateTeam(team); err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+// DeleteTeam ลบทีม สมาชิกของทีมจะกลับไปเป็นผู้เล่นที่ยังไม่มีทีม
+func (s *GameService) DeleteTeam(sessionID string, hostID uint, teamID uint) error {
+	if _, err := s.requireTeamLobby(sessionID, hostID); err != nil {
+		return err
+	}
+	return s.gameTeamRepo.DeleteTeam(sessionID, teamID)
+}
+
+// AssignPlayerTeam ย้ายผู้เล่นเข้าทีม teamID เป็น 0 เพื่อนำผู้เล่นออกจากทีม
+func (s *GameService) AssignPlayerTeam(sessionID string, hostID uint, userID uint, teamID uint) error {
+	session, err := s.requireTeamLobby(sessionID, hostID)
+	if err != nil {
+		return err
+	}
+	if userID == session.HostID {
+		return errors.New("the host cannot be placed in a team")
+	}
+
+	if teamID == 0 {
+		return s.gameTeamRepo.SetPlayerTeam(sessionID, userID, nil)
+	}
+	if _, err := s.gameTeamRepo.GetTeam(sessionID, teamID); err != nil {
+		return err
+	}
+	return s.gameTeamRepo.SetPlayerTeam(sessionID, userID, &teamID)
+}
+
+// AutoBalanceTeams สุ่มแบ่งผู้เล่นทุกคน (ไม่รวมโฮสต์) ลงทีมให้จำนวนใกล้เคียงกัน
+// ถ้ามีทีมน้อยกว่า teamCount จะสร้างทีมเพิ่มให้ครบ teamCount เป็น 0 เพื่อใช้ทีมที่มีอยู่
+func (s *GameService) AutoBalanceTeams(sessionID string, hostID uint, teamCount int) error {
+	session, err := s.requireTeamLobby(sessionID, hostID)
+	if err != nil {
+		return err
+	}
+	if teamCount < 0 || teamCount > maxTeams {
+		return fmt.Errorf("team count must be between 0 and %d", maxTeams)
+	}
+
+	teams, err := s.gameTeamRepo.GetTeamsBySessionID(sessionID)
+	if err != nil {
+		return err
+	}
+
+	// สร้างทีมที่ยังขาดโดยตั้งชื่อ Team 1, Team 2, ... ที่ยังไม่ถูกใช้
+	if missing := teamCount - len(teams); missing > 0 {
+		used := make(map[string]bool, len(teams))
+		for _, team := range teams {
+			used[strings.ToLower(team.Name)] = true
+		}
+		newTeams := make([]models.GameTeam, 0, missing)
+		for n := 1; len(newTeams) < missing; n++ {
+			name := fmt.Sprintf("Team %d", n)
+			if used[strings.ToLower(name)] {
+				continue
+			}
+			newTeams = append(newTeams, models.GameTeam{SessionID: sessionID, Name: name})
+		}
+		if err := s.gameTeamRepo.CreateTeams(newTeams); err != nil {
+			return err
+		}
+		if teams, err = s.gameTeamRepo.GetTeamsBySessionID(sessionID); err != nil {
+			return err
+		}
+	}
+	if len(teams) < 2 {
+		return errors.New("at least two teams are needed to balance players")
+	}
+
+	players, err := s.gamePlayerRepo.GetPlayersBySessionID(sessionID)
+	if err != nil {
+		return err
+	}
+	userIDs := make([]uint, 0, len(players))
+	for _, player := range players {
+		if player.UserID != session.HostID {
+			userIDs = append(userIDs, player.UserID)
+		}
+	}
+	rand.Shuffle(len(userIDs), func(i, j int) {
+		userIDs[i], userIDs[j] = userIDs[j], userIDs[i]
+	})
+
+	assignments := make(map[uint]uint, len(userIDs))
+	for i, userID := range userIDs {
+		assignments[userID] = teams[i%len(teams)].ID
+	}
+	return s.gameTeamRepo.ReplaceTeamAssignments(sessionID, assignments)
+}
+
+// GetTeams ดึงทีมทั้งหมดของเกมพร้อมสมาชิกและคะแนนทีม
+func (s *GameService) GetTeams(sessionID string) (*dto.GameTeamsResponse, error) {
+	session, err := s.gameSessionRepo.GetGameSessionByID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if !session.TeamMode {
+		return nil, ErrNotTeamMode
+	}
+
+	teams, err := s.gameTeamRepo.GetTeamsBySessionID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	players, err := s.gamePlayerRepo.GetPlayersBySessionID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.GameTeamsResponse{
+		SessionID:       session.ID,
+		TeamScoring:     session.TeamScoring,
+		TeamTalkSeconds: session.TeamTalkSeconds,
+		Teams:           make([]dto.GameTeamResponse, 0, len(teams)),
+		Unassigned:      []dto.TeamMember{},
+	}
+
+	indexByTeam := make(map[uint]int, len(teams))
+	for i, team := range teams {
+		indexByTeam[team.ID] = i
+		response.Teams = append(response.Teams, dto.GameTeamResponse{
+			ID:      team.ID,
+			Name:    team.Name,
+			Members: []dto.TeamMember{},
+		})
+	}
+	for _, player := range players {
+		if player.UserID == session.HostID {
+			continue
+		}
+		member := dto.TeamMember{UserID: player.UserID, Nickname: player.Nickname, Score: player.Score}
+		if player.TeamID == nil {
+			response.Unassigned = append(response.Unassigned, member)
+			continue
+		}
+		if i, ok := indexByTeam[*player.TeamID]; ok {
+			response.Teams[i].Members = append(response.Teams[i].Members, member)
+		}
+	}
+	for i := range response.Teams {
+		response.Teams[i].Score = teamScore(session.TeamScoring, response.Teams[i].Members)
+	}
+
+	return response, nil
+}
+
+// GetTeamLeaderboard เรียงทีมตามคะแนนทีมจากมากไปน้อย
+func (s *GameService) GetTeamLeaderboard(sessionID string) ([]dto.TeamStanding, error) {
+	teams, err := s.GetTeams(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	standings := make([]dto.TeamStanding, 0, len(teams.Teams))
+	for _, team := range teams.Teams {
+		standings = append(standings, dto.TeamStanding{
+			TeamID:      team.ID,
+			Name:        team.Name,
+			Score:       team.Score,
+			MemberCount: len(team.Members),
+		})
+	}
+	sort.SliceStable(standings, func(i, j int) bool {
+		return standings[i].Score > standings[j].Score
+	})
+	for i := range standings {
+		standings[i].Rank = i + 1
+		if i > 0 && standings[i].Score == standings[i-1].Score {
+			standings[i].Rank = standings[i-1].Rank
+		}
+	}
+	return standings, nil
+}
+
+// GetTeammateUserIDs ดึง ID ผู้ใช้ทุกคนในทีมเดียวกับผู้ใช้ (รวมตัวเอง) สำหรับส่งข้อความคุยกันในทีม
+func (s *GameService) GetTeammateUserIDs(sessionID string, userID uint) ([]uint, error) {
+	player, err := s.gamePlayerRepo.GetPlayerBySessionAndUserID(sessionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if player.TeamID == nil {
+		return nil, errors.New("you are not in a team")
+	}
+
+	players, err := s.gamePlayerRepo.GetPlayersBySessionID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	userIDs := make([]uint, 0, len(players))
+	for _, teammate := range players {
+		if teammate.TeamID != nil && *teammate.TeamID == *player.TeamID {
+			userIDs = append(userIDs, teammate.UserID)
+		}
+	}
+	return userIDs, nil
+}
+
+// checkTeamTalk ตรวจว่าช่วงคุยกันในทีมของคำถามปัจจุบันจบแล้ว
+func checkTeamTalk(session *models.GameSession) error {
+	if endsAt := session.TeamTalkEndsAt(); endsAt != nil && time.Now().Before(*endsAt) {
+		return ErrTeamTalkInProgress
+	}
+	return nil
+}
+
+// teamScore คิดคะแนนทีมจากคะแนนสมาชิกด้วยผลรวมหรือค่าเฉลี่ย
+func teamScore(scoring string, members []dto.TeamMember) float64 {
+	var total float64
+	for _, member := range members {
+		total += float64(member.Score)
+	}
+	if scoring == models.TeamScoringAverage {
+		if len(members) == 0 {
+			return 0
+		}
+		return total / float64(len(members))
+	}
+	return total
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/patiphanak/league-of-quiz/auth/oauth"
+	models "github.com/patiphanak/league-of-quiz/model"
+	"github.com/patiphanak/league-of-quiz/repositories"
+)
+
+// IdentityService เชื่อมบัญชีภายนอกจาก OAuth/OIDC provider เข้ากับผู้ใช้ในระบบ
+type IdentityService struct {
+	identityRepo *repositories.UserIdentityRepository
+	userRepo     *repositories.UserRepository
+}
+
+// NewIdentityService สร้าง instance ใหม่ของ IdentityService
+func NewIdentityService(identityRepo *repositories.UserIdentityRepository, userRepo *repositories.UserRepository) *IdentityService {
+	return &IdentityService{identityRepo: identityRepo, userRepo: userRepo}
+}
+
+// ResolveUser หาผู้ใช้ของ identity ที่เข้าสู่ระบบ
+// ถ้ายังไม่เคยเชื่อม จะเชื่อมกับผู้ใช้เดิมที่มีอีเมลเดียวกันเมื่อ provider ยืนยันอีเมลแล้ว
+// มิฉะนั้นสร้างผู้ใช้ใหม่ด้วยบทบาท newUserRole
+func (s *IdentityService) ResolveUser(provider string, info *oauth.UserInfo, newUserRole string) (*models.User, error) {
+	if info == nil || info.ID == "" {
+		return nil, errors.New("identity has no subject")
+	}
+
+	identity, err := s.identityRepo.GetIdentity(provider, info.ID)
+	if err == nil {
+		return &identity.User, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	identity = &models.UserIdentity{
+		Provider: provider,
+		Subject:  info.ID,
+		Email:    info.Email,
+	}
+
+	email := strings.TrimSpace(info.Email)
+	if email != "" {
+		user, err := s.identityRepo.GetUserByEmail(email)
+		if err == nil && !info.EmailVerified {
+			// อีเมลที่ provider ไม่ได้ยืนยันผูกกับบัญชีเดิมไม่ได้ และอีเมลในตาราง users ต้องไม่ซ้ำกัน
+			// จึงสร้างบัญชีใหม่โดยไม่มีอีเมล (identity ยังเก็บอีเมลจาก provider ไว้)
+			email = ""
+		} else if err == nil {
+			identity.UserID = user.ID
+			if err := s.identityRepo.CreateIdentity(identity); err != nil {
+				return nil, err
+			}
+			// บัญชีอีเมลที่ยังไม่ยืนยันอาจถูกผู้อื่นสมัครไว้ก่อน ให้ลบรหัสผ่านนั้นทิ้ง
+			if !user.IsEmailVerified() {
+				if err := s.userRepo.MarkEmailVerified(user.ID, true); err != nil {
+					return nil, err
+				}
+				now := time.Now()
+				user.EmailVerifiedAt = &now
+				user.PasswordHash = ""
+			}
+			return user, nil
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	user := &models.User{
+		Email:       email,
+		DisplayName: info.Name,
+		PictureURL:  info.Picture,
+		Role:        newUserRole,
+	}
+	if info.EmailVerified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	if err := s.identityRepo.CreateUserWithIdentity(user, identity); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
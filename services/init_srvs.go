@@ -4,7 +4,10 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/patiphanak/league-of-quiz/auth/jwt"
+	"github.com/patiphanak/league-of-quiz/mailer"
 	"github.com/patiphanak/league-of-quiz/repositories"
 )
 
@@ -13,6 +16,10 @@ type FileServiceOptions struct {
 	BaseDir      string
 	AllowedTypes []string
 	MaxFileSize  int64
+	AudioTypes   []string
+	MaxAudioSize int64
+	VideoTypes   []string
+	MaxVideoSize int64
 }
 
 // DefaultFileOptions returns default options for FileService
@@ -26,20 +33,50 @@ func DefaultFileOptions(baseDir string) FileServiceOptions {
 			"image/webp",
 		},
 		MaxFileSize: 5 * 1024 * 1024, // 5MB
+		AudioTypes: []string{
+			"audio/mpeg",
+			"audio/ogg",
+			"audio/mp4",
+		},
+		MaxAudioSize: 20 * 1024 * 1024, // 20MB
+		VideoTypes: []string{
+			"video/mp4",
+			"video/webm",
+		},
+		MaxVideoSize: 50 * 1024 * 1024, // 50MB
 	}
 }
 
 // Services holds all service instances
 type Services struct {
-	Quiz        *QuizService
-	File        *FileService
-	Choice      *ChoiceService
-	Question    *QuestionService
-	GameService *GameService
+	Quiz         *QuizService
+	File         *FileService
+	Upload       *UploadService
+	Choice       *ChoiceService
+	Question     *QuestionService
+	QuestionBank *QuestionBankService
+	Collaborator *QuizCollaboratorService
+	Admin        *AdminService
+	Category     *CategoryService
+	Token        *TokenService
+	Session      *SessionService
+	Identity     *IdentityService
+	LocalAuth    *LocalAuthService
+	AccessToken  *PersonalAccessTokenService
+	Account      *AccountService
+	Organization *OrganizationService
+	Classroom    *ClassroomService
+	GameService  *GameService
 }
 
 // InitServices initializes all services with proper error handling
-func InitServices(repos *repositories.Repositories, storagePath string) (*Services, error) {
+func InitServices(
+	repos *repositories.Repositories,
+	storagePath string,
+	jwtService *jwt.JWTService,
+	mail mailer.Mailer,
+	frontendURL string,
+) (*Services, error) {
 	log.Println("Starting service initialization")
 
 	// Create storage directory structure if it doesn't exist
@@ -52,6 +89,10 @@ func InitServices(repos *repositories.Repositories, storagePath string) (*Servic
 	fileService := NewFileService(fileOptions.BaseDir)
 	fileService.allowedTypes = fileOptions.AllowedTypes
 	fileService.maxFileSize = fileOptions.MaxFileSize
+	fileService.audioTypes = fileOptions.AudioTypes
+	fileService.maxAudioSize = fileOptions.MaxAudioSize
+	fileService.videoTypes = fileOptions.VideoTypes
+	fileService.maxVideoSize = fileOptions.MaxVideoSize
 
 	log.Println("FileService initialized with storage at:", storagePath)
 
@@ -59,21 +100,48 @@ func InitServices(repos *repositories.Repositories, storagePath string) (*Servic
 	questionService := NewQuestionService(repos.Question, repos.Quiz, fileService, repos.Choice)
 	choiceService := NewChoiceService(repos.Choice, repos.Question, repos.Quiz, fileService)
 	quizService := NewQuizService(repos.Quiz, fileService)
+	collaboratorService := NewQuizCollaboratorService(repos.Quiz, repos.Collaborator)
+	sessionService := NewSessionService(repos.Session, repos.RefreshToken, jwt.AccessTokenTTL)
+	tokenService := NewTokenService(repos.RefreshToken, repos.User, sessionService, jwtService)
+	// จำกัดการเข้าสู่ระบบผิด 5 ครั้งต่อบัญชี และ 20 ครั้งต่อ IP ใน 15 นาที
+	loginThrottle := NewLoginThrottle(15*time.Minute, 5, 20)
+	localAuthService := NewLocalAuthService(repos.User, repos.UserToken, sessionService, mail, loginThrottle, frontendURL)
+	adminService := NewAdminService(repos.User, repos.Quiz, quizService, tokenService)
+	categoryService := NewCategoryService(repos.Category)
+	organizationService := NewOrganizationService(repos.Organization, repos.User, repos.Quiz)
+	classroomService := NewClassroomService(repos.Classroom, repos.Organization, repos.User)
+	questionBankService := NewQuestionBankService(repos.QuestionBank, repos.Question, repos.Quiz, fileService)
 	gameService := NewGameService(
 		repos,
 		repos.GameSession,
 		repos.GamePlayer,
 		repos.PlayerAnswer,
 		repos.Choice,
+		repos.JoinRequest,
+		repos.GameTeam,
+		classroomService,
 	)
 
 	// Create the services container
 	services := &Services{
-		Quiz:        quizService,
-		File:        fileService,
-		Question:    questionService,
-		Choice:      choiceService,
-		GameService: gameService,
+		Quiz:         quizService,
+		File:         fileService,
+		Upload:       NewUploadService(fileService, repos.UploadedFile),
+		Question:     questionService,
+		Choice:       choiceService,
+		QuestionBank: questionBankService,
+		Collaborator: collaboratorService,
+		Admin:        adminService,
+		Category:     categoryService,
+		Token:        tokenService,
+		Session:      sessionService,
+		Identity:     NewIdentityService(repos.Identity, repos.User),
+		LocalAuth:    localAuthService,
+		AccessToken:  NewPersonalAccessTokenService(repos.AccessToken),
+		Account:      NewAccountService(repos.User, repos.Account, repos.Identity, fileService, sessionService),
+		Organization: organizationService,
+		Classroom:    classroomService,
+		GameService:  gameService,
 	}
 
 	log.Println("All services initialized successfully")
@@ -88,7 +156,7 @@ func ensureStorageDirectories(baseDir string) error {
 	}
 
 	// Create subdirectories for different file types
-	for _, fileType := range []string{"quiz", "question", "choice"} {
+	for _, fileType := range []string{"quiz", "question", "choice", "explanation", "media", "avatar"} {
 		dirPath := filepath.Join(baseDir, fileType)
 		if err := os.MkdirAll(dirPath, 0755); err != nil {
 			return err
@@ -0,0 +1,326 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"github.com/patiphanak/league-of-quiz/mailer"
+	models "github.com/patiphanak/league-of-quiz/model"
+	"github.com/patiphanak/league-of-quiz/repositories"
+)
+
+const (
+	passwordHashCost      = 12
+	minPasswordLength     = 8
+	maxPasswordLength     = 72 // bcrypt ใช้แค่ 72 byte แรก
+	verifyEmailTokenTTL   = 24 * time.Hour
+	resetPasswordTokenTTL = time.Hour
+)
+
+var (
+	ErrEmailTaken         = errors.New("email is already registered")
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrEmailNotVerified   = errors.New("email address has not been verified")
+	ErrInvalidEmailToken  = errors.New("invalid or expired token")
+)
+
+// ErrTooManyAttempts ถูกจำกัดการเข้าสู่ระบบชั่วคราว
+type ErrTooManyAttempts struct {
+	RetryAfter time.Duration
+}
+
+func (e *ErrTooManyAttempts) Error() string {
+	return fmt.Sprintf("too many login attempts, try again in %d seconds", int(e.RetryAfter.Seconds())+1)
+}
+
+// LocalAuthService สำหรับบัญชีแบบอีเมลและรหัสผ่าน
+type LocalAuthService struct {
+	userRepo       *repositories.UserRepository
+	tokenRepo      *repositories.UserTokenRepository
+	sessionService *SessionService
+	mailer         mailer.Mailer
+	throttle       *LoginThrottle
+	frontendURL    string
+}
+
+// NewLocalAuthService สร้าง instance ใหม่ของ LocalAuthService
+func NewLocalAuthService(
+	userRepo *repositories.UserRepository,
+	tokenRepo *repositories.UserTokenRepository,
+	sessionService *SessionService,
+	mailer mailer.Mailer,
+	throttle *LoginThrottle,
+	frontendURL string,
+) *LocalAuthService {
+	return &LocalAuthService{
+		userRepo:       userRepo,
+		tokenRepo:      tokenRepo,
+		sessionService: sessionService,
+		mailer:         mailer,
+		throttle:       throttle,
+		frontendURL:    strings.TrimSuffix(frontendURL, "/"),
+	}
+}
+
+// Register สมัครสมาชิกด้วยอีเมลและรหัสผ่าน แล้วส่งอีเมลยืนยัน
+func (s *LocalAuthService) Register(email, password, displayName string) (*models.User, error) {
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if err := validatePassword(password); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.userRepo.GetUserByEmail(email); err == nil {
+		return nil, ErrEmailTaken
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), passwordHashCost)
+	if err != nil {
+		return nil, err
+	}
+
+	displayName = strings.TrimSpace(displayName)
+	if displayName == "" {
+		displayName = strings.Split(email, "@")[0]
+	}
+
+	user := &models.User{
+		Email:        email,
+		DisplayName:  displayName,
+		Role:         models.UserRoleUser,
+		PasswordHash: string(hash),
+	}
+	if err := s.userRepo.CreateUser(user); err != nil {
+		// การสมัครพร้อมกันด้วยอีเมลเดียวกันจะชน unique index ของอีเมล
+		if repositories.IsUniqueViolation(err) {
+			return nil, ErrEmailTaken
+		}
+		return nil, err
+	}
+
+	if err := s.sendVerificationEmail(user); err != nil {
+		log.Printf("Failed to send verification email to user %d: %v", user.ID, err)
+	}
+	return user, nil
+}
+
+// Login ตรวจอีเมลและรหัสผ่าน โดยจำกัดจำนวนครั้งที่ผิดต่อบัญชีและต่อ IP
+func (s *LocalAuthService) Login(email, password, ip string) (*models.User, error) {
+	account := strings.ToLower(strings.TrimSpace(email))
+	if wait := s.throttle.Reserve(account, ip); wait > 0 {
+		return nil, &ErrTooManyAttempts{RetryAfter: wait}
+	}
+
+	user, err := s.userRepo.GetUserByEmail(account)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if user == nil || !user.HasPassword() {
+		// เทียบกับ hash ปลอมเพื่อให้เวลาตอบสนองใกล้เคียงกับกรณีที่มีบัญชี
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return nil, ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	s.throttle.Release(account, ip)
+
+	if user.IsDisabled() {
+		return nil, ErrAccountDisabled
+	}
+	if !user.IsEmailVerified() {
+		return nil, ErrEmailNotVerified
+	}
+	return user, nil
+}
+
+// VerifyEmail ยืนยันอีเมลด้วย token ที่ส่งไปทางอีเมล
+func (s *LocalAuthService) VerifyEmail(token string) error {
+	record, err := s.tokenRepo.GetActiveToken(hashOpaqueToken(token), models.UserTokenVerifyEmail, time.Now())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidEmailToken
+		}
+		return err
+	}
+
+	err = s.tokenRepo.ConsumeToken(record, func(tx *gorm.DB) error {
+		return tx.Model(&models.User{}).
+			Where("id = ? AND email_verified_at IS NULL", record.UserID).
+			Update("email_verified_at", time.Now()).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrInvalidEmailToken
+	}
+	return err
+}
+
+// ResendVerification ส่งอีเมลยืนยันอีกครั้ง ไม่บอกว่ามีบัญชีนี้อยู่หรือไม่
+func (s *LocalAuthService) ResendVerification(email string) error {
+	user, err := s.userRepo.GetUserByEmail(strings.TrimSpace(email))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.IsEmailVerified() || !user.HasPassword() {
+		return nil
+	}
+	return s.sendVerificationEmail(user)
+}
+
+// RequestPasswordReset ส่งลิงก์รีเซ็ตรหัสผ่าน ไม่บอกว่ามีบัญชีนี้อยู่หรือไม่
+func (s *LocalAuthService) RequestPasswordReset(email string) error {
+	user, err := s.userRepo.GetUserByEmail(strings.TrimSpace(email))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.IsDisabled() {
+		return nil
+	}
+
+	token, err := s.issueEmailToken(user.ID, models.UserTokenResetPassword, resetPasswordTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/auth/reset-password?token=%s", s.frontendURL, url.QueryEscape(token))
+	return s.mailer.Send(context.Background(), mailer.Message{
+		To:      user.Email,
+		Subject: "รีเซ็ตรหัสผ่าน League of Quiz",
+		Body: fmt.Sprintf("สวัสดี %s\n\nตั้งรหัสผ่านใหม่ได้ที่ลิงก์นี้ภายใน 1 ชั่วโมง:\n%s\n\nถ้าคุณไม่ได้ขอรีเซ็ตรหัสผ่าน ไม่ต้องทำอะไร",
+			user.DisplayName, link),
+	})
+}
+
+// ResetPassword ตั้งรหัสผ่านใหม่ด้วย token จากอีเมล แล้วออกจากระบบทุก session
+// การรีเซ็ตสำเร็จถือว่ายืนยันอีเมลแล้วด้วย
+func (s *LocalAuthService) ResetPassword(token, newPassword string) error {
+	if err := validatePassword(newPassword); err != nil {
+		return err
+	}
+
+	record, err := s.tokenRepo.GetActiveToken(hashOpaqueToken(token), models.UserTokenResetPassword, time.Now())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidEmailToken
+		}
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), passwordHashCost)
+	if err != nil {
+		return err
+	}
+
+	err = s.tokenRepo.ConsumeToken(record, func(tx *gorm.DB) error {
+		return tx.Model(&models.User{}).
+			Where("id = ?", record.UserID).
+			Updates(map[string]interface{}{
+				"password_hash":     string(hash),
+				"email_verified_at": gorm.Expr("COALESCE(email_verified_at, ?)", time.Now()),
+			}).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidEmailToken
+		}
+		return err
+	}
+
+	if user, err := s.userRepo.GetUserByID(record.UserID); err == nil {
+		s.throttle.Reset(strings.ToLower(user.Email))
+	}
+	return s.sessionService.RevokeAllForUser(record.UserID)
+}
+
+// PromoteToAdmin เลื่อนผู้ใช้เป็นผู้ดูแลระบบ (อีเมลอยู่ใน ADMIN_EMAILS)
+func (s *LocalAuthService) PromoteToAdmin(user *models.User) error {
+	if err := s.userRepo.UpdateUserRole(user.ID, models.UserRoleAdmin); err != nil {
+		return err
+	}
+	user.Role = models.UserRoleAdmin
+	return nil
+}
+
+// sendVerificationEmail สร้าง token ยืนยันอีเมลและส่งลิงก์ให้ผู้ใช้
+func (s *LocalAuthService) sendVerificationEmail(user *models.User) error {
+	token, err := s.issueEmailToken(user.ID, models.UserTokenVerifyEmail, verifyEmailTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/auth/verify-email?token=%s", s.frontendURL, url.QueryEscape(token))
+	return s.mailer.Send(context.Background(), mailer.Message{
+		To:      user.Email,
+		Subject: "ยืนยันอีเมลสำหรับ League of Quiz",
+		Body: fmt.Sprintf("สวัสดี %s\n\nยืนยันอีเมลของคุณได้ที่ลิงก์นี้ภายใน 24 ชั่วโมง:\n%s",
+			user.DisplayName, link),
+	})
+}
+
+// issueEmailToken สร้าง token แบบสุ่ม เก็บเฉพาะ hash และยกเลิก token เดิมในจุดประสงค์เดียวกัน
+func (s *LocalAuthService) issueEmailToken(userID uint, purpose string, ttl time.Duration) (string, error) {
+	if err := s.tokenRepo.DeleteExpired(time.Now()); err != nil {
+		log.Printf("Failed to delete expired user tokens: %v", err)
+	}
+
+	token, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	record := &models.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashOpaqueToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := s.tokenRepo.ReplaceToken(record); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// dummyPasswordHash ใช้เทียบเมื่อไม่พบบัญชี
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("league-of-quiz-dummy-password"), passwordHashCost)
+
+// normalizeEmail ตรวจรูปแบบอีเมลและแปลงเป็นตัวพิมพ์เล็ก
+func normalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return "", errors.New("invalid email address")
+	}
+	return email, nil
+}
+
+// validatePassword ตรวจความยาวรหัสผ่าน
+func validatePassword(password string) error {
+	if len(password) < minPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+	if len(password) > maxPasswordLength {
+		return fmt.Errorf("password must be at most %d bytes", maxPasswordLength)
+	}
+	return nil
+}
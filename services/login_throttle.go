@@ -0,0 +1,98 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// LoginThrottle จำกัดจำนวนครั้งที่เข้าสู่ระบบผิดพลาดต่อบัญชีและต่อ IP ภายในช่วงเวลาหนึ่ง
+// ตัวนับเก็บในหน่วยความจำของ process
+type LoginThrottle struct {
+	mu         sync.Mutex
+	window     time.Duration
+	maxAccount int
+	maxIP      int
+	failures   map[string]*loginFailures
+}
+
+// loginFailures จำนวนครั้งที่ผิดพลาดของ key หนึ่งนับจาก windowStart
+type loginFailures struct {
+	count       int
+	windowStart time.Time
+}
+
+// NewLoginThrottle สร้าง LoginThrottle ที่อนุญาตให้ผิดได้ maxAccount ครั้งต่อบัญชี และ maxIP ครั้งต่อ IP ในแต่ละ window
+func NewLoginThrottle(window time.Duration, maxAccount, maxIP int) *LoginThrottle {
+	return &LoginThrottle{
+		window:     window,
+		maxAccount: maxAccount,
+		maxIP:      maxIP,
+		failures:   make(map[string]*loginFailures),
+	}
+}
+
+// Reserve จองสิทธิ์ลองเข้าสู่ระบบหนึ่งครั้ง คืนค่าเวลาที่ต้องรอถ้าบัญชีหรือ IP ถูกจำกัด (0 หมายถึงลองได้)
+// การลองจะถูกนับทันทีภายใต้ lock เดียวกับการตรวจ เพื่อไม่ให้ request ที่ส่งพร้อมกันหลุดข้อจำกัด
+// เมื่อเข้าสู่ระบบสำเร็จให้เรียก Release เพื่อคืนสิทธิ์ที่จองไว้
+func (t *LoginThrottle) Reserve(account, ip string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	wait := t.retryAfter("account:"+account, t.maxAccount, now)
+	if ipWait := t.retryAfter("ip:"+ip, t.maxIP, now); ipWait > wait {
+		wait = ipWait
+	}
+	if wait > 0 {
+		return wait
+	}
+
+	t.prune(now)
+	t.increment("account:"+account, now)
+	t.increment("ip:"+ip, now)
+	return 0
+}
+
+// Release ล้างตัวนับของบัญชีและคืนสิทธิ์ที่จองไว้ของ IP เมื่อเข้าสู่ระบบสำเร็จ
+func (t *LoginThrottle) Release(account, ip string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.failures, "account:"+account)
+	if entry, ok := t.failures["ip:"+ip]; ok && entry.count > 0 {
+		entry.count--
+	}
+}
+
+// Reset ล้างตัวนับของบัญชีเมื่อรีเซ็ตรหัสผ่าน
+func (t *LoginThrottle) Reset(account string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.failures, "account:"+account)
+}
+
+func (t *LoginThrottle) retryAfter(key string, max int, now time.Time) time.Duration {
+	entry, ok := t.failures[key]
+	if !ok || now.Sub(entry.windowStart) >= t.window || entry.count < max {
+		return 0
+	}
+	return entry.windowStart.Add(t.window).Sub(now)
+}
+
+func (t *LoginThrottle) increment(key string, now time.Time) {
+	entry, ok := t.failures[key]
+	if !ok || now.Sub(entry.windowStart) >= t.window {
+		t.failures[key] = &loginFailures{count: 1, windowStart: now}
+		return
+	}
+	entry.count++
+}
+
+// prune ลบตัวนับที่หมด window แล้ว
+func (t *LoginThrottle) prune(now time.Time) {
+	for key, entry := range t.failures {
+		if now.Sub(entry.windowStart) >= t.window {
+			delete(t.failures, key)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/patiphanak/league-of-quiz/dto"
+	models "github.com/patiphanak/league-of-quiz/model"
+	"github.com/patiphanak/league-of-quiz/repositories"
+	"github.com/patiphanak/league-of-quiz/utils"
+	"gorm.io/gorm"
+)
+
+// authorizeOrg ตรวจสอบว่าผู้ใช้มีบทบาทในองค์กรอย่างน้อยเท่ากับ required และคืนค่าบทบาทนั้น
+func authorizeOrg(orgRepo *repositories.OrganizationRepository, orgID uint, userID uint, required string) (string, error) {
+	role, err := orgRepo.GetMemberRole(orgID, userID)
+	if err != nil {
+		return "", err
+	}
+	if !models.OrgRoleAllows(role, required) {
+		return "", fmt.Errorf("unauthorized: %s access to this organization is required", required)
+	}
+	return role, nil
+}
+
+// OrganizationService สำหรับการจัดการองค์กร สมาชิก และ quiz ที่แชร์ในองค์กร
+type OrganizationService struct {
+	orgRepo  *repositories.OrganizationRepository
+	userRepo *repositories.UserRepository
+	quizRepo *repositories.QuizRepository
+}
+
+// NewOrganizationService สร้าง instance ใหม่ของ OrganizationService
+func NewOrganizationService(
+	orgRepo *repositories.OrganizationRepository,
+	userRepo *repositories.UserRepository,
+	quizRepo *repositories.QuizRepository,
+) *OrganizationService {
+	return &OrganizationService{
+		orgRepo:  orgRepo,
+		userRepo: userRepo,
+		quizRepo: quizRepo,
+	}
+}
+
+// CreateOrganization สร้างองค์กรใหม่ ผู้สร้างเป็น owner
+func (s *OrganizationService) CreateOrganization(req dto.CreateOrganizationRequest, userID uint) (*dto.OrganizationResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("organization name is required")
+	}
+
+	slug := utils.Slugify(req.Slug)
+	if slug == "" {
+		slug = utils.Slugify(name)
+	}
+	if slug == "" {
+		return nil, errors.New("organization slug is required")
+	}
+
+	exists, err := s.orgRepo.SlugExists(slug)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.New("organization slug already exists")
+	}
+
+	org := &models.Organization{
+		Name:        name,
+		Slug:        slug,
+		CreatedByID: userID,
+	}
+	if err := s.orgRepo.CreateOrganization(org); err != nil {
+		return nil, err
+	}
+
+	response := toOrganizationResponse(*org, models.OrgRoleOwner)
+	return &response, nil
+}
+
+// ListOrganizations ดึงองค์กรทั้งหมดที่ผู้ใช้เป็นสมาชิก
+func (s *OrganizationService) ListOrganizations(userID uint) ([]dto.OrganizationResponse, error) {
+	memberships, err := s.orgRepo.GetMembershipsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.OrganizationResponse, 0, len(memberships))
+	for _, membership := range memberships {
+		result = append(result, toOrganizationResponse(membership.Organization, membership.Role))
+	}
+	return result, nil
+}
+
+// GetOrganization ดึงข้อมูลองค์กร เฉพาะสมาชิกเท่านั้น
+func (s *OrganizationService) GetOrganization(orgID uint, userID uint) (*dto.OrganizationResponse, error) {
+	role, err := authorizeOrg(s.orgRepo, orgID, userID, models.OrgRoleMember)
+	if err != nil {
+		return nil, err
+	}
+
+	org, err := s.orgRepo.GetOrganizationByID(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toOrganizationResponse(*org, role)
+	return &response, nil
+}
+
+// UpdateOrganization แก้ไขชื่อองค์กร เฉพาะ admin ขึ้นไป
+func (s *OrganizationService) UpdateOrganization(orgID uint, req dto.UpdateOrganizationRequest, userID uint) (*dto.OrganizationResponse, error) {
+	if _, err := authorizeOrg(s.orgRepo, orgID, userID, models.OrgRoleAdmin); err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("organization name is required")
+	}
+	if err := s.orgRepo.UpdateOrganization(orgID, map[string]interface{}{"name": name}); err != nil {
+		return nil, err
+	}
+	return s.GetOrganization(orgID, userID)
+}
+
+// DeleteOrganization ลบองค์กร เฉพาะ owner
+func (s *OrganizationService) DeleteOrganization(orgID uint, userID uint) error {
+	if _, err := authorizeOrg(s.orgRepo, orgID, userID, models.OrgRoleOwner); err != nil {
+		return err
+	}
+	return s.orgRepo.DeleteOrganization(orgID)
+}
+
+// ListMembers ดึงรายชื่อสมาชิกขององค์กร เฉพาะสมาชิกเท่านั้น
+func (s *OrganizationService) ListMembers(orgID uint, userID uint) ([]dto.OrganizationMemberResponse, error) {
+	if _, err := authorizeOrg(s.orgRepo, orgID, userID, models.OrgRoleMember); err != nil {
+		return nil, err
+	}
+
+	members, err := s.orgRepo.GetMembers(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.OrganizationMemberResponse, 0, len(members))
+	for _, member := range members {
+		result = append(result, toOrganizationMemberResponse(member))
+	}
+	return result, nil
+}
+
+// AddMember เพิ่มสมาชิกด้วยอีเมล ถ้าเป็นสมาชิกอยู่แล้วจะเปลี่ยนบทบาทแทน
+// admin จัดการได้เฉพาะบทบาทที่ต่ำกว่า owner ส่วนการตั้งหรือเปลี่ยน owner ต้องเป็น owner
+func (s *OrganizationService) AddMember(orgID uint, req dto.AddOrganizationMemberRequest, userID uint) (*dto.OrganizationMemberResponse, error) {
+	actorRole, err := authorizeOrg(s.orgRepo, orgID, userID, models.OrgRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	email := strings.TrimSpace(req.Email)
+	if email == "" {
+		return nil, errors.New("email is required")
+	}
+	role := req.Role
+	if role == "" {
+		role = models.OrgRoleMember
+	}
+	if !models.IsValidOrgRole(role) {
+		return nil, errors.New("role must be one of member, teacher, admin or owner")
+	}
+
+	user, err := s.userRepo.GetUserByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+
+	// ใช้บทบาทจากแถวสมาชิกจริง ไม่ใช่บทบาทที่ได้จากการเป็นผู้ดูแลระบบ
+	currentRole := ""
+	existing, err := s.orgRepo.GetMember(orgID, user.ID)
+	if err == nil {
+		currentRole = existing.Role
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if (role == models.OrgRoleOwner || currentRole == models.OrgRoleOwner) && actorRole != models.OrgRoleOwner {
+		return nil, errors.New("unauthorized: only an owner can grant or change the owner role")
+	}
+	if currentRole == models.OrgRoleOwner && role != models.OrgRoleOwner {
+		if err := s.ensureAnotherOwner(orgID); err != nil {
+			return nil, err
+		}
+	}
+
+	member := &models.OrganizationMember{
+		OrganizationID: orgID,
+		UserID:         user.ID,
+		Role:           role,
+	}
+	if err := s.orgRepo.UpsertMember(member); err != nil {
+		return nil, err
+	}
+
+	member.User = *user
+	response := toOrganizationMemberResponse(*member)
+	return &response, nil
+}
+
+// RemoveMember ลบสมาชิกออกจากองค์กร admin ลบสมาชิกได้ และสมาชิกออกจากองค์กรเองได้
+// owner คนสุดท้ายออกจากองค์กรไม่ได้
+func (s *OrganizationService) RemoveMember(orgID uint, memberID uint, userID uint) error {
+	actorRole := ""
+	if memberID != userID {
+		role, err := authorizeOrg(s.orgRepo, orgID, userID, models.OrgRoleAdmin)
+		if err != nil {
+			return err
+		}
+		actorRole = role
+	}
+
+	member, err := s.orgRepo.GetMember(orgID, memberID)
+	if err != nil {
+		return err
+	}
+	if member.Role == models.OrgRoleOwner {
+		if memberID != userID && actorRole != models.OrgRoleOwner {
+			return errors.New("unauthorized: only an owner can remove another owner")
+		}
+		if err := s.ensureAnotherOwner(orgID); err != nil {
+			return err
+		}
+	}
+
+	return s.orgRepo.DeleteMember(orgID, memberID)
+}
+
+// ensureAnotherOwner ป้องกันไม่ให้องค์กรไม่มี owner เหลืออยู่
+func (s *OrganizationService) ensureAnotherOwner(orgID uint) error {
+	owners, err := s.orgRepo.CountOwners(orgID)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return errors.New("an organization must keep at least one owner")
+	}
+	return nil
+}
+
+// ListQuizzes ดึง quiz ที่แชร์ในองค์กร ใช้ตัวกรองเดียวกับรายการ quiz ทั่วไป
+func (s *OrganizationService) ListQuizzes(orgID uint, userID uint, offset, limit int, search string, categories []uint) ([]models.Quiz, int64, error) {
+	if _, err := authorizeOrg(s.orgRepo, orgID, userID, models.OrgRoleMember); err != nil {
+		return nil, 0, err
+	}
+	return s.quizRepo.GetFilteredQuizzes(offset, limit, "", search, categories, orgID)
+}
+
+// ShareQuiz แชร์ quiz ให้องค์กร หรือยกเลิกการแชร์เมื่อ orgID เป็น nil
+// ต้องเป็น owner ของ quiz และเป็น teacher ขึ้นไปในองค์กรปลายทาง
+func (s *OrganizationService) ShareQuiz(quizID uint, orgID *uint, userID uint) error {
+	if err := authorizeQuiz(s.quizRepo, quizID, userID, models.QuizRoleOwner); err != nil {
+		return err
+	}
+	if orgID != nil {
+		if _, err := authorizeOrg(s.orgRepo, *orgID, userID, models.OrgRoleTeacher); err != nil {
+			return err
+		}
+	}
+	return s.quizRepo.SetQuizOrganization(quizID, orgID)
+}
+
+// toOrganizationResponse แปลงองค์กรและบทบาทของผู้ใช้เป็น response
+func toOrganizationResponse(org models.Organization, role string) dto.OrganizationResponse {
+	return dto.OrganizationResponse{
+		ID:        org.ID,
+		Name:      org.Name,
+		Slug:      org.Slug,
+		Role:      role,
+		CreatedAt: org.CreatedAt,
+	}
+}
+
+// toOrganizationMemberResponse แปลงสมาชิกขององค์กรเป็น response
+func toOrganizationMemberResponse(member models.OrganizationMember) dto.OrganizationMemberResponse {
+	return dto.OrganizationMemberResponse{
+		UserID:      member.UserID,
+		Email:       member.User.Email,
+		DisplayName: member.User.DisplayName,
+		Role:        member.Role,
+		CreatedAt:   member.CreatedAt,
+	}
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/patiphanak/league-of-quiz/dto"
+	models "github.com/patiphanak/league-of-quiz/model"
+	"github.com/patiphanak/league-of-quiz/repositories"
+)
+
+const (
+	maxTokenNameLength     = 100
+	maxTokenExpiresInDays  = 365
+	tokenLastUsedInterval  = time.Minute
+	personalTokenPrefixLen = len(models.PersonalAccessTokenPrefix) + 6
+)
+
+var ErrInvalidAccessToken = errors.New("invalid personal access token")
+
+// PersonalAccessTokenService จัดการ personal access token สำหรับเรียก API จากสคริปต์
+type PersonalAccessTokenService struct {
+	tokenRepo *repositories.PersonalAccessTokenRepository
+}
+
+// NewPersonalAccessTokenService สร้าง instance ใหม่ของ PersonalAccessTokenService
+func NewPersonalAccessTokenService(tokenRepo *repositories.PersonalAccessTokenRepository) *PersonalAccessTokenService {
+	return &PersonalAccessTokenService{tokenRepo: tokenRepo}
+}
+
+// CreateToken สร้าง token ใหม่ ค่า token จริงคืนให้ครั้งเดียว ในฐานข้อมูลเก็บแค่ hash
+func (s *PersonalAccessTokenService) CreateToken(userID uint, req dto.CreatePersonalAccessTokenRequest) (*dto.CreatedPersonalAccessTokenResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("token name is required")
+	}
+	if len([]rune(name)) > maxTokenNameLength {
+		return nil, fmt.Errorf("token name must be at most %d characters", maxTokenNameLength)
+	}
+
+	scopes, err := normalizeScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ExpiresInDays < 0 || req.ExpiresInDays > maxTokenExpiresInDays {
+		return nil, fmt.Errorf("expiresInDays must be between 0 and %d", maxTokenExpiresInDays)
+	}
+	var expiresAt *time.Time
+	if req.ExpiresInDays > 0 {
+		t := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		expiresAt = &t
+	}
+
+	secret, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	token := models.PersonalAccessTokenPrefix + secret
+
+	record := &models.PersonalAccessToken{
+		UserID:      userID,
+		Name:        name,
+		TokenPrefix: token[:personalTokenPrefixLen],
+		TokenHash:   hashOpaqueToken(token),
+		Scopes:      strings.Join(scopes, " "),
+		ExpiresAt:   expiresAt,
+	}
+	if err := s.tokenRepo.CreateToken(record); err != nil {
+		return nil, err
+	}
+
+	return &dto.CreatedPersonalAccessTokenResponse{
+		PersonalAccessTokenResponse: toPersonalAccessTokenResponse(*record),
+		Token:                       token,
+	}, nil
+}
+
+// ListTokens ดึง token ที่ยังไม่ถูกเพิกถอนของผู้ใช้
+func (s *PersonalAccessTokenService) ListTokens(userID uint) ([]dto.PersonalAccessTokenResponse, error) {
+	tokens, err := s.tokenRepo.GetActiveTokensByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.PersonalAccessTokenResponse, 0, len(tokens))
+	for _, token := range tokens {
+		result = append(result, toPersonalAccessTokenResponse(token))
+	}
+	return result, nil
+}
+
+// RevokeToken เพิกถอน token ของผู้ใช้
+func (s *PersonalAccessTokenService) RevokeToken(id, userID uint) error {
+	return s.tokenRepo.RevokeToken(id, userID)
+}
+
+// Authenticate ตรวจ token จาก Authorization header และคืนค่าผู้ใช้เจ้าของ token
+// บันทึกเวลาที่ใช้ล่าสุดไม่บ่อยกว่า tokenLastUsedInterval
+func (s *PersonalAccessTokenService) Authenticate(token string) (*models.PersonalAccessToken, error) {
+	record, err := s.tokenRepo.GetTokenByHash(hashOpaqueToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAccessToken
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if !record.IsActive(now) {
+		return nil, ErrInvalidAccessToken
+	}
+
+	if record.LastUsedAt == nil || now.Sub(*record.LastUsedAt) >= tokenLastUsedInterval {
+		if err := s.tokenRepo.UpdateLastUsed(record.ID, now); err != nil {
+			log.Printf("Failed to update last use of access token %d: %v", record.ID, err)
+		}
+		record.LastUsedAt = &now
+	}
+	return record, nil
+}
+
+// normalizeScopes ตรวจและตัด scope ที่ซ้ำออก
+func normalizeScopes(scopes []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var result []string
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if !models.IsValidTokenScope(scope) {
+			return nil, fmt.Errorf("unknown scope %q (allowed: %s, %s, %s)", scope,
+				models.ScopeQuizzesRead, models.ScopeQuizzesWrite, models.ScopeGamesHost)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			result = append(result, scope)
+		}
+	}
+	if len(result) == 0 {
+		return nil, errors.New("at least one scope is required")
+	}
+	return result, nil
+}
+
+func toPersonalAccessTokenResponse(token models.PersonalAccessToken) dto.PersonalAccessTokenResponse {
+	return dto.PersonalAccessTokenResponse{
+		ID:          token.ID,
+		Name:        token.Name,
+		TokenPrefix: token.TokenPrefix,
+		Scopes:      token.ScopeList(),
+		ExpiresAt:   token.ExpiresAt,
+		LastUsedAt:  token.LastUsedAt,
+		CreatedAt:   token.CreatedAt,
+	}
+}
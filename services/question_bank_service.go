@@ -0,0 +1,317 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+
+	"github.com/patiphanak/league-of-quiz/dto"
+	models "github.com/patiphanak/league-of-quiz/model"
+	"github.com/patiphanak/league-of-quiz/repositories"
+)
+
+// QuestionBankService สำหรับการจัดการคลังข้อสอบส่วนตัว
+type QuestionBankService struct {
+	bankRepo     *repositories.QuestionBankRepository
+	questionRepo *repositories.QuestionRepository
+	quizRepo     *repositories.QuizRepository
+	fileService  *FileService
+}
+
+// NewQuestionBankService สร้าง instance ใหม่ของ QuestionBankService
+func NewQuestionBankService(
+	bankRepo *repositories.QuestionBankRepository,
+	questionRepo *repositories.QuestionRepository,
+	quizRepo *repositories.QuizRepository,
+	fileService *FileService,
+) *QuestionBankService {
+	return &QuestionBankService{
+		bankRepo:     bankRepo,
+		questionRepo: questionRepo,
+		quizRepo:     quizRepo,
+		fileService:  fileService,
+	}
+}
+
+// checkOwnership ตรวจสอบว่าผู้ใช้เป็นเจ้าของคำถามในคลังหรือไม่
+func (s *QuestionBankService) checkOwnership(bankQuestionID uint, userID uint) error {
+	isOwner, err := s.bankRepo.CheckBankQuestionOwnership(bankQuestionID, userID)
+	if err != nil {
+		return err
+	}
+	if !isOwner {
+		return errors.New("unauthorized: you are not the owner of this bank question")
+	}
+	return nil
+}
+
+// buildBankChoices แปลงข้อมูลตัวเลือกจาก form เป็น model
+func buildBankChoices(choices []dto.ChoiceFormData) []models.BankChoice {
+	bankChoices := make([]models.BankChoice, 0, len(choices))
+	for i, choice := range choices {
+		bankChoices = append(bankChoices, models.BankChoice{
+			Text:      choice.Text,
+			IsCorrect: choice.IsCorrect,
+			Position:  i + 1,
+		})
+	}
+	return bankChoices
+}
+
+// CreateBankQuestion สร้างคำถามใหม่ในคลังของผู้ใช้
+func (s *QuestionBankService) CreateBankQuestion(formData dto.BankQuestionFormData, imageFile *multipart.FileHeader, userID uint) (*models.BankQuestion, error) {
+	question := &models.BankQuestion{
+		OwnerID:     userID,
+		Text:        formData.Text,
+		Explanation: formData.Explanation,
+		Choices:     buildBankChoices(formData.Choices),
+	}
+
+	if imageFile != nil {
+		imageURL, err := s.fileService.UploadFile(imageFile, string(QuestionType))
+		if err != nil {
+			return nil, err
+		}
+		question.ImageURL = imageURL
+	}
+
+	if err := s.bankRepo.CreateBankQuestion(question, formData.Tags); err != nil {
+		return nil, err
+	}
+	return s.bankRepo.GetBankQuestionByID(question.ID)
+}
+
+// GetBankQuestion ดึงคำถามในคลังพร้อมรายการ quiz ที่นำไปใช้
+func (s *QuestionBankService) GetBankQuestion(id uint, userID uint) (*models.BankQuestion, []uint, error) {
+	if err := s.checkOwnership(id, userID); err != nil {
+		return nil, nil, err
+	}
+
+	question, err := s.bankRepo.GetBankQuestionByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	quizIDs, err := s.bankRepo.GetLinkedQuizIDs(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return question, quizIDs, nil
+}
+
+// SearchBankQuestions ค้นหาคำถามในคลังของผู้ใช้
+func (s *QuestionBankService) SearchBankQuestions(userID uint, search string, tags []string, offset, limit int) ([]models.BankQuestion, int64, error) {
+	return s.bankRepo.SearchBankQuestions(userID, search, tags, offset, limit)
+}
+
+// UpdateBankQuestion อัปเดตคำถามในคลัง แล้วซิงก์การแก้ไขไปยังคำถามใน quiz ทุกข้อที่ลิงก์กับคำถามนี้
+// คืนค่า ID ของคำถามใน quiz ที่ไม่ได้ซิงก์เพราะมีคำตอบจากเกมที่เล่นไปแล้ว
+func (s *QuestionBankService) UpdateBankQuestion(id uint, formData dto.BankQuestionFormData, imageFile *multipart.FileHeader, userID uint) (*models.BankQuestion, []uint, error) {
+	if err := s.checkOwnership(id, userID); err != nil {
+		return nil, nil, err
+	}
+
+	existing, err := s.bankRepo.GetBankQuestionByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	question := &models.BankQuestion{
+		ID:          id,
+		OwnerID:     existing.OwnerID,
+		Text:        formData.Text,
+		ImageURL:    existing.ImageURL,
+		Explanation: formData.Explanation,
+		Choices:     buildBankChoices(formData.Choices),
+	}
+
+	if imageFile != nil {
+		imageURL, err := s.fileService.UpdateFile(imageFile, existing.ImageURL, string(QuestionType))
+		if err != nil {
+			return nil, nil, err
+		}
+		question.ImageURL = imageURL
+	}
+
+	if err := s.bankRepo.UpdateBankQuestion(question, formData.Tags); err != nil {
+		return nil, nil, err
+	}
+
+	updated, err := s.bankRepo.GetBankQuestionByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	skipped, err := s.syncLinkedQuestions(updated, imageFile != nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("bank question updated but failed to sync linked quiz questions: %w", err)
+	}
+	return updated, skipped, nil
+}
+
+// syncLinkedQuestions อัปเดตข้อความ คำอธิบาย และตัวเลือกของคำถามใน quiz ที่ลิงก์กับคำถามในคลัง
+// แต่ละ quiz มีไฟล์รูปภาพของตัวเอง ถ้ารูปในคลังเปลี่ยนจะคัดลอกรูปใหม่ให้และลบรูปเดิมของ quiz
+// คำถามที่มีคำตอบจากเกมที่เล่นไปแล้วจะถูกข้ามและคืนค่า ID กลับไป
+func (s *QuestionBankService) syncLinkedQuestions(bankQuestion *models.BankQuestion, imageChanged bool) ([]uint, error) {
+	linked, err := s.questionRepo.GetQuestionsByBankQuestionID(bankQuestion.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	skipped := []uint{}
+	for _, question := range linked {
+		updates := map[string]interface{}{
+			"text":        bankQuestion.Text,
+			"explanation": bankQuestion.Explanation,
+		}
+		if imageChanged {
+			imageURL, err := s.fileService.CopyFileByURL(bankQuestion.ImageURL, string(QuestionType))
+			if err != nil {
+				return nil, fmt.Errorf("failed to copy question image: %w", err)
+			}
+			updates["image_url"] = imageURL
+		}
+
+		synced, err := s.questionRepo.SyncQuestionFromBank(question.ID, updates, bankQuestion.Choices)
+		if err != nil {
+			return nil, err
+		}
+		if !synced {
+			// ลบรูปที่คัดลอกไว้ เพราะคำถามนี้ยังใช้รูปเดิม
+			if imageURL, ok := updates["image_url"].(string); ok && imageURL != "" {
+				_ = s.fileService.DeleteFileByURL(imageURL)
+			}
+			skipped = append(skipped, question.ID)
+			continue
+		}
+		if imageChanged && question.ImageURL != "" && s.fileService.IsStoredFile(question.ImageURL) {
+			_ = s.fileService.DeleteFileByURL(question.ImageURL)
+		}
+	}
+	return skipped, nil
+}
+
+// DeleteBankQuestion ลบคำถามในคลัง
+func (s *QuestionBankService) DeleteBankQuestion(id uint, userID uint) error {
+	if err := s.checkOwnership(id, userID); err != nil {
+		return err
+	}
+
+	question, err := s.bankRepo.GetBankQuestionByID(id)
+	if err != nil {
+		return err
+	}
+
+	if question.ImageURL != "" {
+		_ = s.fileService.DeleteFileByURL(question.ImageURL)
+	}
+
+	return s.bankRepo.DeleteBankQuestion(id)
+}
+
+// toQuizQuestions สร้างคำถามของ quiz จากคำถามในคลัง พร้อมคัดลอกรูปภาพเป็นไฟล์ใหม่
+func (s *QuestionBankService) toQuizQuestions(bankQuestions []models.BankQuestion) ([]models.Question, error) {
+	questions := make([]models.Question, 0, len(bankQuestions))
+	for _, bankQuestion := range bankQuestions {
+		bankQuestionID := bankQuestion.ID
+		imageURL, err := s.fileService.CopyFileByURL(bankQuestion.ImageURL, string(QuestionType))
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy question image: %w", err)
+		}
+
+		question := models.Question{
+			Text:           bankQuestion.Text,
+			ImageURL:       imageURL,
+			Explanation:    bankQuestion.Explanation,
+			BankQuestionID: &bankQuestionID,
+		}
+		for _, bankChoice := range bankQuestion.Choices {
+			choiceImageURL, err := s.fileService.CopyFileByURL(bankChoice.ImageURL, string(ChoiceType))
+			if err != nil {
+				return nil, fmt.Errorf("failed to copy choice image: %w", err)
+			}
+			question.Choices = append(question.Choices, models.Choice{
+				Text:      bankChoice.Text,
+				ImageURL:  choiceImageURL,
+				IsCorrect: bankChoice.IsCorrect,
+				Position:  bankChoice.Position,
+			})
+		}
+		questions = append(questions, question)
+	}
+	return questions, nil
+}
+
+// AddToQuiz นำคำถามจากคลังไปเพิ่มต่อท้าย quiz โดยลิงก์กับคำถามในคลังผ่าน BankQuestionID
+// การแก้ไขคำถามในคลังภายหลังจะถูกซิงก์ไปยัง quiz ด้วย
+func (s *QuestionBankService) AddToQuiz(quizID uint, bankQuestionIDs []uint, userID uint) ([]models.Question, error) {
+	// ตรวจสอบว่าผู้ใช้มีสิทธิ์แก้ไข quiz หรือไม่
+	if err := authorizeQuiz(s.quizRepo, quizID, userID, models.QuizRoleEditor); err != nil {
+		return nil, err
+	}
+
+	if len(bankQuestionIDs) == 0 {
+		return nil, errors.New("bank question IDs are required")
+	}
+
+	// ดึงเฉพาะคำถามที่ผู้ใช้เป็นเจ้าของ
+	bankQuestions, err := s.bankRepo.GetBankQuestionsByIDs(userID, bankQuestionIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(bankQuestions) != len(bankQuestionIDs) {
+		return nil, errors.New("unauthorized: some bank questions were not found or are not yours")
+	}
+
+	questions, err := s.toQuizQuestions(bankQuestions)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.questionRepo.CreateQuestionsInQuiz(quizID, questions); err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+// DrawQuiz สร้าง quiz ใหม่โดยสุ่มคำถามจากคลังตาม tag ที่กำหนด
+func (s *QuestionBankService) DrawQuiz(req dto.DrawQuizRequest, userID uint) (*models.Quiz, error) {
+	if req.Title == "" {
+		return nil, errors.New("title is required")
+	}
+	if req.Count <= 0 {
+		return nil, errors.New("count must be greater than zero")
+	}
+
+	bankQuestions, err := s.bankRepo.GetRandomBankQuestions(userID, req.Tags, req.Count)
+	if err != nil {
+		return nil, err
+	}
+	if len(bankQuestions) < req.Count {
+		return nil, fmt.Errorf("not enough questions in bank: found %d, requested %d", len(bankQuestions), req.Count)
+	}
+
+	questions, err := s.toQuizQuestions(bankQuestions)
+	if err != nil {
+		return nil, err
+	}
+	for i := range questions {
+		questions[i].Position = i + 1
+	}
+
+	description := req.Description
+	if description == "" {
+		description = req.Title
+	}
+
+	// gorm สร้าง quiz พร้อมคำถามและตัวเลือกทั้งหมดใน transaction เดียว
+	quiz := &models.Quiz{
+		Title:       req.Title,
+		Description: description,
+		CreatorID:   userID,
+		Questions:   questions,
+	}
+	if err := s.quizRepo.CreateQuiz(quiz); err != nil {
+		return nil, err
+	}
+	return quiz, nil
+}
@@ -0,0 +1,504 @@
+package services
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+
+	dto "github.com/patiphanak/league-of-quiz/dto"
+	models "github.com/patiphanak/league-of-quiz/model"
+	"github.com/patiphanak/league-of-quiz/quizformat"
+)
+
+// maxImportChoices จำนวนคอลัมน์ตัวเลือกสูงสุดต่อแถว (choice1 ถึง choice6)
+const maxImportChoices = 6
+
+// importSheetRow แถวข้อมูลจากไฟล์นำเข้า พร้อมหมายเลขแถวจริงในไฟล์
+type importSheetRow struct {
+	Number int
+	Cells  []string
+}
+
+// importColumns ตำแหน่งคอลัมน์ที่อ่านได้จากแถวหัวตาราง (-1 คือไม่มีคอลัมน์นั้น)
+type importColumns struct {
+	question  int
+	correct   int
+	timeLimit int
+	imageURL  int
+	choices   [maxImportChoices]int
+}
+
+// importedQuestion คำถามที่ผ่านการตรวจสอบแล้วพร้อมบันทึก
+type importedQuestion struct {
+	row      int
+	question models.Question
+	choices  []dto.ChoiceFormData
+}
+
+// withTx คืนค่า QuestionService ที่ใช้ repository ภายใน transaction ที่กำหนด
+func (s *QuestionService) withTx(tx *gorm.DB) *QuestionService {
+	return &QuestionService{
+		questionRepo: s.questionRepo.WithTx(tx),
+		quizRepo:     s.quizRepo.WithTx(tx),
+		choiceRepo:   s.choiceRepo.WithTx(tx),
+		fileService:  s.fileService,
+	}
+}
+
+// ImportQuestions นำเข้าคำถามจากไฟล์ CSV หรือ XLSX ทีละแถว
+// ถ้าเป็น dry run จะตรวจสอบอย่างเดียวโดยไม่บันทึก ส่วนแถวที่ถูกต้องจะถูกบันทึกใน transaction เดียว
+func (s *QuestionService) ImportQuestions(quizID uint, file *multipart.FileHeader, dryRun bool, userID uint) (*dto.QuestionImportResult, error) {
+	if err := authorizeQuiz(s.quizRepo, quizID, userID, models.QuizRoleEditor); err != nil {
+		return nil, err
+	}
+
+	rows, err := readImportSheet(file)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("file is empty")
+	}
+
+	columns, err := parseImportHeader(rows[0].Cells)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.QuestionImportResult{
+		DryRun:      dryRun,
+		QuestionIDs: []uint{},
+		Errors:      []dto.QuestionImportRowError{},
+	}
+
+	var valid []importedQuestion
+	for _, row := range rows[1:] {
+		if isBlankRow(row.Cells) {
+			continue
+		}
+		result.TotalRows++
+
+		imported, rowErrors := columns.parseRow(row)
+		if imageURL := imported.question.ImageURL; imageURL != "" {
+			if err := s.fileService.ValidateStoredFileURL(imageURL); err != nil {
+				rowErrors = append(rowErrors, fmt.Sprintf("image URL %q must point to an image uploaded to this server", imageURL))
+			}
+		}
+		if len(rowErrors) > 0 {
+			result.Errors = append(result.Errors, dto.QuestionImportRowError{Row: row.Number, Errors: rowErrors})
+			continue
+		}
+		imported.question.QuizID = quizID
+		valid = append(valid, imported)
+	}
+	result.ValidRows = len(valid)
+
+	if dryRun || len(valid) == 0 {
+		return result, nil
+	}
+
+	if err := s.createImportedQuestions(valid, userID, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ImportFormattedQuestions นำเข้าคำถามจากไฟล์ข้อความรูปแบบ GIFT หรือ Aiken
+// คำถามที่แปลงไม่ได้จะถูกรายงานใน Errors ส่วนข้อมูลที่หายไปบางส่วนจะถูกรายงานใน Warnings
+func (s *QuestionService) ImportFormattedQuestions(quizID uint, format quizformat.Format, file *multipart.FileHeader, dryRun bool, userID uint) (*dto.QuestionImportResult, error) {
+	if err := authorizeQuiz(s.quizRepo, quizID, userID, models.QuizRoleEditor); err != nil {
+		return nil, err
+	}
+
+	if file == nil {
+		return nil, errors.New("import file is required")
+	}
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	content, err := io.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, issues := quizformat.Parse(format, string(content))
+
+	result := &dto.QuestionImportResult{
+		DryRun:      dryRun,
+		ValidRows:   len(parsed),
+		QuestionIDs: []uint{},
+		Errors:      []dto.QuestionImportRowError{},
+	}
+	for _, issue := range issues {
+		rowError := dto.QuestionImportRowError{Row: issue.Line, Errors: []string{issue.Message}}
+		if issue.Skipped {
+			result.Errors = append(result.Errors, rowError)
+		} else {
+			result.Warnings = append(result.Warnings, rowError)
+		}
+	}
+	result.TotalRows = len(parsed) + len(result.Errors)
+
+	valid := make([]importedQuestion, 0, len(parsed))
+	for _, item := range parsed {
+		imported := importedQuestion{row: item.Line, question: item.Question}
+		imported.question.QuizID = quizID
+		imported.question.Choices = nil
+		for _, choice := range item.Question.Choices {
+			imported.choices = append(imported.choices, dto.ChoiceFormData{Text: choice.Text, IsCorrect: choice.IsCorrect})
+		}
+		valid = append(valid, imported)
+	}
+
+	if dryRun || len(valid) == 0 {
+		return result, nil
+	}
+
+	if err := s.createImportedQuestions(valid, userID, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// createImportedQuestions บันทึกคำถามที่ผ่านการตรวจสอบทั้งหมดใน transaction เดียว
+// รูปภาพที่อ้างถึงจะถูกคัดลอกเป็นไฟล์ใหม่ของคำถาม เพื่อไม่ให้การลบคำถามกระทบไฟล์ต้นฉบับ
+// ถ้าคำถามใดล้มเหลวจะไม่มีคำถามใดถูกบันทึก และไฟล์ที่คัดลอกไปแล้วจะถูกลบ
+func (s *QuestionService) createImportedQuestions(valid []importedQuestion, userID uint, result *dto.QuestionImportResult) error {
+	var copied []string
+	err := s.quizRepo.GetDB().Transaction(func(tx *gorm.DB) error {
+		txService := s.withTx(tx)
+		for i := range valid {
+			if imageURL := valid[i].question.ImageURL; imageURL != "" {
+				copiedURL, err := s.fileService.CopyFileByURL(imageURL, string(QuestionType))
+				if err != nil {
+					return fmt.Errorf("row %d: failed to copy image: %w", valid[i].row, err)
+				}
+				copied = append(copied, copiedURL)
+				valid[i].question.ImageURL = copiedURL
+			}
+			questionID, err := txService.CreateQuestionWithChoices(&valid[i].question, valid[i].choices, QuestionFiles{}, userID)
+			if err != nil {
+				return fmt.Errorf("row %d: %w", valid[i].row, err)
+			}
+			result.QuestionIDs = append(result.QuestionIDs, questionID)
+		}
+		return nil
+	})
+	if err != nil {
+		for _, fileURL := range copied {
+			_ = s.fileService.DeleteFileByURL(fileURL)
+		}
+		result.QuestionIDs = []uint{}
+		return err
+	}
+
+	result.Created = len(result.QuestionIDs)
+	return nil
+}
+
+// parseImportHeader อ่านแถวหัวตารางเพื่อหาตำแหน่งคอลัมน์ที่ต้องใช้
+func parseImportHeader(header []string) (*importColumns, error) {
+	columns := &importColumns{question: -1, correct: -1, timeLimit: -1, imageURL: -1}
+	for i := range columns.choices {
+		columns.choices[i] = -1
+	}
+
+	for i, name := range header {
+		key := strings.ToLower(strings.TrimSpace(name))
+		key = strings.NewReplacer(" ", "", "_", "", "-", "").Replace(key)
+
+		switch key {
+		case "question", "text":
+			columns.question = i
+		case "correct", "answer":
+			columns.correct = i
+		case "timelimit":
+			columns.timeLimit = i
+		case "imageurl", "image":
+			columns.imageURL = i
+		default:
+			if n, ok := strings.CutPrefix(key, "choice"); ok {
+				index, err := strconv.Atoi(n)
+				if err == nil && index >= 1 && index <= maxImportChoices {
+					columns.choices[index-1] = i
+				}
+			}
+		}
+	}
+
+	if columns.question < 0 {
+		return nil, errors.New("header row must contain a question column")
+	}
+	if columns.correct < 0 {
+		return nil, errors.New("header row must contain a correct column")
+	}
+	if columns.choices[0] < 0 || columns.choices[1] < 0 {
+		return nil, errors.New("header row must contain at least choice1 and choice2 columns")
+	}
+	return columns, nil
+}
+
+// parseRow ตรวจสอบแถวข้อมูลและแปลงเป็นคำถาม คืนค่ารายการข้อผิดพลาดทั้งหมดของแถว
+func (c *importColumns) parseRow(row importSheetRow) (importedQuestion, []string) {
+	var rowErrors []string
+	cell := func(index int) string {
+		if index < 0 || index >= len(row.Cells) {
+			return ""
+		}
+		return strings.TrimSpace(row.Cells[index])
+	}
+
+	imported := importedQuestion{row: row.Number}
+	imported.question.Text = cell(c.question)
+	if imported.question.Text == "" {
+		rowErrors = append(rowErrors, "question text is required")
+	}
+
+	// เก็บตำแหน่งคอลัมน์ตัวเลือกเดิม เพื่อให้ correct อ้างอิงตามหมายเลขคอลัมน์ได้
+	choiceIndex := make(map[int]int)
+	for i, column := range c.choices {
+		text := cell(column)
+		if text == "" {
+			continue
+		}
+		choiceIndex[i+1] = len(imported.choices)
+		imported.choices = append(imported.choices, dto.ChoiceFormData{Text: text})
+	}
+	if len(imported.choices) < 2 {
+		rowErrors = append(rowErrors, "at least two choices are required")
+	}
+
+	correct := cell(c.correct)
+	if correct == "" {
+		rowErrors = append(rowErrors, "correct choice index is required")
+	}
+	for _, value := range strings.FieldsFunc(correct, func(r rune) bool { return r == ',' || r == ';' }) {
+		index, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			rowErrors = append(rowErrors, fmt.Sprintf("correct choice index %q is not a number", value))
+			continue
+		}
+		position, ok := choiceIndex[index]
+		if !ok {
+			rowErrors = append(rowErrors, fmt.Sprintf("correct choice index %d does not refer to a choice", index))
+			continue
+		}
+		imported.choices[position].IsCorrect = true
+	}
+
+	if value := cell(c.timeLimit); value != "" {
+		timeLimit, err := strconv.ParseUint(value, 10, 32)
+		if err != nil || timeLimit == 0 {
+			rowErrors = append(rowErrors, fmt.Sprintf("time limit %q must be a positive number of seconds", value))
+		} else {
+			imported.question.TimeLimit = uint(timeLimit)
+		}
+	}
+
+	// URL รูปภาพถูกตรวจสอบกับ storage ใน ImportQuestions
+	imported.question.ImageURL = cell(c.imageURL)
+
+	return imported, rowErrors
+}
+
+// isBlankRow ตรวจสอบว่าแถวไม่มีข้อมูลเลย
+func isBlankRow(cells []string) bool {
+	for _, cell := range cells {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// readImportSheet อ่านแถวทั้งหมดจากไฟล์ CSV หรือ XLSX ตามนามสกุลไฟล์
+func readImportSheet(file *multipart.FileHeader) ([]importSheetRow, error) {
+	if file == nil {
+		return nil, errors.New("import file is required")
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	switch strings.ToLower(filepath.Ext(file.Filename)) {
+	case ".csv":
+		return readCSVRows(src)
+	case ".xlsx":
+		return readXLSXRows(src, file.Size)
+	default:
+		return nil, errors.New("unsupported file format: only .csv and .xlsx are supported")
+	}
+}
+
+// readCSVRows อ่านแถวจากไฟล์ CSV
+func readCSVRows(src io.Reader) ([]importSheetRow, error) {
+	reader := csv.NewReader(src)
+	reader.FieldsPerRecord = -1
+
+	var rows []importSheetRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		if len(rows) == 0 && len(record) > 0 {
+			// ตัด BOM ที่โปรแกรม spreadsheet มักใส่ไว้ต้นไฟล์
+			record[0] = strings.TrimPrefix(record[0], "\ufeff")
+		}
+		rows = append(rows, importSheetRow{Number: line, Cells: record})
+	}
+	return rows, nil
+}
+
+// xlsxSharedStrings ตารางข้อความที่ใช้ร่วมกันในไฟล์ XLSX
+type xlsxSharedStrings struct {
+	Items []struct {
+		Text string `xml:"t"`
+		Runs []struct {
+			Text string `xml:"t"`
+		} `xml:"r"`
+	} `xml:"si"`
+}
+
+// xlsxWorksheet ข้อมูลแถวและเซลล์ใน worksheet ของไฟล์ XLSX
+type xlsxWorksheet struct {
+	Rows []struct {
+		Number int `xml:"r,attr"`
+		Cells  []struct {
+			Ref    string `xml:"r,attr"`
+			Type   string `xml:"t,attr"`
+			Value  string `xml:"v"`
+			Inline struct {
+				Text string `xml:"t"`
+			} `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readXLSXRows อ่านแถวจาก worksheet แรกของไฟล์ XLSX
+func readXLSXRows(src io.ReaderAt, size int64) ([]importSheetRow, error) {
+	zr, err := zip.NewReader(src, size)
+	if err != nil {
+		return nil, errors.New("invalid XLSX: not a zip archive")
+	}
+
+	var sheetFiles []*zip.File
+	var sharedStringsFile *zip.File
+	for _, f := range zr.File {
+		switch {
+		case f.Name == "xl/sharedStrings.xml":
+			sharedStringsFile = f
+		case strings.HasPrefix(f.Name, "xl/worksheets/") && strings.HasSuffix(f.Name, ".xml"):
+			sheetFiles = append(sheetFiles, f)
+		}
+	}
+	if len(sheetFiles) == 0 {
+		return nil, errors.New("invalid XLSX: no worksheet found")
+	}
+	sort.Slice(sheetFiles, func(i, j int) bool { return sheetFiles[i].Name < sheetFiles[j].Name })
+
+	var sharedStrings []string
+	if sharedStringsFile != nil {
+		var sst xlsxSharedStrings
+		if err := decodeZipXML(sharedStringsFile, &sst); err != nil {
+			return nil, err
+		}
+		for _, item := range sst.Items {
+			text := item.Text
+			for _, run := range item.Runs {
+				text += run.Text
+			}
+			sharedStrings = append(sharedStrings, text)
+		}
+	}
+
+	var sheet xlsxWorksheet
+	if err := decodeZipXML(sheetFiles[0], &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := make([]importSheetRow, 0, len(sheet.Rows))
+	for i, sheetRow := range sheet.Rows {
+		row := importSheetRow{Number: sheetRow.Number}
+		if row.Number == 0 {
+			row.Number = i + 1
+		}
+
+		for j, c := range sheetRow.Cells {
+			column := xlsxColumnIndex(c.Ref)
+			if column < 0 {
+				column = j
+			}
+
+			value := c.Value
+			switch c.Type {
+			case "s":
+				index, err := strconv.Atoi(c.Value)
+				if err != nil || index < 0 || index >= len(sharedStrings) {
+					return nil, fmt.Errorf("invalid XLSX: bad shared string reference in cell %s", c.Ref)
+				}
+				value = sharedStrings[index]
+			case "inlineStr":
+				value = c.Inline.Text
+			}
+
+			for len(row.Cells) <= column {
+				row.Cells = append(row.Cells, "")
+			}
+			row.Cells[column] = value
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// decodeZipXML แปลงไฟล์ XML ภายใน archive
+func decodeZipXML(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("invalid XLSX: %s: %w", f.Name, err)
+	}
+	return nil
+}
+
+// xlsxColumnIndex แปลงตำแหน่งเซลล์ เช่น "C7" เป็นดัชนีคอลัมน์เริ่มจาก 0
+func xlsxColumnIndex(ref string) int {
+	column := 0
+	letters := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		column = column*26 + int(r-'A'+1)
+		letters++
+	}
+	if letters == 0 {
+		return -1
+	}
+	return column - 1
+}
@@ -19,6 +19,14 @@ type QuestionService struct {
 	fileService  *FileService
 }
 
+// QuestionFiles รวมไฟล์ที่อัปโหลดมาพร้อมกับคำถาม
+type QuestionFiles struct {
+	Image            *multipart.FileHeader
+	ExplanationImage *multipart.FileHeader
+	Media            *multipart.FileHeader
+	ChoiceImages     map[int]*multipart.FileHeader
+}
+
 // NewQuestionService สร้าง instance ใหม่ของ QuestionService
 func NewQuestionService(
 	questionRepo *repositories.QuestionRepository,
@@ -36,14 +44,10 @@ func NewQuestionService(
 
 // CreateQuestion สร้างคำถามใหม่
 func (s *QuestionService) CreateQuestion(question *models.Question, imageFile *multipart.FileHeader, currentUserID uint) error {
-	// ตรวจสอบว่าผู้ใช้เป็นเจ้าของ quiz หรือไม่
-	isOwner, err := s.quizRepo.CheckQuizOwnership(question.QuizID, currentUserID)
-	if err != nil {
+	// ตรวจสอบว่าผู้ใช้มีสิทธิ์แก้ไข quiz หรือไม่
+	if err := authorizeQuiz(s.quizRepo, question.QuizID, currentUserID, models.QuizRoleEditor); err != nil {
 		return err
 	}
-	if !isOwner {
-		return errors.New("unauthorized: you are not the owner of this quiz")
-	}
 
 	// จัดการไฟล์รูปภาพ
 	if imageFile != nil {
@@ -76,31 +80,65 @@ func (s *QuestionService) DeleteQuestion(questionID uint, currentUserID uint) er
 		return err
 	}
 
-	// ตรวจสอบว่าผู้ใช้เป็นเจ้าของ quiz หรือไม่
-	isOwner, err := s.quizRepo.CheckQuizOwnership(question.QuizID, currentUserID)
-	if err != nil {
+	// ตรวจสอบว่าผู้ใช้มีสิทธิ์แก้ไข quiz หรือไม่
+	if err := authorizeQuiz(s.quizRepo, question.QuizID, currentUserID, models.QuizRoleEditor); err != nil {
 		return err
 	}
-	if !isOwner {
-		return errors.New("unauthorized: you are not the owner of this quiz")
-	}
 
 	// ลบรูปภาพของคำถามถ้ามี
 	if question.ImageURL != "" {
 		_ = s.fileService.DeleteFileByURL(question.ImageURL)
 	}
+	if question.ExplanationImageURL != "" {
+		_ = s.fileService.DeleteFileByURL(question.ExplanationImageURL)
+	}
+	if question.MediaURL != "" {
+		_ = s.fileService.DeleteFileByURL(question.MediaURL)
+	}
 
 	// ลบข้อมูลคำถาม
 	return s.questionRepo.DeleteQuestion(questionID)
 }
 
+// ReorderQuestions จัดลำดับคำถามใน quiz ใหม่ตามลำดับของ questionIDs
+func (s *QuestionService) ReorderQuestions(quizID uint, questionIDs []uint, userID uint) error {
+	// ตรวจสอบว่าผู้ใช้มีสิทธิ์แก้ไข quiz หรือไม่
+	if err := authorizeQuiz(s.quizRepo, quizID, userID, models.QuizRoleEditor); err != nil {
+		return err
+	}
+
+	// รายการที่ส่งมาต้องครอบคลุมคำถามทุกข้อใน quiz และไม่ซ้ำกัน
+	existingQuestions, err := s.questionRepo.GetQuestionsByQuizID(quizID)
+	if err != nil {
+		return err
+	}
+	if len(questionIDs) != len(existingQuestions) {
+		return errors.New("question order must include every question in the quiz")
+	}
+
+	existingIDs := make(map[uint]bool, len(existingQuestions))
+	for _, question := range existingQuestions {
+		existingIDs[question.ID] = true
+	}
+	seen := make(map[uint]bool, len(questionIDs))
+	for _, questionID := range questionIDs {
+		if !existingIDs[questionID] {
+			return fmt.Errorf("question %d does not belong to this quiz", questionID)
+		}
+		if seen[questionID] {
+			return fmt.Errorf("question %d appears more than once", questionID)
+		}
+		seen[questionID] = true
+	}
+
+	return s.questionRepo.ReorderQuestions(quizID, questionIDs)
+}
+
 // UpdateQuestionWithChoices อัปเดตคำถามพร้อมตัวเลือกทั้งหมดในครั้งเดียว
 func (s *QuestionService) UpdateQuestionWithChoices(
 	questionID uint,
-	text string,
-	choices []dto.ChoiceFormData,
-	questionImage *multipart.FileHeader,
-	choiceImages map[int]*multipart.FileHeader,
+	formData dto.QuestionFormData,
+	files QuestionFiles,
 	userID uint,
 ) error {
 	// ตรวจสอบว่าคำถามมีอยู่จริง
@@ -111,24 +149,21 @@ func (s *QuestionService) UpdateQuestionWithChoices(
 
 	// ตรวจสอบสิทธิ์ - ดึง QuizID และตรวจสอบความเป็นเจ้าของ
 	quizID := existingQuestion.QuizID
-	isOwner, err := s.quizRepo.CheckQuizOwnership(quizID, userID)
-	if err != nil {
+	if err := authorizeQuiz(s.quizRepo, quizID, userID, models.QuizRoleEditor); err != nil {
 		return err
 	}
-	if !isOwner {
-		return errors.New("unauthorized: you are not the owner of this quiz")
-	}
 
 	// 1. อัปเดตข้อมูลคำถาม
 	questionToUpdate := &models.Question{
-		ID:     questionID,
-		QuizID: quizID, // ไม่อนุญาตให้เปลี่ยน QuizID
-		Text:   text,
+		ID:          questionID,
+		QuizID:      quizID, // ไม่อนุญาตให้เปลี่ยน QuizID
+		Text:        formData.Text,
+		Explanation: formData.Explanation,
 	}
 
 	// จัดการไฟล์รูปภาพคำถาม
-	if questionImage != nil {
-		imageURL, err := s.fileService.UpdateFile(questionImage, existingQuestion.ImageURL, string(QuestionType))
+	if files.Image != nil {
+		imageURL, err := s.fileService.UpdateFile(files.Image, existingQuestion.ImageURL, string(QuestionType))
 		if err != nil {
 			return err
 		}
@@ -137,6 +172,33 @@ func (s *QuestionService) UpdateQuestionWithChoices(
 		questionToUpdate.ImageURL = existingQuestion.ImageURL
 	}
 
+	// จัดการไฟล์รูปภาพคำอธิบายเฉลย
+	if files.ExplanationImage != nil {
+		imageURL, err := s.fileService.UpdateFile(files.ExplanationImage, existingQuestion.ExplanationImageURL, string(ExplanationType))
+		if err != nil {
+			return err
+		}
+		questionToUpdate.ExplanationImageURL = imageURL
+	} else {
+		questionToUpdate.ExplanationImageURL = existingQuestion.ExplanationImageURL
+	}
+
+	// จัดการไฟล์เสียง/วิดีโอ
+	if files.Media != nil {
+		mediaURL, mediaKind, err := s.fileService.UploadMedia(files.Media)
+		if err != nil {
+			return err
+		}
+		if existingQuestion.MediaURL != "" {
+			_ = s.fileService.DeleteFileByURL(existingQuestion.MediaURL)
+		}
+		questionToUpdate.MediaURL = mediaURL
+		questionToUpdate.MediaType = string(mediaKind)
+	} else {
+		questionToUpdate.MediaURL = existingQuestion.MediaURL
+		questionToUpdate.MediaType = existingQuestion.MediaType
+	}
+
 	// อัปเดตข้อมูลคำถาม
 	if err := s.questionRepo.UpdateQuestion(questionToUpdate); err != nil {
 		return err
@@ -152,9 +214,9 @@ func (s *QuestionService) UpdateQuestionWithChoices(
 	processedChoiceIds := make(map[uint]bool)
 
 	// 3. จัดการตัวเลือก
-	for i, choiceData := range choices {
+	for i, choiceData := range formData.Choices {
 		// ดึงไฟล์รูปภาพสำหรับตัวเลือกนี้ (ถ้ามี)
-		choiceImage := choiceImages[i]
+		choiceImage := files.ChoiceImages[i]
 
 		if choiceData.ID == "" || choiceData.ID == "0" {
 			// 3.1 สร้างตัวเลือกใหม่
@@ -162,6 +224,7 @@ func (s *QuestionService) UpdateQuestionWithChoices(
 				QuestionID: questionID,
 				Text:       choiceData.Text,
 				IsCorrect:  choiceData.IsCorrect,
+				Position:   i + 1,
 			}
 
 			// จัดการรูปภาพ
@@ -199,6 +262,7 @@ func (s *QuestionService) UpdateQuestionWithChoices(
 				QuestionID: questionID, // คงค่าเดิม
 				Text:       choiceData.Text,
 				IsCorrect:  choiceData.IsCorrect,
+				Position:   i + 1,
 			}
 
 			// จัดการรูปภาพ
@@ -240,17 +304,45 @@ func (s *QuestionService) UpdateQuestionWithChoices(
 func (s *QuestionService) CreateQuestionWithChoices(
 	question *models.Question,
 	choices []dto.ChoiceFormData,
-	questionImage *multipart.FileHeader,
-	choiceImages map[int]*multipart.FileHeader,
+	files QuestionFiles,
 	userID uint,
 ) (uint, error) {
-	// ตรวจสอบว่าผู้ใช้เป็นเจ้าของ quiz หรือไม่
-	isOwner, err := s.quizRepo.CheckQuizOwnership(question.QuizID, userID)
-	if err != nil {
-		return 0, fmt.Errorf("failed to check quiz ownership: %w", err)
+	// ตรวจสอบว่าผู้ใช้มีสิทธิ์แก้ไข quiz หรือไม่
+	if err := authorizeQuiz(s.quizRepo, question.QuizID, userID, models.QuizRoleEditor); err != nil {
+		return 0, err
 	}
-	if !isOwner {
-		return 0, errors.New("unauthorized: you are not the owner of this quiz")
+
+	// จัดการไฟล์รูปภาพคำถามและรูปภาพคำอธิบายเฉลย
+	if files.Image != nil {
+		imageURL, err := s.fileService.UploadFile(files.Image, string(QuestionType))
+		if err != nil {
+			return 0, fmt.Errorf("failed to upload question image: %w", err)
+		}
+		question.ImageURL = imageURL
+	}
+	if files.ExplanationImage != nil {
+		imageURL, err := s.fileService.UploadFile(files.ExplanationImage, string(ExplanationType))
+		if err != nil {
+			return 0, fmt.Errorf("failed to upload explanation image: %w", err)
+		}
+		question.ExplanationImageURL = imageURL
+	}
+	if files.Media != nil {
+		mediaURL, mediaKind, err := s.fileService.UploadMedia(files.Media)
+		if err != nil {
+			return 0, fmt.Errorf("failed to upload question media: %w", err)
+		}
+		question.MediaURL = mediaURL
+		question.MediaType = string(mediaKind)
+	}
+
+	// กำหนดลำดับคำถามต่อท้าย quiz ถ้าไม่ได้ระบุมา
+	if question.Position == 0 {
+		position, err := s.questionRepo.GetNextQuestionPosition(question.QuizID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get question position: %w", err)
+		}
+		question.Position = position
 	}
 
 	// 1. สร้างคำถาม
@@ -264,12 +356,13 @@ func (s *QuestionService) CreateQuestionWithChoices(
 
 	// 2. สร้างตัวเลือก
 	for i, choiceData := range choices {
-		choiceImage, exists := choiceImages[i]
+		choiceImage, exists := files.ChoiceImages[i]
 
 		newChoice := &models.Choice{
 			QuestionID: question.ID,
 			Text:       choiceData.Text,
 			IsCorrect:  choiceData.IsCorrect,
+			Position:   i + 1,
 		}
 
 		if exists && choiceImage != nil {
@@ -0,0 +1,398 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/patiphanak/league-of-quiz/dto"
+	models "github.com/patiphanak/league-of-quiz/model"
+	"github.com/patiphanak/league-of-quiz/quizformat"
+)
+
+// maxBundleManifestSize ขนาดสูงสุดของไฟล์ quiz.json ภายใน bundle
+const maxBundleManifestSize = 10 * 1024 * 1024
+
+// ExportQuiz ส่งออก quiz เป็นไฟล์ zip ที่มี quiz.json และไฟล์ที่อ้างอิงถึง
+func (s *QuizService) ExportQuiz(quizID uint, userID uint) ([]byte, error) {
+	if err := authorizeQuiz(s.quizRepo, quizID, userID, models.QuizRoleViewer); err != nil {
+		return nil, err
+	}
+
+	quiz, err := s.quizRepo.GetQuizByID(quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+
+	addFile := newArchiveWriter(zw, s.fileService, "files").add
+
+	bundle := dto.QuizBundle{
+		Version:    dto.QuizBundleVersion,
+		ExportedAt: time.Now().UTC(),
+		Quiz: dto.BundleQuiz{
+			Title:       quiz.Title,
+			Description: quiz.Description,
+			TimeLimit:   quiz.TimeLimit,
+			Categories:  make([]string, 0, len(quiz.Categories)),
+			Questions:   make([]dto.BundleQuestion, 0, len(quiz.Questions)),
+		},
+	}
+
+	if bundle.Quiz.Image, err = addFile(quiz.ImageURL); err != nil {
+		return nil, fmt.Errorf("failed to export quiz image: %w", err)
+	}
+	for _, category := range quiz.Categories {
+		bundle.Quiz.Categories = append(bundle.Quiz.Categories, category.Name)
+	}
+
+	for _, question := range quiz.Questions {
+		bq := dto.BundleQuestion{
+			Text:        question.Text,
+			MediaType:   question.MediaType,
+			Explanation: question.Explanation,
+			TimeLimit:   question.TimeLimit,
+			Choices:     make([]dto.BundleChoice, 0, len(question.Choices)),
+		}
+		if bq.Image, err = addFile(question.ImageURL); err != nil {
+			return nil, fmt.Errorf("failed to export image of question %d: %w", question.ID, err)
+		}
+		if bq.Media, err = addFile(question.MediaURL); err != nil {
+			return nil, fmt.Errorf("failed to export media of question %d: %w", question.ID, err)
+		}
+		if bq.ExplanationImage, err = addFile(question.ExplanationImageURL); err != nil {
+			return nil, fmt.Errorf("failed to export explanation image of question %d: %w", question.ID, err)
+		}
+
+		for _, choice := range question.Choices {
+			bc := dto.BundleChoice{
+				Text:      choice.Text,
+				IsCorrect: choice.IsCorrect,
+			}
+			if bc.Image, err = addFile(choice.ImageURL); err != nil {
+				return nil, fmt.Errorf("failed to export image of choice %d: %w", choice.ID, err)
+			}
+			bq.Choices = append(bq.Choices, bc)
+		}
+		bundle.Quiz.Questions = append(bundle.Quiz.Questions, bq)
+	}
+
+	manifest, err := zw.Create(dto.QuizBundleManifest)
+	if err != nil {
+		return nil, err
+	}
+	encoder := json.NewEncoder(manifest)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(bundle); err != nil {
+		return nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ImportQuiz นำเข้า quiz จากไฟล์ zip ที่ได้จาก ExportQuiz โดยผู้ใช้ปัจจุบันเป็นเจ้าของ
+// คืนค่า quiz ที่สร้างขึ้นและคำเตือน เช่น หมวดหมู่ที่ไม่มีในระบบนี้
+func (s *QuizService) ImportQuiz(file *multipart.FileHeader, userID uint) (*models.Quiz, []string, error) {
+	if file == nil {
+		return nil, nil, errors.New("bundle file is required")
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer src.Close()
+
+	zr, err := zip.NewReader(src, file.Size)
+	if err != nil {
+		return nil, nil, errors.New("invalid bundle: not a zip archive")
+	}
+
+	entries := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		entries[f.Name] = f
+	}
+
+	bundle, err := readBundleManifest(entries[dto.QuizBundleManifest])
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := validateBundle(bundle, entries); err != nil {
+		return nil, nil, err
+	}
+
+	// อัปโหลดไฟล์ใหม่ผ่าน FileService และลบทิ้งทั้งหมดถ้าการนำเข้าล้มเหลว
+	uploader := newArchiveUploader(entries, s.fileService)
+	success := false
+	defer func() {
+		if !success {
+			uploader.rollback()
+		}
+	}()
+	upload := func(archivePath string, fileType FileType) (string, error) {
+		fileURL, _, err := uploader.upload(archivePath, fileType)
+		return fileURL, err
+	}
+
+	quiz := &models.Quiz{
+		Title:       strings.TrimSpace(bundle.Quiz.Title),
+		Description: bundle.Quiz.Description,
+		TimeLimit:   bundle.Quiz.TimeLimit,
+		CreatorID:   userID,
+	}
+	if quiz.ImageURL, err = upload(bundle.Quiz.Image, QuizType); err != nil {
+		return nil, nil, err
+	}
+
+	for i, bq := range bundle.Quiz.Questions {
+		question := models.Question{
+			Text:        bq.Text,
+			Position:    i + 1,
+			Explanation: bq.Explanation,
+			TimeLimit:   bq.TimeLimit,
+		}
+		if question.ImageURL, err = upload(bq.Image, QuestionType); err != nil {
+			return nil, nil, err
+		}
+		if question.ExplanationImageURL, err = upload(bq.ExplanationImage, ExplanationType); err != nil {
+			return nil, nil, err
+		}
+		// ใช้ประเภทสื่อที่ตรวจพบจากเนื้อหาไฟล์จริงแทนค่าใน bundle
+		mediaURL, kind, err := uploader.upload(bq.Media, MediaType)
+		if err != nil {
+			return nil, nil, err
+		}
+		question.MediaURL = mediaURL
+		question.MediaType = string(kind)
+
+		for j, bc := range bq.Choices {
+			choice := models.Choice{
+				Text:      bc.Text,
+				IsCorrect: bc.IsCorrect,
+				Position:  j + 1,
+			}
+			if choice.ImageURL, err = upload(bc.Image, ChoiceType); err != nil {
+				return nil, nil, err
+			}
+			question.Choices = append(question.Choices, choice)
+		}
+		quiz.Questions = append(quiz.Questions, question)
+	}
+
+	missing, err := s.quizRepo.CreateQuizWithCategoryNames(quiz, bundle.Quiz.Categories)
+	if err != nil {
+		return nil, nil, err
+	}
+	success = true
+
+	warnings := make([]string, 0, len(missing))
+	for _, name := range missing {
+		warnings = append(warnings, fmt.Sprintf("category %q does not exist and was skipped", name))
+	}
+	return quiz, warnings, nil
+}
+
+// archiveWriter เขียนไฟล์จาก storage ลง zip โดยเขียนไฟล์เดียวกันเพียงครั้งเดียว
+type archiveWriter struct {
+	zw          *zip.Writer
+	fileService *FileService
+	dir         string
+	written     map[string]string
+}
+
+func newArchiveWriter(zw *zip.Writer, fileService *FileService, dir string) *archiveWriter {
+	return &archiveWriter{
+		zw:          zw,
+		fileService: fileService,
+		dir:         dir,
+		written:     make(map[string]string),
+	}
+}
+
+// add เขียนไฟล์จาก URL ลง archive และคืนค่า path ภายใน archive
+func (a *archiveWriter) add(fileURL string) (string, error) {
+	if fileURL == "" {
+		return "", nil
+	}
+	if archivePath, ok := a.written[fileURL]; ok {
+		return archivePath, nil
+	}
+
+	filename, fileType, err := a.fileService.ExtractInfoFromURL(fileURL)
+	if err != nil {
+		return "", err
+	}
+	src, _, err := a.fileService.OpenFileByURL(fileURL)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	archivePath := path.Join(a.dir, fileType, filename)
+	w, err := a.zw.Create(archivePath)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(w, src); err != nil {
+		return "", err
+	}
+	a.written[fileURL] = archivePath
+	return archivePath, nil
+}
+
+// archiveUploader อัปโหลดไฟล์จาก zip ผ่าน FileService และจำไว้เพื่อลบทิ้งเมื่อการนำเข้าล้มเหลว
+type archiveUploader struct {
+	entries     map[string]*zip.File
+	fileService *FileService
+	uploaded    []string
+}
+
+func newArchiveUploader(entries map[string]*zip.File, fileService *FileService) *archiveUploader {
+	return &archiveUploader{entries: entries, fileService: fileService}
+}
+
+// upload อัปโหลดไฟล์ใน archive และคืนค่า URL กับประเภทสื่อที่ตรวจพบจริง
+func (u *archiveUploader) upload(archivePath string, fileType FileType) (string, MediaKind, error) {
+	if archivePath == "" {
+		return "", "", nil
+	}
+	entry, ok := u.entries[archivePath]
+	if !ok {
+		return "", "", fmt.Errorf("%s: file not found in archive", archivePath)
+	}
+	rc, err := entry.Open()
+	if err != nil {
+		return "", "", err
+	}
+	defer rc.Close()
+
+	fileURL, kind, err := u.fileService.UploadFromReader(rc, int64(entry.UncompressedSize64), string(fileType))
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", archivePath, err)
+	}
+	u.uploaded = append(u.uploaded, fileURL)
+	return fileURL, kind, nil
+}
+
+// rollback ลบไฟล์ทั้งหมดที่อัปโหลดไปแล้ว
+func (u *archiveUploader) rollback() {
+	for _, fileURL := range u.uploaded {
+		_ = u.fileService.DeleteFileByURL(fileURL)
+	}
+}
+
+// readBundleManifest อ่านและแปลง quiz.json จาก archive
+func readBundleManifest(entry *zip.File) (*dto.QuizBundle, error) {
+	if entry == nil {
+		return nil, fmt.Errorf("invalid bundle: %s not found", dto.QuizBundleManifest)
+	}
+	if entry.UncompressedSize64 > maxBundleManifestSize {
+		return nil, fmt.Errorf("invalid bundle: %s is too large", dto.QuizBundleManifest)
+	}
+
+	rc, err := entry.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var bundle dto.QuizBundle
+	decoder := json.NewDecoder(io.LimitReader(rc, maxBundleManifestSize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&bundle); err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+	return &bundle, nil
+}
+
+// validateBundle ตรวจสอบโครงสร้างของ bundle และไฟล์ที่อ้างอิงก่อนเริ่มนำเข้า
+func validateBundle(bundle *dto.QuizBundle, entries map[string]*zip.File) error {
+	if bundle.Version != dto.QuizBundleVersion {
+		return fmt.Errorf("invalid bundle: unsupported version %d", bundle.Version)
+	}
+	if strings.TrimSpace(bundle.Quiz.Title) == "" {
+		return errors.New("invalid bundle: quiz title is required")
+	}
+
+	checkFile := func(archivePath, field string) error {
+		if archivePath == "" {
+			return nil
+		}
+		if _, ok := entries[archivePath]; !ok {
+			return fmt.Errorf("invalid bundle: %s references missing file %s", field, archivePath)
+		}
+		return nil
+	}
+
+	if err := checkFile(bundle.Quiz.Image, "quiz.image"); err != nil {
+		return err
+	}
+
+	for i, question := range bundle.Quiz.Questions {
+		field := fmt.Sprintf("quiz.questions[%d]", i)
+		if strings.TrimSpace(question.Text) == "" {
+			return fmt.Errorf("invalid bundle: %s.text is required", field)
+		}
+		if len(question.Choices) == 0 {
+			return fmt.Errorf("invalid bundle: %s must have at least one choice", field)
+		}
+		if question.Media == "" && question.MediaType != "" {
+			return fmt.Errorf("invalid bundle: %s.mediaType is set without media", field)
+		}
+		if err := checkFile(question.Image, field+".image"); err != nil {
+			return err
+		}
+		if err := checkFile(question.Media, field+".media"); err != nil {
+			return err
+		}
+		if err := checkFile(question.ExplanationImage, field+".explanationImage"); err != nil {
+			return err
+		}
+
+		hasCorrect := false
+		for j, choice := range question.Choices {
+			if strings.TrimSpace(choice.Text) == "" && choice.Image == "" {
+				return fmt.Errorf("invalid bundle: %s.choices[%d] must have text or image", field, j)
+			}
+			if err := checkFile(choice.Image, fmt.Sprintf("%s.choices[%d].image", field, j)); err != nil {
+				return err
+			}
+			hasCorrect = hasCorrect || choice.IsCorrect
+		}
+		if !hasCorrect {
+			return fmt.Errorf("invalid bundle: %s must have at least one correct choice", field)
+		}
+	}
+	return nil
+}
+
+// ExportQuizAsText ส่งออกคำถามของ quiz เป็นไฟล์ข้อความรูปแบบ GIFT หรือ Aiken
+// คืนค่าเนื้อหาไฟล์และรายการข้อมูลที่รูปแบบนั้นเก็บไม่ได้
+func (s *QuizService) ExportQuizAsText(quizID uint, format quizformat.Format, userID uint) (string, []quizformat.Issue, error) {
+	if err := authorizeQuiz(s.quizRepo, quizID, userID, models.QuizRoleViewer); err != nil {
+		return "", nil, err
+	}
+
+	quiz, err := s.quizRepo.GetQuizByID(quizID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	content, issues := quizformat.Write(format, quiz.Questions)
+	if issues == nil {
+		issues = []quizformat.Issue{}
+	}
+	return content, issues, nil
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/patiphanak/league-of-quiz/dto"
+	models "github.com/patiphanak/league-of-quiz/model"
+	"github.com/patiphanak/league-of-quiz/repositories"
+)
+
+// QuizCollaboratorService สำหรับการจัดการผู้ร่วมแก้ไข quiz
+type QuizCollaboratorService struct {
+	quizRepo         *repositories.QuizRepository
+	collaboratorRepo *repositories.QuizCollaboratorRepository
+}
+
+// NewQuizCollaboratorService สร้าง instance ใหม่ของ QuizCollaboratorService
+func NewQuizCollaboratorService(
+	quizRepo *repositories.QuizRepository,
+	collaboratorRepo *repositories.QuizCollaboratorRepository,
+) *QuizCollaboratorService {
+	return &QuizCollaboratorService{
+		quizRepo:         quizRepo,
+		collaboratorRepo: collaboratorRepo,
+	}
+}
+
+// ListCollaborators ดึงรายชื่อผู้ร่วมแก้ไข quiz รวมถึงผู้สร้างซึ่งเป็น owner เสมอ
+func (s *QuizCollaboratorService) ListCollaborators(quizID uint, userID uint) ([]dto.CollaboratorResponse, error) {
+	if err := authorizeQuiz(s.quizRepo, quizID, userID, models.QuizRoleViewer); err != nil {
+		return nil, err
+	}
+
+	quiz, err := s.quizRepo.GetQuizByID(quizID)
+	if err != nil {
+		return nil, err
+	}
+	collaborators, err := s.collaboratorRepo.GetCollaboratorsByQuizID(quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	var creator models.User
+	if err := s.quizRepo.GetDB().First(&creator, quiz.CreatorID).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.CollaboratorResponse, 0, len(collaborators)+1)
+	result = append(result, toCollaboratorResponse(creator, models.QuizRoleOwner, quiz.CreatedAt))
+	for _, collaborator := range collaborators {
+		result = append(result, toCollaboratorResponse(collaborator.User, collaborator.Role, collaborator.CreatedAt))
+	}
+	return result, nil
+}
+
+// InviteCollaborator เพิ่มผู้ร่วมแก้ไขด้วยอีเมล ถ้ามีอยู่แล้วจะเปลี่ยนบทบาทแทน
+// เฉพาะ owner เท่านั้นที่เชิญได้
+func (s *QuizCollaboratorService) InviteCollaborator(quizID uint, email string, role string, userID uint) (*dto.CollaboratorResponse, error) {
+	if err := authorizeQuiz(s.quizRepo, quizID, userID, models.QuizRoleOwner); err != nil {
+		return nil, err
+	}
+
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return nil, errors.New("email is required")
+	}
+	if role == "" {
+		role = models.QuizRoleViewer
+	}
+	if !models.IsValidQuizRole(role) {
+		return nil, errors.New("role must be one of viewer, editor or owner")
+	}
+
+	user, err := s.collaboratorRepo.GetUserByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+
+	quiz, err := s.quizRepo.GetQuizByID(quizID)
+	if err != nil {
+		return nil, err
+	}
+	if user.ID == quiz.CreatorID {
+		return nil, errors.New("the quiz creator is always an owner")
+	}
+
+	collaborator := &models.QuizCollaborator{
+		QuizID:      quizID,
+		UserID:      user.ID,
+		Role:        role,
+		InvitedByID: userID,
+	}
+	if err := s.collaboratorRepo.UpsertCollaborator(collaborator); err != nil {
+		return nil, err
+	}
+
+	response := toCollaboratorResponse(*user, role, collaborator.CreatedAt)
+	return &response, nil
+}
+
+// RemoveCollaborator ลบผู้ร่วมแก้ไขด้วยอีเมล
+// owner ลบใครก็ได้ยกเว้นผู้สร้าง ส่วนผู้ร่วมแก้ไขลบตัวเองออกจาก quiz ได้
+func (s *QuizCollaboratorService) RemoveCollaborator(quizID uint, email string, userID uint) error {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return errors.New("email is required")
+	}
+
+	user, err := s.collaboratorRepo.GetUserByEmail(email)
+	if err != nil {
+		return err
+	}
+
+	if user.ID != userID {
+		if err := authorizeQuiz(s.quizRepo, quizID, userID, models.QuizRoleOwner); err != nil {
+			return err
+		}
+	}
+
+	quiz, err := s.quizRepo.GetQuizByID(quizID)
+	if err != nil {
+		return err
+	}
+	if user.ID == quiz.CreatorID {
+		return errors.New("the quiz creator cannot be removed")
+	}
+
+	return s.collaboratorRepo.DeleteCollaborator(quizID, user.ID)
+}
+
+// GetSharedQuizzes ดึง quiz ของผู้อื่นที่แชร์ให้ผู้ใช้
+func (s *QuizCollaboratorService) GetSharedQuizzes(userID uint, page, limit int) ([]models.Quiz, int64, error) {
+	return s.collaboratorRepo.GetQuizzesSharedWithUser(userID, page, limit)
+}
+
+// toCollaboratorResponse แปลงข้อมูลผู้ใช้และบทบาทเป็น response
+func toCollaboratorResponse(user models.User, role string, createdAt time.Time) dto.CollaboratorResponse {
+	return dto.CollaboratorResponse{
+		UserID:      user.ID,
+		Email:       user.Email,
+		DisplayName: user.DisplayName,
+		PictureURL:  user.PictureURL,
+		Role:        role,
+		CreatedAt:   createdAt,
+	}
+}
@@ -0,0 +1,25 @@
+package services
+
+import (
+	"fmt"
+
+	models "github.com/patiphanak/league-of-quiz/model"
+)
+
+// quizRoleStore หาบทบาทของผู้ใช้ใน quiz เช่น repositories.QuizRepository
+type quizRoleStore interface {
+	GetQuizRole(quizID uint, userID uint) (string, error)
+}
+
+// authorizeQuiz ตรวจสอบว่าผู้ใช้มีบทบาทใน quiz อย่างน้อยเท่ากับ required
+// ทุก service ที่แก้ไขหรืออ่านข้อมูลส่วนตัวของ quiz ต้องตรวจสิทธิ์ผ่านฟังก์ชันนี้
+func authorizeQuiz(quizRepo quizRoleStore, quizID uint, userID uint, required string) error {
+	role, err := quizRepo.GetQuizRole(quizID, userID)
+	if err != nil {
+		return err
+	}
+	if !models.QuizRoleAllows(role, required) {
+		return fmt.Errorf("unauthorized: %s access to this quiz is required", required)
+	}
+	return nil
+}
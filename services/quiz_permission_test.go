@@ -0,0 +1,64 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	models "github.com/patiphanak/league-of-quiz/model"
+)
+
+// fakeRoleStore บทบาทของผู้ใช้ใน quiz เดียว เก็บไว้ในหน่วยความจำ
+type fakeRoleStore struct {
+	roles map[uint]string
+	err   error
+}
+
+func (s fakeRoleStore) GetQuizRole(quizID uint, userID uint) (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+	return s.roles[userID], nil
+}
+
+func TestAuthorizeQuiz(t *testing.T) {
+	const (
+		owner uint = iota + 1
+		editor
+		viewer
+		outsider
+	)
+	store := fakeRoleStore{roles: map[uint]string{
+		owner:  models.QuizRoleOwner,
+		editor: models.QuizRoleEditor,
+		viewer: models.QuizRoleViewer,
+	}}
+
+	cases := []struct {
+		name     string
+		userID   uint
+		required string
+		allowed  bool
+	}{
+		{"owner may delete", owner, models.QuizRoleOwner, true},
+		{"editor may not delete", editor, models.QuizRoleOwner, false},
+		{"editor may edit", editor, models.QuizRoleEditor, true},
+		{"viewer may not edit", viewer, models.QuizRoleEditor, false},
+		{"viewer may read", viewer, models.QuizRoleViewer, true},
+		{"non-member may not read", outsider, models.QuizRoleViewer, false},
+	}
+	for _, tc := range cases {
+		err := authorizeQuiz(store, 1, tc.userID, tc.required)
+		if tc.allowed && err != nil {
+			t.Errorf("%s: got error %v, want access", tc.name, err)
+		}
+		if !tc.allowed && (err == nil || !strings.HasPrefix(err.Error(), "unauthorized")) {
+			t.Errorf("%s: got error %v, want an unauthorized error", tc.name, err)
+		}
+	}
+
+	lookupErr := errors.New("lookup failed")
+	if err := authorizeQuiz(fakeRoleStore{err: lookupErr}, 1, owner, models.QuizRoleViewer); !errors.Is(err, lookupErr) {
+		t.Errorf("lookup error: got %v, want %v", err, lookupErr)
+	}
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+
+	models "github.com/patiphanak/league-of-quiz/model"
+	"github.com/patiphanak/league-of-quiz/quizformat"
+)
+
+// ExportQuizQTI ส่งออก quiz เป็นแพ็กเกจ QTI 2.1 (zip) พร้อมไฟล์รูปภาพและสื่อที่อ้างอิง
+// คืนค่ารายการข้อมูลที่ QTI เก็บไม่ได้
+func (s *QuizService) ExportQuizQTI(quizID uint, userID uint) ([]byte, []quizformat.Issue, error) {
+	if err := authorizeQuiz(s.quizRepo, quizID, userID, models.QuizRoleViewer); err != nil {
+		return nil, nil, err
+	}
+
+	quiz, err := s.quizRepo.GetQuizByID(quizID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+
+	issues, err := quizformat.WriteQTIPackage(zw, quiz, newArchiveWriter(zw, s.fileService, "media").add)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), issues, nil
+}
+
+// ImportQuizQTI นำเข้าแพ็กเกจ QTI 2.1 เป็น quiz ใหม่ของผู้ใช้ปัจจุบัน
+// ไฟล์ในแพ็กเกจจะถูกอัปโหลดใหม่ผ่าน FileService และคำถามที่แปลงไม่ได้จะถูกรายงานเป็น Issue
+// ไฟล์ที่อ้างอิงเป็น URL ภายนอกจะถูกตัดทิ้งและรายงานเป็น Issue เพราะระบบเก็บได้เฉพาะไฟล์ใน storage
+func (s *QuizService) ImportQuizQTI(file *multipart.FileHeader, userID uint) (*models.Quiz, []quizformat.Issue, error) {
+	if file == nil {
+		return nil, nil, errors.New("QTI package is required")
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer src.Close()
+
+	zr, err := zip.NewReader(src, file.Size)
+	if err != nil {
+		return nil, nil, errors.New("invalid QTI package: not a zip archive")
+	}
+
+	entries := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		entries[f.Name] = f
+	}
+
+	pkg, issues, err := quizformat.ReadQTIPackage(entries)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(pkg.Questions) == 0 {
+		return nil, issues, errors.New("QTI package has no supported questions")
+	}
+
+	title := strings.TrimSpace(pkg.Title)
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(file.Filename), filepath.Ext(file.Filename))
+	}
+
+	// อัปโหลดไฟล์ใหม่ผ่าน FileService และลบทิ้งทั้งหมดถ้าการนำเข้าล้มเหลว
+	uploader := newArchiveUploader(entries, s.fileService)
+	success := false
+	defer func() {
+		if !success {
+			uploader.rollback()
+		}
+	}()
+	upload := func(position int, ref string, fileType FileType) (string, MediaKind, error) {
+		if ref == "" {
+			return "", "", nil
+		}
+		if !quizformat.IsPackagePath(ref) {
+			issues = append(issues, quizformat.Issue{
+				Question: position,
+				Message:  fmt.Sprintf("external file %s was not imported; only files inside the package are supported", ref),
+			})
+			return "", "", nil
+		}
+		return uploader.upload(ref, fileType)
+	}
+
+	quiz := &models.Quiz{
+		Title:     title,
+		CreatorID: userID,
+	}
+	for i, question := range pkg.Questions {
+		question.Position = i + 1
+
+		if question.ImageURL, _, err = upload(question.Position, question.ImageURL, QuestionType); err != nil {
+			return nil, nil, err
+		}
+		if question.MediaURL != "" {
+			mediaURL, kind, err := upload(question.Position, question.MediaURL, MediaType)
+			if err != nil {
+				return nil, nil, err
+			}
+			question.MediaURL = mediaURL
+			question.MediaType = string(kind)
+		}
+		for j := range question.Choices {
+			if question.Choices[j].ImageURL, _, err = upload(question.Position, question.Choices[j].ImageURL, ChoiceType); err != nil {
+				return nil, nil, err
+			}
+		}
+		quiz.Questions = append(quiz.Questions, question)
+	}
+
+	if _, err := s.quizRepo.CreateQuizWithCategoryNames(quiz, nil); err != nil {
+		return nil, nil, err
+	}
+	success = true
+
+	if issues == nil {
+		issues = []quizformat.Issue{}
+	}
+	return quiz, issues, nil
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"errors"
+	"fmt"
 	"mime/multipart"
 
 	models "github.com/patiphanak/league-of-quiz/model"
@@ -62,14 +63,10 @@ func (s *QuizService) GetQuizzesByCreator(creatorID uint, page, limit int) ([]mo
 
 // UpdateQuiz อัปเดตข้อมูล quiz
 func (s *QuizService) UpdateQuiz(quiz *models.Quiz, imageFile *multipart.FileHeader, currentUserID uint) error {
-	// ตรวจสอบว่าผู้ใช้เป็นเจ้าของ quiz หรือไม่
-	isOwner, err := s.quizRepo.CheckQuizOwnership(quiz.ID, currentUserID)
-	if err != nil {
+	// ตรวจสอบว่าผู้ใช้มีสิทธิ์แก้ไข quiz หรือไม่
+	if err := authorizeQuiz(s.quizRepo, quiz.ID, currentUserID, models.QuizRoleEditor); err != nil {
 		return err
 	}
-	if !isOwner {
-		return errors.New("unauthorized: you are not the owner of this quiz")
-	}
 
 	// ดึงข้อมูล quiz เดิม
 	existingQuiz, err := s.quizRepo.GetQuizByID(quiz.ID)
@@ -98,14 +95,10 @@ func (s *QuizService) UpdateQuiz(quiz *models.Quiz, imageFile *multipart.FileHea
 
 // PatchQuiz อัปเดตข้อมูล quiz บางส่วน
 func (s *QuizService) PatchQuiz(quizID uint, updates map[string]interface{}, imageFile *multipart.FileHeader, currentUserID uint) error {
-	// ตรวจสอบว่าผู้ใช้เป็นเจ้าของ quiz หรือไม่
-	isOwner, err := s.quizRepo.CheckQuizOwnership(quizID, currentUserID)
-	if err != nil {
+	// ตรวจสอบว่าผู้ใช้มีสิทธิ์แก้ไข quiz หรือไม่
+	if err := authorizeQuiz(s.quizRepo, quizID, currentUserID, models.QuizRoleEditor); err != nil {
 		return err
 	}
-	if !isOwner {
-		return errors.New("unauthorized: you are not the owner of this quiz")
-	}
 
 	// ป้องกันการเปลี่ยน CreatorID
 	delete(updates, "creator_id")
@@ -132,13 +125,9 @@ func (s *QuizService) PatchQuiz(quizID uint, updates map[string]interface{}, ima
 // DeleteQuiz ลบ quiz
 func (s *QuizService) DeleteQuiz(quizID uint, currentUserID uint) error {
 	// ตรวจสอบว่าผู้ใช้เป็นเจ้าของ quiz หรือไม่
-	isOwner, err := s.quizRepo.CheckQuizOwnership(quizID, currentUserID)
-	if err != nil {
+	if err := authorizeQuiz(s.quizRepo, quizID, currentUserID, models.QuizRoleOwner); err != nil {
 		return err
 	}
-	if !isOwner {
-		return errors.New("unauthorized: you are not the owner of this quiz")
-	}
 
 	// ดึงข้อมูล quiz เพื่อลบรูปภาพที่เกี่ยวข้อง
 	quiz, err := s.quizRepo.GetQuizByID(quizID)
@@ -157,21 +146,23 @@ func (s *QuizService) DeleteQuiz(quizID uint, currentUserID uint) error {
 
 // UpdateQuizCategories อัปเดตหมวดหมู่ของ quiz
 func (s *QuizService) UpdateQuizCategories(quizID uint, categoryIDs []uint, currentUserID uint) error {
-	// ตรวจสอบว่าผู้ใช้เป็นเจ้าของ quiz หรือไม่
-	isOwner, err := s.quizRepo.CheckQuizOwnership(quizID, currentUserID)
-	if err != nil {
+	// ตรวจสอบว่าผู้ใช้มีสิทธิ์แก้ไข quiz หรือไม่
+	if err := authorizeQuiz(s.quizRepo, quizID, currentUserID, models.QuizRoleEditor); err != nil {
 		return err
 	}
-	if !isOwner {
-		return errors.New("unauthorized: you are not the owner of this quiz")
-	}
 
 	return s.quizRepo.UpdateQuizCategories(quizID, categoryIDs)
 }
 
-// GetFilteredQuizzes ดึง quiz ตามเงื่อนไขการกรอง
-func (s *QuizService) GetFilteredQuizzes(offset, limit int, isPublished string, search string, categories []uint) ([]models.Quiz, int64, error) {
-	return s.quizRepo.GetFilteredQuizzes(offset, limit, isPublished, search, categories)
+// CheckQuizAccess ตรวจสอบว่าผู้ใช้มีบทบาทใน quiz อย่างน้อยเท่ากับ role
+func (s *QuizService) CheckQuizAccess(quizID uint, userID uint, role string) error {
+	return authorizeQuiz(s.quizRepo, quizID, userID, role)
+}
+
+// GetFilteredQuizzes ดึง quiz ที่เผยแพร่แล้วตามเงื่อนไขการกรอง ไม่รวม quiz ที่แชร์ในองค์กร
+// รายการนี้เปิดให้ทุกคนดูได้ จึงไม่รวม quiz ที่ยังไม่เผยแพร่
+func (s *QuizService) GetFilteredQuizzes(offset, limit int, search string, categories []uint) ([]models.Quiz, int64, error) {
+	return s.quizRepo.GetFilteredQuizzes(offset, limit, "true", search, categories, 0)
 }
 
 // GetAllCategories ดึงหมวดหมู่ทั้งหมด
@@ -182,4 +173,101 @@ func (s *QuizService) GetAllCategories() ([]models.Category, error) {
 		return nil, err
 	}
 	return categories, nil
-}
\ No newline at end of file
+}
+
+// DuplicateQuiz คัดลอก quiz พร้อมคำถาม ตัวเลือก และหมวดหมู่ให้เป็นของผู้ใช้ปัจจุบัน
+// คัดลอกได้เฉพาะ quiz ที่ผู้ใช้มีสิทธิ์เข้าถึงหรือ quiz ที่เผยแพร่แล้วและไม่ได้แชร์ในองค์กร ไฟล์ที่อ้างอิงจะถูกคัดลอกเป็นไฟล์ใหม่
+func (s *QuizService) DuplicateQuiz(quizID uint, userID uint) (*models.Quiz, error) {
+	source, err := s.quizRepo.GetQuizByID(quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := s.quizRepo.GetQuizRole(quizID, userID)
+	if err != nil {
+		return nil, err
+	}
+	isOwner := source.CreatorID == userID
+	if role == "" && !source.IsPublic() {
+		return nil, errors.New("unauthorized: you cannot duplicate this quiz")
+	}
+
+	// ลบไฟล์ที่คัดลอกไปแล้วทั้งหมดถ้าการคัดลอกล้มเหลว
+	var copied []string
+	success := false
+	defer func() {
+		if !success {
+			for _, fileURL := range copied {
+				_ = s.fileService.DeleteFileByURL(fileURL)
+			}
+		}
+	}()
+	copyFile := func(fileURL string, fileType FileType) (string, error) {
+		newURL, err := s.fileService.CopyFileByURL(fileURL, string(fileType))
+		if err != nil {
+			return "", fmt.Errorf("failed to copy file %s: %w", fileURL, err)
+		}
+		if newURL != "" && newURL != fileURL {
+			copied = append(copied, newURL)
+		}
+		return newURL, nil
+	}
+
+	quiz := &models.Quiz{
+		Title:       source.Title + " (copy)",
+		Description: source.Description,
+		TimeLimit:   source.TimeLimit,
+		CreatorID:   userID,
+		Categories:  source.Categories,
+	}
+	if !isOwner {
+		quiz.ForkedFromQuizID = &source.ID
+		quiz.ForkedFromCreatorID = &source.CreatorID
+	}
+	if quiz.ImageURL, err = copyFile(source.ImageURL, QuizType); err != nil {
+		return nil, err
+	}
+
+	for _, sourceQuestion := range source.Questions {
+		question := models.Question{
+			Text:        sourceQuestion.Text,
+			Position:    sourceQuestion.Position,
+			MediaType:   sourceQuestion.MediaType,
+			Explanation: sourceQuestion.Explanation,
+			TimeLimit:   sourceQuestion.TimeLimit,
+		}
+		// คลังข้อสอบเป็นข้อมูลส่วนตัว จึงเก็บการเชื่อมโยงไว้เฉพาะเมื่อคัดลอก quiz ของตัวเอง
+		if isOwner {
+			question.BankQuestionID = sourceQuestion.BankQuestionID
+		}
+		if question.ImageURL, err = copyFile(sourceQuestion.ImageURL, QuestionType); err != nil {
+			return nil, err
+		}
+		if question.MediaURL, err = copyFile(sourceQuestion.MediaURL, MediaType); err != nil {
+			return nil, err
+		}
+		if question.ExplanationImageURL, err = copyFile(sourceQuestion.ExplanationImageURL, ExplanationType); err != nil {
+			return nil, err
+		}
+
+		for _, sourceChoice := range sourceQuestion.Choices {
+			choice := models.Choice{
+				Text:      sourceChoice.Text,
+				IsCorrect: sourceChoice.IsCorrect,
+				Position:  sourceChoice.Position,
+			}
+			if choice.ImageURL, err = copyFile(sourceChoice.ImageURL, ChoiceType); err != nil {
+				return nil, err
+			}
+			question.Choices = append(question.Choices, choice)
+		}
+		quiz.Questions = append(quiz.Questions, question)
+	}
+
+	if err := s.quizRepo.CreateQuizWithCategories(quiz); err != nil {
+		return nil, err
+	}
+	success = true
+
+	return quiz, nil
+}
@@ -0,0 +1,297 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/patiphanak/league-of-quiz/dto"
+	models "github.com/patiphanak/league-of-quiz/model"
+	"github.com/patiphanak/league-of-quiz/repositories"
+)
+
+var ErrSessionRevoked = errors.New("session revoked")
+
+const (
+	// sessionCacheTTL ระยะเวลาที่เชื่อผลตรวจ session ที่ยังใช้งานได้ใน cache ก่อนถามฐานข้อมูลใหม่
+	// session ที่ถูกเพิกถอนผ่าน instance นี้จะถูกปฏิเสธทันทีโดยไม่ต้องรอ TTL
+	sessionCacheTTL = 30 * time.Second
+	// sessionTouchInterval ระยะห่างขั้นต่ำในการบันทึกเวลาใช้งานล่าสุดลงฐานข้อมูล
+	sessionTouchInterval = time.Minute
+)
+
+// SessionInfo ข้อมูลอุปกรณ์ของ request ที่สร้างหรือใช้งาน session
+type SessionInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// sessionCacheEntry ผลการตรวจ session ที่เก็บไว้ในหน่วยความจำ
+type sessionCacheEntry struct {
+	userID    uint
+	revoked   bool
+	checkedAt time.Time
+	touchedAt time.Time
+}
+
+// sessionCache ชุด session ที่ตรวจแล้ว (รวมถึงที่ถูกเพิกถอน) ภายใน process
+type sessionCache struct {
+	mu      sync.Mutex
+	entries map[uint]*sessionCacheEntry
+}
+
+func newSessionCache() *sessionCache {
+	return &sessionCache{entries: make(map[uint]*sessionCacheEntry)}
+}
+
+// get คืนค่าผลตรวจที่ยังไม่หมดอายุ session ที่ถูกเพิกถอนไม่มีวันหมดอายุจาก cache
+func (c *sessionCache) get(id uint, now time.Time) (sessionCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[id]
+	if !ok || (!entry.revoked && now.Sub(entry.checkedAt) > sessionCacheTTL) {
+		return sessionCacheEntry{}, false
+	}
+	return *entry, true
+}
+
+func (c *sessionCache) set(id, userID uint, revoked bool, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[id]
+	if !ok {
+		entry = &sessionCacheEntry{}
+		c.entries[id] = entry
+	}
+	entry.userID = userID
+	entry.revoked = revoked
+	entry.checkedAt = now
+}
+
+// shouldTouch คืนค่า true หากถึงเวลาบันทึกเวลาใช้งานล่าสุดของ session อีกครั้ง
+func (c *sessionCache) shouldTouch(id uint, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[id]
+	if !ok {
+		entry = &sessionCacheEntry{}
+		c.entries[id] = entry
+	}
+	if now.Sub(entry.touchedAt) < sessionTouchInterval {
+		return false
+	}
+	entry.touchedAt = now
+	return true
+}
+
+// prune ลบผลตรวจที่หมดอายุ session ที่ถูกเพิกถอนเก็บไว้จนกว่า access token ของมันจะหมดอายุแน่นอน
+func (c *sessionCache) prune(now time.Time, revokedTTL time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, entry := range c.entries {
+		ttl := sessionCacheTTL
+		if entry.revoked {
+			ttl = revokedTTL
+		}
+		if now.Sub(entry.checkedAt) > ttl && now.Sub(entry.touchedAt) > sessionTouchInterval {
+			delete(c.entries, id)
+		}
+	}
+}
+
+// SessionService จัดการ session การเข้าสู่ระบบและการเพิกถอน
+type SessionService struct {
+	sessionRepo *repositories.SessionRepository
+	refreshRepo *repositories.RefreshTokenRepository
+	cache       *sessionCache
+	revokedTTL  time.Duration
+}
+
+// NewSessionService สร้าง instance ใหม่ของ SessionService
+// revokedTTL ควรยาวอย่างน้อยเท่าอายุของ access token
+func NewSessionService(
+	sessionRepo *repositories.SessionRepository,
+	refreshRepo *repositories.RefreshTokenRepository,
+	revokedTTL time.Duration,
+) *SessionService {
+	return &SessionService{
+		sessionRepo: sessionRepo,
+		refreshRepo: refreshRepo,
+		cache:       newSessionCache(),
+		revokedTTL:  revokedTTL,
+	}
+}
+
+// CreateSession สร้าง session ใหม่สำหรับ family ของ refresh token
+func (s *SessionService) CreateSession(userID uint, familyID string, info SessionInfo) (*models.Session, error) {
+	now := time.Now()
+	s.cache.prune(now, s.revokedTTL)
+
+	session := &models.Session{
+		UserID:     userID,
+		FamilyID:   familyID,
+		Device:     describeDevice(info.UserAgent),
+		IP:         info.IP,
+		UserAgent:  info.UserAgent,
+		LastSeenAt: now,
+	}
+	if err := s.sessionRepo.CreateSession(session); err != nil {
+		return nil, err
+	}
+	s.cache.set(session.ID, userID, false, now)
+	return session, nil
+}
+
+// GetSessionByFamilyID ดึง session ของ family ของ refresh token
+func (s *SessionService) GetSessionByFamilyID(familyID string) (*models.Session, error) {
+	return s.sessionRepo.GetSessionByFamilyID(familyID)
+}
+
+// ValidateSession ตรวจว่า session ยังไม่ถูกเพิกถอนและเป็นของผู้ใช้ที่ระบุ
+func (s *SessionService) ValidateSession(sessionID, userID uint) error {
+	if sessionID == 0 {
+		return ErrSessionRevoked
+	}
+
+	now := time.Now()
+	entry, ok := s.cache.get(sessionID, now)
+	if !ok {
+		session, err := s.sessionRepo.GetSessionByID(sessionID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrSessionRevoked
+			}
+			return err
+		}
+		entry = sessionCacheEntry{userID: session.UserID, revoked: session.RevokedAt != nil}
+		s.cache.set(sessionID, session.UserID, entry.revoked, now)
+	}
+
+	if entry.revoked || entry.userID != userID {
+		return ErrSessionRevoked
+	}
+	return nil
+}
+
+// Touch บันทึกเวลาใช้งานล่าสุดและ IP ของ session โดยเขียนลงฐานข้อมูลไม่บ่อยกว่า sessionTouchInterval
+func (s *SessionService) Touch(sessionID uint, ip string) error {
+	now := time.Now()
+	if !s.cache.shouldTouch(sessionID, now) {
+		return nil
+	}
+	return s.sessionRepo.TouchSession(sessionID, ip, now)
+}
+
+// ListSessions ดึง session ที่ยังใช้งานอยู่ของผู้ใช้ โดยระบุว่า session ใดเป็นของ request ปัจจุบัน
+func (s *SessionService) ListSessions(userID, currentSessionID uint) ([]dto.SessionResponse, error) {
+	sessions, err := s.sessionRepo.GetActiveSessionsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, dto.SessionResponse{
+			ID:         session.ID,
+			Device:     session.Device,
+			IP:         session.IP,
+			UserAgent:  session.UserAgent,
+			LastSeenAt: session.LastSeenAt,
+			CreatedAt:  session.CreatedAt,
+			Current:    session.ID == currentSessionID,
+		})
+	}
+	return result, nil
+}
+
+// RevokeSession เพิกถอน session ของผู้ใช้พร้อม refresh token ทั้ง family
+func (s *SessionService) RevokeSession(sessionID, userID uint) error {
+	session, err := s.sessionRepo.GetSessionByID(sessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID {
+		return gorm.ErrRecordNotFound
+	}
+	return s.revoke(session)
+}
+
+// RevokeFamily เพิกถอน session และ refresh token ทั้งหมดของ family
+func (s *SessionService) RevokeFamily(familyID string) error {
+	session, err := s.sessionRepo.GetSessionByFamilyID(familyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// family ที่ออกก่อนมีตาราง sessions
+			return s.refreshRepo.RevokeFamily(familyID)
+		}
+		return err
+	}
+	return s.revoke(session)
+}
+
+// RevokeAllForUser เพิกถอน session และ refresh token ทั้งหมดของผู้ใช้
+func (s *SessionService) RevokeAllForUser(userID uint) error {
+	ids, err := s.sessionRepo.GetActiveSessionIDsByUserID(userID)
+	if err != nil {
+		return err
+	}
+	if err := s.sessionRepo.RevokeAllForUser(userID); err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, id := range ids {
+		s.cache.set(id, userID, true, now)
+	}
+	return s.refreshRepo.RevokeAllForUser(userID)
+}
+
+// revoke เพิกถอน session และทำให้ access token ของมันใช้ไม่ได้ทันทีใน process นี้
+func (s *SessionService) revoke(session *models.Session) error {
+	if err := s.sessionRepo.RevokeSession(session.ID); err != nil {
+		return err
+	}
+	s.cache.set(session.ID, session.UserID, true, time.Now())
+	return s.refreshRepo.RevokeFamily(session.FamilyID)
+}
+
+// describeDevice สรุปชื่ออุปกรณ์อย่างคร่าว ๆ จาก User-Agent เช่น "Chrome on Windows"
+func describeDevice(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	if ua == "" {
+		return "Unknown device"
+	}
+
+	browser := "Unknown browser"
+	switch {
+	case strings.Contains(ua, "edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "opr/") || strings.Contains(ua, "opera"):
+		browser = "Opera"
+	case strings.Contains(ua, "firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "chrome/") || strings.Contains(ua, "crios/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "safari/"):
+		browser = "Safari"
+	}
+
+	platform := "Unknown OS"
+	switch {
+	case strings.Contains(ua, "android"):
+		platform = "Android"
+	case strings.Contains(ua, "iphone") || strings.Contains(ua, "ipad"):
+		platform = "iOS"
+	case strings.Contains(ua, "windows"):
+		platform = "Windows"
+	case strings.Contains(ua, "mac os"):
+		platform = "macOS"
+	case strings.Contains(ua, "linux"):
+		platform = "Linux"
+	}
+
+	return fmt.Sprintf("%s on %s", browser, platform)
+}
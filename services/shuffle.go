@@ -0,0 +1,55 @@
+package services
+
+import (
+	"math/rand"
+
+	models "github.com/patiphanak/league-of-quiz/model"
+)
+
+// โหมดการสุ่มลำดับของเกม
+const (
+	ShuffleRoom   = "room"   // สุ่มครั้งเดียวใช้ทั้งห้อง
+	ShufflePlayer = "player" // สุ่มแยกสำหรับผู้เล่นแต่ละคน
+)
+
+// shuffleSeedFor คืนค่า seed ที่ใช้สุ่มสำหรับผู้เล่น
+// โหมด room ทุกคนใช้ seed เดียวกัน ส่วนโหมด player จะผสม userID เข้าไปด้วย
+func shuffleSeedFor(session *models.GameSession, userID uint) int64 {
+	if session.ShuffleMode == ShufflePlayer {
+		return session.ShuffleSeed + int64(userID)*1_000_003
+	}
+	return session.ShuffleSeed
+}
+
+// arrangeQuestions จัดลำดับคำถามและตัวเลือกตามที่ผู้เล่นเห็นจริงในเกม
+// ผลลัพธ์คำนวณจาก seed ที่เก็บใน GameSession จึงสร้างซ้ำได้ทุกครั้ง
+func arrangeQuestions(session *models.GameSession, userID uint, questions []models.Question) []models.Question {
+	if !session.ShuffleQuestions && !session.ShuffleChoices {
+		return questions
+	}
+
+	seed := shuffleSeedFor(session, userID)
+	arranged := make([]models.Question, len(questions))
+	copy(arranged, questions)
+
+	if session.ShuffleQuestions {
+		for i, j := range rand.New(rand.NewSource(seed)).Perm(len(questions)) {
+			arranged[i] = questions[j]
+		}
+	}
+
+	if session.ShuffleChoices {
+		for i := range arranged {
+			choices := arranged[i].Choices
+			shuffled := make([]models.Choice, len(choices))
+			// ใช้ ID ของคำถามผสมกับ seed เพื่อให้แต่ละข้อสุ่มไม่เหมือนกัน
+			perm := rand.New(rand.NewSource(seed + int64(arranged[i].ID))).Perm(len(choices))
+			for k, j := range perm {
+				shuffled[k] = choices[j]
+			}
+			arranged[i].Choices = shuffled
+		}
+	}
+
+	return arranged
+}
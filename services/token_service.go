@@ -0,0 +1,186 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/patiphanak/league-of-quiz/auth/jwt"
+	models "github.com/patiphanak/league-of-quiz/model"
+	"github.com/patiphanak/league-of-quiz/repositories"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+	ErrAccountDisabled     = errors.New("account disabled")
+)
+
+// AuthTokens access token และ refresh token ที่ออกให้ผู้ใช้
+type AuthTokens struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// TokenService สำหรับออกและหมุน token ของผู้ใช้
+type TokenService struct {
+	refreshRepo    *repositories.RefreshTokenRepository
+	userRepo       *repositories.UserRepository
+	sessionService *SessionService
+	jwtService     *jwt.JWTService
+}
+
+// NewTokenService สร้าง instance ใหม่ของ TokenService
+func NewTokenService(
+	refreshRepo *repositories.RefreshTokenRepository,
+	userRepo *repositories.UserRepository,
+	sessionService *SessionService,
+	jwtService *jwt.JWTService,
+) *TokenService {
+	return &TokenService{
+		refreshRepo:    refreshRepo,
+		userRepo:       userRepo,
+		sessionService: sessionService,
+		jwtService:     jwtService,
+	}
+}
+
+// IssueTokens ออก access token และ refresh token ชุดใหม่เมื่อผู้ใช้เข้าสู่ระบบ (เริ่ม family และ session ใหม่)
+func (s *TokenService) IssueTokens(user *models.User, info SessionInfo) (*AuthTokens, error) {
+	// ถือโอกาสลบ refresh token ที่หมดอายุแล้ว
+	if err := s.refreshRepo.DeleteExpired(time.Now()); err != nil {
+		log.Printf("Failed to delete expired refresh tokens: %v", err)
+	}
+
+	refreshToken, hash, err := s.jwtService.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	familyID := uuid.New().String()
+	session, err := s.sessionService.CreateSession(user.ID, familyID, info)
+	if err != nil {
+		return nil, err
+	}
+	record := &models.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(jwt.RefreshTokenTTL),
+	}
+	if err := s.refreshRepo.CreateRefreshToken(record); err != nil {
+		return nil, err
+	}
+
+	accessToken, err := s.jwtService.GenerateToken(user, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &AuthTokens{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// Refresh หมุน refresh token: เพิกถอนตัวเดิมและออก token ชุดใหม่ใน family เดียวกัน
+// ถ้า token ที่ส่งมาถูกหมุนหรือเพิกถอนไปแล้ว ถือว่าถูกขโมยไปใช้ซ้ำ และจะเพิกถอนทั้ง family
+func (s *TokenService) Refresh(refreshToken string, info SessionInfo) (*AuthTokens, error) {
+	if refreshToken == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	current, err := s.refreshRepo.GetRefreshTokenByHash(s.jwtService.HashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	if current.RevokedAt != nil {
+		return nil, s.revokeReusedFamily(current)
+	}
+	if !current.IsActive(time.Now()) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.userRepo.GetUserByID(current.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if user.IsDisabled() {
+		_ = s.sessionService.RevokeFamily(current.FamilyID)
+		return nil, ErrAccountDisabled
+	}
+
+	session, err := s.sessionService.GetSessionByFamilyID(current.FamilyID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// family ที่ออกก่อนมีตาราง sessions จะได้ session ใหม่ในการหมุนครั้งแรก
+		session, err = s.sessionService.CreateSession(user.ID, current.FamilyID, info)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if session.RevokedAt != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	nextToken, hash, err := s.jwtService.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	next := &models.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  current.FamilyID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(jwt.RefreshTokenTTL),
+	}
+	if err := s.refreshRepo.RotateRefreshToken(current, next); err != nil {
+		// token ถูกหมุนไปแล้วโดย request อื่นระหว่างนี้
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, s.revokeReusedFamily(current)
+		}
+		return nil, err
+	}
+
+	if err := s.sessionService.Touch(session.ID, info.IP); err != nil {
+		log.Printf("Failed to update session %d: %v", session.ID, err)
+	}
+
+	accessToken, err := s.jwtService.GenerateToken(user, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &AuthTokens{AccessToken: accessToken, RefreshToken: nextToken}, nil
+}
+
+// Revoke เพิกถอน session ของ refresh token และ token อื่นใน family เดียวกันเมื่อผู้ใช้ออกจากระบบ
+func (s *TokenService) Revoke(refreshToken string) error {
+	if refreshToken == "" {
+		return nil
+	}
+	current, err := s.refreshRepo.GetRefreshTokenByHash(s.jwtService.HashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	return s.sessionService.RevokeFamily(current.FamilyID)
+}
+
+// RevokeAllForUser เพิกถอน session และ refresh token ทั้งหมดของผู้ใช้
+func (s *TokenService) RevokeAllForUser(userID uint) error {
+	return s.sessionService.RevokeAllForUser(userID)
+}
+
+// revokeReusedFamily เพิกถอนทั้ง family เมื่อพบการใช้ refresh token ซ้ำ
+func (s *TokenService) revokeReusedFamily(token *models.RefreshToken) error {
+	log.Printf("Refresh token reuse detected for user %d, revoking family %s", token.UserID, token.FamilyID)
+	if err := s.sessionService.RevokeFamily(token.FamilyID); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"mime/multipart"
+
+	models "github.com/patiphanak/league-of-quiz/model"
+	"github.com/patiphanak/league-of-quiz/repositories"
+	"gorm.io/gorm"
+)
+
+// UploadService จัดการไฟล์ที่อัปโหลดผ่าน route อัปโหลดทั่วไป พร้อมบันทึกผู้อัปโหลด
+type UploadService struct {
+	fileService *FileService
+	uploadRepo  *repositories.UploadedFileRepository
+}
+
+// NewUploadService สร้าง instance ใหม่ของ UploadService
+func NewUploadService(fileService *FileService, uploadRepo *repositories.UploadedFileRepository) *UploadService {
+	return &UploadService{
+		fileService: fileService,
+		uploadRepo:  uploadRepo,
+	}
+}
+
+// storagePath แปลง URL ของไฟล์เป็น path ภายใต้ /storage ที่ใช้เป็น key ของบันทึกผู้อัปโหลด
+func (s *UploadService) storagePath(fileURL string) (string, error) {
+	filename, fileType, err := s.fileService.ExtractInfoFromURL(fileURL)
+	if err != nil {
+		return "", err
+	}
+	return "/storage/" + fileType + "/" + filename, nil
+}
+
+// UploadFile อัปโหลดไฟล์และบันทึกผู้อัปโหลด
+// ถ้ามี oldFileURL จะลบไฟล์เดิมเฉพาะเมื่อผู้ใช้เป็นผู้อัปโหลดไฟล์นั้นเอง
+func (s *UploadService) UploadFile(file *multipart.FileHeader, fileType string, oldFileURL string, userID uint) (string, error) {
+	fileURL, err := s.fileService.UploadFile(file, fileType)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := s.storagePath(fileURL)
+	if err == nil {
+		err = s.uploadRepo.CreateUploadedFile(&models.UploadedFile{Path: path, UploaderID: userID})
+	}
+	if err != nil {
+		_ = s.fileService.DeleteFileByURL(fileURL)
+		return "", err
+	}
+
+	if oldFileURL != "" {
+		if err := s.deleteOwnFile(oldFileURL, userID); err != nil {
+			log.Printf("WARNING: Failed to delete old file: %v", err)
+		}
+	}
+	return fileURL, nil
+}
+
+// deleteOwnFile ลบไฟล์ที่ผู้ใช้อัปโหลดเอง ไฟล์ของผู้อื่นหรือไฟล์ที่ไม่มีบันทึกผู้อัปโหลดจะถูกข้าม
+func (s *UploadService) deleteOwnFile(fileURL string, userID uint) error {
+	path, err := s.storagePath(fileURL)
+	if err != nil {
+		return err
+	}
+	uploaderID, err := s.uploadRepo.GetUploaderID(path)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && uploaderID != userID) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.fileService.DeleteFileByURL(fileURL); err != nil {
+		return err
+	}
+	return s.uploadRepo.DeleteByPath(path)
+}
+
+// DeleteFile ลบไฟล์และบันทึกผู้อัปโหลด ผู้เรียกต้องตรวจสิทธิ์ก่อน (ดู Policy.RequireFileAccess)
+func (s *UploadService) DeleteFile(filename string, fileType string) error {
+	if err := s.fileService.DeleteFile(filename, fileType); err != nil {
+		return err
+	}
+	return s.uploadRepo.DeleteByPath("/storage/" + fileType + "/" + filename)
+}
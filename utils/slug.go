@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Slugify แปลงข้อความเป็น slug ตัวพิมพ์เล็กคั่นด้วยขีด เก็บตัวอักษรทุกภาษาและตัวเลขไว้
+// เช่น "Science & Physics" เป็น "science-physics"
+func Slugify(text string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(text)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
@@ -5,14 +5,20 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	models "github.com/patiphanak/league-of-quiz/model"
 	"github.com/patiphanak/league-of-quiz/services"
 )
 
+// AuthenticatedUserHeader header ที่ route ของ /ws ตั้งเป็น ID ของผู้ใช้ที่ผ่าน AuthMiddleware แล้ว
+// ค่านี้ถูกเขียนทับฝั่ง server เสมอ client จึงปลอมไม่ได้
+const AuthenticatedUserHeader = "X-Authenticated-User-Id"
+
 // EventType กำหนดประเภทของเหตุการณ์ WebSocket
 type EventType string
 
@@ -25,6 +31,15 @@ const (
 	EventQuestionEnded   EventType = "question_ended"
 	EventGameEnded       EventType = "game_ended"
 	EventChatMessage     EventType = "chat_message"
+	EventJoinPending     EventType = "join_pending"
+	EventJoinRequested   EventType = "join_requested"
+	EventJoinApproved    EventType = "join_approved"
+	EventJoinDenied      EventType = "join_denied"
+	EventLobbyLocked     EventType = "lobby_locked"
+	EventLobbyUnlocked   EventType = "lobby_unlocked"
+	EventTeamsUpdated    EventType = "teams_updated"
+	EventTeamLeaderboard EventType = "team_leaderboard"
+	EventTeamChat        EventType = "team_chat"
 )
 
 // Message แทนข้อความ WebSocket
@@ -39,23 +54,40 @@ type ClientMessage struct {
 	Payload json.RawMessage `json:"payload"`
 }
 
+// ผู้ใช้ของทุก action มาจากการเชื่อมต่อที่ยืนยันตัวตนแล้ว ไม่ได้มาจาก payload
+
 // JoinSessionPayload แทนข้อมูลที่ใช้ในการเข้าร่วมเกม
 type JoinSessionPayload struct {
 	SessionID string `json:"sessionId"`
-	UserID    uint   `json:"userId"`
 	Nickname  string `json:"nickname"`
+	Password  string `json:"password"`
 }
 
 // GameActionPayload แทนข้อมูลที่ใช้ในการควบคุมเกม
 type GameActionPayload struct {
 	SessionID string `json:"sessionId"`
-	UserID    uint   `json:"userId"`
+}
+
+// JoinDecisionPayload แทนข้อมูลที่โฮสต์ใช้อนุมัติหรือปฏิเสธคำขอเข้าร่วม
+type JoinDecisionPayload struct {
+	SessionID   string `json:"sessionId"`
+	RequesterID uint   `json:"requesterId"`
+}
+
+// TeamActionPayload แทนข้อมูลที่โฮสต์ใช้จัดการทีมในล็อบบี้
+// ใช้ Name กับ create_team, TeamID กับ delete_team, PlayerID และ TeamID กับ assign_team (TeamID 0 = นำออกจากทีม)
+// และ TeamCount กับ auto_balance_teams
+type TeamActionPayload struct {
+	SessionID string `json:"sessionId"`
+	TeamID    uint   `json:"teamId"`
+	PlayerID  uint   `json:"playerId"`
+	Name      string `json:"name"`
+	TeamCount int    `json:"teamCount"`
 }
 
 // SubmitAnswerPayload แทนข้อมูลที่ใช้ในการส่งคำตอบ
 type SubmitAnswerPayload struct {
 	SessionID  string  `json:"sessionId"`
-	UserID     uint    `json:"userId"`
 	QuestionID uint    `json:"questionId"`
 	ChoiceID   uint    `json:"choiceId"`
 	TimeSpent  float64 `json:"timeSpent"`
@@ -64,7 +96,6 @@ type SubmitAnswerPayload struct {
 // ChatMessagePayload แทนข้อมูลข้อความแชท
 type ChatMessagePayload struct {
 	SessionID string `json:"sessionId"`
-	UserID    uint   `json:"userId"`
 	Message   string `json:"message"`
 }
 
@@ -85,6 +116,7 @@ var upgrader = websocket.Upgrader{
 type Manager struct {
 	gameService *services.GameService
 	sessions    map[string]map[string]*websocket.Conn // sessionID -> connID -> conn
+	pending     map[string]map[string]*websocket.Conn // sessionID -> connID -> conn ที่รอโฮสต์อนุมัติ
 	users       map[string]uint                      // connID -> userID
 	mu          sync.RWMutex
 }
@@ -95,11 +127,16 @@ func NewManager(gameService *services.GameService) (*Manager, error) {
 		return nil, fmt.Errorf("gameService cannot be nil")
 	}
 	
-	return &Manager{
+	m := &Manager{
 		gameService: gameService,
 		sessions:    make(map[string]map[string]*websocket.Conn),
+		pending:     make(map[string]map[string]*websocket.Conn),
 		users:       make(map[string]uint),
-	}, nil
+	}
+
+	// รับคำขอเข้าร่วมที่ต้องรออนุมัติ ทั้งจาก REST และ WebSocket
+	gameService.SetJoinEventListener(m)
+	return m, nil
 }
 
 // generateConnID สร้าง ID ที่ไม่ซ้ำกันสำหรับการเชื่อมต่อ
@@ -116,6 +153,12 @@ func (m *Manager) HandleHTTP() http.Handler {
 
 // HandleWebSocket จัดการการเชื่อมต่อ WebSocket ใหม่
 func (m *Manager) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseUint(r.Header.Get(AuthenticatedUserHeader), 10, 32)
+	if err != nil || userID == 0 {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("Error upgrading to WebSocket: %v", err)
@@ -123,7 +166,11 @@ func (m *Manager) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	}
 	
 	connID := generateConnID()
-	log.Printf("Client connected: %s", connID)
+	log.Printf("Client connected: %s (user %d)", connID, userID)
+
+	m.mu.Lock()
+	m.users[connID] = uint(userID)
+	m.mu.Unlock()
 	
 	// เริ่มต้นอ่านข้อความจากการเชื่อมต่อ
 	go m.readPump(conn, connID)
@@ -131,6 +178,10 @@ func (m *Manager) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 
 // readPump อ่านข้อความจากการเชื่อมต่อ WebSocket และจัดการข้อความ
 func (m *Manager) readPump(conn *websocket.Conn, connID string) {
+	m.mu.RLock()
+	userID := m.users[connID]
+	m.mu.RUnlock()
+
 	defer func() {
 		m.handleDisconnect(connID)
 		conn.Close()
@@ -174,7 +225,7 @@ func (m *Manager) readPump(conn *websocket.Conn, connID string) {
 				m.sendError(conn, "Invalid join_session payload")
 				continue
 			}
-			m.handleJoinSession(conn, connID, payload.SessionID, payload.UserID, payload.Nickname)
+			m.handleJoinSession(conn, connID, payload.SessionID, userID, payload.Nickname, payload.Password)
 			
 		case "start_game":
 			var payload GameActionPayload
@@ -182,7 +233,7 @@ func (m *Manager) readPump(conn *websocket.Conn, connID string) {
 				m.sendError(conn, "Invalid start_game payload")
 				continue
 			}
-			m.handleStartGame(conn, connID, payload.SessionID, payload.UserID)
+			m.handleStartGame(conn, connID, payload.SessionID, userID)
 			
 		case "submit_answer":
 			var payload SubmitAnswerPayload
@@ -190,7 +241,7 @@ func (m *Manager) readPump(conn *websocket.Conn, connID string) {
 				m.sendError(conn, "Invalid submit_answer payload")
 				continue
 			}
-			m.handleSubmitAnswer(conn, connID, payload.SessionID, payload.UserID, 
+			m.handleSubmitAnswer(conn, connID, payload.SessionID, userID, 
 				payload.QuestionID, payload.ChoiceID, payload.TimeSpent)
 			
 		case "end_game":
@@ -199,7 +250,23 @@ func (m *Manager) readPump(conn *websocket.Conn, connID string) {
 				m.sendError(conn, "Invalid end_game payload")
 				continue
 			}
-			m.handleEndGame(conn, connID, payload.SessionID, payload.UserID)
+			m.handleEndGame(conn, connID, payload.SessionID, userID)
+			
+		case "next_question":
+			var payload GameActionPayload
+			if err := json.Unmarshal(message.Payload, &payload); err != nil {
+				m.sendError(conn, "Invalid next_question payload")
+				continue
+			}
+			m.handleNextQuestion(conn, connID, payload.SessionID, userID)
+			
+		case "end_question":
+			var payload GameActionPayload
+			if err := json.Unmarshal(message.Payload, &payload); err != nil {
+				m.sendError(conn, "Invalid end_question payload")
+				continue
+			}
+			m.handleEndQuestion(conn, connID, payload.SessionID, userID)
 			
 		case "chat_message":
 			var payload ChatMessagePayload
@@ -207,7 +274,51 @@ func (m *Manager) readPump(conn *websocket.Conn, connID string) {
 				m.sendError(conn, "Invalid chat_message payload")
 				continue
 			}
-			m.handleChatMessage(conn, connID, payload.SessionID, payload.UserID, payload.Message)
+			m.handleChatMessage(conn, connID, payload.SessionID, userID, payload.Message)
+
+		case "lock_lobby", "unlock_lobby":
+			var payload GameActionPayload
+			if err := json.Unmarshal(message.Payload, &payload); err != nil {
+				m.sendError(conn, "Invalid "+message.Action+" payload")
+				continue
+			}
+			m.handleLobbyLock(conn, connID, payload.SessionID, userID, message.Action == "lock_lobby")
+
+		case "create_team", "delete_team", "assign_team", "auto_balance_teams":
+			var payload TeamActionPayload
+			if err := json.Unmarshal(message.Payload, &payload); err != nil {
+				m.sendError(conn, "Invalid "+message.Action+" payload")
+				continue
+			}
+			m.handleTeamAction(conn, connID, message.Action, userID, payload)
+
+		case "team_chat":
+			var payload ChatMessagePayload
+			if err := json.Unmarshal(message.Payload, &payload); err != nil {
+				m.sendError(conn, "Invalid team_chat payload")
+				continue
+			}
+			m.handleTeamChat(conn, connID, payload.SessionID, userID, payload.Message)
+
+		case "approve_join":
+			var payload JoinDecisionPayload
+			if err := json.Unmarshal(message.Payload, &payload); err != nil {
+				m.sendError(conn, "Invalid approve_join payload")
+				continue
+			}
+			if _, err := m.gameService.ApproveJoinRequest(payload.SessionID, userID, payload.RequesterID); err != nil {
+				m.sendError(conn, "Cannot approve join request: "+err.Error())
+			}
+
+		case "deny_join":
+			var payload JoinDecisionPayload
+			if err := json.Unmarshal(message.Payload, &payload); err != nil {
+				m.sendError(conn, "Invalid deny_join payload")
+				continue
+			}
+			if err := m.gameService.DenyJoinRequest(payload.SessionID, userID, payload.RequesterID); err != nil {
+				m.sendError(conn, "Cannot deny join request: "+err.Error())
+			}
 			
 		default:
 			m.sendError(conn, "Unknown action: "+message.Action)
@@ -288,22 +399,35 @@ func (m *Manager) BroadcastToSessionExcept(sessionID string, exceptConnID string
 }
 
 // handleJoinSession จัดการการเข้าร่วมเกมของผู้เล่น
-func (m *Manager) handleJoinSession(conn *websocket.Conn, connID string, sessionID string, userID uint, nickname string) {
-	// เพิ่มผู้เล่นลงในห้อง
-	m.mu.Lock()
-	if _, exists := m.sessions[sessionID]; !exists {
-		m.sessions[sessionID] = make(map[string]*websocket.Conn)
-	}
-	m.sessions[sessionID][connID] = conn
-	m.users[connID] = userID
-	m.mu.Unlock()
-	
+// การเชื่อมต่อจะได้รับข้อความของห้องก็ต่อเมื่อเข้าร่วมสำเร็จแล้วเท่านั้น
+func (m *Manager) handleJoinSession(conn *websocket.Conn, connID string, sessionID string, userID uint, nickname string, password string) {
 	// เข้าร่วมเกมผ่าน service
-	player, err := m.gameService.JoinGameSession(sessionID, userID, nickname)
+	player, request, err := m.gameService.JoinGameSession(sessionID, userID, nickname, password)
 	if err != nil {
 		m.sendError(conn, "Cannot join game: "+err.Error())
 		return
 	}
+
+	// เกมที่ต้องให้โฮสต์อนุมัติ เก็บการเชื่อมต่อไว้รอจนกว่าโฮสต์จะตัดสิน
+	if request != nil {
+		m.mu.Lock()
+		addConn(m.pending, sessionID, connID, conn)
+		m.mu.Unlock()
+
+		m.sendMessage(conn, Message{
+			Type: EventJoinPending,
+			Payload: map[string]interface{}{
+				"sessionId":   sessionID,
+				"joinRequest": request,
+			},
+		})
+		return
+	}
+
+	// เพิ่มผู้เล่นลงในห้อง
+	m.mu.Lock()
+	addConn(m.sessions, sessionID, connID, conn)
+	m.mu.Unlock()
 	
 	// ดึงข้อมูลเกม
 	session, _ := m.gameService.GetGameSession(sessionID)
@@ -327,6 +451,29 @@ func (m *Manager) handleJoinSession(conn *websocket.Conn, connID string, session
 	})
 }
 
+// handleLobbyLock จัดการการล็อกและปลดล็อกล็อบบี้
+func (m *Manager) handleLobbyLock(conn *websocket.Conn, _ string, sessionID string, hostID uint, locked bool) {
+	session, err := m.gameService.SetLobbyLocked(sessionID, hostID, locked)
+	if err != nil {
+		m.sendError(conn, "Cannot change lobby lock: "+err.Error())
+		return
+	}
+
+	eventType := EventLobbyUnlocked
+	if locked {
+		eventType = EventLobbyLocked
+	}
+
+	// แจ้งผู้เล่นทั้งหมดในห้อง
+	m.BroadcastToSession(sessionID, Message{
+		Type: eventType,
+		Payload: map[string]interface{}{
+			"sessionId":  sessionID,
+			"maxPlayers": session.MaxPlayers,
+		},
+	})
+}
+
 // handleStartGame จัดการการเริ่มเกม
 func (m *Manager) handleStartGame(conn *websocket.Conn, _ string, sessionID string, hostID uint) {
 	err := m.gameService.StartGameSession(sessionID, hostID)
@@ -385,17 +532,210 @@ func (m *Manager) handleEndGame(conn *websocket.Conn, _ string, sessionID string
 	// ดึงรายชื่อผู้เล่น
 	players, _ := m.gameService.GetPlayersBySessionID(sessionID)
 	
+	payload := map[string]interface{}{
+		"sessionId": sessionID,
+		"session":   session,
+		"players":   players,
+	}
+	// เกมโหมดทีมส่งอันดับของทีมไปด้วย
+	if session.TeamMode {
+		if teams, err := m.gameService.GetTeamLeaderboard(sessionID); err == nil {
+			payload["teams"] = teams
+		}
+	}
+
 	// ส่งข้อความไปยังผู้เล่นทั้งหมดว่าเกมจบแล้ว
 	m.BroadcastToSession(sessionID, Message{
-		Type: EventGameEnded,
+		Type:    EventGameEnded,
+		Payload: payload,
+	})
+}
+
+// BroadcastToSessionPerUser ส่งข้อความที่สร้างแยกตามผู้ใช้ของแต่ละการเชื่อมต่อในห้อง
+// ใช้เมื่อผู้เล่นแต่ละคนเห็นข้อมูลไม่เหมือนกัน เช่น เกมที่สุ่มลำดับแยกรายคน
+// build ถูกเรียกหลังปล่อย lock แล้ว จึงไม่บล็อกการเชื่อมต่อและการส่งข้อความอื่นระหว่างสร้างข้อความ
+func (m *Manager) BroadcastToSessionPerUser(sessionID string, build func(userID uint) (Message, error)) {
+	type recipient struct {
+		connID string
+		conn   *websocket.Conn
+		userID uint
+	}
+
+	// เก็บสำเนาการเชื่อมต่อในห้องไว้ก่อน แล้วจึงปล่อย lock
+	m.mu.RLock()
+	sessionConns, exists := m.sessions[sessionID]
+	recipients := make([]recipient, 0, len(sessionConns))
+	for connID, conn := range sessionConns {
+		recipients = append(recipients, recipient{connID: connID, conn: conn, userID: m.users[connID]})
+	}
+	m.mu.RUnlock()
+
+	if !exists {
+		log.Printf("Cannot broadcast to session %s: session not found", sessionID)
+		return
+	}
+
+	for _, r := range recipients {
+		message, err := build(r.userID)
+		if err != nil {
+			log.Printf("Error building message for %s: %v", r.connID, err)
+			continue
+		}
+		if err := m.sendMessage(r.conn, message); err != nil {
+			log.Printf("Error sending message: %v", err)
+		}
+	}
+}
+
+// handleNextQuestion จัดการการเริ่มคำถามถัดไป
+func (m *Manager) handleNextQuestion(conn *websocket.Conn, _ string, sessionID string, hostID uint) {
+	session, err := m.gameService.NextQuestion(sessionID, hostID)
+	if err != nil {
+		m.sendError(conn, "Cannot start question: "+err.Error())
+		return
+	}
+
+	// โหลดคำถามของเกมครั้งเดียวสำหรับผู้เล่นทุกคน
+	questions, err := m.gameService.GetSessionQuestions(session)
+	if err != nil {
+		m.sendError(conn, "Cannot start question: "+err.Error())
+		return
+	}
+
+	// ส่งคำถามไปยังผู้เล่นแต่ละคนตามลำดับที่สุ่มไว้ (ไม่มีเฉลยและคำอธิบาย)
+	m.BroadcastToSessionPerUser(sessionID, func(userID uint) (Message, error) {
+		question, err := m.gameService.GetPlayerQuestion(session, questions, userID)
+		if err != nil {
+			return Message{}, err
+		}
+		return Message{
+			Type: EventQuestionStarted,
+			Payload: map[string]interface{}{
+				"sessionId": sessionID,
+				"question":  question,
+			},
+		}, nil
+	})
+}
+
+// handleEndQuestion จัดการการจบคำถามปัจจุบันและเฉลยคำตอบ
+func (m *Manager) handleEndQuestion(conn *websocket.Conn, _ string, sessionID string, hostID uint) {
+	session, err := m.gameService.EndQuestion(sessionID, hostID)
+	if err != nil {
+		m.sendError(conn, "Cannot end question: "+err.Error())
+		return
+	}
+
+	// โหลดคำถามของเกมครั้งเดียวสำหรับผู้เล่นทุกคน
+	questions, err := m.gameService.GetSessionQuestions(session)
+	if err != nil {
+		m.sendError(conn, "Cannot end question: "+err.Error())
+		return
+	}
+
+	// ส่งเฉลยพร้อมคำอธิบายไปยังผู้เล่นแต่ละคน
+	m.BroadcastToSessionPerUser(sessionID, func(userID uint) (Message, error) {
+		reveal, err := m.gameService.GetQuestionReveal(session, questions, userID)
+		if err != nil {
+			return Message{}, err
+		}
+		return Message{
+			Type: EventQuestionEnded,
+			Payload: map[string]interface{}{
+				"sessionId": sessionID,
+				"result":    reveal,
+			},
+		}, nil
+	})
+
+	// เกมโหมดทีมส่งอันดับของทีมระหว่างคำถาม
+	if session.TeamMode {
+		m.broadcastTeamLeaderboard(sessionID)
+	}
+}
+
+// broadcastTeamLeaderboard ส่งอันดับของทีมไปยังผู้เล่นทั้งหมดในห้อง
+func (m *Manager) broadcastTeamLeaderboard(sessionID string) {
+	standings, err := m.gameService.GetTeamLeaderboard(sessionID)
+	if err != nil {
+		log.Printf("Error getting team leaderboard for %s: %v", sessionID, err)
+		return
+	}
+
+	m.BroadcastToSession(sessionID, Message{
+		Type: EventTeamLeaderboard,
 		Payload: map[string]interface{}{
 			"sessionId": sessionID,
-			"session":   session,
-			"players":   players,
+			"teams":     standings,
+		},
+	})
+}
+
+// handleTeamAction จัดการการสร้าง ลบ และจัดผู้เล่นลงทีมของโฮสต์ แล้วส่งทีมล่าสุดให้ทุกคนในห้อง
+func (m *Manager) handleTeamAction(conn *websocket.Conn, _ string, action string, hostID uint, payload TeamActionPayload) {
+	var err error
+	switch action {
+	case "create_team":
+		_, err = m.gameService.CreateTeam(payload.SessionID, hostID, payload.Name)
+	case "delete_team":
+		err = m.gameService.DeleteTeam(payload.SessionID, hostID, payload.TeamID)
+	case "assign_team":
+		err = m.gameService.AssignPlayerTeam(payload.SessionID, hostID, payload.PlayerID, payload.TeamID)
+	case "auto_balance_teams":
+		err = m.gameService.AutoBalanceTeams(payload.SessionID, hostID, payload.TeamCount)
+	}
+	if err != nil {
+		m.sendError(conn, "Cannot update teams: "+err.Error())
+		return
+	}
+
+	teams, err := m.gameService.GetTeams(payload.SessionID)
+	if err != nil {
+		m.sendError(conn, "Cannot get teams: "+err.Error())
+		return
+	}
+
+	m.BroadcastToSession(payload.SessionID, Message{
+		Type: EventTeamsUpdated,
+		Payload: map[string]interface{}{
+			"sessionId": payload.SessionID,
+			"teams":     teams,
 		},
 	})
 }
 
+// handleTeamChat ส่งข้อความไปยังเพื่อนร่วมทีมในห้องเท่านั้น ใช้คุยกันระหว่างช่วงคุยกันในทีม
+func (m *Manager) handleTeamChat(conn *websocket.Conn, _ string, sessionID string, userID uint, message string) {
+	teammates, err := m.gameService.GetTeammateUserIDs(sessionID, userID)
+	if err != nil {
+		m.sendError(conn, "Cannot send team message: "+err.Error())
+		return
+	}
+	isTeammate := make(map[uint]bool, len(teammates))
+	for _, id := range teammates {
+		isTeammate[id] = true
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for connID, teammateConn := range m.sessions[sessionID] {
+		if !isTeammate[m.users[connID]] {
+			continue
+		}
+		if err := m.sendMessage(teammateConn, Message{
+			Type: EventTeamChat,
+			Payload: map[string]interface{}{
+				"sessionId": sessionID,
+				"userId":    userID,
+				"message":   message,
+			},
+		}); err != nil {
+			log.Printf("Error sending message: %v", err)
+		}
+	}
+}
+
 // handleChatMessage จัดการข้อความแชท
 func (m *Manager) handleChatMessage(_ *websocket.Conn, _ string, sessionID string, userID uint, message string) {
 	// ส่งข้อความไปยังผู้เล่นทั้งหมดในห้อง
@@ -409,10 +749,94 @@ func (m *Manager) handleChatMessage(_ *websocket.Conn, _ string, sessionID strin
 	})
 }
 
+// addConn เพิ่มการเชื่อมต่อลงในห้อง ต้องถือ m.mu ไว้ก่อนเรียก
+func addConn(rooms map[string]map[string]*websocket.Conn, sessionID string, connID string, conn *websocket.Conn) {
+	if _, exists := rooms[sessionID]; !exists {
+		rooms[sessionID] = make(map[string]*websocket.Conn)
+	}
+	rooms[sessionID][connID] = conn
+}
+
+// removeConn ลบการเชื่อมต่อออกจากห้อง และลบห้องที่ว่างแล้ว ต้องถือ m.mu ไว้ก่อนเรียก
+func removeConn(rooms map[string]map[string]*websocket.Conn, sessionID string, connID string) {
+	delete(rooms[sessionID], connID)
+	if len(rooms[sessionID]) == 0 {
+		delete(rooms, sessionID)
+	}
+}
+
+// JoinRequested ส่งคำขอเข้าร่วมไปยังการเชื่อมต่อของโฮสต์ในห้อง
+func (m *Manager) JoinRequested(request models.GameJoinRequest, hostID uint) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for connID, conn := range m.sessions[request.SessionID] {
+		if m.users[connID] != hostID {
+			continue
+		}
+		if err := m.sendMessage(conn, Message{
+			Type: EventJoinRequested,
+			Payload: map[string]interface{}{
+				"sessionId":   request.SessionID,
+				"joinRequest": request,
+			},
+		}); err != nil {
+			log.Printf("Error sending message: %v", err)
+		}
+	}
+}
+
+// JoinDecided แจ้งผลการตัดสินให้ผู้ขอ ถ้าอนุมัติจะย้ายการเชื่อมต่อเข้าห้องและแจ้งผู้เล่นทุกคน
+func (m *Manager) JoinDecided(request models.GameJoinRequest, player *models.GamePlayer) {
+	eventType := EventJoinDenied
+	if player != nil {
+		eventType = EventJoinApproved
+	}
+
+	m.mu.Lock()
+	for connID, conn := range m.pending[request.SessionID] {
+		if m.users[connID] != request.UserID {
+			continue
+		}
+		removeConn(m.pending, request.SessionID, connID)
+		if player != nil {
+			addConn(m.sessions, request.SessionID, connID, conn)
+		}
+		if err := m.sendMessage(conn, Message{
+			Type: eventType,
+			Payload: map[string]interface{}{
+				"sessionId":   request.SessionID,
+				"joinRequest": request,
+				"player":      player,
+			},
+		}); err != nil {
+			log.Printf("Error sending message: %v", err)
+		}
+	}
+	m.mu.Unlock()
+
+	if player != nil {
+		m.BroadcastToSession(request.SessionID, Message{
+			Type: EventPlayerJoined,
+			Payload: map[string]interface{}{
+				"sessionId": request.SessionID,
+				"player":    player,
+			},
+		})
+	}
+}
+
 // handleDisconnect จัดการการยกเลิกการเชื่อมต่อของผู้เล่น
 func (m *Manager) handleDisconnect(connID string) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+
+	// ลบการเชื่อมต่อที่ยังรอโฮสต์อนุมัติ
+	for sessionID, clients := range m.pending {
+		if _, exists := clients[connID]; exists {
+			removeConn(m.pending, sessionID, connID)
+		}
+	}
 	
 	// หาว่าผู้เล่นอยู่ในห้องไหน
 	var sessionIDToRemove string